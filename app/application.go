@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strconv"
@@ -21,40 +22,142 @@ const (
 
 func NewApplication(dbType string) handlers.Application {
 
-	var sessionRepo session.Repository
 	logger := logrus.NewEntry(logrus.StandardLogger())
-	var redisDb int
 
 	durStr := getEnvVar("MEMORY_DB_DURATION", fmt.Sprint(aDay))
 	dur := toInt(durStr)
 
 	duration := time.Duration(dur) * time.Second
 
-	host := getEnvVar("MEMORY_DB_HOST", "localhost")
-	port := getEnvVar("MEMORY_DB_PORT", "6379")
-	password := getEnvVar("MEMORY_DB_PASSWORD", "")
-	addr := fmt.Sprintf("%s:%s", host, port)
+	store := newSessionStore(dbType, "MEMORY_DB_", duration)
 
-	switch dbType {
-	case "redis":
-		db := getEnvVar("MEMORY_DB_ID", "0")
-		redisDb = toInt(db)
-		sessionRepo = adapters.NewRedisCache(addr, redisDb, password, duration)
+	if fromDbType := getEnvVar("MEMORY_DB_MIGRATE_FROM", ""); fromDbType != "" {
+		mirror := adapters.NewMirrorRepository(
+			newSessionStore(fromDbType, "MEMORY_DB_MIGRATE_FROM_", duration),
+			store,
+		)
+		if toBool(getEnvVar("MEMORY_DB_MIGRATE_COPY", "true")) {
+			if !mirror.CanCopyAll() {
+				panic(fmt.Sprintf("db type '%s' cannot list its sessions to copy them, set MEMORY_DB_MIGRATE_COPY=false to only copy them as they are read", fromDbType))
+			}
+			go func() {
+				if err := mirror.CopyAll(context.Background()); err != nil {
+					logger.WithError(err).Error("Failed to copy sessions from the old db")
+				}
+			}()
+		}
+		store = mirror
+	}
+
+	keyring, err := adapters.KeyringFrom(adapters.Config{Prefix: "SESSION_"})
+	if err != nil {
+		panic(err)
+	}
+	if keyring != nil {
+		encrypted := adapters.NewEncryptedRepository(store, keyring)
+		if toBool(getEnvVar("SESSION_ENCRYPTION_REENCRYPT", "false")) {
+			if !encrypted.CanReencrypt() {
+				panic(fmt.Sprintf("db type '%s' cannot list its sessions to re-encrypt them, unset SESSION_ENCRYPTION_REENCRYPT", dbType))
+			}
+			go func() {
+				if err := encrypted.Reencrypt(context.Background()); err != nil {
+					logger.WithError(err).Error("Failed to re-encrypt sessions")
+				}
+			}()
+		}
+		store = encrypted
+	}
+
+	// Sessions are compressed before being encrypted, as ciphertexts do not compress.
+	if format := getEnvVar("SESSION_COMPRESSION", ""); format != "" {
+		threshold := toInt(getEnvVar("SESSION_COMPRESSION_THRESHOLD", "1024"))
+		store = adapters.NewCompressedRepository(store, format, threshold)
+	}
+
+	if toBool(getEnvVar("SESSION_RESILIENCE", "false")) {
+		store = adapters.NewResilientRepository(store, adapters.ResilienceConfig{
+			Timeout:          time.Duration(toInt(getEnvVar("SESSION_TIMEOUT_MS", "500"))) * time.Millisecond,
+			Retries:          toInt(getEnvVar("SESSION_RETRIES", "2")),
+			Backoff:          time.Duration(toInt(getEnvVar("SESSION_RETRY_BACKOFF_MS", "50"))) * time.Millisecond,
+			MaxBackoff:       time.Duration(toInt(getEnvVar("SESSION_RETRY_MAX_BACKOFF_MS", "1000"))) * time.Millisecond,
+			BreakerThreshold: toInt(getEnvVar("SESSION_BREAKER_THRESHOLD", "5")),
+			BreakerOpen:      time.Duration(toInt(getEnvVar("SESSION_BREAKER_OPEN", "10"))) * time.Second,
+		})
+	}
+
+	if size := toInt(getEnvVar("SESSION_LOCAL_CACHE_SIZE", "0")); size > 0 {
+		invalidationConfig, err := adapters.RedisConfigFrom(adapters.Config{Prefix: "INVALIDATION_REDIS_"})
+		if err != nil {
+			panic(err)
+		}
+		channel := getEnvVar("INVALIDATION_REDIS_CHANNEL", "session-invalidations")
+		localTTL := toInt(getEnvVar("SESSION_LOCAL_CACHE_TTL", "5"))
+		store = adapters.NewTieredRepository(
+			store,
+			adapters.NewRedisInvalidator(invalidationConfig, channel),
+			size,
+			time.Duration(localTTL)*time.Second,
+		)
+	}
+
+	lifetime := session.LifetimePolicy{
+		Idle:     toBool(getEnvVar("SESSION_SLIDING_EXPIRATION", "false")),
+		Absolute: time.Duration(toInt(getEnvVar("SESSION_ABSOLUTE_LIFETIME", "0"))) * time.Second,
+	}
+
+	sessionRepo := adapters.NewSessionRepository(store, duration, lifetime)
+
+	ttlPolicy := session.TTLPolicy{
+		Max: time.Duration(toInt(getEnvVar("SESSION_MAX_TTL", "0"))) * time.Second,
+	}
+	switch maxTTLPolicy := getEnvVar("SESSION_MAX_TTL_POLICY", "reject"); maxTTLPolicy {
+	case "reject":
+	case "clamp":
+		ttlPolicy.Clamp = true
 	default:
-		panic(fmt.Sprintf("db type '%s' not supported", dbType))
+		panic(fmt.Sprintf("max ttl policy '%s' not supported", maxTTLPolicy))
 	}
 
+	idFormat, err := session.ParseIDFormat(getEnvVar("SESSION_ID_FORMAT", string(session.IDFormatRandom)))
+	if err != nil {
+		panic(err)
+	}
+	maxRotationGrace := time.Duration(toInt(getEnvVar("SESSION_MAX_ROTATION_GRACE", "60"))) * time.Second
+
 	return handlers.Application{
 		Commands: handlers.Commands{
-			DeleteSession: command.NewDeleteSessionHandler(sessionRepo, logger),
-			SetSession:    command.NewSetSessionHandler(sessionRepo, logger),
+			CreateSession:           command.NewCreateSessionHandler(sessionRepo, ttlPolicy, idFormat, logger),
+			DeleteSession:           command.NewDeleteSessionHandler(sessionRepo, logger),
+			DeleteSessionValue:      command.NewDeleteSessionValueHandler(sessionRepo, logger),
+			DeleteSubjectSessions:   command.NewDeleteSubjectSessionsHandler(sessionRepo, logger),
+			IncrementSessionCounter: command.NewIncrementSessionCounterHandler(sessionRepo, logger),
+			PatchSession:            command.NewPatchSessionHandler(sessionRepo, logger),
+			RotateSession:           command.NewRotateSessionHandler(sessionRepo, idFormat, maxRotationGrace, logger),
+			SetSession:              command.NewSetSessionHandler(sessionRepo, ttlPolicy, logger),
+			SetSessionFields:        command.NewSetSessionFieldsHandler(sessionRepo, logger),
+			SetSessionValue:         command.NewSetSessionValueHandler(sessionRepo, logger),
+			TouchSession:            command.NewTouchSessionHandler(sessionRepo, ttlPolicy, logger),
 		},
 		Queries: handlers.Queries{
-			GetSession: query.NewGetSessionHandler(sessionRepo, logger),
+			GetSession:         query.NewGetSessionHandler(sessionRepo, lifetime, logger),
+			GetSessionFields:   query.NewGetSessionFieldsHandler(sessionRepo, logger),
+			GetSessionValue:    query.NewGetSessionValueHandler(sessionRepo, lifetime, logger),
+			GetSubjectSessions: query.NewGetSubjectSessionsHandler(sessionRepo, logger),
 		},
 	}
 }
 
+// newSessionStore builds the store registered as dbType, which reads its
+// settings from the environment variables starting with prefix.
+func newSessionStore(dbType, prefix string, duration time.Duration) adapters.Store {
+
+	store, err := adapters.Open(dbType, adapters.Config{Prefix: prefix, Expires: duration})
+	if err != nil {
+		panic(err)
+	}
+	return store
+}
+
 func getEnvVar(varName, varDefaultValue string) string {
 	varValue := os.Getenv(varName)
 	if varValue == "" {
@@ -71,3 +174,11 @@ func toInt(valueStr string) int {
 	}
 	return dur
 }
+
+func toBool(valueStr string) bool {
+	b, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		panic(fmt.Errorf("error '%s' when parsing to boolean value", err))
+	}
+	return b
+}
@@ -20,6 +20,23 @@ func TestNewApplicationShouldPanicIfDBTypeNotSet(t *testing.T) {
 
 }
 
+func TestNewApplicationShouldListAvailableDBTypesIfDBTypeUnknown(t *testing.T) {
+	t.Parallel()
+
+	assert.PanicsWithError(t, "db type 'unknown' not supported, available db types are: bolt, memcached, memory, postgres, redis", func() {
+		NewApplication("unknown")
+	})
+}
+
+func TestNewApplicationShouldSupportMemoryDBType(t *testing.T) {
+	t.Parallel()
+
+	app := NewApplication("memory")
+
+	assert.NotNil(t, app.Commands.SetSession, "SetSession handler should be set")
+	assert.NotNil(t, app.Queries.GetSession, "GetSession handler should be set")
+}
+
 func TestGetEnvVarShouldReturnEnvironmentVariableValue(t *testing.T) {
 	t.Parallel()
 
@@ -91,19 +91,62 @@ func WithRequestEditorFn(fn RequestEditorFn) ClientOption {
 // The interface specification for the client above.
 type ClientInterface interface {
 	// SetSession request with any body
-	SetSessionWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+	SetSessionWithBody(ctx context.Context, params *SetSessionParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
 
-	SetSession(ctx context.Context, body SetSessionJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+	SetSession(ctx context.Context, params *SetSessionParams, body SetSessionJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
 
 	// DeleteSession request
 	DeleteSession(ctx context.Context, sessionId string, reqEditors ...RequestEditorFn) (*http.Response, error)
 
 	// GetSession request
-	GetSession(ctx context.Context, sessionId string, reqEditors ...RequestEditorFn) (*http.Response, error)
+	GetSession(ctx context.Context, sessionId string, params *GetSessionParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// PatchSession request with any body
+	PatchSessionWithBody(ctx context.Context, sessionId string, params *PatchSessionParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// IncrementSessionCounter request with any body
+	IncrementSessionCounterWithBody(ctx context.Context, sessionId string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	IncrementSessionCounter(ctx context.Context, sessionId string, body IncrementSessionCounterJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetSessionFields request
+	GetSessionFields(ctx context.Context, sessionId string, params *GetSessionFieldsParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// SetSessionFields request with any body
+	SetSessionFieldsWithBody(ctx context.Context, sessionId string, params *SetSessionFieldsParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	SetSessionFields(ctx context.Context, sessionId string, params *SetSessionFieldsParams, body SetSessionFieldsJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// RotateSession request with any body
+	RotateSessionWithBody(ctx context.Context, sessionId string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	RotateSession(ctx context.Context, sessionId string, body RotateSessionJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// TouchSession request with any body
+	TouchSessionWithBody(ctx context.Context, sessionId string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	TouchSession(ctx context.Context, sessionId string, body TouchSessionJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// DeleteSessionValue request
+	DeleteSessionValue(ctx context.Context, sessionId string, params *DeleteSessionValueParams, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetSessionValue request
+	GetSessionValue(ctx context.Context, sessionId string, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// SetSessionValue request with any body
+	SetSessionValueWithBody(ctx context.Context, sessionId string, params *SetSessionValueParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	SetSessionValue(ctx context.Context, sessionId string, params *SetSessionValueParams, body SetSessionValueJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// DeleteSubjectSessions request
+	DeleteSubjectSessions(ctx context.Context, subject string, reqEditors ...RequestEditorFn) (*http.Response, error)
+
+	// GetSubjectSessions request
+	GetSubjectSessions(ctx context.Context, subject string, reqEditors ...RequestEditorFn) (*http.Response, error)
 }
 
-func (c *Client) SetSessionWithBody(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
-	req, err := NewSetSessionRequestWithBody(c.Server, contentType, body)
+func (c *Client) SetSessionWithBody(ctx context.Context, params *SetSessionParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewSetSessionRequestWithBody(c.Server, params, contentType, body)
 	if err != nil {
 		return nil, err
 	}
@@ -114,8 +157,8 @@ func (c *Client) SetSessionWithBody(ctx context.Context, contentType string, bod
 	return c.Client.Do(req)
 }
 
-func (c *Client) SetSession(ctx context.Context, body SetSessionJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
-	req, err := NewSetSessionRequest(c.Server, body)
+func (c *Client) SetSession(ctx context.Context, params *SetSessionParams, body SetSessionJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewSetSessionRequest(c.Server, params, body)
 	if err != nil {
 		return nil, err
 	}
@@ -138,8 +181,200 @@ func (c *Client) DeleteSession(ctx context.Context, sessionId string, reqEditors
 	return c.Client.Do(req)
 }
 
-func (c *Client) GetSession(ctx context.Context, sessionId string, reqEditors ...RequestEditorFn) (*http.Response, error) {
-	req, err := NewGetSessionRequest(c.Server, sessionId)
+func (c *Client) GetSession(ctx context.Context, sessionId string, params *GetSessionParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetSessionRequest(c.Server, sessionId, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) PatchSessionWithBody(ctx context.Context, sessionId string, params *PatchSessionParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewPatchSessionRequestWithBody(c.Server, sessionId, params, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) IncrementSessionCounterWithBody(ctx context.Context, sessionId string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewIncrementSessionCounterRequestWithBody(c.Server, sessionId, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) IncrementSessionCounter(ctx context.Context, sessionId string, body IncrementSessionCounterJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewIncrementSessionCounterRequest(c.Server, sessionId, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetSessionFields(ctx context.Context, sessionId string, params *GetSessionFieldsParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetSessionFieldsRequest(c.Server, sessionId, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) SetSessionFieldsWithBody(ctx context.Context, sessionId string, params *SetSessionFieldsParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewSetSessionFieldsRequestWithBody(c.Server, sessionId, params, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) SetSessionFields(ctx context.Context, sessionId string, params *SetSessionFieldsParams, body SetSessionFieldsJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewSetSessionFieldsRequest(c.Server, sessionId, params, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) RotateSessionWithBody(ctx context.Context, sessionId string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewRotateSessionRequestWithBody(c.Server, sessionId, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) RotateSession(ctx context.Context, sessionId string, body RotateSessionJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewRotateSessionRequest(c.Server, sessionId, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) TouchSessionWithBody(ctx context.Context, sessionId string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewTouchSessionRequestWithBody(c.Server, sessionId, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) TouchSession(ctx context.Context, sessionId string, body TouchSessionJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewTouchSessionRequest(c.Server, sessionId, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) DeleteSessionValue(ctx context.Context, sessionId string, params *DeleteSessionValueParams, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewDeleteSessionValueRequest(c.Server, sessionId, params)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetSessionValue(ctx context.Context, sessionId string, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetSessionValueRequest(c.Server, sessionId)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) SetSessionValueWithBody(ctx context.Context, sessionId string, params *SetSessionValueParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewSetSessionValueRequestWithBody(c.Server, sessionId, params, contentType, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) SetSessionValue(ctx context.Context, sessionId string, params *SetSessionValueParams, body SetSessionValueJSONRequestBody, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewSetSessionValueRequest(c.Server, sessionId, params, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) DeleteSubjectSessions(ctx context.Context, subject string, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewDeleteSubjectSessionsRequest(c.Server, subject)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if err := c.applyEditors(ctx, req, reqEditors); err != nil {
+		return nil, err
+	}
+	return c.Client.Do(req)
+}
+
+func (c *Client) GetSubjectSessions(ctx context.Context, subject string, reqEditors ...RequestEditorFn) (*http.Response, error) {
+	req, err := NewGetSubjectSessionsRequest(c.Server, subject)
 	if err != nil {
 		return nil, err
 	}
@@ -151,18 +386,18 @@ func (c *Client) GetSession(ctx context.Context, sessionId string, reqEditors ..
 }
 
 // NewSetSessionRequest calls the generic SetSession builder with application/json body
-func NewSetSessionRequest(server string, body SetSessionJSONRequestBody) (*http.Request, error) {
+func NewSetSessionRequest(server string, params *SetSessionParams, body SetSessionJSONRequestBody) (*http.Request, error) {
 	var bodyReader io.Reader
 	buf, err := json.Marshal(body)
 	if err != nil {
 		return nil, err
 	}
 	bodyReader = bytes.NewReader(buf)
-	return NewSetSessionRequestWithBody(server, "application/json", bodyReader)
+	return NewSetSessionRequestWithBody(server, params, "application/json", bodyReader)
 }
 
 // NewSetSessionRequestWithBody generates requests for SetSession with any type of body
-func NewSetSessionRequestWithBody(server string, contentType string, body io.Reader) (*http.Request, error) {
+func NewSetSessionRequestWithBody(server string, params *SetSessionParams, contentType string, body io.Reader) (*http.Request, error) {
 	var err error
 
 	serverURL, err := url.Parse(server)
@@ -187,6 +422,28 @@ func NewSetSessionRequestWithBody(server string, contentType string, body io.Rea
 
 	req.Header.Add("Content-Type", contentType)
 
+	if params.IfMatch != nil {
+		var headerParam0 string
+
+		headerParam0, err = runtime.StyleParamWithLocation("simple", false, "If-Match", runtime.ParamLocationHeader, *params.IfMatch)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("If-Match", headerParam0)
+	}
+
+	if params.IfNoneMatch != nil {
+		var headerParam1 string
+
+		headerParam1, err = runtime.StyleParamWithLocation("simple", false, "If-None-Match", runtime.ParamLocationHeader, *params.IfNoneMatch)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("If-None-Match", headerParam1)
+	}
+
 	return req, nil
 }
 
@@ -225,7 +482,7 @@ func NewDeleteSessionRequest(server string, sessionId string) (*http.Request, er
 }
 
 // NewGetSessionRequest generates requests for GetSession
-func NewGetSessionRequest(server string, sessionId string) (*http.Request, error) {
+func NewGetSessionRequest(server string, sessionId string, params *GetSessionParams) (*http.Request, error) {
 	var err error
 
 	var pathParam0 string
@@ -255,175 +512,1446 @@ func NewGetSessionRequest(server string, sessionId string) (*http.Request, error
 		return nil, err
 	}
 
-	return req, nil
-}
+	if params.IfNoneMatch != nil {
+		var headerParam0 string
 
-func (c *Client) applyEditors(ctx context.Context, req *http.Request, additionalEditors []RequestEditorFn) error {
-	for _, r := range c.RequestEditors {
-		if err := r(ctx, req); err != nil {
-			return err
-		}
-	}
-	for _, r := range additionalEditors {
-		if err := r(ctx, req); err != nil {
-			return err
+		headerParam0, err = runtime.StyleParamWithLocation("simple", false, "If-None-Match", runtime.ParamLocationHeader, *params.IfNoneMatch)
+		if err != nil {
+			return nil, err
 		}
+
+		req.Header.Set("If-None-Match", headerParam0)
 	}
-	return nil
-}
 
-// ClientWithResponses builds on ClientInterface to offer response payloads
-type ClientWithResponses struct {
-	ClientInterface
+	return req, nil
 }
 
-// NewClientWithResponses creates a new ClientWithResponses, which wraps
-// Client with return type handling
-func NewClientWithResponses(server string, opts ...ClientOption) (*ClientWithResponses, error) {
-	client, err := NewClient(server, opts...)
+// NewPatchSessionRequestWithBody generates requests for PatchSession with any type of body
+func NewPatchSessionRequestWithBody(server string, sessionId string, params *PatchSessionParams, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "sessionId", runtime.ParamLocationPath, sessionId)
 	if err != nil {
 		return nil, err
 	}
-	return &ClientWithResponses{client}, nil
-}
 
-// WithBaseURL overrides the baseURL.
-func WithBaseURL(baseURL string) ClientOption {
-	return func(c *Client) error {
-		newBaseURL, err := url.Parse(baseURL)
-		if err != nil {
-			return err
-		}
-		c.Server = newBaseURL.String()
-		return nil
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
 	}
-}
 
-// ClientWithResponsesInterface is the interface specification for the client with responses above.
-type ClientWithResponsesInterface interface {
-	// SetSession request with any body
-	SetSessionWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*SetSessionResponse, error)
+	operationPath := fmt.Sprintf("/session/%s", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
 
-	SetSessionWithResponse(ctx context.Context, body SetSessionJSONRequestBody, reqEditors ...RequestEditorFn) (*SetSessionResponse, error)
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
 
-	// DeleteSession request
-	DeleteSessionWithResponse(ctx context.Context, sessionId string, reqEditors ...RequestEditorFn) (*DeleteSessionResponse, error)
+	req, err := http.NewRequest("PATCH", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
 
-	// GetSession request
-	GetSessionWithResponse(ctx context.Context, sessionId string, reqEditors ...RequestEditorFn) (*GetSessionResponse, error)
-}
+	req.Header.Add("Content-Type", contentType)
 
-type SetSessionResponse struct {
-	Body         []byte
-	HTTPResponse *http.Response
+	if params.IfMatch != nil {
+		var headerParam0 string
+
+		headerParam0, err = runtime.StyleParamWithLocation("simple", false, "If-Match", runtime.ParamLocationHeader, *params.IfMatch)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("If-Match", headerParam0)
+	}
+
+	return req, nil
+}
+
+// NewIncrementSessionCounterRequest calls the generic IncrementSessionCounter builder with application/json body
+func NewIncrementSessionCounterRequest(server string, sessionId string, body IncrementSessionCounterJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewIncrementSessionCounterRequestWithBody(server, sessionId, "application/json", bodyReader)
+}
+
+// NewIncrementSessionCounterRequestWithBody generates requests for IncrementSessionCounter with any type of body
+func NewIncrementSessionCounterRequestWithBody(server string, sessionId string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "sessionId", runtime.ParamLocationPath, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/session/%s/counters", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewGetSessionFieldsRequest generates requests for GetSessionFields
+func NewGetSessionFieldsRequest(server string, sessionId string, params *GetSessionFieldsParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "sessionId", runtime.ParamLocationPath, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/session/%s/fields", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	queryValues := queryURL.Query()
+
+	if queryFrag, err := runtime.StyleParamWithLocation("form", true, "field", runtime.ParamLocationQuery, params.Field); err != nil {
+		return nil, err
+	} else if parsed, err := url.ParseQuery(queryFrag); err != nil {
+		return nil, err
+	} else {
+		for k, v := range parsed {
+			for _, v2 := range v {
+				queryValues.Add(k, v2)
+			}
+		}
+	}
+
+	queryURL.RawQuery = queryValues.Encode()
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewSetSessionFieldsRequest calls the generic SetSessionFields builder with application/json body
+func NewSetSessionFieldsRequest(server string, sessionId string, params *SetSessionFieldsParams, body SetSessionFieldsJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewSetSessionFieldsRequestWithBody(server, sessionId, params, "application/json", bodyReader)
+}
+
+// NewSetSessionFieldsRequestWithBody generates requests for SetSessionFields with any type of body
+func NewSetSessionFieldsRequestWithBody(server string, sessionId string, params *SetSessionFieldsParams, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "sessionId", runtime.ParamLocationPath, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/session/%s/fields", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PUT", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	if params.IfMatch != nil {
+		var headerParam0 string
+
+		headerParam0, err = runtime.StyleParamWithLocation("simple", false, "If-Match", runtime.ParamLocationHeader, *params.IfMatch)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("If-Match", headerParam0)
+	}
+
+	return req, nil
+}
+
+// NewRotateSessionRequest calls the generic RotateSession builder with application/json body
+func NewRotateSessionRequest(server string, sessionId string, body RotateSessionJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewRotateSessionRequestWithBody(server, sessionId, "application/json", bodyReader)
+}
+
+// NewRotateSessionRequestWithBody generates requests for RotateSession with any type of body
+func NewRotateSessionRequestWithBody(server string, sessionId string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "sessionId", runtime.ParamLocationPath, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/session/%s/rotate", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewTouchSessionRequest calls the generic TouchSession builder with application/json body
+func NewTouchSessionRequest(server string, sessionId string, body TouchSessionJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewTouchSessionRequestWithBody(server, sessionId, "application/json", bodyReader)
+}
+
+// NewTouchSessionRequestWithBody generates requests for TouchSession with any type of body
+func NewTouchSessionRequestWithBody(server string, sessionId string, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "sessionId", runtime.ParamLocationPath, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/session/%s/touch", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	return req, nil
+}
+
+// NewDeleteSessionValueRequest generates requests for DeleteSessionValue
+func NewDeleteSessionValueRequest(server string, sessionId string, params *DeleteSessionValueParams) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "sessionId", runtime.ParamLocationPath, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/session/%s/values", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("DELETE", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.IfMatch != nil {
+		var headerParam0 string
+
+		headerParam0, err = runtime.StyleParamWithLocation("simple", false, "If-Match", runtime.ParamLocationHeader, *params.IfMatch)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("If-Match", headerParam0)
+	}
+
+	return req, nil
+}
+
+// NewGetSessionValueRequest generates requests for GetSessionValue
+func NewGetSessionValueRequest(server string, sessionId string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "sessionId", runtime.ParamLocationPath, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/session/%s/values", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewSetSessionValueRequest calls the generic SetSessionValue builder with application/json body
+func NewSetSessionValueRequest(server string, sessionId string, params *SetSessionValueParams, body SetSessionValueJSONRequestBody) (*http.Request, error) {
+	var bodyReader io.Reader
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	bodyReader = bytes.NewReader(buf)
+	return NewSetSessionValueRequestWithBody(server, sessionId, params, "application/json", bodyReader)
+}
+
+// NewSetSessionValueRequestWithBody generates requests for SetSessionValue with any type of body
+func NewSetSessionValueRequestWithBody(server string, sessionId string, params *SetSessionValueParams, contentType string, body io.Reader) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "sessionId", runtime.ParamLocationPath, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/session/%s/values", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PUT", queryURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	if params.IfMatch != nil {
+		var headerParam0 string
+
+		headerParam0, err = runtime.StyleParamWithLocation("simple", false, "If-Match", runtime.ParamLocationHeader, *params.IfMatch)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("If-Match", headerParam0)
+	}
+
+	return req, nil
+}
+
+// NewDeleteSubjectSessionsRequest generates requests for DeleteSubjectSessions
+func NewDeleteSubjectSessionsRequest(server string, subject string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "subject", runtime.ParamLocationPath, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/subject/%s/sessions", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("DELETE", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// NewGetSubjectSessionsRequest generates requests for GetSubjectSessions
+func NewGetSubjectSessionsRequest(server string, subject string) (*http.Request, error) {
+	var err error
+
+	var pathParam0 string
+
+	pathParam0, err = runtime.StyleParamWithLocation("simple", false, "subject", runtime.ParamLocationPath, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	operationPath := fmt.Sprintf("/subject/%s/sessions", pathParam0)
+	if operationPath[0] == '/' {
+		operationPath = "." + operationPath
+	}
+
+	queryURL, err := serverURL.Parse(operationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", queryURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (c *Client) applyEditors(ctx context.Context, req *http.Request, additionalEditors []RequestEditorFn) error {
+	for _, r := range c.RequestEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+	for _, r := range additionalEditors {
+		if err := r(ctx, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClientWithResponses builds on ClientInterface to offer response payloads
+type ClientWithResponses struct {
+	ClientInterface
+}
+
+// NewClientWithResponses creates a new ClientWithResponses, which wraps
+// Client with return type handling
+func NewClientWithResponses(server string, opts ...ClientOption) (*ClientWithResponses, error) {
+	client, err := NewClient(server, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientWithResponses{client}, nil
+}
+
+// WithBaseURL overrides the baseURL.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) error {
+		newBaseURL, err := url.Parse(baseURL)
+		if err != nil {
+			return err
+		}
+		c.Server = newBaseURL.String()
+		return nil
+	}
+}
+
+// ClientWithResponsesInterface is the interface specification for the client with responses above.
+type ClientWithResponsesInterface interface {
+	// SetSession request with any body
+	SetSessionWithBodyWithResponse(ctx context.Context, params *SetSessionParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*SetSessionResponse, error)
+
+	SetSessionWithResponse(ctx context.Context, params *SetSessionParams, body SetSessionJSONRequestBody, reqEditors ...RequestEditorFn) (*SetSessionResponse, error)
+
+	// DeleteSession request
+	DeleteSessionWithResponse(ctx context.Context, sessionId string, reqEditors ...RequestEditorFn) (*DeleteSessionResponse, error)
+
+	// GetSession request
+	GetSessionWithResponse(ctx context.Context, sessionId string, params *GetSessionParams, reqEditors ...RequestEditorFn) (*GetSessionResponse, error)
+
+	// PatchSession request with any body
+	PatchSessionWithBodyWithResponse(ctx context.Context, sessionId string, params *PatchSessionParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PatchSessionResponse, error)
+
+	// IncrementSessionCounter request with any body
+	IncrementSessionCounterWithBodyWithResponse(ctx context.Context, sessionId string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*IncrementSessionCounterResponse, error)
+
+	IncrementSessionCounterWithResponse(ctx context.Context, sessionId string, body IncrementSessionCounterJSONRequestBody, reqEditors ...RequestEditorFn) (*IncrementSessionCounterResponse, error)
+
+	// GetSessionFields request
+	GetSessionFieldsWithResponse(ctx context.Context, sessionId string, params *GetSessionFieldsParams, reqEditors ...RequestEditorFn) (*GetSessionFieldsResponse, error)
+
+	// SetSessionFields request with any body
+	SetSessionFieldsWithBodyWithResponse(ctx context.Context, sessionId string, params *SetSessionFieldsParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*SetSessionFieldsResponse, error)
+
+	SetSessionFieldsWithResponse(ctx context.Context, sessionId string, params *SetSessionFieldsParams, body SetSessionFieldsJSONRequestBody, reqEditors ...RequestEditorFn) (*SetSessionFieldsResponse, error)
+
+	// RotateSession request with any body
+	RotateSessionWithBodyWithResponse(ctx context.Context, sessionId string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*RotateSessionResponse, error)
+
+	RotateSessionWithResponse(ctx context.Context, sessionId string, body RotateSessionJSONRequestBody, reqEditors ...RequestEditorFn) (*RotateSessionResponse, error)
+
+	// TouchSession request with any body
+	TouchSessionWithBodyWithResponse(ctx context.Context, sessionId string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*TouchSessionResponse, error)
+
+	TouchSessionWithResponse(ctx context.Context, sessionId string, body TouchSessionJSONRequestBody, reqEditors ...RequestEditorFn) (*TouchSessionResponse, error)
+
+	// DeleteSessionValue request
+	DeleteSessionValueWithResponse(ctx context.Context, sessionId string, params *DeleteSessionValueParams, reqEditors ...RequestEditorFn) (*DeleteSessionValueResponse, error)
+
+	// GetSessionValue request
+	GetSessionValueWithResponse(ctx context.Context, sessionId string, reqEditors ...RequestEditorFn) (*GetSessionValueResponse, error)
+
+	// SetSessionValue request with any body
+	SetSessionValueWithBodyWithResponse(ctx context.Context, sessionId string, params *SetSessionValueParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*SetSessionValueResponse, error)
+
+	SetSessionValueWithResponse(ctx context.Context, sessionId string, params *SetSessionValueParams, body SetSessionValueJSONRequestBody, reqEditors ...RequestEditorFn) (*SetSessionValueResponse, error)
+
+	// DeleteSubjectSessions request
+	DeleteSubjectSessionsWithResponse(ctx context.Context, subject string, reqEditors ...RequestEditorFn) (*DeleteSubjectSessionsResponse, error)
+
+	// GetSubjectSessions request
+	GetSubjectSessionsWithResponse(ctx context.Context, subject string, reqEditors ...RequestEditorFn) (*GetSubjectSessionsResponse, error)
+}
+
+type SetSessionResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON201      *CreatedSession
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r SetSessionResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r SetSessionResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type DeleteSessionResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r DeleteSessionResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r DeleteSessionResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetSessionResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *GetSession
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r GetSessionResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetSessionResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type PatchSessionResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r PatchSessionResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r PatchSessionResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type IncrementSessionCounterResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *SessionCounter
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r IncrementSessionCounterResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r IncrementSessionCounterResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetSessionFieldsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *SessionFields
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r GetSessionFieldsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetSessionFieldsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type SetSessionFieldsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r SetSessionFieldsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r SetSessionFieldsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type RotateSessionResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON201      *CreatedSession
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r RotateSessionResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r RotateSessionResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type TouchSessionResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r TouchSessionResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r TouchSessionResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type DeleteSessionValueResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r DeleteSessionValueResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r DeleteSessionValueResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetSessionValueResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *SessionValue
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r GetSessionValueResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetSessionValueResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type SetSessionValueResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r SetSessionValueResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r SetSessionValueResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type DeleteSubjectSessionsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *DeletedSessions
+	JSONDefault  *Error
+}
+
+// Status returns HTTPResponse.Status
+func (r DeleteSubjectSessionsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r DeleteSubjectSessionsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+type GetSubjectSessionsResponse struct {
+	Body         []byte
+	HTTPResponse *http.Response
+	JSON200      *SubjectSessions
 	JSONDefault  *Error
 }
 
-// Status returns HTTPResponse.Status
-func (r SetSessionResponse) Status() string {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.Status
+// Status returns HTTPResponse.Status
+func (r GetSubjectSessionsResponse) Status() string {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.Status
+	}
+	return http.StatusText(0)
+}
+
+// StatusCode returns HTTPResponse.StatusCode
+func (r GetSubjectSessionsResponse) StatusCode() int {
+	if r.HTTPResponse != nil {
+		return r.HTTPResponse.StatusCode
+	}
+	return 0
+}
+
+// SetSessionWithBodyWithResponse request with arbitrary body returning *SetSessionResponse
+func (c *ClientWithResponses) SetSessionWithBodyWithResponse(ctx context.Context, params *SetSessionParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*SetSessionResponse, error) {
+	rsp, err := c.SetSessionWithBody(ctx, params, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSetSessionResponse(rsp)
+}
+
+func (c *ClientWithResponses) SetSessionWithResponse(ctx context.Context, params *SetSessionParams, body SetSessionJSONRequestBody, reqEditors ...RequestEditorFn) (*SetSessionResponse, error) {
+	rsp, err := c.SetSession(ctx, params, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSetSessionResponse(rsp)
+}
+
+// DeleteSessionWithResponse request returning *DeleteSessionResponse
+func (c *ClientWithResponses) DeleteSessionWithResponse(ctx context.Context, sessionId string, reqEditors ...RequestEditorFn) (*DeleteSessionResponse, error) {
+	rsp, err := c.DeleteSession(ctx, sessionId, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDeleteSessionResponse(rsp)
+}
+
+// GetSessionWithResponse request returning *GetSessionResponse
+func (c *ClientWithResponses) GetSessionWithResponse(ctx context.Context, sessionId string, params *GetSessionParams, reqEditors ...RequestEditorFn) (*GetSessionResponse, error) {
+	rsp, err := c.GetSession(ctx, sessionId, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetSessionResponse(rsp)
+}
+
+// PatchSessionWithBodyWithResponse request with arbitrary body returning *PatchSessionResponse
+func (c *ClientWithResponses) PatchSessionWithBodyWithResponse(ctx context.Context, sessionId string, params *PatchSessionParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*PatchSessionResponse, error) {
+	rsp, err := c.PatchSessionWithBody(ctx, sessionId, params, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePatchSessionResponse(rsp)
+}
+
+// IncrementSessionCounterWithBodyWithResponse request with arbitrary body returning *IncrementSessionCounterResponse
+func (c *ClientWithResponses) IncrementSessionCounterWithBodyWithResponse(ctx context.Context, sessionId string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*IncrementSessionCounterResponse, error) {
+	rsp, err := c.IncrementSessionCounterWithBody(ctx, sessionId, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseIncrementSessionCounterResponse(rsp)
+}
+
+func (c *ClientWithResponses) IncrementSessionCounterWithResponse(ctx context.Context, sessionId string, body IncrementSessionCounterJSONRequestBody, reqEditors ...RequestEditorFn) (*IncrementSessionCounterResponse, error) {
+	rsp, err := c.IncrementSessionCounter(ctx, sessionId, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseIncrementSessionCounterResponse(rsp)
+}
+
+// GetSessionFieldsWithResponse request returning *GetSessionFieldsResponse
+func (c *ClientWithResponses) GetSessionFieldsWithResponse(ctx context.Context, sessionId string, params *GetSessionFieldsParams, reqEditors ...RequestEditorFn) (*GetSessionFieldsResponse, error) {
+	rsp, err := c.GetSessionFields(ctx, sessionId, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetSessionFieldsResponse(rsp)
+}
+
+// SetSessionFieldsWithBodyWithResponse request with arbitrary body returning *SetSessionFieldsResponse
+func (c *ClientWithResponses) SetSessionFieldsWithBodyWithResponse(ctx context.Context, sessionId string, params *SetSessionFieldsParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*SetSessionFieldsResponse, error) {
+	rsp, err := c.SetSessionFieldsWithBody(ctx, sessionId, params, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSetSessionFieldsResponse(rsp)
+}
+
+func (c *ClientWithResponses) SetSessionFieldsWithResponse(ctx context.Context, sessionId string, params *SetSessionFieldsParams, body SetSessionFieldsJSONRequestBody, reqEditors ...RequestEditorFn) (*SetSessionFieldsResponse, error) {
+	rsp, err := c.SetSessionFields(ctx, sessionId, params, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSetSessionFieldsResponse(rsp)
+}
+
+// RotateSessionWithBodyWithResponse request with arbitrary body returning *RotateSessionResponse
+func (c *ClientWithResponses) RotateSessionWithBodyWithResponse(ctx context.Context, sessionId string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*RotateSessionResponse, error) {
+	rsp, err := c.RotateSessionWithBody(ctx, sessionId, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRotateSessionResponse(rsp)
+}
+
+func (c *ClientWithResponses) RotateSessionWithResponse(ctx context.Context, sessionId string, body RotateSessionJSONRequestBody, reqEditors ...RequestEditorFn) (*RotateSessionResponse, error) {
+	rsp, err := c.RotateSession(ctx, sessionId, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRotateSessionResponse(rsp)
+}
+
+// TouchSessionWithBodyWithResponse request with arbitrary body returning *TouchSessionResponse
+func (c *ClientWithResponses) TouchSessionWithBodyWithResponse(ctx context.Context, sessionId string, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*TouchSessionResponse, error) {
+	rsp, err := c.TouchSessionWithBody(ctx, sessionId, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTouchSessionResponse(rsp)
+}
+
+func (c *ClientWithResponses) TouchSessionWithResponse(ctx context.Context, sessionId string, body TouchSessionJSONRequestBody, reqEditors ...RequestEditorFn) (*TouchSessionResponse, error) {
+	rsp, err := c.TouchSession(ctx, sessionId, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTouchSessionResponse(rsp)
+}
+
+// DeleteSessionValueWithResponse request returning *DeleteSessionValueResponse
+func (c *ClientWithResponses) DeleteSessionValueWithResponse(ctx context.Context, sessionId string, params *DeleteSessionValueParams, reqEditors ...RequestEditorFn) (*DeleteSessionValueResponse, error) {
+	rsp, err := c.DeleteSessionValue(ctx, sessionId, params, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDeleteSessionValueResponse(rsp)
+}
+
+// GetSessionValueWithResponse request returning *GetSessionValueResponse
+func (c *ClientWithResponses) GetSessionValueWithResponse(ctx context.Context, sessionId string, reqEditors ...RequestEditorFn) (*GetSessionValueResponse, error) {
+	rsp, err := c.GetSessionValue(ctx, sessionId, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetSessionValueResponse(rsp)
+}
+
+// SetSessionValueWithBodyWithResponse request with arbitrary body returning *SetSessionValueResponse
+func (c *ClientWithResponses) SetSessionValueWithBodyWithResponse(ctx context.Context, sessionId string, params *SetSessionValueParams, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*SetSessionValueResponse, error) {
+	rsp, err := c.SetSessionValueWithBody(ctx, sessionId, params, contentType, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSetSessionValueResponse(rsp)
+}
+
+func (c *ClientWithResponses) SetSessionValueWithResponse(ctx context.Context, sessionId string, params *SetSessionValueParams, body SetSessionValueJSONRequestBody, reqEditors ...RequestEditorFn) (*SetSessionValueResponse, error) {
+	rsp, err := c.SetSessionValue(ctx, sessionId, params, body, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSetSessionValueResponse(rsp)
+}
+
+// DeleteSubjectSessionsWithResponse request returning *DeleteSubjectSessionsResponse
+func (c *ClientWithResponses) DeleteSubjectSessionsWithResponse(ctx context.Context, subject string, reqEditors ...RequestEditorFn) (*DeleteSubjectSessionsResponse, error) {
+	rsp, err := c.DeleteSubjectSessions(ctx, subject, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDeleteSubjectSessionsResponse(rsp)
+}
+
+// GetSubjectSessionsWithResponse request returning *GetSubjectSessionsResponse
+func (c *ClientWithResponses) GetSubjectSessionsWithResponse(ctx context.Context, subject string, reqEditors ...RequestEditorFn) (*GetSubjectSessionsResponse, error) {
+	rsp, err := c.GetSubjectSessions(ctx, subject, reqEditors...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGetSubjectSessionsResponse(rsp)
+}
+
+// ParseSetSessionResponse parses an HTTP response from a SetSessionWithResponse call
+func ParseSetSessionResponse(rsp *http.Response) (*SetSessionResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &SetSessionResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
+		var dest CreatedSession
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON201 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseDeleteSessionResponse parses an HTTP response from a DeleteSessionWithResponse call
+func ParseDeleteSessionResponse(rsp *http.Response) (*DeleteSessionResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &DeleteSessionResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
+}
+
+// ParseGetSessionResponse parses an HTTP response from a GetSessionWithResponse call
+func ParseGetSessionResponse(rsp *http.Response) (*GetSessionResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &GetSessionResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest GetSession
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
 	}
-	return http.StatusText(0)
+
+	return response, nil
 }
 
-// StatusCode returns HTTPResponse.StatusCode
-func (r SetSessionResponse) StatusCode() int {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.StatusCode
+// ParsePatchSessionResponse parses an HTTP response from a PatchSessionWithResponse call
+func ParsePatchSessionResponse(rsp *http.Response) (*PatchSessionResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
 	}
-	return 0
-}
 
-type DeleteSessionResponse struct {
-	Body         []byte
-	HTTPResponse *http.Response
-	JSONDefault  *Error
-}
+	response := &PatchSessionResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
 
-// Status returns HTTPResponse.Status
-func (r DeleteSessionResponse) Status() string {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.Status
 	}
-	return http.StatusText(0)
+
+	return response, nil
 }
 
-// StatusCode returns HTTPResponse.StatusCode
-func (r DeleteSessionResponse) StatusCode() int {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.StatusCode
+// ParseIncrementSessionCounterResponse parses an HTTP response from a IncrementSessionCounterWithResponse call
+func ParseIncrementSessionCounterResponse(rsp *http.Response) (*IncrementSessionCounterResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
 	}
-	return 0
-}
 
-type GetSessionResponse struct {
-	Body         []byte
-	HTTPResponse *http.Response
-	JSON200      *GetSession
-	JSONDefault  *Error
+	response := &IncrementSessionCounterResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest SessionCounter
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
 }
 
-// Status returns HTTPResponse.Status
-func (r GetSessionResponse) Status() string {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.Status
+// ParseGetSessionFieldsResponse parses an HTTP response from a GetSessionFieldsWithResponse call
+func ParseGetSessionFieldsResponse(rsp *http.Response) (*GetSessionFieldsResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
 	}
-	return http.StatusText(0)
+
+	response := &GetSessionFieldsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest SessionFields
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
 }
 
-// StatusCode returns HTTPResponse.StatusCode
-func (r GetSessionResponse) StatusCode() int {
-	if r.HTTPResponse != nil {
-		return r.HTTPResponse.StatusCode
+// ParseSetSessionFieldsResponse parses an HTTP response from a SetSessionFieldsWithResponse call
+func ParseSetSessionFieldsResponse(rsp *http.Response) (*SetSessionFieldsResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
+	if err != nil {
+		return nil, err
 	}
-	return 0
+
+	response := &SetSessionFieldsResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
 }
 
-// SetSessionWithBodyWithResponse request with arbitrary body returning *SetSessionResponse
-func (c *ClientWithResponses) SetSessionWithBodyWithResponse(ctx context.Context, contentType string, body io.Reader, reqEditors ...RequestEditorFn) (*SetSessionResponse, error) {
-	rsp, err := c.SetSessionWithBody(ctx, contentType, body, reqEditors...)
+// ParseRotateSessionResponse parses an HTTP response from a RotateSessionWithResponse call
+func ParseRotateSessionResponse(rsp *http.Response) (*RotateSessionResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
 	if err != nil {
 		return nil, err
 	}
-	return ParseSetSessionResponse(rsp)
+
+	response := &RotateSessionResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 201:
+		var dest CreatedSession
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON201 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
 }
 
-func (c *ClientWithResponses) SetSessionWithResponse(ctx context.Context, body SetSessionJSONRequestBody, reqEditors ...RequestEditorFn) (*SetSessionResponse, error) {
-	rsp, err := c.SetSession(ctx, body, reqEditors...)
+// ParseTouchSessionResponse parses an HTTP response from a TouchSessionWithResponse call
+func ParseTouchSessionResponse(rsp *http.Response) (*TouchSessionResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
 	if err != nil {
 		return nil, err
 	}
-	return ParseSetSessionResponse(rsp)
+
+	response := &TouchSessionResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
 }
 
-// DeleteSessionWithResponse request returning *DeleteSessionResponse
-func (c *ClientWithResponses) DeleteSessionWithResponse(ctx context.Context, sessionId string, reqEditors ...RequestEditorFn) (*DeleteSessionResponse, error) {
-	rsp, err := c.DeleteSession(ctx, sessionId, reqEditors...)
+// ParseDeleteSessionValueResponse parses an HTTP response from a DeleteSessionValueWithResponse call
+func ParseDeleteSessionValueResponse(rsp *http.Response) (*DeleteSessionValueResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
 	if err != nil {
 		return nil, err
 	}
-	return ParseDeleteSessionResponse(rsp)
+
+	response := &DeleteSessionValueResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
 }
 
-// GetSessionWithResponse request returning *GetSessionResponse
-func (c *ClientWithResponses) GetSessionWithResponse(ctx context.Context, sessionId string, reqEditors ...RequestEditorFn) (*GetSessionResponse, error) {
-	rsp, err := c.GetSession(ctx, sessionId, reqEditors...)
+// ParseGetSessionValueResponse parses an HTTP response from a GetSessionValueWithResponse call
+func ParseGetSessionValueResponse(rsp *http.Response) (*GetSessionValueResponse, error) {
+	bodyBytes, err := ioutil.ReadAll(rsp.Body)
+	defer func() { _ = rsp.Body.Close() }()
 	if err != nil {
 		return nil, err
 	}
-	return ParseGetSessionResponse(rsp)
+
+	response := &GetSessionValueResponse{
+		Body:         bodyBytes,
+		HTTPResponse: rsp,
+	}
+
+	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest SessionValue
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
+		var dest Error
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSONDefault = &dest
+
+	}
+
+	return response, nil
 }
 
-// ParseSetSessionResponse parses an HTTP response from a SetSessionWithResponse call
-func ParseSetSessionResponse(rsp *http.Response) (*SetSessionResponse, error) {
+// ParseSetSessionValueResponse parses an HTTP response from a SetSessionValueWithResponse call
+func ParseSetSessionValueResponse(rsp *http.Response) (*SetSessionValueResponse, error) {
 	bodyBytes, err := ioutil.ReadAll(rsp.Body)
 	defer func() { _ = rsp.Body.Close() }()
 	if err != nil {
 		return nil, err
 	}
 
-	response := &SetSessionResponse{
+	response := &SetSessionValueResponse{
 		Body:         bodyBytes,
 		HTTPResponse: rsp,
 	}
@@ -441,20 +1969,27 @@ func ParseSetSessionResponse(rsp *http.Response) (*SetSessionResponse, error) {
 	return response, nil
 }
 
-// ParseDeleteSessionResponse parses an HTTP response from a DeleteSessionWithResponse call
-func ParseDeleteSessionResponse(rsp *http.Response) (*DeleteSessionResponse, error) {
+// ParseDeleteSubjectSessionsResponse parses an HTTP response from a DeleteSubjectSessionsWithResponse call
+func ParseDeleteSubjectSessionsResponse(rsp *http.Response) (*DeleteSubjectSessionsResponse, error) {
 	bodyBytes, err := ioutil.ReadAll(rsp.Body)
 	defer func() { _ = rsp.Body.Close() }()
 	if err != nil {
 		return nil, err
 	}
 
-	response := &DeleteSessionResponse{
+	response := &DeleteSubjectSessionsResponse{
 		Body:         bodyBytes,
 		HTTPResponse: rsp,
 	}
 
 	switch {
+	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
+		var dest DeletedSessions
+		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
+			return nil, err
+		}
+		response.JSON200 = &dest
+
 	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && true:
 		var dest Error
 		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
@@ -467,22 +2002,22 @@ func ParseDeleteSessionResponse(rsp *http.Response) (*DeleteSessionResponse, err
 	return response, nil
 }
 
-// ParseGetSessionResponse parses an HTTP response from a GetSessionWithResponse call
-func ParseGetSessionResponse(rsp *http.Response) (*GetSessionResponse, error) {
+// ParseGetSubjectSessionsResponse parses an HTTP response from a GetSubjectSessionsWithResponse call
+func ParseGetSubjectSessionsResponse(rsp *http.Response) (*GetSubjectSessionsResponse, error) {
 	bodyBytes, err := ioutil.ReadAll(rsp.Body)
 	defer func() { _ = rsp.Body.Close() }()
 	if err != nil {
 		return nil, err
 	}
 
-	response := &GetSessionResponse{
+	response := &GetSubjectSessionsResponse{
 		Body:         bodyBytes,
 		HTTPResponse: rsp,
 	}
 
 	switch {
 	case strings.Contains(rsp.Header.Get("Content-Type"), "json") && rsp.StatusCode == 200:
-		var dest GetSession
+		var dest SubjectSessions
 		if err := json.Unmarshal(bodyBytes, &dest); err != nil {
 			return nil, err
 		}
@@ -23,9 +23,20 @@ const _ = grpc.SupportPackageIsVersion7
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type SessionServiceClient interface {
-	SetSession(ctx context.Context, in *SetSessionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	SetSession(ctx context.Context, in *SetSessionRequest, opts ...grpc.CallOption) (*SetSessionResponse, error)
 	GetSession(ctx context.Context, in *GetSessionRequest, opts ...grpc.CallOption) (*GetSessionResponse, error)
 	DeleteSession(ctx context.Context, in *DeleteSessionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetSessionFields(ctx context.Context, in *GetSessionFieldsRequest, opts ...grpc.CallOption) (*GetSessionResponse, error)
+	SetSessionFields(ctx context.Context, in *SetSessionFieldsRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	TouchSession(ctx context.Context, in *TouchSessionRequest, opts ...grpc.CallOption) (*TouchSessionResponse, error)
+	PatchSession(ctx context.Context, in *PatchSessionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetSessionValue(ctx context.Context, in *GetSessionValueRequest, opts ...grpc.CallOption) (*GetSessionValueResponse, error)
+	SetSessionValue(ctx context.Context, in *SetSessionValueRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	DeleteSessionValue(ctx context.Context, in *DeleteSessionValueRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	IncrementSessionCounter(ctx context.Context, in *IncrementSessionCounterRequest, opts ...grpc.CallOption) (*IncrementSessionCounterResponse, error)
+	RotateSession(ctx context.Context, in *RotateSessionRequest, opts ...grpc.CallOption) (*RotateSessionResponse, error)
+	GetSubjectSessions(ctx context.Context, in *GetSubjectSessionsRequest, opts ...grpc.CallOption) (*GetSubjectSessionsResponse, error)
+	DeleteSubjectSessions(ctx context.Context, in *DeleteSubjectSessionsRequest, opts ...grpc.CallOption) (*DeleteSubjectSessionsResponse, error)
 }
 
 type sessionServiceClient struct {
@@ -36,8 +47,8 @@ func NewSessionServiceClient(cc grpc.ClientConnInterface) SessionServiceClient {
 	return &sessionServiceClient{cc}
 }
 
-func (c *sessionServiceClient) SetSession(ctx context.Context, in *SetSessionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
-	out := new(emptypb.Empty)
+func (c *sessionServiceClient) SetSession(ctx context.Context, in *SetSessionRequest, opts ...grpc.CallOption) (*SetSessionResponse, error) {
+	out := new(SetSessionResponse)
 	err := c.cc.Invoke(ctx, "/session.SessionService/SetSession", in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -63,20 +74,130 @@ func (c *sessionServiceClient) DeleteSession(ctx context.Context, in *DeleteSess
 	return out, nil
 }
 
+func (c *sessionServiceClient) GetSessionFields(ctx context.Context, in *GetSessionFieldsRequest, opts ...grpc.CallOption) (*GetSessionResponse, error) {
+	out := new(GetSessionResponse)
+	err := c.cc.Invoke(ctx, "/session.SessionService/GetSessionFields", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionServiceClient) SetSessionFields(ctx context.Context, in *SetSessionFieldsRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/session.SessionService/SetSessionFields", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionServiceClient) TouchSession(ctx context.Context, in *TouchSessionRequest, opts ...grpc.CallOption) (*TouchSessionResponse, error) {
+	out := new(TouchSessionResponse)
+	err := c.cc.Invoke(ctx, "/session.SessionService/TouchSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionServiceClient) PatchSession(ctx context.Context, in *PatchSessionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/session.SessionService/PatchSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionServiceClient) GetSessionValue(ctx context.Context, in *GetSessionValueRequest, opts ...grpc.CallOption) (*GetSessionValueResponse, error) {
+	out := new(GetSessionValueResponse)
+	err := c.cc.Invoke(ctx, "/session.SessionService/GetSessionValue", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionServiceClient) SetSessionValue(ctx context.Context, in *SetSessionValueRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/session.SessionService/SetSessionValue", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionServiceClient) DeleteSessionValue(ctx context.Context, in *DeleteSessionValueRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/session.SessionService/DeleteSessionValue", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionServiceClient) IncrementSessionCounter(ctx context.Context, in *IncrementSessionCounterRequest, opts ...grpc.CallOption) (*IncrementSessionCounterResponse, error) {
+	out := new(IncrementSessionCounterResponse)
+	err := c.cc.Invoke(ctx, "/session.SessionService/IncrementSessionCounter", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionServiceClient) RotateSession(ctx context.Context, in *RotateSessionRequest, opts ...grpc.CallOption) (*RotateSessionResponse, error) {
+	out := new(RotateSessionResponse)
+	err := c.cc.Invoke(ctx, "/session.SessionService/RotateSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionServiceClient) GetSubjectSessions(ctx context.Context, in *GetSubjectSessionsRequest, opts ...grpc.CallOption) (*GetSubjectSessionsResponse, error) {
+	out := new(GetSubjectSessionsResponse)
+	err := c.cc.Invoke(ctx, "/session.SessionService/GetSubjectSessions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionServiceClient) DeleteSubjectSessions(ctx context.Context, in *DeleteSubjectSessionsRequest, opts ...grpc.CallOption) (*DeleteSubjectSessionsResponse, error) {
+	out := new(DeleteSubjectSessionsResponse)
+	err := c.cc.Invoke(ctx, "/session.SessionService/DeleteSubjectSessions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SessionServiceServer is the server API for SessionService service.
 // All implementations should embed UnimplementedSessionServiceServer
 // for forward compatibility
 type SessionServiceServer interface {
-	SetSession(context.Context, *SetSessionRequest) (*emptypb.Empty, error)
+	SetSession(context.Context, *SetSessionRequest) (*SetSessionResponse, error)
 	GetSession(context.Context, *GetSessionRequest) (*GetSessionResponse, error)
 	DeleteSession(context.Context, *DeleteSessionRequest) (*emptypb.Empty, error)
+	GetSessionFields(context.Context, *GetSessionFieldsRequest) (*GetSessionResponse, error)
+	SetSessionFields(context.Context, *SetSessionFieldsRequest) (*emptypb.Empty, error)
+	TouchSession(context.Context, *TouchSessionRequest) (*TouchSessionResponse, error)
+	PatchSession(context.Context, *PatchSessionRequest) (*emptypb.Empty, error)
+	GetSessionValue(context.Context, *GetSessionValueRequest) (*GetSessionValueResponse, error)
+	SetSessionValue(context.Context, *SetSessionValueRequest) (*emptypb.Empty, error)
+	DeleteSessionValue(context.Context, *DeleteSessionValueRequest) (*emptypb.Empty, error)
+	IncrementSessionCounter(context.Context, *IncrementSessionCounterRequest) (*IncrementSessionCounterResponse, error)
+	RotateSession(context.Context, *RotateSessionRequest) (*RotateSessionResponse, error)
+	GetSubjectSessions(context.Context, *GetSubjectSessionsRequest) (*GetSubjectSessionsResponse, error)
+	DeleteSubjectSessions(context.Context, *DeleteSubjectSessionsRequest) (*DeleteSubjectSessionsResponse, error)
 }
 
 // UnimplementedSessionServiceServer should be embedded to have forward compatible implementations.
 type UnimplementedSessionServiceServer struct {
 }
 
-func (UnimplementedSessionServiceServer) SetSession(context.Context, *SetSessionRequest) (*emptypb.Empty, error) {
+func (UnimplementedSessionServiceServer) SetSession(context.Context, *SetSessionRequest) (*SetSessionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SetSession not implemented")
 }
 func (UnimplementedSessionServiceServer) GetSession(context.Context, *GetSessionRequest) (*GetSessionResponse, error) {
@@ -85,6 +206,39 @@ func (UnimplementedSessionServiceServer) GetSession(context.Context, *GetSession
 func (UnimplementedSessionServiceServer) DeleteSession(context.Context, *DeleteSessionRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteSession not implemented")
 }
+func (UnimplementedSessionServiceServer) GetSessionFields(context.Context, *GetSessionFieldsRequest) (*GetSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSessionFields not implemented")
+}
+func (UnimplementedSessionServiceServer) SetSessionFields(context.Context, *SetSessionFieldsRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSessionFields not implemented")
+}
+func (UnimplementedSessionServiceServer) TouchSession(context.Context, *TouchSessionRequest) (*TouchSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TouchSession not implemented")
+}
+func (UnimplementedSessionServiceServer) PatchSession(context.Context, *PatchSessionRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PatchSession not implemented")
+}
+func (UnimplementedSessionServiceServer) GetSessionValue(context.Context, *GetSessionValueRequest) (*GetSessionValueResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSessionValue not implemented")
+}
+func (UnimplementedSessionServiceServer) SetSessionValue(context.Context, *SetSessionValueRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSessionValue not implemented")
+}
+func (UnimplementedSessionServiceServer) DeleteSessionValue(context.Context, *DeleteSessionValueRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSessionValue not implemented")
+}
+func (UnimplementedSessionServiceServer) IncrementSessionCounter(context.Context, *IncrementSessionCounterRequest) (*IncrementSessionCounterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IncrementSessionCounter not implemented")
+}
+func (UnimplementedSessionServiceServer) RotateSession(context.Context, *RotateSessionRequest) (*RotateSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RotateSession not implemented")
+}
+func (UnimplementedSessionServiceServer) GetSubjectSessions(context.Context, *GetSubjectSessionsRequest) (*GetSubjectSessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSubjectSessions not implemented")
+}
+func (UnimplementedSessionServiceServer) DeleteSubjectSessions(context.Context, *DeleteSubjectSessionsRequest) (*DeleteSubjectSessionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSubjectSessions not implemented")
+}
 
 // UnsafeSessionServiceServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to SessionServiceServer will
@@ -151,6 +305,204 @@ func _SessionService_DeleteSession_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SessionService_GetSessionFields_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSessionFieldsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServiceServer).GetSessionFields(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/session.SessionService/GetSessionFields",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServiceServer).GetSessionFields(ctx, req.(*GetSessionFieldsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionService_SetSessionFields_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSessionFieldsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServiceServer).SetSessionFields(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/session.SessionService/SetSessionFields",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServiceServer).SetSessionFields(ctx, req.(*SetSessionFieldsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionService_TouchSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TouchSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServiceServer).TouchSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/session.SessionService/TouchSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServiceServer).TouchSession(ctx, req.(*TouchSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionService_PatchSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PatchSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServiceServer).PatchSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/session.SessionService/PatchSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServiceServer).PatchSession(ctx, req.(*PatchSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionService_GetSessionValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSessionValueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServiceServer).GetSessionValue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/session.SessionService/GetSessionValue",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServiceServer).GetSessionValue(ctx, req.(*GetSessionValueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionService_SetSessionValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSessionValueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServiceServer).SetSessionValue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/session.SessionService/SetSessionValue",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServiceServer).SetSessionValue(ctx, req.(*SetSessionValueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionService_DeleteSessionValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSessionValueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServiceServer).DeleteSessionValue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/session.SessionService/DeleteSessionValue",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServiceServer).DeleteSessionValue(ctx, req.(*DeleteSessionValueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionService_IncrementSessionCounter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IncrementSessionCounterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServiceServer).IncrementSessionCounter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/session.SessionService/IncrementSessionCounter",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServiceServer).IncrementSessionCounter(ctx, req.(*IncrementSessionCounterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionService_RotateSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RotateSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServiceServer).RotateSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/session.SessionService/RotateSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServiceServer).RotateSession(ctx, req.(*RotateSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionService_GetSubjectSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSubjectSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServiceServer).GetSubjectSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/session.SessionService/GetSubjectSessions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServiceServer).GetSubjectSessions(ctx, req.(*GetSubjectSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SessionService_DeleteSubjectSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSubjectSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServiceServer).DeleteSubjectSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/session.SessionService/DeleteSubjectSessions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServiceServer).DeleteSubjectSessions(ctx, req.(*DeleteSubjectSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // SessionService_ServiceDesc is the grpc.ServiceDesc for SessionService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -170,6 +522,50 @@ var SessionService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteSession",
 			Handler:    _SessionService_DeleteSession_Handler,
 		},
+		{
+			MethodName: "GetSessionFields",
+			Handler:    _SessionService_GetSessionFields_Handler,
+		},
+		{
+			MethodName: "SetSessionFields",
+			Handler:    _SessionService_SetSessionFields_Handler,
+		},
+		{
+			MethodName: "TouchSession",
+			Handler:    _SessionService_TouchSession_Handler,
+		},
+		{
+			MethodName: "PatchSession",
+			Handler:    _SessionService_PatchSession_Handler,
+		},
+		{
+			MethodName: "GetSessionValue",
+			Handler:    _SessionService_GetSessionValue_Handler,
+		},
+		{
+			MethodName: "SetSessionValue",
+			Handler:    _SessionService_SetSessionValue_Handler,
+		},
+		{
+			MethodName: "DeleteSessionValue",
+			Handler:    _SessionService_DeleteSessionValue_Handler,
+		},
+		{
+			MethodName: "IncrementSessionCounter",
+			Handler:    _SessionService_IncrementSessionCounter_Handler,
+		},
+		{
+			MethodName: "RotateSession",
+			Handler:    _SessionService_RotateSession_Handler,
+		},
+		{
+			MethodName: "GetSubjectSessions",
+			Handler:    _SessionService_GetSubjectSessions_Handler,
+		},
+		{
+			MethodName: "DeleteSubjectSessions",
+			Handler:    _SessionService_DeleteSubjectSessions_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "session.proto",
@@ -11,17 +11,30 @@ import (
 	"github.com/jruben-rg/go-session-svc/genproto/session"
 	"github.com/jruben-rg/go-session-svc/server"
 	"github.com/jruben-rg/go-session-svc/service"
+	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 )
 
 func main() {
 
-	application := app.NewApplication("redis")
+	dbType := strings.ToLower(os.Getenv("MEMORY_DB_TYPE"))
+	if dbType == "" {
+		dbType = "redis"
+	}
+
+	application := app.NewApplication(dbType)
+	go func() {
+		// The API keeps being served without its metrics.
+		if err := server.RunMetricsServer(); err != nil {
+			logrus.WithError(err).Error("Unable to serve metrics")
+		}
+	}()
+
 	serverType := strings.ToLower(os.Getenv("SERVER_TYPE"))
 	switch serverType {
 	case "http":
 		server.RunHTTPServer(func(router chi.Router) http.Handler {
-			return server.HandlerFromMux(
+			return server.HandlerFromMuxWithValues(
 				service.NewHttpService(application),
 				router,
 			)
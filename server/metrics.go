@@ -0,0 +1,31 @@
+package server
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// RunMetricsServer serves the metrics under /debug/vars on METRICS_PORT, apart
+// from the API so they can be kept internal. It does nothing when METRICS_PORT
+// is not set, and returns the error the server stops with otherwise.
+func RunMetricsServer() error {
+	port := os.Getenv("METRICS_PORT")
+	if port == "" {
+		return nil
+	}
+	return RunMetricsServerOnAddr(fmt.Sprintf(":%s", port))
+}
+
+func RunMetricsServerOnAddr(addr string) error {
+	router := chi.NewRouter()
+	router.Mount("/debug/vars", expvar.Handler())
+
+	logrus.WithField("metricsEndpoint", addr).Info("Starting metrics server")
+
+	return http.ListenAndServe(addr, router)
+}
@@ -15,13 +15,46 @@ import (
 type ServerInterface interface {
 
 	// (POST /session)
-	SetSession(w http.ResponseWriter, r *http.Request)
+	SetSession(w http.ResponseWriter, r *http.Request, params SetSessionParams)
 
 	// (DELETE /session/{sessionId})
 	DeleteSession(w http.ResponseWriter, r *http.Request, sessionId string)
 
 	// (GET /session/{sessionId})
-	GetSession(w http.ResponseWriter, r *http.Request, sessionId string)
+	GetSession(w http.ResponseWriter, r *http.Request, sessionId string, params GetSessionParams)
+
+	// (PATCH /session/{sessionId})
+	PatchSession(w http.ResponseWriter, r *http.Request, sessionId string, params PatchSessionParams)
+
+	// (POST /session/{sessionId}/counters)
+	IncrementSessionCounter(w http.ResponseWriter, r *http.Request, sessionId string)
+
+	// (GET /session/{sessionId}/fields)
+	GetSessionFields(w http.ResponseWriter, r *http.Request, sessionId string, params GetSessionFieldsParams)
+
+	// (PUT /session/{sessionId}/fields)
+	SetSessionFields(w http.ResponseWriter, r *http.Request, sessionId string, params SetSessionFieldsParams)
+
+	// (POST /session/{sessionId}/rotate)
+	RotateSession(w http.ResponseWriter, r *http.Request, sessionId string)
+
+	// (POST /session/{sessionId}/touch)
+	TouchSession(w http.ResponseWriter, r *http.Request, sessionId string)
+
+	// (DELETE /session/{sessionId}/values)
+	DeleteSessionValue(w http.ResponseWriter, r *http.Request, sessionId string, params DeleteSessionValueParams)
+
+	// (GET /session/{sessionId}/values)
+	GetSessionValue(w http.ResponseWriter, r *http.Request, sessionId string)
+
+	// (PUT /session/{sessionId}/values)
+	SetSessionValue(w http.ResponseWriter, r *http.Request, sessionId string, params SetSessionValueParams)
+
+	// (DELETE /subject/{subject}/sessions)
+	DeleteSubjectSessions(w http.ResponseWriter, r *http.Request, subject string)
+
+	// (GET /subject/{subject}/sessions)
+	GetSubjectSessions(w http.ResponseWriter, r *http.Request, subject string)
 }
 
 // ServerInterfaceWrapper converts contexts to parameters.
@@ -37,8 +70,53 @@ type MiddlewareFunc func(http.HandlerFunc) http.HandlerFunc
 func (siw *ServerInterfaceWrapper) SetSession(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params SetSessionParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-Match", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-Match", runtime.ParamLocationHeader, valueList[0], &IfMatch)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-Match", Err: err})
+			return
+		}
+
+		params.IfMatch = &IfMatch
+
+	}
+
+	// ------------- Optional header parameter "If-None-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
+		var IfNoneMatch string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-None-Match", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-None-Match", runtime.ParamLocationHeader, valueList[0], &IfNoneMatch)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-None-Match", Err: err})
+			return
+		}
+
+		params.IfNoneMatch = &IfNoneMatch
+
+	}
+
 	var handler = func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.SetSession(w, r)
+		siw.Handler.SetSession(w, r, params)
 	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -89,8 +167,431 @@ func (siw *ServerInterfaceWrapper) GetSession(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetSessionParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "If-None-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
+		var IfNoneMatch string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-None-Match", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-None-Match", runtime.ParamLocationHeader, valueList[0], &IfNoneMatch)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-None-Match", Err: err})
+			return
+		}
+
+		params.IfNoneMatch = &IfNoneMatch
+
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetSession(w, r, sessionId, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// PatchSession operation middleware
+func (siw *ServerInterfaceWrapper) PatchSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "sessionId" -------------
+	var sessionId string
+
+	err = runtime.BindStyledParameter("simple", false, "sessionId", chi.URLParam(r, "sessionId"), &sessionId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sessionId", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PatchSessionParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-Match", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-Match", runtime.ParamLocationHeader, valueList[0], &IfMatch)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-Match", Err: err})
+			return
+		}
+
+		params.IfMatch = &IfMatch
+
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PatchSession(w, r, sessionId, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// IncrementSessionCounter operation middleware
+func (siw *ServerInterfaceWrapper) IncrementSessionCounter(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "sessionId" -------------
+	var sessionId string
+
+	err = runtime.BindStyledParameter("simple", false, "sessionId", chi.URLParam(r, "sessionId"), &sessionId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sessionId", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.IncrementSessionCounter(w, r, sessionId)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetSessionFields operation middleware
+func (siw *ServerInterfaceWrapper) GetSessionFields(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "sessionId" -------------
+	var sessionId string
+
+	err = runtime.BindStyledParameter("simple", false, "sessionId", chi.URLParam(r, "sessionId"), &sessionId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sessionId", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetSessionFieldsParams
+
+	// ------------- Required query parameter "field" -------------
+	if paramValue := r.URL.Query().Get("field"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "field"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "field", r.URL.Query(), &params.Field)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "field", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetSessionFields(w, r, sessionId, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// SetSessionFields operation middleware
+func (siw *ServerInterfaceWrapper) SetSessionFields(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "sessionId" -------------
+	var sessionId string
+
+	err = runtime.BindStyledParameter("simple", false, "sessionId", chi.URLParam(r, "sessionId"), &sessionId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sessionId", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params SetSessionFieldsParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-Match", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-Match", runtime.ParamLocationHeader, valueList[0], &IfMatch)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-Match", Err: err})
+			return
+		}
+
+		params.IfMatch = &IfMatch
+
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.SetSessionFields(w, r, sessionId, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// RotateSession operation middleware
+func (siw *ServerInterfaceWrapper) RotateSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "sessionId" -------------
+	var sessionId string
+
+	err = runtime.BindStyledParameter("simple", false, "sessionId", chi.URLParam(r, "sessionId"), &sessionId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sessionId", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.RotateSession(w, r, sessionId)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// TouchSession operation middleware
+func (siw *ServerInterfaceWrapper) TouchSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "sessionId" -------------
+	var sessionId string
+
+	err = runtime.BindStyledParameter("simple", false, "sessionId", chi.URLParam(r, "sessionId"), &sessionId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sessionId", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.TouchSession(w, r, sessionId)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// DeleteSessionValue operation middleware
+func (siw *ServerInterfaceWrapper) DeleteSessionValue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "sessionId" -------------
+	var sessionId string
+
+	err = runtime.BindStyledParameter("simple", false, "sessionId", chi.URLParam(r, "sessionId"), &sessionId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sessionId", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DeleteSessionValueParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-Match", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-Match", runtime.ParamLocationHeader, valueList[0], &IfMatch)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-Match", Err: err})
+			return
+		}
+
+		params.IfMatch = &IfMatch
+
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteSessionValue(w, r, sessionId, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetSessionValue operation middleware
+func (siw *ServerInterfaceWrapper) GetSessionValue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "sessionId" -------------
+	var sessionId string
+
+	err = runtime.BindStyledParameter("simple", false, "sessionId", chi.URLParam(r, "sessionId"), &sessionId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sessionId", Err: err})
+		return
+	}
+
 	var handler = func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetSession(w, r, sessionId)
+		siw.Handler.GetSessionValue(w, r, sessionId)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// SetSessionValue operation middleware
+func (siw *ServerInterfaceWrapper) SetSessionValue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "sessionId" -------------
+	var sessionId string
+
+	err = runtime.BindStyledParameter("simple", false, "sessionId", chi.URLParam(r, "sessionId"), &sessionId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sessionId", Err: err})
+		return
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params SetSessionValueParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-Match", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-Match", runtime.ParamLocationHeader, valueList[0], &IfMatch)
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-Match", Err: err})
+			return
+		}
+
+		params.IfMatch = &IfMatch
+
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.SetSessionValue(w, r, sessionId, params)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// DeleteSubjectSessions operation middleware
+func (siw *ServerInterfaceWrapper) DeleteSubjectSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "subject" -------------
+	var subject string
+
+	err = runtime.BindStyledParameter("simple", false, "subject", chi.URLParam(r, "subject"), &subject)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "subject", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.DeleteSubjectSessions(w, r, subject)
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler(w, r.WithContext(ctx))
+}
+
+// GetSubjectSessions operation middleware
+func (siw *ServerInterfaceWrapper) GetSubjectSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var err error
+
+	// ------------- Path parameter "subject" -------------
+	var subject string
+
+	err = runtime.BindStyledParameter("simple", false, "subject", chi.URLParam(r, "subject"), &subject)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "subject", Err: err})
+		return
+	}
+
+	var handler = func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetSubjectSessions(w, r, subject)
 	}
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -222,6 +723,39 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/session/{sessionId}", wrapper.GetSession)
 	})
+	r.Group(func(r chi.Router) {
+		r.Patch(options.BaseURL+"/session/{sessionId}", wrapper.PatchSession)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/session/{sessionId}/counters", wrapper.IncrementSessionCounter)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/session/{sessionId}/fields", wrapper.GetSessionFields)
+	})
+	r.Group(func(r chi.Router) {
+		r.Put(options.BaseURL+"/session/{sessionId}/fields", wrapper.SetSessionFields)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/session/{sessionId}/rotate", wrapper.RotateSession)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/session/{sessionId}/touch", wrapper.TouchSession)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/session/{sessionId}/values", wrapper.DeleteSessionValue)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/session/{sessionId}/values", wrapper.GetSessionValue)
+	})
+	r.Group(func(r chi.Router) {
+		r.Put(options.BaseURL+"/session/{sessionId}/values", wrapper.SetSessionValue)
+	})
+	r.Group(func(r chi.Router) {
+		r.Delete(options.BaseURL+"/subject/{subject}/sessions", wrapper.DeleteSubjectSessions)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/subject/{subject}/sessions", wrapper.GetSubjectSessions)
+	})
 
 	return r
 }
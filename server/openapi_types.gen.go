@@ -3,6 +3,32 @@
 // Code generated by github.com/deepmap/oapi-codegen version v1.11.0 DO NOT EDIT.
 package server
 
+import (
+	"time"
+)
+
+// Defines values for JsonPatchOperationOp.
+const (
+	Add     JsonPatchOperationOp = "add"
+	Copy    JsonPatchOperationOp = "copy"
+	Move    JsonPatchOperationOp = "move"
+	Remove  JsonPatchOperationOp = "remove"
+	Replace JsonPatchOperationOp = "replace"
+	Test    JsonPatchOperationOp = "test"
+)
+
+// CreatedSession defines model for CreatedSession.
+type CreatedSession struct {
+	// Key generated for the session
+	SessionKey string `json:"sessionKey"`
+}
+
+// DeletedSessions defines model for DeletedSessions.
+type DeletedSessions struct {
+	// Number of sessions deleted
+	Deleted int `json:"deleted"`
+}
+
 // Error defines model for Error.
 type Error struct {
 	Message string `json:"message"`
@@ -13,14 +39,169 @@ type GetSession struct {
 	Values *map[string]interface{} `json:"values,omitempty"`
 }
 
+// IncrementSessionCounter defines model for IncrementSessionCounter.
+type IncrementSessionCounter struct {
+	// Added to the counter, which counts from zero when missing. A negative delta decrements it
+	Delta int64 `json:"delta"`
+}
+
+// JsonPatch defines model for JsonPatch.
+type JsonPatch = []JsonPatchOperation
+
+// JsonPatchOperation defines model for JsonPatchOperation.
+type JsonPatchOperation struct {
+	// JSON Pointer to the value moved or copied
+	From *string              `json:"from,omitempty"`
+	Op   JsonPatchOperationOp `json:"op"`
+
+	// JSON Pointer (RFC 6901) to the value the operation applies to
+	Path string `json:"path"`
+
+	// Value added, replaced or tested
+	Value *interface{} `json:"value,omitempty"`
+}
+
+// JsonPatchOperationOp defines model for JsonPatchOperation.Op.
+type JsonPatchOperationOp string
+
+// MergePatch defines model for MergePatch.
+type MergePatch = map[string]interface{}
+
 // PostSession defines model for PostSession.
 type PostSession struct {
-	SessionKey   string                 `json:"sessionKey"`
+	// Leaves an existing session the time it has left instead of restarting its expiry
+	KeepTtl *bool `json:"keepTtl,omitempty"`
+
+	// When the session becomes valid, its ttl counting from then
+	NotBefore *time.Time `json:"notBefore,omitempty"`
+
+	// Key to store the session under. When missing the session is created under a key generated by the server
+	SessionKey   *string                `json:"sessionKey,omitempty"`
 	SessionValue map[string]interface{} `json:"sessionValue"`
+
+	// Who the session belongs to, such as a user id, so the sessions of a subject can be listed and deleted together. An existing session keeps the subject it belongs to when missing, and belongs to no one when empty
+	Subject *string `json:"subject,omitempty"`
+
+	// Seconds the session is kept for instead of the default expiry, up to the configured maximum
+	Ttl *int64 `json:"ttl,omitempty"`
+}
+
+// RotateSession defines model for RotateSession.
+type RotateSession struct {
+	// Seconds the session stays under its old key for, so requests already using it still succeed, up to the configured maximum
+	Grace *int64 `json:"grace,omitempty"`
+}
+
+// SessionCounter defines model for SessionCounter.
+type SessionCounter struct {
+	Value int64 `json:"value"`
+}
+
+// SessionFields defines model for SessionFields.
+type SessionFields = map[string]interface{}
+
+// Any JSON value held by a session
+type SessionValue = interface{}
+
+// SubjectSession defines model for SubjectSession.
+type SubjectSession struct {
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+
+	// Missing when the session never expires
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	SessionKey string     `json:"sessionKey"`
+	UpdatedAt  *time.Time `json:"updatedAt,omitempty"`
+	Version    int64      `json:"version"`
+}
+
+// SubjectSessions defines model for SubjectSessions.
+type SubjectSessions struct {
+	Sessions []SubjectSession `json:"sessions"`
+}
+
+// TouchSession defines model for TouchSession.
+type TouchSession struct {
+	// Seconds the session is kept for from now instead of its own ttl, up to the configured maximum
+	Ttl *int64 `json:"ttl,omitempty"`
 }
 
 // SetSessionJSONBody defines parameters for SetSession.
 type SetSessionJSONBody = PostSession
 
+// SetSessionParams defines parameters for SetSession.
+type SetSessionParams struct {
+	// Only write the session while its ETag is this one, or while it exists at all when it is *
+	IfMatch *string `json:"If-Match,omitempty"`
+
+	// Only write the session when it does not exist yet, must be *
+	IfNoneMatch *string `json:"If-None-Match,omitempty"`
+}
+
+// GetSessionParams defines parameters for GetSession.
+type GetSessionParams struct {
+	// ETags of the session already held by the client
+	IfNoneMatch *string `json:"If-None-Match,omitempty"`
+}
+
+// PatchSessionParams defines parameters for PatchSession.
+type PatchSessionParams struct {
+	// Only patch the session while its ETag is this one
+	IfMatch *string `json:"If-Match,omitempty"`
+}
+
+// IncrementSessionCounterJSONBody defines parameters for IncrementSessionCounter.
+type IncrementSessionCounterJSONBody = IncrementSessionCounter
+
+// GetSessionFieldsParams defines parameters for GetSessionFields.
+type GetSessionFieldsParams struct {
+	// Top level fields of the session to retrieve
+	Field []string `form:"field" json:"field"`
+}
+
+// SetSessionFieldsJSONBody defines parameters for SetSessionFields.
+type SetSessionFieldsJSONBody = SessionFields
+
+// SetSessionFieldsParams defines parameters for SetSessionFields.
+type SetSessionFieldsParams struct {
+	// Only set the fields while the ETag of the session is this one
+	IfMatch *string `json:"If-Match,omitempty"`
+}
+
+// RotateSessionJSONBody defines parameters for RotateSession.
+type RotateSessionJSONBody = RotateSession
+
+// TouchSessionJSONBody defines parameters for TouchSession.
+type TouchSessionJSONBody = TouchSession
+
+// DeleteSessionValueParams defines parameters for DeleteSessionValue.
+type DeleteSessionValueParams struct {
+	// Only delete the value while the ETag of the session is this one
+	IfMatch *string `json:"If-Match,omitempty"`
+}
+
+// SetSessionValueJSONBody defines parameters for SetSessionValue.
+type SetSessionValueJSONBody = SessionValue
+
+// SetSessionValueParams defines parameters for SetSessionValue.
+type SetSessionValueParams struct {
+	// Only set the value while the ETag of the session is this one
+	IfMatch *string `json:"If-Match,omitempty"`
+}
+
 // SetSessionJSONRequestBody defines body for SetSession for application/json ContentType.
 type SetSessionJSONRequestBody = SetSessionJSONBody
+
+// IncrementSessionCounterJSONRequestBody defines body for IncrementSessionCounter for application/json ContentType.
+type IncrementSessionCounterJSONRequestBody = IncrementSessionCounterJSONBody
+
+// SetSessionFieldsJSONRequestBody defines body for SetSessionFields for application/json ContentType.
+type SetSessionFieldsJSONRequestBody = SetSessionFieldsJSONBody
+
+// RotateSessionJSONRequestBody defines body for RotateSession for application/json ContentType.
+type RotateSessionJSONRequestBody = RotateSessionJSONBody
+
+// TouchSessionJSONRequestBody defines body for TouchSession for application/json ContentType.
+type TouchSessionJSONRequestBody = TouchSessionJSONBody
+
+// SetSessionValueJSONRequestBody defines body for SetSessionValue for application/json ContentType.
+type SetSessionValueJSONRequestBody = SetSessionValueJSONBody
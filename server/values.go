@@ -0,0 +1,35 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// HandlerFromMuxWithValues is HandlerFromMux, also routing the values and the
+// counters of a session under the JSON Pointer the rest of their path makes
+// up, which the OpenAPI paths cannot tell. The pointer is read from the "*"
+// URL param.
+func HandlerFromMuxWithValues(si ServerInterface, r chi.Router) http.Handler {
+	wrapper := ServerInterfaceWrapper{
+		Handler: si,
+		ErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		},
+	}
+
+	r.Group(func(r chi.Router) {
+		r.Delete("/session/{sessionId}/values/*", wrapper.DeleteSessionValue)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get("/session/{sessionId}/values/*", wrapper.GetSessionValue)
+	})
+	r.Group(func(r chi.Router) {
+		r.Put("/session/{sessionId}/values/*", wrapper.SetSessionValue)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post("/session/{sessionId}/counters/*", wrapper.IncrementSessionCounter)
+	})
+
+	return HandlerFromMux(si, r)
+}
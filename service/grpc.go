@@ -2,16 +2,20 @@ package service
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"time"
 
 	"github.com/jruben-rg/go-session-svc/genproto/session"
+	domain "github.com/jruben-rg/go-session-svc/sessions/domain/session"
 	"github.com/jruben-rg/go-session-svc/sessions/handlers"
 	"github.com/jruben-rg/go-session-svc/sessions/handlers/command"
 	"github.com/jruben-rg/go-session-svc/sessions/handlers/query"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type GrpcService struct {
@@ -22,21 +26,69 @@ func NewGrpcService(application handlers.Application) GrpcService {
 	return GrpcService{application}
 }
 
-func (g GrpcService) SetSession(ctx context.Context, request *session.SetSessionRequest) (*emptypb.Empty, error) {
+func (g GrpcService) SetSession(ctx context.Context, request *session.SetSessionRequest) (*session.SetSessionResponse, error) {
 
-	if request.Session.Key == "" || request.Session.Value == nil {
-		return nil, status.Error(codes.InvalidArgument, "SessionKey cannot be empty")
+	if request.Session.GetValue() == nil {
+		return nil, status.Error(codes.InvalidArgument, "Session Value cannot be empty")
+	}
+
+	ttl, err := fromProtoTTL(request.Ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	var notBefore time.Time
+	if request.NotBefore != nil {
+		if err := request.NotBefore.CheckValid(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "NotBefore is not valid")
+		}
+		notBefore = request.NotBefore.AsTime()
+	}
+
+	// An empty subject leaves an existing session the one it belongs to,
+	// unless it is to be cleared.
+	var subject *string
+	switch {
+	case request.Session.Subject != "" && request.ClearSubject:
+		return nil, status.Error(codes.InvalidArgument, "ClearSubject cannot be set along with a Subject")
+	case request.Session.Subject != "" || request.ClearSubject:
+		subject = &request.Session.Subject
+	}
+
+	// Sessions without a key are created under one generated for them.
+	if request.Session.Key == "" {
+		if request.ExpectedVersion != 0 || request.UpdateOnly {
+			return nil, status.Error(codes.InvalidArgument, "ExpectedVersion and UpdateOnly need a SessionKey")
+		}
+		key, err := g.app.Commands.CreateSession.Handle(ctx, command.CreateSession{
+			Value:     request.Session.Value.AsMap(),
+			TTL:       ttl,
+			NotBefore: notBefore,
+			Subject:   request.Session.Subject,
+		})
+		if err != nil {
+			return nil, grpcError(err)
+		}
+		return &session.SetSessionResponse{Key: key}, nil
 	}
 
-	if err := g.app.Commands.SetSession.Handle(ctx,
+	version, err := g.app.Commands.SetSession.Handle(ctx,
 		command.SetSession{
-			Key:   request.Session.Key,
-			Value: request.Session.Value.AsMap(),
-		}); err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+			Key:        request.Session.Key,
+			Value:      request.Session.Value.AsMap(),
+			TTL:        ttl,
+			KeepTTL:    request.KeepTtl,
+			NotBefore:  notBefore,
+			IfVersion:  request.ExpectedVersion,
+			CreateOnly: request.CreateOnly,
+			UpdateOnly: request.UpdateOnly,
+			Subject:    subject,
+		})
+	if err != nil {
+		return nil, grpcError(err)
 	}
 
-	return &emptypb.Empty{}, nil
+	return &session.SetSessionResponse{Key: request.Session.Key, Version: version}, nil
 }
 
 func (g GrpcService) GetSession(ctx context.Context, request *session.GetSessionRequest) (*session.GetSessionResponse, error) {
@@ -47,45 +99,339 @@ func (g GrpcService) GetSession(ctx context.Context, request *session.GetSession
 
 	res, err := g.app.Queries.GetSession.Handle(ctx, query.GetSession{Key: request.Key})
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, grpcError(err)
 	}
 
-	if res == "" {
-		return nil, status.Errorf(codes.NotFound, "session id not found")
+	protoSession, err := toProtoSession(res)
+	if err != nil {
+		return nil, err
 	}
 
-	resStr, ok := res.(string)
-	if !ok {
-		return nil, status.Errorf(codes.Internal, "cannot parse session value")
+	return &session.GetSessionResponse{Session: protoSession}, nil
+}
+
+func (g GrpcService) DeleteSession(ctx context.Context, request *session.DeleteSessionRequest) (*emptypb.Empty, error) {
+
+	if request.Key == "" {
+		return nil, status.Error(codes.InvalidArgument, "SessionKey cannot be empty")
 	}
 
-	sessionValue := command.SessionValue{}
-	if err := json.Unmarshal([]byte(resStr), &sessionValue); err != nil {
-		return nil, status.Errorf(codes.Internal, "cannot unmarshall session value to JSON")
+	if err := g.app.Commands.DeleteSession.Handle(ctx, command.DeleteSession{Key: request.Key}); err != nil {
+		return nil, grpcError(err)
 	}
 
-	structSession, err := structpb.NewStruct(sessionValue)
+	return &emptypb.Empty{}, nil
+}
+
+func (g GrpcService) GetSessionFields(ctx context.Context, request *session.GetSessionFieldsRequest) (*session.GetSessionResponse, error) {
+
+	if request.Key == "" || len(request.Fields) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "SessionKey and Fields cannot be empty")
+	}
+
+	fields, err := g.app.Queries.GetSessionFields.Handle(ctx, query.GetSessionFields{
+		Key:    request.Key,
+		Fields: request.Fields,
+	})
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "cannot transform session value to proto struct type")
+		return nil, grpcError(err)
+	}
+
+	structFields, err := structpb.NewStruct(fields)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot transform session fields to proto struct type")
 	}
 
 	return &session.GetSessionResponse{
 		Session: &session.Session{
 			Key:   request.Key,
-			Value: structSession,
+			Value: structFields,
 		},
 	}, nil
 }
 
-func (g GrpcService) DeleteSession(ctx context.Context, request *session.DeleteSessionRequest) (*emptypb.Empty, error) {
+func (g GrpcService) SetSessionFields(ctx context.Context, request *session.SetSessionFieldsRequest) (*emptypb.Empty, error) {
+
+	if request.Key == "" || len(request.Fields.GetFields()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "SessionKey and Fields cannot be empty")
+	}
+
+	if _, err := g.app.Commands.SetSessionFields.Handle(ctx, command.SetSessionFields{
+		Key:       request.Key,
+		Values:    request.Fields.AsMap(),
+		IfVersion: request.ExpectedVersion,
+	}); err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (g GrpcService) TouchSession(ctx context.Context, request *session.TouchSessionRequest) (*session.TouchSessionResponse, error) {
 
 	if request.Key == "" {
 		return nil, status.Error(codes.InvalidArgument, "SessionKey cannot be empty")
 	}
 
-	if err := g.app.Commands.DeleteSession.Handle(ctx, command.DeleteSession{Key: request.Key}); err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+	ttl, err := fromProtoTTL(request.Ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt, err := g.app.Commands.TouchSession.Handle(ctx, command.TouchSession{
+		Key: request.Key,
+		TTL: ttl,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &session.TouchSessionResponse{ExpiresAt: toProtoTimestamp(expiresAt)}, nil
+}
+
+func (g GrpcService) PatchSession(ctx context.Context, request *session.PatchSessionRequest) (*emptypb.Empty, error) {
+
+	if request.Key == "" {
+		return nil, status.Error(codes.InvalidArgument, "SessionKey cannot be empty")
+	}
+
+	cmd := command.PatchSession{Key: request.Key, IfVersion: request.ExpectedVersion}
+	switch patch := request.Patch.(type) {
+	case *session.PatchSessionRequest_MergePatch:
+		if patch.MergePatch == nil {
+			return nil, status.Error(codes.InvalidArgument, "MergePatch cannot be empty")
+		}
+		cmd.Patch = domain.MergePatch(patch.MergePatch.AsMap())
+	case *session.PatchSessionRequest_JsonPatch:
+		jsonPatch, err := fromProtoJsonPatch(patch.JsonPatch)
+		if err != nil {
+			return nil, err
+		}
+		cmd.Patch = jsonPatch
+	default:
+		return nil, status.Error(codes.InvalidArgument, "Patch cannot be empty")
+	}
+
+	if _, err := g.app.Commands.PatchSession.Handle(ctx, cmd); err != nil {
+		return nil, grpcError(err)
 	}
 
 	return &emptypb.Empty{}, nil
 }
+
+func (g GrpcService) GetSessionValue(ctx context.Context, request *session.GetSessionValueRequest) (*session.GetSessionValueResponse, error) {
+
+	if request.Key == "" {
+		return nil, status.Error(codes.InvalidArgument, "SessionKey cannot be empty")
+	}
+
+	value, err := g.app.Queries.GetSessionValue.Handle(ctx, query.GetSessionValue{
+		Key:     request.Key,
+		Pointer: request.Pointer,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	protoValue, err := structpb.NewValue(value)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot transform session value to proto value type")
+	}
+
+	return &session.GetSessionValueResponse{Value: protoValue}, nil
+}
+
+func (g GrpcService) SetSessionValue(ctx context.Context, request *session.SetSessionValueRequest) (*emptypb.Empty, error) {
+
+	if request.Key == "" || request.Value == nil {
+		return nil, status.Error(codes.InvalidArgument, "SessionKey and Value cannot be empty")
+	}
+
+	if _, err := g.app.Commands.SetSessionValue.Handle(ctx, command.SetSessionValue{
+		Key:       request.Key,
+		Pointer:   request.Pointer,
+		Value:     request.Value.AsInterface(),
+		IfVersion: request.ExpectedVersion,
+	}); err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (g GrpcService) DeleteSessionValue(ctx context.Context, request *session.DeleteSessionValueRequest) (*emptypb.Empty, error) {
+
+	if request.Key == "" {
+		return nil, status.Error(codes.InvalidArgument, "SessionKey cannot be empty")
+	}
+
+	if _, err := g.app.Commands.DeleteSessionValue.Handle(ctx, command.DeleteSessionValue{
+		Key:       request.Key,
+		Pointer:   request.Pointer,
+		IfVersion: request.ExpectedVersion,
+	}); err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (g GrpcService) IncrementSessionCounter(ctx context.Context, request *session.IncrementSessionCounterRequest) (*session.IncrementSessionCounterResponse, error) {
+
+	if request.Key == "" {
+		return nil, status.Error(codes.InvalidArgument, "SessionKey cannot be empty")
+	}
+
+	value, err := g.app.Commands.IncrementSessionCounter.Handle(ctx, command.IncrementSessionCounter{
+		Key:     request.Key,
+		Pointer: request.Pointer,
+		Delta:   request.Delta,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &session.IncrementSessionCounterResponse{Value: value}, nil
+}
+
+func (g GrpcService) RotateSession(ctx context.Context, request *session.RotateSessionRequest) (*session.RotateSessionResponse, error) {
+
+	if request.Key == "" {
+		return nil, status.Error(codes.InvalidArgument, "SessionKey cannot be empty")
+	}
+
+	grace, err := fromProtoTTL(request.Grace)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "Grace is not valid")
+	}
+
+	key, err := g.app.Commands.RotateSession.Handle(ctx, command.RotateSession{
+		Key:   request.Key,
+		Grace: grace,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &session.RotateSessionResponse{Key: key}, nil
+}
+
+func (g GrpcService) GetSubjectSessions(ctx context.Context, request *session.GetSubjectSessionsRequest) (*session.GetSubjectSessionsResponse, error) {
+
+	if request.Subject == "" {
+		return nil, status.Error(codes.InvalidArgument, "Subject cannot be empty")
+	}
+
+	sessions, err := g.app.Queries.GetSubjectSessions.Handle(ctx, query.GetSubjectSessions{
+		Subject: request.Subject,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	res := &session.GetSubjectSessionsResponse{Sessions: make([]*session.Session, 0, len(sessions))}
+	for _, s := range sessions {
+		protoSession, err := toProtoSession(s)
+		if err != nil {
+			return nil, err
+		}
+		res.Sessions = append(res.Sessions, protoSession)
+	}
+	return res, nil
+}
+
+func (g GrpcService) DeleteSubjectSessions(ctx context.Context, request *session.DeleteSubjectSessionsRequest) (*session.DeleteSubjectSessionsResponse, error) {
+
+	if request.Subject == "" {
+		return nil, status.Error(codes.InvalidArgument, "Subject cannot be empty")
+	}
+
+	deleted, err := g.app.Commands.DeleteSubjectSessions.Handle(ctx, command.DeleteSubjectSessions{
+		Subject: request.Subject,
+	})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &session.DeleteSubjectSessionsResponse{Deleted: int64(deleted)}, nil
+}
+
+// fromProtoJsonPatch converts the operations of a JSON Patch, failing when one
+// of them is missing the value it needs.
+func fromProtoJsonPatch(jsonPatch *session.JsonPatch) (domain.JSONPatch, error) {
+	patch := make(domain.JSONPatch, 0, len(jsonPatch.GetOperations()))
+	for i, op := range jsonPatch.GetOperations() {
+		operation := domain.PatchOperation{
+			Op:    op.Op,
+			Path:  op.Path,
+			From:  op.From,
+			Value: op.Value.AsInterface(),
+		}
+		if op.Value == nil && operation.NeedsValue() {
+			return nil, status.Errorf(codes.InvalidArgument, "Operation %d (%s) needs a value", i, op.Op)
+		}
+		patch = append(patch, operation)
+	}
+	return patch, nil
+}
+
+// fromProtoTTL converts an optional ttl, zero when missing.
+func fromProtoTTL(ttl *durationpb.Duration) (time.Duration, error) {
+	if ttl == nil {
+		return 0, nil
+	}
+	if err := ttl.CheckValid(); err != nil {
+		return 0, status.Error(codes.InvalidArgument, "Ttl is not valid")
+	}
+	return ttl.AsDuration(), nil
+}
+
+// toProtoSession converts a domain session, leaving out the timestamps it does
+// not know.
+func toProtoSession(s *domain.Session) (*session.Session, error) {
+
+	structSession, err := structpb.NewStruct(s.Data)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "cannot transform session value to proto struct type")
+	}
+
+	return &session.Session{
+		Key:       s.ID,
+		Value:     structSession,
+		CreatedAt: toProtoTimestamp(s.CreatedAt),
+		UpdatedAt: toProtoTimestamp(s.UpdatedAt),
+		ExpiresAt: toProtoTimestamp(s.ExpiresAt),
+		NotBefore: toProtoTimestamp(s.NotBefore),
+		Version:   s.Version,
+		Subject:   s.Subject,
+	}, nil
+}
+
+func toProtoTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// grpcError builds the status matching a handler error.
+func grpcError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrInvalid):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrNotSupported):
+		return status.Error(codes.Unimplemented, err.Error())
+	case errors.Is(err, domain.ErrPreconditionFailed):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrConflict):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, domain.ErrReadOnly):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
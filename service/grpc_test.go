@@ -4,16 +4,21 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/jruben-rg/go-session-svc/genproto/session"
 	"github.com/jruben-rg/go-session-svc/service"
+	domain "github.com/jruben-rg/go-session-svc/sessions/domain/session"
 	"github.com/jruben-rg/go-session-svc/sessions/handlers"
 	"github.com/jruben-rg/go-session-svc/sessions/handlers/command"
 	"github.com/jruben-rg/go-session-svc/sessions/handlers/query"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type testExpectationsGrpc struct {
@@ -30,6 +35,7 @@ type DeleteSessionHandlerGrpc struct {
 type SetSessionHandlerGrpc struct {
 	command.SetSessionHandler
 	testExpectationsGrpc
+	cmd command.SetSession
 }
 
 type GetSessionHandlerGrpc struct {
@@ -42,16 +48,172 @@ func (d *DeleteSessionHandlerGrpc) Handle(ctx context.Context, cmd command.Delet
 	return d.handlerErr
 }
 
-func (s *SetSessionHandlerGrpc) Handle(ctx context.Context, cmd command.SetSession) error {
+func (s *SetSessionHandlerGrpc) Handle(ctx context.Context, cmd command.SetSession) (int64, error) {
 	s.invoked = true
-	return s.handlerErr
+	s.cmd = cmd
+	version, _ := s.handlerVal.(int64)
+	return version, s.handlerErr
 }
 
-func (g *GetSessionHandlerGrpc) Handle(ctx context.Context, cmd query.GetSession) (interface{}, error) {
+type CreateSessionHandlerGrpc struct {
+	command.CreateSessionHandler
+	testExpectationsGrpc
+	cmd command.CreateSession
+}
+
+func (c *CreateSessionHandlerGrpc) Handle(ctx context.Context, cmd command.CreateSession) (string, error) {
+	c.invoked = true
+	c.cmd = cmd
+	key, _ := c.handlerVal.(string)
+	return key, c.handlerErr
+}
+
+type RotateSessionHandlerGrpc struct {
+	command.RotateSessionHandler
+	testExpectationsGrpc
+	cmd command.RotateSession
+}
+
+func (r *RotateSessionHandlerGrpc) Handle(ctx context.Context, cmd command.RotateSession) (string, error) {
+	r.invoked = true
+	r.cmd = cmd
+	key, _ := r.handlerVal.(string)
+	return key, r.handlerErr
+}
+
+type GetSubjectSessionsHandlerGrpc struct {
+	query.GetSubjectSessionsHandler
+	testExpectationsGrpc
+	query query.GetSubjectSessions
+}
+
+func (g *GetSubjectSessionsHandlerGrpc) Handle(ctx context.Context, q query.GetSubjectSessions) ([]*domain.Session, error) {
+	g.invoked = true
+	g.query = q
+	sessions, _ := g.handlerVal.([]*domain.Session)
+	return sessions, g.handlerErr
+}
+
+type DeleteSubjectSessionsHandlerGrpc struct {
+	command.DeleteSubjectSessionsHandler
+	testExpectationsGrpc
+	cmd command.DeleteSubjectSessions
+}
+
+func (d *DeleteSubjectSessionsHandlerGrpc) Handle(ctx context.Context, cmd command.DeleteSubjectSessions) (int, error) {
+	d.invoked = true
+	d.cmd = cmd
+	deleted, _ := d.handlerVal.(int)
+	return deleted, d.handlerErr
+}
+
+func (g *GetSessionHandlerGrpc) Handle(ctx context.Context, cmd query.GetSession) (*domain.Session, error) {
+	g.invoked = true
+	s, _ := g.handlerVal.(*domain.Session)
+	return s, g.handlerErr
+}
+
+type SetSessionFieldsHandlerGrpc struct {
+	command.SetSessionFieldsHandler
+	testExpectationsGrpc
+	cmd command.SetSessionFields
+}
+
+type GetSessionFieldsHandlerGrpc struct {
+	query.GetSessionFieldsHandler
+	testExpectationsGrpc
+}
+
+func (s *SetSessionFieldsHandlerGrpc) Handle(ctx context.Context, cmd command.SetSessionFields) (int64, error) {
+	s.invoked = true
+	s.cmd = cmd
+	version, _ := s.handlerVal.(int64)
+	return version, s.handlerErr
+}
+
+func (g *GetSessionFieldsHandlerGrpc) Handle(ctx context.Context, cmd query.GetSessionFields) (domain.Data, error) {
+	g.invoked = true
+	fields, _ := g.handlerVal.(map[string]interface{})
+	return fields, g.handlerErr
+}
+
+type TouchSessionHandlerGrpc struct {
+	command.TouchSessionHandler
+	testExpectationsGrpc
+	ttl time.Duration
+}
+
+func (s *TouchSessionHandlerGrpc) Handle(ctx context.Context, cmd command.TouchSession) (time.Time, error) {
+	s.invoked = true
+	s.ttl = cmd.TTL
+	expiresAt, _ := s.handlerVal.(time.Time)
+	return expiresAt, s.handlerErr
+}
+
+type PatchSessionHandlerGrpc struct {
+	command.PatchSessionHandler
+	testExpectationsGrpc
+	cmd command.PatchSession
+}
+
+func (p *PatchSessionHandlerGrpc) Handle(ctx context.Context, cmd command.PatchSession) (int64, error) {
+	p.invoked = true
+	p.cmd = cmd
+	version, _ := p.handlerVal.(int64)
+	return version, p.handlerErr
+}
+
+type GetSessionValueHandlerGrpc struct {
+	query.GetSessionValueHandler
+	testExpectationsGrpc
+	cmd query.GetSessionValue
+}
+
+func (g *GetSessionValueHandlerGrpc) Handle(ctx context.Context, cmd query.GetSessionValue) (interface{}, error) {
 	g.invoked = true
+	g.cmd = cmd
 	return g.handlerVal, g.handlerErr
 }
 
+type SetSessionValueHandlerGrpc struct {
+	command.SetSessionValueHandler
+	testExpectationsGrpc
+	cmd command.SetSessionValue
+}
+
+func (s *SetSessionValueHandlerGrpc) Handle(ctx context.Context, cmd command.SetSessionValue) (int64, error) {
+	s.invoked = true
+	s.cmd = cmd
+	version, _ := s.handlerVal.(int64)
+	return version, s.handlerErr
+}
+
+type DeleteSessionValueHandlerGrpc struct {
+	command.DeleteSessionValueHandler
+	testExpectationsGrpc
+	cmd command.DeleteSessionValue
+}
+
+func (d *DeleteSessionValueHandlerGrpc) Handle(ctx context.Context, cmd command.DeleteSessionValue) (int64, error) {
+	d.invoked = true
+	d.cmd = cmd
+	version, _ := d.handlerVal.(int64)
+	return version, d.handlerErr
+}
+
+type IncrementSessionCounterHandlerGrpc struct {
+	command.IncrementSessionCounterHandler
+	testExpectationsGrpc
+	cmd command.IncrementSessionCounter
+}
+
+func (i *IncrementSessionCounterHandlerGrpc) Handle(ctx context.Context, cmd command.IncrementSessionCounter) (int64, error) {
+	i.invoked = true
+	i.cmd = cmd
+	value, _ := i.handlerVal.(int64)
+	return value, i.handlerErr
+}
+
 func TestSetGrpcSession(t *testing.T) {
 	t.Parallel()
 
@@ -87,6 +249,30 @@ func TestSetGrpcSession(t *testing.T) {
 			sessionRequest:  session.SetSessionRequest{Session: &session.Session{Key: "Key", Value: nil}},
 			handlerErr:      nil,
 		},
+		{
+			scenario:        "Should respond with bad request if ttl is not valid",
+			expectedInvoked: false,
+			expectedError:   true,
+			expectedStatus:  codes.InvalidArgument,
+			sessionRequest:  session.SetSessionRequest{Session: &session.Session{Key: "Key", Value: sessionValue.GetStructValue()}, Ttl: &durationpb.Duration{Seconds: 1, Nanos: -1}},
+			handlerErr:      nil,
+		},
+		{
+			scenario:        "Should respond with bad request if not before is not valid",
+			expectedInvoked: false,
+			expectedError:   true,
+			expectedStatus:  codes.InvalidArgument,
+			sessionRequest:  session.SetSessionRequest{Session: &session.Session{Key: "Key", Value: sessionValue.GetStructValue()}, NotBefore: &timestamppb.Timestamp{Seconds: 1, Nanos: -1}},
+			handlerErr:      nil,
+		},
+		{
+			scenario:        "Should respond with bad request if ttl is over the maximum",
+			expectedInvoked: true,
+			expectedError:   true,
+			expectedStatus:  codes.InvalidArgument,
+			sessionRequest:  session.SetSessionRequest{Session: &session.Session{Key: "Key", Value: sessionValue.GetStructValue()}, Ttl: durationpb.New(time.Hour)},
+			handlerErr:      fmt.Errorf("wrapped: %w", domain.ErrInvalid),
+		},
 		{
 			scenario:        "Should respond with internal error if handler returns an error",
 			expectedInvoked: true,
@@ -95,6 +281,30 @@ func TestSetGrpcSession(t *testing.T) {
 			sessionRequest:  session.SetSessionRequest{Session: &session.Session{Key: "Key", Value: sessionValue.GetStructValue()}},
 			handlerErr:      fmt.Errorf("Error from handler"),
 		},
+		{
+			scenario:        "Should respond with failed precondition if the session does not match the request",
+			expectedInvoked: true,
+			expectedError:   true,
+			expectedStatus:  codes.FailedPrecondition,
+			sessionRequest:  session.SetSessionRequest{Session: &session.Session{Key: "Key", Value: sessionValue.GetStructValue()}, ExpectedVersion: 2},
+			handlerErr:      fmt.Errorf("wrapped: %w", domain.ErrPreconditionFailed),
+		},
+		{
+			scenario:        "Should respond with aborted if the session keeps changing concurrently",
+			expectedInvoked: true,
+			expectedError:   true,
+			expectedStatus:  codes.Aborted,
+			sessionRequest:  session.SetSessionRequest{Session: &session.Session{Key: "Key", Value: sessionValue.GetStructValue()}},
+			handlerErr:      fmt.Errorf("wrapped: %w", domain.ErrConflict),
+		},
+		{
+			scenario:        "Should respond with failed precondition if the session was rotated",
+			expectedInvoked: true,
+			expectedError:   true,
+			expectedStatus:  codes.FailedPrecondition,
+			sessionRequest:  session.SetSessionRequest{Session: &session.Session{Key: "Key", Value: sessionValue.GetStructValue()}},
+			handlerErr:      fmt.Errorf("wrapped: %w", domain.ErrReadOnly),
+		},
 		{
 			scenario:        "Should not return any errors if no errors are found",
 			expectedInvoked: true,
@@ -104,7 +314,8 @@ func TestSetGrpcSession(t *testing.T) {
 		},
 	}
 
-	for _, test := range tests {
+	for i := range tests {
+		test := &tests[i]
 
 		setSessionHandler := &SetSessionHandlerGrpc{
 			testExpectationsGrpc: testExpectationsGrpc{
@@ -151,6 +362,89 @@ func TestSetGrpcSession(t *testing.T) {
 
 }
 
+func TestSetGrpcSessionShouldPassConditions(t *testing.T) {
+	t.Parallel()
+
+	value, err := structpb.NewStruct(map[string]interface{}{"test": "Grpc"})
+	if err != nil {
+		t.Errorf("Cannot create session value.")
+	}
+
+	setSessionHandler := &SetSessionHandlerGrpc{testExpectationsGrpc: testExpectationsGrpc{handlerVal: int64(4)}}
+	grpcSvc := service.NewGrpcService(handlers.Application{
+		Commands: handlers.Commands{SetSession: setSessionHandler},
+	})
+
+	response, err := grpcSvc.SetSession(context.Background(), &session.SetSessionRequest{
+		Session:         &session.Session{Key: "Key", Value: value},
+		ExpectedVersion: 3,
+		UpdateOnly:      true,
+	})
+
+	assert.Nil(t, err, "No error is expected")
+	assert.Equal(t, int64(4), response.Version, "Expect the version written to be returned")
+	assert.Equal(t, int64(3), setSessionHandler.cmd.IfVersion, "Expect the expected version to be passed")
+	assert.True(t, setSessionHandler.cmd.UpdateOnly, "Expect the update only flag to be passed")
+	assert.False(t, setSessionHandler.cmd.CreateOnly, "Expect the create only flag not to be set")
+}
+
+func TestSetGrpcSessionShouldPassSubject(t *testing.T) {
+	t.Parallel()
+
+	value, err := structpb.NewStruct(map[string]interface{}{"test": "Grpc"})
+	if err != nil {
+		t.Errorf("Cannot create session value.")
+	}
+
+	someUser, noOne := "someUser", ""
+	tests := []struct {
+		scenario        string
+		request         *session.SetSessionRequest
+		expectedStatus  codes.Code
+		expectedSubject *string
+	}{
+		{
+			scenario:        "Should keep the subject of the session when none is given",
+			request:         &session.SetSessionRequest{Session: &session.Session{Key: "Key", Value: value}},
+			expectedStatus:  codes.OK,
+			expectedSubject: nil,
+		},
+		{
+			scenario:        "Should give the session to its subject",
+			request:         &session.SetSessionRequest{Session: &session.Session{Key: "Key", Value: value, Subject: someUser}},
+			expectedStatus:  codes.OK,
+			expectedSubject: &someUser,
+		},
+		{
+			scenario:        "Should leave the session to no one when its subject is cleared",
+			request:         &session.SetSessionRequest{Session: &session.Session{Key: "Key", Value: value}, ClearSubject: true},
+			expectedStatus:  codes.OK,
+			expectedSubject: &noOne,
+		},
+		{
+			scenario:       "Should respond with Invalid Argument if the subject is given and cleared",
+			request:        &session.SetSessionRequest{Session: &session.Session{Key: "Key", Value: value, Subject: someUser}, ClearSubject: true},
+			expectedStatus: codes.InvalidArgument,
+		},
+	}
+
+	for _, test := range tests {
+		setSessionHandler := &SetSessionHandlerGrpc{testExpectationsGrpc: testExpectationsGrpc{handlerVal: int64(4)}}
+		grpcSvc := service.NewGrpcService(handlers.Application{
+			Commands: handlers.Commands{SetSession: setSessionHandler},
+		})
+
+		_, err := grpcSvc.SetSession(context.Background(), test.request)
+
+		assert.Equal(t, test.expectedStatus, status.Code(err), test.scenario)
+		if test.expectedStatus == codes.OK {
+			assert.Equal(t, test.expectedSubject, setSessionHandler.cmd.Subject, test.scenario)
+		} else {
+			assert.False(t, setSessionHandler.invoked, test.scenario)
+		}
+	}
+}
+
 func TestGetGrpcSession(t *testing.T) {
 	t.Parallel()
 
@@ -180,25 +474,40 @@ func TestGetGrpcSession(t *testing.T) {
 			handlerErr:     fmt.Errorf("Error from handler"),
 		},
 		{
-			scenario:        "Should respond Not Found if handler returns empty response",
-			expectedError:   true,
-			expectedStatus:  codes.NotFound,
-			sessionRequest:  session.GetSessionRequest{Key: "Key"},
-			handlerInvoked:  true,
-			handlerErr:      nil,
-			handlerResponse: "",
+			scenario:       "Should respond with Not Found if session does not exist",
+			expectedError:  true,
+			expectedStatus: codes.NotFound,
+			sessionRequest: session.GetSessionRequest{Key: "Key"},
+			handlerInvoked: true,
+			handlerErr:     domain.ErrNotFound,
 		},
 		{
-			scenario:        "Should return session value",
-			expectedError:   false,
-			sessionRequest:  session.GetSessionRequest{Key: "Key"},
-			handlerInvoked:  true,
-			handlerErr:      nil,
-			handlerResponse: `{"response":"value"}`,
+			scenario:       "Should respond with Unavailable if backend is unavailable",
+			expectedError:  true,
+			expectedStatus: codes.Unavailable,
+			sessionRequest: session.GetSessionRequest{Key: "Key"},
+			handlerInvoked: true,
+			handlerErr:     fmt.Errorf("wrapped: %w", domain.ErrUnavailable),
+		},
+		{
+			scenario:       "Should return session value",
+			expectedError:  false,
+			sessionRequest: session.GetSessionRequest{Key: "Key"},
+			handlerInvoked: true,
+			handlerErr:     nil,
+			handlerResponse: &domain.Session{
+				ID:        "Key",
+				Data:      domain.Data{"response": "value"},
+				CreatedAt: time.UnixMilli(1000),
+				UpdatedAt: time.UnixMilli(2000),
+				NotBefore: time.UnixMilli(1500),
+				Version:   2,
+			},
 		},
 	}
 
-	for _, test := range tests {
+	for i := range tests {
+		test := &tests[i]
 
 		getSessionHandler := &GetSessionHandlerGrpc{
 			testExpectationsGrpc: testExpectationsGrpc{
@@ -234,9 +543,14 @@ func TestGetGrpcSession(t *testing.T) {
 			}
 
 		} else {
-			fmt.Printf("sessionResponse %v\n", sessionResponse.Session.Value.String())
-			assert.True(t, test.sessionRequest.Key == sessionResponse.Session.Key, "Session Key should match")
 			assert.Nil(t, err, fmt.Sprintf("Wasnt expecting an error for scenario '%s'. Got '%v'.\n", test.scenario, err))
+			assert.True(t, test.sessionRequest.Key == sessionResponse.Session.Key, "Session Key should match")
+			assert.Equal(t, map[string]interface{}{"response": "value"}, sessionResponse.Session.Value.AsMap(), "Session value should match")
+			assert.Equal(t, time.UnixMilli(1000).UTC(), sessionResponse.Session.CreatedAt.AsTime(), "Session creation time should match")
+			assert.Equal(t, time.UnixMilli(2000).UTC(), sessionResponse.Session.UpdatedAt.AsTime(), "Session update time should match")
+			assert.Nil(t, sessionResponse.Session.ExpiresAt, "Session without expiry should not have an expiry time")
+			assert.Equal(t, time.UnixMilli(1500).UTC(), sessionResponse.Session.NotBefore.AsTime(), "Session validity start should match")
+			assert.Equal(t, int64(2), sessionResponse.Session.Version, "Session version should match")
 		}
 
 		if test.handlerInvoked {
@@ -284,7 +598,8 @@ func TestDeleteGrpcSession(t *testing.T) {
 		},
 	}
 
-	for _, test := range tests {
+	for i := range tests {
+		test := &tests[i]
 
 		deleteSessionHandler := &DeleteSessionHandlerGrpc{
 			testExpectationsGrpc: testExpectationsGrpc{
@@ -330,3 +645,884 @@ func TestDeleteGrpcSession(t *testing.T) {
 	}
 
 }
+
+func TestGetGrpcSessionFields(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario        string
+		expectedStatus  codes.Code
+		expectedError   bool
+		sessionRequest  *session.GetSessionFieldsRequest
+		handlerInvoked  bool
+		handlerErr      error
+		handlerResponse interface{}
+	}{
+		{
+			scenario:       "Should respond with Invalid Argument if SessionKey empty",
+			expectedError:  true,
+			expectedStatus: codes.InvalidArgument,
+			sessionRequest: &session.GetSessionFieldsRequest{Key: "", Fields: []string{"field"}},
+			handlerInvoked: false,
+		},
+		{
+			scenario:       "Should respond with Invalid Argument if Fields empty",
+			expectedError:  true,
+			expectedStatus: codes.InvalidArgument,
+			sessionRequest: &session.GetSessionFieldsRequest{Key: "Key"},
+			handlerInvoked: false,
+		},
+		{
+			scenario:       "Should respond with Not Found if session does not exist",
+			expectedError:  true,
+			expectedStatus: codes.NotFound,
+			sessionRequest: &session.GetSessionFieldsRequest{Key: "Key", Fields: []string{"field"}},
+			handlerInvoked: true,
+			handlerErr:     domain.ErrNotFound,
+		},
+		{
+			scenario:       "Should respond with Unimplemented if storage does not support fields",
+			expectedError:  true,
+			expectedStatus: codes.Unimplemented,
+			sessionRequest: &session.GetSessionFieldsRequest{Key: "Key", Fields: []string{"field"}},
+			handlerInvoked: true,
+			handlerErr:     domain.ErrNotSupported,
+		},
+		{
+			scenario:        "Should return session fields",
+			expectedError:   false,
+			sessionRequest:  &session.GetSessionFieldsRequest{Key: "Key", Fields: []string{"field"}},
+			handlerInvoked:  true,
+			handlerResponse: map[string]interface{}{"field": "value"},
+		},
+	}
+
+	for _, test := range tests {
+
+		getSessionFieldsHandler := &GetSessionFieldsHandlerGrpc{
+			testExpectationsGrpc: testExpectationsGrpc{
+				handlerErr: test.handlerErr,
+				handlerVal: test.handlerResponse,
+			},
+		}
+
+		appSet := handlers.Application{
+			Queries: handlers.Queries{
+				GetSessionFields: getSessionFieldsHandler,
+			},
+		}
+
+		grpcSvc := service.NewGrpcService(appSet)
+
+		sessionResponse, err := grpcSvc.GetSessionFields(context.Background(), test.sessionRequest)
+
+		if test.expectedError {
+			e, _ := status.FromError(err)
+			assert.True(t, e.Code() == test.expectedStatus, fmt.Sprintf("Expected error is '%d', found '%d'. Scenario %s\n", test.expectedStatus, e.Code(), test.scenario))
+		} else {
+			assert.Nil(t, err, fmt.Sprintf("Wasnt expecting an error for scenario '%s'. Got '%v'.\n", test.scenario, err))
+			assert.Equal(t, test.handlerResponse, sessionResponse.Session.Value.AsMap(), "Session fields should match")
+		}
+
+		assert.Equal(t, test.handlerInvoked, getSessionFieldsHandler.invoked, "'Handle' invocation should match")
+	}
+
+}
+
+func TestSetGrpcSessionFields(t *testing.T) {
+	t.Parallel()
+
+	fields, err := structpb.NewStruct(map[string]interface{}{"field": "value"})
+	if err != nil {
+		t.Errorf("Cannot create session fields.")
+	}
+
+	tests := []struct {
+		scenario        string
+		expectedInvoked bool
+		expectedStatus  codes.Code
+		expectedError   bool
+		sessionRequest  *session.SetSessionFieldsRequest
+		handlerErr      error
+	}{
+		{
+			scenario:        "Should respond with Invalid Argument if SessionKey empty",
+			expectedInvoked: false,
+			expectedError:   true,
+			expectedStatus:  codes.InvalidArgument,
+			sessionRequest:  &session.SetSessionFieldsRequest{Key: "", Fields: fields},
+		},
+		{
+			scenario:        "Should respond with Invalid Argument if Fields empty",
+			expectedInvoked: false,
+			expectedError:   true,
+			expectedStatus:  codes.InvalidArgument,
+			sessionRequest:  &session.SetSessionFieldsRequest{Key: "Key"},
+		},
+		{
+			scenario:        "Should respond with Not Found if session does not exist",
+			expectedInvoked: true,
+			expectedError:   true,
+			expectedStatus:  codes.NotFound,
+			sessionRequest:  &session.SetSessionFieldsRequest{Key: "Key", Fields: fields},
+			handlerErr:      domain.ErrNotFound,
+		},
+		{
+			scenario:        "Should respond with Internal error if handler returns an error",
+			expectedInvoked: true,
+			expectedError:   true,
+			expectedStatus:  codes.Internal,
+			sessionRequest:  &session.SetSessionFieldsRequest{Key: "Key", Fields: fields},
+			handlerErr:      fmt.Errorf("Error from handler"),
+		},
+		{
+			scenario:        "Should respond with Failed Precondition if session is at another version",
+			expectedInvoked: true,
+			expectedError:   true,
+			expectedStatus:  codes.FailedPrecondition,
+			sessionRequest:  &session.SetSessionFieldsRequest{Key: "Key", Fields: fields, ExpectedVersion: 2},
+			handlerErr:      fmt.Errorf("wrapped: %w", domain.ErrPreconditionFailed),
+		},
+		{
+			scenario:        "Should not return any errors if no errors are found",
+			expectedInvoked: true,
+			expectedError:   false,
+			sessionRequest:  &session.SetSessionFieldsRequest{Key: "Key", Fields: fields, ExpectedVersion: 3},
+		},
+	}
+
+	for _, test := range tests {
+
+		setSessionFieldsHandler := &SetSessionFieldsHandlerGrpc{
+			testExpectationsGrpc: testExpectationsGrpc{
+				handlerErr: test.handlerErr,
+			},
+		}
+
+		appSet := handlers.Application{
+			Commands: handlers.Commands{
+				SetSessionFields: setSessionFieldsHandler,
+			},
+		}
+
+		grpcSvc := service.NewGrpcService(appSet)
+
+		_, err := grpcSvc.SetSessionFields(context.Background(), test.sessionRequest)
+
+		if test.expectedError {
+			e, _ := status.FromError(err)
+			assert.True(t, e.Code() == test.expectedStatus, fmt.Sprintf("Expected error is '%d', found '%d'. Scenario %s\n", test.expectedStatus, e.Code(), test.scenario))
+		} else {
+			assert.Nil(t, err, fmt.Sprintf("Wasnt expecting an error for scenario '%s'. Got '%v'.\n", test.scenario, err))
+		}
+
+		assert.Equal(t, test.expectedInvoked, setSessionFieldsHandler.invoked, "'Handle' invocation should match")
+		assert.Equal(t, test.sessionRequest.ExpectedVersion, setSessionFieldsHandler.cmd.IfVersion, test.scenario)
+	}
+
+}
+
+func TestTouchGrpcSession(t *testing.T) {
+	t.Parallel()
+
+	expiresAt := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		scenario          string
+		expectedInvoked   bool
+		expectedStatus    codes.Code
+		expectedError     bool
+		expectedTTL       time.Duration
+		expectedExpiresAt *timestamppb.Timestamp
+		sessionRequest    *session.TouchSessionRequest
+		handlerVal        interface{}
+		handlerErr        error
+	}{
+		{
+			scenario:        "Should respond with Invalid Argument if SessionKey empty",
+			expectedInvoked: false,
+			expectedError:   true,
+			expectedStatus:  codes.InvalidArgument,
+			sessionRequest:  &session.TouchSessionRequest{Key: ""},
+		},
+		{
+			scenario:        "Should respond with Invalid Argument if Ttl is not valid",
+			expectedInvoked: false,
+			expectedError:   true,
+			expectedStatus:  codes.InvalidArgument,
+			sessionRequest:  &session.TouchSessionRequest{Key: "Key", Ttl: &durationpb.Duration{Seconds: 1, Nanos: -1}},
+		},
+		{
+			scenario:        "Should respond with Not Found if session does not exist",
+			expectedInvoked: true,
+			expectedError:   true,
+			expectedStatus:  codes.NotFound,
+			sessionRequest:  &session.TouchSessionRequest{Key: "Key"},
+			handlerErr:      domain.ErrNotFound,
+		},
+		{
+			scenario:          "Should touch for the session own ttl if none is given",
+			expectedInvoked:   true,
+			expectedError:     false,
+			expectedExpiresAt: timestamppb.New(expiresAt),
+			sessionRequest:    &session.TouchSessionRequest{Key: "Key"},
+			handlerVal:        expiresAt,
+		},
+		{
+			scenario:        "Should touch for the requested ttl",
+			expectedInvoked: true,
+			expectedError:   false,
+			expectedTTL:     time.Minute,
+			sessionRequest:  &session.TouchSessionRequest{Key: "Key", Ttl: durationpb.New(time.Minute)},
+		},
+	}
+
+	for _, test := range tests {
+
+		touchSessionHandler := &TouchSessionHandlerGrpc{
+			testExpectationsGrpc: testExpectationsGrpc{
+				handlerVal: test.handlerVal,
+				handlerErr: test.handlerErr,
+			},
+		}
+
+		appTouch := handlers.Application{
+			Commands: handlers.Commands{
+				TouchSession: touchSessionHandler,
+			},
+		}
+
+		grpcSvc := service.NewGrpcService(appTouch)
+
+		res, err := grpcSvc.TouchSession(context.Background(), test.sessionRequest)
+
+		if test.expectedError {
+			e, _ := status.FromError(err)
+			assert.True(t, e.Code() == test.expectedStatus, fmt.Sprintf("Expected error is '%d', found '%d'. Scenario %s\n", test.expectedStatus, e.Code(), test.scenario))
+		} else {
+			assert.Nil(t, err, fmt.Sprintf("Wasnt expecting an error for scenario '%s'. Got '%v'.\n", test.scenario, err))
+			assert.True(t, proto.Equal(test.expectedExpiresAt, res.ExpiresAt), test.scenario)
+		}
+
+		assert.Equal(t, test.expectedInvoked, touchSessionHandler.invoked, "'Handle' invocation should match")
+		assert.Equal(t, test.expectedTTL, touchSessionHandler.ttl, test.scenario)
+	}
+
+}
+
+func TestPatchGrpcSession(t *testing.T) {
+	t.Parallel()
+
+	mergePatch, err := structpb.NewStruct(map[string]interface{}{"theme": "dark", "user": nil})
+	if err != nil {
+		t.Errorf("Cannot create merge patch.")
+	}
+
+	tests := []struct {
+		scenario        string
+		expectedInvoked bool
+		expectedStatus  codes.Code
+		expectedPatch   domain.Patch
+		request         *session.PatchSessionRequest
+		handlerErr      error
+	}{
+		{
+			scenario:       "Should respond with Invalid Argument if SessionKey empty",
+			expectedStatus: codes.InvalidArgument,
+			request:        &session.PatchSessionRequest{Patch: &session.PatchSessionRequest_MergePatch{MergePatch: mergePatch}},
+		},
+		{
+			scenario:       "Should respond with Invalid Argument if Patch empty",
+			expectedStatus: codes.InvalidArgument,
+			request:        &session.PatchSessionRequest{Key: "Key"},
+		},
+		{
+			scenario:       "Should respond with Invalid Argument if a json patch operation is missing its value",
+			expectedStatus: codes.InvalidArgument,
+			request: &session.PatchSessionRequest{Key: "Key", Patch: &session.PatchSessionRequest_JsonPatch{JsonPatch: &session.JsonPatch{
+				Operations: []*session.JsonPatchOperation{{Op: "replace", Path: "/theme"}},
+			}}},
+		},
+		{
+			scenario:        "Should respond with Aborted if a test operation fails",
+			expectedInvoked: true,
+			expectedStatus:  codes.Aborted,
+			expectedPatch:   domain.JSONPatch{{Op: "test", Path: "/theme", Value: nil}},
+			request: &session.PatchSessionRequest{Key: "Key", Patch: &session.PatchSessionRequest_JsonPatch{JsonPatch: &session.JsonPatch{
+				Operations: []*session.JsonPatchOperation{{Op: "test", Path: "/theme", Value: structpb.NewNullValue()}},
+			}}},
+			handlerErr: fmt.Errorf("wrapped: %w", domain.ErrConflict),
+		},
+		{
+			scenario:        "Should respond with Failed Precondition if the session does not match",
+			expectedInvoked: true,
+			expectedStatus:  codes.FailedPrecondition,
+			expectedPatch:   domain.MergePatch{"theme": "dark", "user": nil},
+			request:         &session.PatchSessionRequest{Key: "Key", Patch: &session.PatchSessionRequest_MergePatch{MergePatch: mergePatch}, ExpectedVersion: 2},
+			handlerErr:      fmt.Errorf("wrapped: %w", domain.ErrPreconditionFailed),
+		},
+		{
+			scenario:        "Should patch the session with a merge patch",
+			expectedInvoked: true,
+			expectedStatus:  codes.OK,
+			expectedPatch:   domain.MergePatch{"theme": "dark", "user": nil},
+			request:         &session.PatchSessionRequest{Key: "Key", Patch: &session.PatchSessionRequest_MergePatch{MergePatch: mergePatch}},
+		},
+		{
+			scenario:        "Should patch the session with a json patch",
+			expectedInvoked: true,
+			expectedStatus:  codes.OK,
+			expectedPatch: domain.JSONPatch{
+				{Op: "add", Path: "/props/cart/-", Value: "pear"},
+				{Op: "move", From: "/theme", Path: "/props/theme"},
+			},
+			request: &session.PatchSessionRequest{Key: "Key", Patch: &session.PatchSessionRequest_JsonPatch{JsonPatch: &session.JsonPatch{
+				Operations: []*session.JsonPatchOperation{
+					{Op: "add", Path: "/props/cart/-", Value: structpb.NewStringValue("pear")},
+					{Op: "move", From: "/theme", Path: "/props/theme"},
+				},
+			}}},
+		},
+	}
+
+	for _, test := range tests {
+
+		patchSessionHandler := &PatchSessionHandlerGrpc{
+			testExpectationsGrpc: testExpectationsGrpc{
+				handlerErr: test.handlerErr,
+			},
+		}
+
+		grpcSvc := service.NewGrpcService(handlers.Application{
+			Commands: handlers.Commands{PatchSession: patchSessionHandler},
+		})
+
+		_, err := grpcSvc.PatchSession(context.Background(), test.request)
+
+		assert.Equal(t, test.expectedStatus, status.Code(err), test.scenario)
+		assert.Equal(t, test.expectedInvoked, patchSessionHandler.invoked, test.scenario)
+		if test.expectedInvoked {
+			assert.Equal(t, test.expectedPatch, patchSessionHandler.cmd.Patch, test.scenario)
+			assert.Equal(t, test.request.ExpectedVersion, patchSessionHandler.cmd.IfVersion, test.scenario)
+		}
+	}
+}
+
+func TestGetGrpcSessionValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario        string
+		expectedInvoked bool
+		expectedStatus  codes.Code
+		request         *session.GetSessionValueRequest
+		handlerVal      interface{}
+		handlerErr      error
+	}{
+		{
+			scenario:       "Should return InvalidArgument if SessionKey is empty",
+			expectedStatus: codes.InvalidArgument,
+			request:        &session.GetSessionValueRequest{Pointer: "/props/cart"},
+		},
+		{
+			scenario:        "Should return NotFound if the session holds no value at the pointer",
+			expectedInvoked: true,
+			expectedStatus:  codes.NotFound,
+			request:         &session.GetSessionValueRequest{Key: "Key", Pointer: "/props/cart"},
+			handlerErr:      fmt.Errorf("%w: no value at '/props'", domain.ErrNotFound),
+		},
+		{
+			scenario:        "Should return InvalidArgument if the pointer is not valid",
+			expectedInvoked: true,
+			expectedStatus:  codes.InvalidArgument,
+			request:         &session.GetSessionValueRequest{Key: "Key", Pointer: "props"},
+			handlerErr:      fmt.Errorf("%w: pointer 'props' must start with /", domain.ErrInvalid),
+		},
+		{
+			scenario:        "Should return the value",
+			expectedInvoked: true,
+			expectedStatus:  codes.OK,
+			request:         &session.GetSessionValueRequest{Key: "Key", Pointer: "/props/cart"},
+			handlerVal:      []interface{}{"apple", "pear"},
+		},
+	}
+
+	for _, test := range tests {
+
+		getSessionValueHandler := &GetSessionValueHandlerGrpc{
+			testExpectationsGrpc: testExpectationsGrpc{
+				handlerVal: test.handlerVal,
+				handlerErr: test.handlerErr,
+			},
+		}
+
+		grpcSvc := service.NewGrpcService(handlers.Application{
+			Queries: handlers.Queries{GetSessionValue: getSessionValueHandler},
+		})
+
+		res, err := grpcSvc.GetSessionValue(context.Background(), test.request)
+
+		assert.Equal(t, test.expectedStatus, status.Code(err), test.scenario)
+		assert.Equal(t, test.expectedInvoked, getSessionValueHandler.invoked, test.scenario)
+		if test.expectedInvoked {
+			assert.Equal(t, query.GetSessionValue{Key: test.request.Key, Pointer: test.request.Pointer}, getSessionValueHandler.cmd, test.scenario)
+		}
+		if err == nil {
+			assert.Equal(t, test.handlerVal, res.Value.AsInterface(), test.scenario)
+		}
+	}
+}
+
+func TestSetGrpcSessionValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario        string
+		expectedInvoked bool
+		expectedStatus  codes.Code
+		request         *session.SetSessionValueRequest
+		handlerErr      error
+	}{
+		{
+			scenario:       "Should return InvalidArgument if SessionKey is empty",
+			expectedStatus: codes.InvalidArgument,
+			request:        &session.SetSessionValueRequest{Pointer: "/theme", Value: structpb.NewStringValue("dark")},
+		},
+		{
+			scenario:       "Should return InvalidArgument if Value is missing",
+			expectedStatus: codes.InvalidArgument,
+			request:        &session.SetSessionValueRequest{Key: "Key", Pointer: "/theme"},
+		},
+		{
+			scenario:        "Should return NotFound if the parent of the value does not exist",
+			expectedInvoked: true,
+			expectedStatus:  codes.NotFound,
+			request:         &session.SetSessionValueRequest{Key: "Key", Pointer: "/props/theme", Value: structpb.NewStringValue("dark")},
+			handlerErr:      fmt.Errorf("%w: no value at '/props'", domain.ErrNotFound),
+		},
+		{
+			scenario:        "Should return FailedPrecondition if the session is at another version",
+			expectedInvoked: true,
+			expectedStatus:  codes.FailedPrecondition,
+			request:         &session.SetSessionValueRequest{Key: "Key", Pointer: "/theme", Value: structpb.NewStringValue("dark"), ExpectedVersion: 2},
+			handlerErr:      domain.ErrPreconditionFailed,
+		},
+		{
+			scenario:        "Should set the value",
+			expectedInvoked: true,
+			expectedStatus:  codes.OK,
+			request:         &session.SetSessionValueRequest{Key: "Key", Pointer: "/theme", Value: structpb.NewStringValue("dark")},
+		},
+	}
+
+	for _, test := range tests {
+
+		setSessionValueHandler := &SetSessionValueHandlerGrpc{
+			testExpectationsGrpc: testExpectationsGrpc{
+				handlerErr: test.handlerErr,
+			},
+		}
+
+		grpcSvc := service.NewGrpcService(handlers.Application{
+			Commands: handlers.Commands{SetSessionValue: setSessionValueHandler},
+		})
+
+		_, err := grpcSvc.SetSessionValue(context.Background(), test.request)
+
+		assert.Equal(t, test.expectedStatus, status.Code(err), test.scenario)
+		assert.Equal(t, test.expectedInvoked, setSessionValueHandler.invoked, test.scenario)
+		if test.expectedInvoked {
+			assert.Equal(t, command.SetSessionValue{
+				Key:       test.request.Key,
+				Pointer:   test.request.Pointer,
+				Value:     "dark",
+				IfVersion: test.request.ExpectedVersion,
+			}, setSessionValueHandler.cmd, test.scenario)
+		}
+	}
+}
+
+func TestDeleteGrpcSessionValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario        string
+		expectedInvoked bool
+		expectedStatus  codes.Code
+		request         *session.DeleteSessionValueRequest
+		handlerErr      error
+	}{
+		{
+			scenario:       "Should return InvalidArgument if SessionKey is empty",
+			expectedStatus: codes.InvalidArgument,
+			request:        &session.DeleteSessionValueRequest{Pointer: "/theme"},
+		},
+		{
+			scenario:        "Should return NotFound if the session holds no value at the pointer",
+			expectedInvoked: true,
+			expectedStatus:  codes.NotFound,
+			request:         &session.DeleteSessionValueRequest{Key: "Key", Pointer: "/theme"},
+			handlerErr:      fmt.Errorf("%w: no value at '/theme'", domain.ErrNotFound),
+		},
+		{
+			scenario:        "Should delete the value",
+			expectedInvoked: true,
+			expectedStatus:  codes.OK,
+			request:         &session.DeleteSessionValueRequest{Key: "Key", Pointer: "/theme", ExpectedVersion: 3},
+		},
+	}
+
+	for _, test := range tests {
+
+		deleteSessionValueHandler := &DeleteSessionValueHandlerGrpc{
+			testExpectationsGrpc: testExpectationsGrpc{
+				handlerErr: test.handlerErr,
+			},
+		}
+
+		grpcSvc := service.NewGrpcService(handlers.Application{
+			Commands: handlers.Commands{DeleteSessionValue: deleteSessionValueHandler},
+		})
+
+		_, err := grpcSvc.DeleteSessionValue(context.Background(), test.request)
+
+		assert.Equal(t, test.expectedStatus, status.Code(err), test.scenario)
+		assert.Equal(t, test.expectedInvoked, deleteSessionValueHandler.invoked, test.scenario)
+		if test.expectedInvoked {
+			assert.Equal(t, command.DeleteSessionValue{
+				Key:       test.request.Key,
+				Pointer:   test.request.Pointer,
+				IfVersion: test.request.ExpectedVersion,
+			}, deleteSessionValueHandler.cmd, test.scenario)
+		}
+	}
+}
+
+func TestIncrementGrpcSessionCounter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario        string
+		expectedInvoked bool
+		expectedStatus  codes.Code
+		request         *session.IncrementSessionCounterRequest
+		handlerVal      interface{}
+		handlerErr      error
+	}{
+		{
+			scenario:       "Should return InvalidArgument if SessionKey is empty",
+			expectedStatus: codes.InvalidArgument,
+			request:        &session.IncrementSessionCounterRequest{Pointer: "/views", Delta: 1},
+		},
+		{
+			scenario:        "Should return InvalidArgument if the counter is not an integer",
+			expectedInvoked: true,
+			expectedStatus:  codes.InvalidArgument,
+			request:         &session.IncrementSessionCounterRequest{Key: "Key", Pointer: "/user", Delta: 1},
+			handlerErr:      fmt.Errorf("%w: value at '/user' is not an integer", domain.ErrInvalid),
+		},
+		{
+			scenario:        "Should return NotFound if the session does not exist",
+			expectedInvoked: true,
+			expectedStatus:  codes.NotFound,
+			request:         &session.IncrementSessionCounterRequest{Key: "Key", Pointer: "/views", Delta: 1},
+			handlerErr:      domain.ErrNotFound,
+		},
+		{
+			scenario:        "Should return the value the counter is left at",
+			expectedInvoked: true,
+			expectedStatus:  codes.OK,
+			request:         &session.IncrementSessionCounterRequest{Key: "Key", Pointer: "/stats/failed", Delta: -1},
+			handlerVal:      int64(2),
+		},
+	}
+
+	for _, test := range tests {
+
+		incrementSessionCounterHandler := &IncrementSessionCounterHandlerGrpc{
+			testExpectationsGrpc: testExpectationsGrpc{
+				handlerVal: test.handlerVal,
+				handlerErr: test.handlerErr,
+			},
+		}
+
+		grpcSvc := service.NewGrpcService(handlers.Application{
+			Commands: handlers.Commands{IncrementSessionCounter: incrementSessionCounterHandler},
+		})
+
+		res, err := grpcSvc.IncrementSessionCounter(context.Background(), test.request)
+
+		assert.Equal(t, test.expectedStatus, status.Code(err), test.scenario)
+		assert.Equal(t, test.expectedInvoked, incrementSessionCounterHandler.invoked, test.scenario)
+		if test.expectedInvoked {
+			assert.Equal(t, command.IncrementSessionCounter{
+				Key:     test.request.Key,
+				Pointer: test.request.Pointer,
+				Delta:   test.request.Delta,
+			}, incrementSessionCounterHandler.cmd, test.scenario)
+		}
+		if err == nil {
+			assert.Equal(t, test.handlerVal, res.Value, test.scenario)
+		}
+	}
+}
+
+func TestSetGrpcSessionShouldCreateSessionsWithoutKey(t *testing.T) {
+	t.Parallel()
+
+	sessionValue, err := structpb.NewStruct(map[string]interface{}{"test": "Grpc"})
+	if err != nil {
+		t.Errorf("Cannot create session value.")
+	}
+
+	tests := []struct {
+		scenario        string
+		expectedInvoked bool
+		expectedStatus  codes.Code
+		expectedKey     string
+		request         *session.SetSessionRequest
+		handlerVal      interface{}
+		handlerErr      error
+	}{
+		{
+			scenario:       "Should respond with Invalid Argument if a version is expected",
+			expectedStatus: codes.InvalidArgument,
+			request:        &session.SetSessionRequest{Session: &session.Session{Value: sessionValue}, ExpectedVersion: 1},
+		},
+		{
+			scenario:        "Should respond with Aborted if every generated key is in use",
+			expectedInvoked: true,
+			expectedStatus:  codes.Aborted,
+			request:         &session.SetSessionRequest{Session: &session.Session{Value: sessionValue}},
+			handlerErr:      domain.ErrConflict,
+		},
+		{
+			scenario:        "Should respond with the generated key",
+			expectedInvoked: true,
+			expectedStatus:  codes.OK,
+			expectedKey:     "generatedKey",
+			request:         &session.SetSessionRequest{Session: &session.Session{Value: sessionValue}, Ttl: durationpb.New(time.Minute)},
+			handlerVal:      "generatedKey",
+		},
+	}
+
+	for _, test := range tests {
+
+		createSessionHandler := &CreateSessionHandlerGrpc{
+			testExpectationsGrpc: testExpectationsGrpc{
+				handlerVal: test.handlerVal,
+				handlerErr: test.handlerErr,
+			},
+		}
+		setSessionHandler := &SetSessionHandlerGrpc{}
+
+		grpcSvc := service.NewGrpcService(handlers.Application{
+			Commands: handlers.Commands{CreateSession: createSessionHandler, SetSession: setSessionHandler},
+		})
+
+		res, err := grpcSvc.SetSession(context.Background(), test.request)
+
+		assert.Equal(t, test.expectedStatus, status.Code(err), test.scenario)
+		assert.Equal(t, test.expectedInvoked, createSessionHandler.invoked, test.scenario)
+		assert.False(t, setSessionHandler.invoked, test.scenario)
+		if test.expectedInvoked {
+			assert.Equal(t, test.request.Session.Value.AsMap(), map[string]interface{}(createSessionHandler.cmd.Value), test.scenario)
+			assert.Equal(t, test.request.Ttl.AsDuration(), createSessionHandler.cmd.TTL, test.scenario)
+		}
+		if err == nil {
+			assert.Equal(t, test.expectedKey, res.Key, test.scenario)
+		}
+	}
+}
+
+func TestRotateGrpcSession(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario        string
+		expectedInvoked bool
+		expectedStatus  codes.Code
+		expectedKey     string
+		request         *session.RotateSessionRequest
+		handlerVal      interface{}
+		handlerErr      error
+	}{
+		{
+			scenario:       "Should respond with Invalid Argument if key is empty",
+			expectedStatus: codes.InvalidArgument,
+			request:        &session.RotateSessionRequest{},
+		},
+		{
+			scenario:       "Should respond with Invalid Argument if grace is not valid",
+			expectedStatus: codes.InvalidArgument,
+			request:        &session.RotateSessionRequest{Key: "key", Grace: &durationpb.Duration{Seconds: 1, Nanos: -1}},
+		},
+		{
+			scenario:        "Should respond with Not Found if session does not exist",
+			expectedInvoked: true,
+			expectedStatus:  codes.NotFound,
+			request:         &session.RotateSessionRequest{Key: "key"},
+			handlerErr:      domain.ErrNotFound,
+		},
+		{
+			scenario:        "Should respond with the key the session is moved to",
+			expectedInvoked: true,
+			expectedStatus:  codes.OK,
+			expectedKey:     "rotatedKey",
+			request:         &session.RotateSessionRequest{Key: "key", Grace: durationpb.New(5 * time.Second)},
+			handlerVal:      "rotatedKey",
+		},
+	}
+
+	for _, test := range tests {
+
+		rotateSessionHandler := &RotateSessionHandlerGrpc{
+			testExpectationsGrpc: testExpectationsGrpc{
+				handlerVal: test.handlerVal,
+				handlerErr: test.handlerErr,
+			},
+		}
+
+		grpcSvc := service.NewGrpcService(handlers.Application{
+			Commands: handlers.Commands{RotateSession: rotateSessionHandler},
+		})
+
+		res, err := grpcSvc.RotateSession(context.Background(), test.request)
+
+		assert.Equal(t, test.expectedStatus, status.Code(err), test.scenario)
+		assert.Equal(t, test.expectedInvoked, rotateSessionHandler.invoked, test.scenario)
+		if test.expectedInvoked {
+			assert.Equal(t, test.request.Key, rotateSessionHandler.cmd.Key, test.scenario)
+			assert.Equal(t, test.request.Grace.AsDuration(), rotateSessionHandler.cmd.Grace, test.scenario)
+		}
+		if err == nil {
+			assert.Equal(t, test.expectedKey, res.Key, test.scenario)
+		}
+	}
+}
+
+func TestGetGrpcSubjectSessions(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		scenario         string
+		expectedInvoked  bool
+		expectedStatus   codes.Code
+		expectedSessions int
+		request          *session.GetSubjectSessionsRequest
+		handlerVal       interface{}
+		handlerErr       error
+	}{
+		{
+			scenario:       "Should respond with Invalid Argument if subject is empty",
+			expectedStatus: codes.InvalidArgument,
+			request:        &session.GetSubjectSessionsRequest{},
+		},
+		{
+			scenario:        "Should respond with Unimplemented if the store cannot index sessions",
+			expectedInvoked: true,
+			expectedStatus:  codes.Unimplemented,
+			request:         &session.GetSubjectSessionsRequest{Subject: "someUser"},
+			handlerErr:      domain.ErrNotSupported,
+		},
+		{
+			scenario:         "Should respond with the sessions of the subject",
+			expectedInvoked:  true,
+			expectedStatus:   codes.OK,
+			expectedSessions: 2,
+			request:          &session.GetSubjectSessionsRequest{Subject: "someUser"},
+			handlerVal: []*domain.Session{
+				{ID: "firstKey", Data: domain.Data{"n": 1.0}, CreatedAt: createdAt, Version: 1, Subject: "someUser"},
+				{ID: "secondKey", Data: domain.Data{"n": 2.0}, CreatedAt: createdAt.Add(time.Second), Version: 3, Subject: "someUser"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+
+		getSubjectSessionsHandler := &GetSubjectSessionsHandlerGrpc{
+			testExpectationsGrpc: testExpectationsGrpc{
+				handlerVal: test.handlerVal,
+				handlerErr: test.handlerErr,
+			},
+		}
+
+		grpcSvc := service.NewGrpcService(handlers.Application{
+			Queries: handlers.Queries{GetSubjectSessions: getSubjectSessionsHandler},
+		})
+
+		res, err := grpcSvc.GetSubjectSessions(context.Background(), test.request)
+
+		assert.Equal(t, test.expectedStatus, status.Code(err), test.scenario)
+		assert.Equal(t, test.expectedInvoked, getSubjectSessionsHandler.invoked, test.scenario)
+		if err == nil {
+			assert.Len(t, res.Sessions, test.expectedSessions, test.scenario)
+			assert.Equal(t, "secondKey", res.Sessions[1].Key, test.scenario)
+			assert.Equal(t, "someUser", res.Sessions[1].Subject, test.scenario)
+			assert.Equal(t, int64(3), res.Sessions[1].Version, test.scenario)
+			assert.Equal(t, map[string]interface{}{"n": 2.0}, res.Sessions[1].Value.AsMap(), test.scenario)
+		}
+	}
+}
+
+func TestDeleteGrpcSubjectSessions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario        string
+		expectedInvoked bool
+		expectedStatus  codes.Code
+		expectedDeleted int64
+		request         *session.DeleteSubjectSessionsRequest
+		handlerVal      interface{}
+		handlerErr      error
+	}{
+		{
+			scenario:       "Should respond with Invalid Argument if subject is empty",
+			expectedStatus: codes.InvalidArgument,
+			request:        &session.DeleteSubjectSessionsRequest{},
+		},
+		{
+			scenario:        "Should respond with Aborted if sessions keep being created concurrently",
+			expectedInvoked: true,
+			expectedStatus:  codes.Aborted,
+			request:         &session.DeleteSubjectSessionsRequest{Subject: "someUser"},
+			handlerErr:      domain.ErrConflict,
+		},
+		{
+			scenario:        "Should respond with how many sessions were deleted",
+			expectedInvoked: true,
+			expectedStatus:  codes.OK,
+			expectedDeleted: 3,
+			request:         &session.DeleteSubjectSessionsRequest{Subject: "someUser"},
+			handlerVal:      3,
+		},
+	}
+
+	for _, test := range tests {
+
+		deleteSubjectSessionsHandler := &DeleteSubjectSessionsHandlerGrpc{
+			testExpectationsGrpc: testExpectationsGrpc{
+				handlerVal: test.handlerVal,
+				handlerErr: test.handlerErr,
+			},
+		}
+
+		grpcSvc := service.NewGrpcService(handlers.Application{
+			Commands: handlers.Commands{DeleteSubjectSessions: deleteSubjectSessionsHandler},
+		})
+
+		res, err := grpcSvc.DeleteSubjectSessions(context.Background(), test.request)
+
+		assert.Equal(t, test.expectedStatus, status.Code(err), test.scenario)
+		assert.Equal(t, test.expectedInvoked, deleteSubjectSessionsHandler.invoked, test.scenario)
+		if test.expectedInvoked {
+			assert.Equal(t, test.request.Subject, deleteSubjectSessionsHandler.cmd.Subject, test.scenario)
+		}
+		if err == nil {
+			assert.Equal(t, test.expectedDeleted, res.Deleted, test.scenario)
+		}
+	}
+}
@@ -2,15 +2,37 @@ package service
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"mime"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 	"github.com/jruben-rg/go-session-svc/server"
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
 	"github.com/jruben-rg/go-session-svc/sessions/handlers"
 	"github.com/jruben-rg/go-session-svc/sessions/handlers/command"
 	"github.com/jruben-rg/go-session-svc/sessions/handlers/query"
 )
 
+// maxTTLSeconds is the longest ttl a time.Duration can hold, in seconds.
+const maxTTLSeconds = int64(math.MaxInt64 / time.Second)
+
+// sessionExpiresAtHeader reports when a session read or touched expires.
+const sessionExpiresAtHeader = "Session-Expires-At"
+
+// Media types of the patches PatchSession accepts.
+const (
+	mergePatchMediaType = "application/merge-patch+json"
+	jsonPatchMediaType  = "application/json-patch+json"
+)
+
 type HttpService struct {
 	app handlers.Application
 }
@@ -21,7 +43,7 @@ func NewHttpService(application handlers.Application) HttpService {
 	}
 }
 
-func (h HttpService) SetSession(w http.ResponseWriter, r *http.Request) {
+func (h HttpService) SetSession(w http.ResponseWriter, r *http.Request, params server.SetSessionParams) {
 
 	postSession := server.PostSession{}
 	if err := render.Decode(r, &postSession); err != nil {
@@ -29,21 +51,142 @@ func (h HttpService) SetSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if postSession.SessionKey == "" {
+	if postSession.SessionKey != nil && *postSession.SessionKey == "" {
 		http.Error(w, "SessionId cannot be empty", http.StatusBadRequest)
 		return
 	}
 
-	err := h.app.Commands.SetSession.Handle(r.Context(), command.SetSession{
-		Key:   postSession.SessionKey,
-		Value: postSession.SessionValue,
-	})
+	ttl, ok := fromTTLSeconds(postSession.Ttl)
+	if !ok {
+		http.Error(w, "Ttl is out of range", http.StatusBadRequest)
+		return
+	}
+
+	var notBefore time.Time
+	if postSession.NotBefore != nil {
+		notBefore = *postSession.NotBefore
+	}
+
+	if postSession.SessionKey == nil {
+		if params.IfMatch != nil {
+			http.Error(w, "If-Match needs a SessionId", http.StatusBadRequest)
+			return
+		}
+		var subject string
+		if postSession.Subject != nil {
+			subject = *postSession.Subject
+		}
+		h.createSession(w, r, command.CreateSession{
+			Value:     postSession.SessionValue,
+			TTL:       ttl,
+			NotBefore: notBefore,
+			Subject:   subject,
+		})
+		return
+	}
+
+	cmd := command.SetSession{
+		Key:       *postSession.SessionKey,
+		Value:     postSession.SessionValue,
+		TTL:       ttl,
+		KeepTTL:   postSession.KeepTtl != nil && *postSession.KeepTtl,
+		NotBefore: notBefore,
+		Subject:   postSession.Subject,
+	}
+
+	if params.IfMatch != nil {
+		if *params.IfMatch == "*" {
+			cmd.UpdateOnly = true
+		} else if cmd.IfVersion, ok = fromETag(*params.IfMatch); !ok {
+			http.Error(w, "If-Match must be * or a single ETag", http.StatusBadRequest)
+			return
+		}
+	}
+	if params.IfNoneMatch != nil {
+		if *params.IfNoneMatch != "*" {
+			http.Error(w, "If-None-Match must be *", http.StatusBadRequest)
+			return
+		}
+		cmd.CreateOnly = true
+	}
+
+	version, err := h.app.Commands.SetSession.Handle(r.Context(), cmd)
 
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		httpError(w, err)
 		return
 	}
 
+	w.Header().Set("ETag", toETag(version))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// createSession stores a session under a generated key, and responds with the
+// key both in the body and as the Location of the session.
+func (h HttpService) createSession(w http.ResponseWriter, r *http.Request, cmd command.CreateSession) {
+
+	key, err := h.app.Commands.CreateSession.Handle(r.Context(), cmd)
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", strings.TrimSuffix(r.URL.Path, "/")+"/"+url.PathEscape(key))
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, server.CreatedSession{SessionKey: key})
+}
+
+func (h HttpService) PatchSession(w http.ResponseWriter, r *http.Request, sessionId string, params server.PatchSessionParams) {
+
+	if sessionId == "" {
+		http.Error(w, "SessionId cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	cmd := command.PatchSession{Key: sessionId}
+
+	if params.IfMatch != nil {
+		var ok bool
+		if cmd.IfVersion, ok = fromETag(*params.IfMatch); !ok {
+			http.Error(w, "If-Match must be a single ETag", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// render.Decode only knows about plain JSON, so patches are decoded here.
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case mergePatchMediaType:
+		mergePatch := server.MergePatch{}
+		if err := json.NewDecoder(r.Body).Decode(&mergePatch); err != nil || mergePatch == nil {
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+		cmd.Patch = session.MergePatch(mergePatch)
+	case jsonPatchMediaType:
+		jsonPatch := []jsonPatchOperation{}
+		if err := json.NewDecoder(r.Body).Decode(&jsonPatch); err != nil {
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+		patch, err := fromJsonPatch(jsonPatch)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		cmd.Patch = patch
+	default:
+		http.Error(w, "Unsupported Media Type", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	version, err := h.app.Commands.PatchSession.Handle(r.Context(), cmd)
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", toETag(version))
 	w.WriteHeader(http.StatusAccepted)
 }
 
@@ -59,7 +202,7 @@ func (h HttpService) DeleteSession(w http.ResponseWriter, r *http.Request, sessi
 	})
 
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		httpError(w, err)
 		return
 	}
 
@@ -67,7 +210,7 @@ func (h HttpService) DeleteSession(w http.ResponseWriter, r *http.Request, sessi
 
 }
 
-func (h HttpService) GetSession(w http.ResponseWriter, r *http.Request, sessionId string) {
+func (h HttpService) GetSession(w http.ResponseWriter, r *http.Request, sessionId string, params server.GetSessionParams) {
 
 	if sessionId == "" {
 		http.Error(w, "SessionId cannot be emmpty", http.StatusBadRequest)
@@ -78,28 +221,456 @@ func (h HttpService) GetSession(w http.ResponseWriter, r *http.Request, sessionI
 		Key: sessionId,
 	})
 
-	sessionStr, ok := res.(string)
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+
+	if res.Version > 0 {
+		etag := toETag(res.Version)
+		w.Header().Set("ETag", etag)
+		if params.IfNoneMatch != nil && etagMatches(*params.IfNoneMatch, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !res.ExpiresAt.IsZero() {
+		w.Header().Set(sessionExpiresAtHeader, res.ExpiresAt.UTC().Format(time.RFC3339Nano))
+	}
+	render.Respond(w, r, res.Data)
+}
+
+func (h HttpService) GetSessionFields(w http.ResponseWriter, r *http.Request, sessionId string, params server.GetSessionFieldsParams) {
+
+	if sessionId == "" || len(params.Field) == 0 {
+		http.Error(w, "SessionId and fields cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	fields, err := h.app.Queries.GetSessionFields.Handle(r.Context(), query.GetSessionFields{
+		Key:    sessionId,
+		Fields: params.Field,
+	})
+
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	render.Respond(w, r, fields)
+}
+
+func (h HttpService) SetSessionFields(w http.ResponseWriter, r *http.Request, sessionId string, params server.SetSessionFieldsParams) {
+
+	if sessionId == "" {
+		http.Error(w, "SessionId cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	cmd := command.SetSessionFields{Key: sessionId}
+
+	if params.IfMatch != nil {
+		var ok bool
+		if cmd.IfVersion, ok = fromETag(*params.IfMatch); !ok {
+			http.Error(w, "If-Match must be a single ETag", http.StatusBadRequest)
+			return
+		}
+	}
+
+	fields := server.SessionFields{}
+	if err := render.Decode(r, &fields); err != nil || len(fields) == 0 {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	cmd.Values = fields
+
+	version, err := h.app.Commands.SetSessionFields.Handle(r.Context(), cmd)
+
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", toETag(version))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h HttpService) TouchSession(w http.ResponseWriter, r *http.Request, sessionId string) {
+
+	if sessionId == "" {
+		http.Error(w, "SessionId cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	// The body is optional, without one the session is touched for its own ttl.
+	touchSession := server.TouchSession{}
+	if r.ContentLength != 0 {
+		if err := render.Decode(r, &touchSession); err != nil {
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ttl, ok := fromTTLSeconds(touchSession.Ttl)
 	if !ok {
-		http.Error(w, "Cannot parse session value", http.StatusInternalServerError)
+		http.Error(w, "Ttl is out of range", http.StatusBadRequest)
 		return
 	}
 
-	if sessionStr == "" {
-		http.Error(w, "Not Found", http.StatusNotFound)
+	expiresAt, err := h.app.Commands.TouchSession.Handle(r.Context(), command.TouchSession{
+		Key: sessionId,
+		TTL: ttl,
+	})
+
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+
+	if !expiresAt.IsZero() {
+		w.Header().Set(sessionExpiresAtHeader, expiresAt.UTC().Format(time.RFC3339Nano))
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h HttpService) RotateSession(w http.ResponseWriter, r *http.Request, sessionId string) {
+
+	if sessionId == "" {
+		http.Error(w, "SessionId cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	// The body is optional, without one the old key stops working right away.
+	rotateSession := server.RotateSession{}
+	if r.ContentLength != 0 {
+		if err := render.Decode(r, &rotateSession); err != nil {
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+	}
+
+	grace, ok := fromTTLSeconds(rotateSession.Grace)
+	if !ok {
+		http.Error(w, "Grace is out of range", http.StatusBadRequest)
 		return
 	}
 
+	key, err := h.app.Commands.RotateSession.Handle(r.Context(), command.RotateSession{
+		Key:   sessionId,
+		Grace: grace,
+	})
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		httpError(w, err)
+		return
+	}
+
+	// The session is now found next to where it was, under its new key.
+	location := strings.TrimSuffix(r.URL.EscapedPath(), "/")
+	for i := 0; i < 2; i++ {
+		location = location[:strings.LastIndex(location, "/")]
+	}
+	w.Header().Set("Location", location+"/"+url.PathEscape(key))
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, server.CreatedSession{SessionKey: key})
+}
+
+func (h HttpService) GetSubjectSessions(w http.ResponseWriter, r *http.Request, subject string) {
+
+	if subject == "" {
+		http.Error(w, "Subject cannot be empty", http.StatusBadRequest)
 		return
 	}
 
-	session := command.SessionValue{}
-	if err := json.Unmarshal([]byte(sessionStr), &session); err != nil {
-		http.Error(w, "Error when unmarshalling session value to json", http.StatusInternalServerError)
+	sessions, err := h.app.Queries.GetSubjectSessions.Handle(r.Context(), query.GetSubjectSessions{
+		Subject: subject,
+	})
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+
+	res := server.SubjectSessions{Sessions: make([]server.SubjectSession, 0, len(sessions))}
+	for _, s := range sessions {
+		res.Sessions = append(res.Sessions, server.SubjectSession{
+			SessionKey: s.ID,
+			CreatedAt:  toTimePtr(s.CreatedAt),
+			UpdatedAt:  toTimePtr(s.UpdatedAt),
+			ExpiresAt:  toTimePtr(s.ExpiresAt),
+			Version:    s.Version,
+		})
+	}
+	render.JSON(w, r, res)
+}
+
+func (h HttpService) DeleteSubjectSessions(w http.ResponseWriter, r *http.Request, subject string) {
+
+	if subject == "" {
+		http.Error(w, "Subject cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := h.app.Commands.DeleteSubjectSessions.Handle(r.Context(), command.DeleteSubjectSessions{
+		Subject: subject,
+	})
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+
+	render.JSON(w, r, server.DeletedSessions{Deleted: deleted})
+}
+
+func (h HttpService) GetSessionValue(w http.ResponseWriter, r *http.Request, sessionId string) {
+
+	if sessionId == "" {
+		http.Error(w, "SessionId cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	pointer, ok := valuePointer(r)
+	if !ok {
+		http.Error(w, "Pointer is malformed", http.StatusBadRequest)
+		return
+	}
+
+	value, err := h.app.Queries.GetSessionValue.Handle(r.Context(), query.GetSessionValue{
+		Key:     sessionId,
+		Pointer: pointer,
+	})
+
+	if err != nil {
+		httpError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	render.Respond(w, r, session)
+	render.Respond(w, r, value)
+}
+
+func (h HttpService) SetSessionValue(w http.ResponseWriter, r *http.Request, sessionId string, params server.SetSessionValueParams) {
+
+	if sessionId == "" {
+		http.Error(w, "SessionId cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	pointer, ok := valuePointer(r)
+	if !ok {
+		http.Error(w, "Pointer is malformed", http.StatusBadRequest)
+		return
+	}
+
+	cmd := command.SetSessionValue{Key: sessionId, Pointer: pointer}
+
+	if params.IfMatch != nil {
+		if cmd.IfVersion, ok = fromETag(*params.IfMatch); !ok {
+			http.Error(w, "If-Match must be a single ETag", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var value server.SessionValue
+	if err := render.Decode(r, &value); err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	cmd.Value = value
+
+	version, err := h.app.Commands.SetSessionValue.Handle(r.Context(), cmd)
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", toETag(version))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h HttpService) DeleteSessionValue(w http.ResponseWriter, r *http.Request, sessionId string, params server.DeleteSessionValueParams) {
+
+	if sessionId == "" {
+		http.Error(w, "SessionId cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	pointer, ok := valuePointer(r)
+	if !ok {
+		http.Error(w, "Pointer is malformed", http.StatusBadRequest)
+		return
+	}
+
+	cmd := command.DeleteSessionValue{Key: sessionId, Pointer: pointer}
+
+	if params.IfMatch != nil {
+		if cmd.IfVersion, ok = fromETag(*params.IfMatch); !ok {
+			http.Error(w, "If-Match must be a single ETag", http.StatusBadRequest)
+			return
+		}
+	}
+
+	version, err := h.app.Commands.DeleteSessionValue.Handle(r.Context(), cmd)
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", toETag(version))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h HttpService) IncrementSessionCounter(w http.ResponseWriter, r *http.Request, sessionId string) {
+
+	if sessionId == "" {
+		http.Error(w, "SessionId cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	pointer, ok := valuePointer(r)
+	if !ok {
+		http.Error(w, "Pointer is malformed", http.StatusBadRequest)
+		return
+	}
+
+	increment := server.IncrementSessionCounter{}
+	if err := render.Decode(r, &increment); err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	value, err := h.app.Commands.IncrementSessionCounter.Handle(r.Context(), command.IncrementSessionCounter{
+		Key:     sessionId,
+		Pointer: pointer,
+		Delta:   increment.Delta,
+	})
+
+	if err != nil {
+		httpError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	render.Respond(w, r, server.SessionCounter{Value: value})
+}
+
+// fromTTLSeconds converts an optional ttl in seconds, zero when missing. It
+// reports whether the ttl is in range.
+func fromTTLSeconds(seconds *int64) (time.Duration, bool) {
+	if seconds == nil {
+		return 0, true
+	}
+	if *seconds < 0 || *seconds > maxTTLSeconds {
+		return 0, false
+	}
+	return time.Duration(*seconds) * time.Second, true
+}
+
+// toTimePtr converts an optional time, nil when zero.
+func toTimePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
+
+// jsonPatchOperation is an operation of a JSON Patch request, its value kept
+// as sent so that a null value can be told from a missing one.
+type jsonPatchOperation struct {
+	server.JsonPatchOperation
+	Value json.RawMessage `json:"value"`
+}
+
+// fromJsonPatch converts the operations of a JSON Patch request, failing when
+// one of them is missing the value it needs.
+func fromJsonPatch(jsonPatch []jsonPatchOperation) (session.JSONPatch, error) {
+	patch := make(session.JSONPatch, 0, len(jsonPatch))
+	for i, op := range jsonPatch {
+		operation := session.PatchOperation{Op: string(op.Op), Path: op.Path}
+		if op.From != nil {
+			operation.From = *op.From
+		}
+		if len(op.Value) == 0 {
+			if operation.NeedsValue() {
+				return nil, fmt.Errorf("Operation %d (%s) needs a value", i, op.Op)
+			}
+		} else if err := json.Unmarshal(op.Value, &operation.Value); err != nil {
+			return nil, fmt.Errorf("Operation %d has a malformed value", i)
+		}
+		patch = append(patch, operation)
+	}
+	return patch, nil
+}
+
+// valuePointer returns the JSON Pointer the rest of the path of a values or
+// counters route makes up, with the slash leading it, or the pointer to the
+// whole data when there is none. It reports whether the path could be
+// unescaped.
+func valuePointer(r *http.Request) (string, bool) {
+	rest := chi.URLParam(r, "*")
+	// Paths are routed escaped when they hold escapes the plain path loses.
+	if r.URL.RawPath != "" {
+		var err error
+		if rest, err = url.PathUnescape(rest); err != nil {
+			return "", false
+		}
+	}
+	if rest == "" {
+		return "", true
+	}
+	return "/" + rest, true
+}
+
+// toETag returns the strong ETag of a session version.
+func toETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// fromETag returns the session version of a strong ETag. It reports whether
+// etag is one.
+func fromETag(etag string) (int64, bool) {
+	if len(etag) < 2 || etag[0] != '"' || etag[len(etag)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.ParseInt(etag[1:len(etag)-1], 10, 64)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+// etagMatches tells whether etag is one of the ETags listed in header, compared
+// weakly as If-None-Match requires.
+func etagMatches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// httpError responds with the status code matching a handler error.
+func httpError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, session.ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, session.ErrNotFound):
+		http.Error(w, "Not Found", http.StatusNotFound)
+	case errors.Is(err, session.ErrNotSupported):
+		http.Error(w, "Not Implemented", http.StatusNotImplemented)
+	case errors.Is(err, session.ErrPreconditionFailed):
+		http.Error(w, "Precondition Failed", http.StatusPreconditionFailed)
+	case errors.Is(err, session.ErrConflict):
+		http.Error(w, "Conflict", http.StatusConflict)
+	case errors.Is(err, session.ErrReadOnly):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, session.ErrUnavailable):
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+	default:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
 }
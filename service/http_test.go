@@ -8,8 +8,12 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/jruben-rg/go-session-svc/server"
 	"github.com/jruben-rg/go-session-svc/service"
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
 	"github.com/jruben-rg/go-session-svc/sessions/handlers"
 	"github.com/jruben-rg/go-session-svc/sessions/handlers/command"
 	"github.com/jruben-rg/go-session-svc/sessions/handlers/query"
@@ -47,9 +51,10 @@ func (ssht *SetSessionHandlerHttp) Handle(ctx context.Context, cmd command.SetSe
 	return ssht.handlerErr
 }
 
-func (gsht *GetSessionHandlerHttp) Handle(ctx context.Context, cmd query.GetSession) (interface{}, error) {
+func (gsht *GetSessionHandlerHttp) Handle(ctx context.Context, cmd query.GetSession) (*session.Session, error) {
 	gsht.invoked = true
-	return gsht.handlerVal, gsht.handlerErr
+	s, _ := gsht.handlerVal.(*session.Session)
+	return s, gsht.handlerErr
 }
 
 func TestSetHttpSession(t *testing.T) {
@@ -60,6 +65,9 @@ func TestSetHttpSession(t *testing.T) {
 		expectedInvoked bool
 		expectedStatus  int
 		requestBody     io.Reader
+		params          server.SetSessionParams
+		expectedCmd     command.SetSession
+		expectedETag    string
 		err             error
 	}{
 		{
@@ -76,6 +84,20 @@ func TestSetHttpSession(t *testing.T) {
 			requestBody:     strings.NewReader(`{"sessionKey":"","sessionValue":{"value":"test"}}`),
 			err:             nil,
 		},
+		{
+			scenario:        "Should respond with bad request if ttl is negative",
+			expectedInvoked: false,
+			expectedStatus:  http.StatusBadRequest,
+			requestBody:     strings.NewReader(`{"sessionKey":"key","sessionValue":{"value":"test"},"ttl":-1}`),
+			err:             nil,
+		},
+		{
+			scenario:        "Should respond with bad request if ttl is over the maximum",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusBadRequest,
+			requestBody:     strings.NewReader(`{"sessionKey":"key","sessionValue":{"value":"test"},"ttl":3600}`),
+			err:             fmt.Errorf("wrapped: %w", session.ErrInvalid),
+		},
 		{
 			scenario:        "Should respond with internal server error if handler returns an error",
 			expectedInvoked: true,
@@ -84,10 +106,78 @@ func TestSetHttpSession(t *testing.T) {
 			err:             fmt.Errorf("Error from handler"),
 		},
 		{
-			scenario:        "Should respond with accepted if no errors are found",
+			scenario:        "Should respond with bad request if If-Match is not an ETag",
+			expectedInvoked: false,
+			expectedStatus:  http.StatusBadRequest,
+			requestBody:     strings.NewReader(`{"sessionKey":"key","sessionValue":{"value":"test"}}`),
+			params:          server.SetSessionParams{IfMatch: stringPtr("3")},
+		},
+		{
+			scenario:        "Should respond with bad request if If-None-Match is not *",
+			expectedInvoked: false,
+			expectedStatus:  http.StatusBadRequest,
+			requestBody:     strings.NewReader(`{"sessionKey":"key","sessionValue":{"value":"test"}}`),
+			params:          server.SetSessionParams{IfNoneMatch: stringPtr(`"3"`)},
+		},
+		{
+			scenario:        "Should respond with precondition failed if the session does not match",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusPreconditionFailed,
+			requestBody:     strings.NewReader(`{"sessionKey":"key","sessionValue":{"value":"test"}}`),
+			params:          server.SetSessionParams{IfMatch: stringPtr(`"3"`)},
+			expectedCmd:     command.SetSession{IfVersion: 3},
+			err:             fmt.Errorf("wrapped: %w", session.ErrPreconditionFailed),
+		},
+		{
+			scenario:        "Should respond with conflict if the session keeps changing concurrently",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusConflict,
+			requestBody:     strings.NewReader(`{"sessionKey":"key","sessionValue":{"value":"test"}}`),
+			err:             fmt.Errorf("wrapped: %w", session.ErrConflict),
+		},
+		{
+			scenario:        "Should respond with conflict if the session was rotated",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusConflict,
+			requestBody:     strings.NewReader(`{"sessionKey":"key","sessionValue":{"value":"test"}}`),
+			err:             fmt.Errorf("wrapped: %w", session.ErrReadOnly),
+		},
+		{
+			scenario:        "Should only update the session if If-Match is *",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusAccepted,
+			requestBody:     strings.NewReader(`{"sessionKey":"key","sessionValue":{"value":"test"}}`),
+			params:          server.SetSessionParams{IfMatch: stringPtr("*")},
+			expectedCmd:     command.SetSession{UpdateOnly: true},
+		},
+		{
+			scenario:        "Should only create the session if If-None-Match is *",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusAccepted,
+			requestBody:     strings.NewReader(`{"sessionKey":"key","sessionValue":{"value":"test"}}`),
+			params:          server.SetSessionParams{IfNoneMatch: stringPtr("*")},
+			expectedCmd:     command.SetSession{CreateOnly: true},
+		},
+		{
+			scenario:        "Should leave the session to no one if subject is empty",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusAccepted,
+			requestBody:     strings.NewReader(`{"sessionKey":"key","sessionValue":{"value":"test"},"subject":""}`),
+			expectedCmd:     command.SetSession{Subject: stringPtr("")},
+		},
+		{
+			scenario:        "Should store the session for its subject",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusAccepted,
+			requestBody:     strings.NewReader(`{"sessionKey":"key","sessionValue":{"value":"test"},"subject":"someUser"}`),
+			expectedCmd:     command.SetSession{Subject: stringPtr("someUser")},
+		},
+		{
+			scenario:        "Should respond with accepted and the ETag written if no errors are found",
 			expectedInvoked: true,
 			expectedStatus:  http.StatusAccepted,
 			requestBody:     strings.NewReader(`{"sessionKey":"key","sessionValue":{"value":"test"}}`),
+			expectedETag:    `"4"`,
 			err:             nil,
 		},
 	}
@@ -96,6 +186,7 @@ func TestSetHttpSession(t *testing.T) {
 
 		setSessionHandler := &SetSessionHandlerGrpc{
 			testExpectationsGrpc: testExpectationsGrpc{
+				handlerVal: int64(4),
 				handlerErr: test.err,
 			},
 		}
@@ -113,12 +204,19 @@ func TestSetHttpSession(t *testing.T) {
 		request := httptest.NewRequest(http.MethodPost, "/api/session", test.requestBody)
 		request.Header.Set("Content-Type", "application/json")
 		response := httptest.NewRecorder()
-		httpSvc.SetSession(response, request)
+		httpSvc.SetSession(response, request, test.params)
 
 		assert.True(t, response.Code == test.expectedStatus, fmt.Sprintf("Should respond with status code %d\n", test.expectedStatus))
 
 		if test.expectedInvoked {
 			assert.True(t, setSessionHandler.invoked, "'Handle' should have been invoked")
+			assert.Equal(t, test.expectedCmd.IfVersion, setSessionHandler.cmd.IfVersion, test.scenario)
+			assert.Equal(t, test.expectedCmd.CreateOnly, setSessionHandler.cmd.CreateOnly, test.scenario)
+			assert.Equal(t, test.expectedCmd.UpdateOnly, setSessionHandler.cmd.UpdateOnly, test.scenario)
+			assert.Equal(t, test.expectedCmd.Subject, setSessionHandler.cmd.Subject, test.scenario)
+			if test.expectedETag != "" {
+				assert.Equal(t, test.expectedETag, response.Header().Get("ETag"), test.scenario)
+			}
 		} else {
 			assert.False(t, setSessionHandler.invoked, "'Handle' should not have been invoked")
 		}
@@ -133,7 +231,10 @@ func TestGetHttpSession(t *testing.T) {
 		scenario        string
 		expectedInvoked bool
 		expectedStatus  int
+		expectedExpiry  string
+		expectedETag    string
 		sessionKey      string
+		params          server.GetSessionParams
 		val             interface{}
 		err             error
 	}{
@@ -152,36 +253,52 @@ func TestGetHttpSession(t *testing.T) {
 			err:             fmt.Errorf("Error from handler"),
 		},
 		{
-			scenario:        "Should respond with internal server error if result cannot be parsed",
+			scenario:        "Should respond with not found if session does not exist",
 			expectedInvoked: true,
-			expectedStatus:  http.StatusInternalServerError,
+			expectedStatus:  http.StatusNotFound,
 			sessionKey:      "sessionKeyValue",
-			val:             nil,
-			err:             nil,
+			err:             session.ErrNotFound,
 		},
 		{
-			scenario:        "Should respond with not found if result is empty",
+			scenario:        "Should respond with service unavailable if backend is unavailable",
 			expectedInvoked: true,
-			expectedStatus:  http.StatusNotFound,
+			expectedStatus:  http.StatusServiceUnavailable,
 			sessionKey:      "sessionKeyValue",
-			val:             ``,
-			err:             nil,
+			err:             fmt.Errorf("wrapped: %w", session.ErrUnavailable),
 		},
 		{
-			scenario:        "Should respond with internal server error if handler response cannot be parsed",
+			scenario:        "Should respond with session data",
 			expectedInvoked: true,
-			expectedStatus:  http.StatusInternalServerError,
+			expectedStatus:  http.StatusOK,
 			sessionKey:      "sessionKeyValue",
-			val:             `aValue`,
+			val:             &session.Session{ID: "sessionKeyValue", Data: session.Data{"sessionValue": map[string]interface{}{"value": "test"}}},
 			err:             nil,
 		},
 		{
-			scenario:        "Should respond with session data",
+			scenario:        "Should report when the session expires",
 			expectedInvoked: true,
 			expectedStatus:  http.StatusOK,
+			expectedExpiry:  "2022-07-01T10:00:00.5Z",
 			sessionKey:      "sessionKeyValue",
-			val:             `{"sessionValue":{"value":"test"}}`,
-			err:             nil,
+			val:             &session.Session{ID: "sessionKeyValue", Data: session.Data{}, ExpiresAt: time.Date(2022, 7, 1, 10, 0, 0, 5e8, time.UTC)},
+		},
+		{
+			scenario:        "Should report the version of the session as its ETag",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusOK,
+			expectedETag:    `"4"`,
+			sessionKey:      "sessionKeyValue",
+			params:          server.GetSessionParams{IfNoneMatch: stringPtr(`"3"`)},
+			val:             &session.Session{ID: "sessionKeyValue", Data: session.Data{}, Version: 4},
+		},
+		{
+			scenario:        "Should respond with not modified if the client holds the session already",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusNotModified,
+			expectedETag:    `"4"`,
+			sessionKey:      "sessionKeyValue",
+			params:          server.GetSessionParams{IfNoneMatch: stringPtr(`"3", W/"4"`)},
+			val:             &session.Session{ID: "sessionKeyValue", Data: session.Data{}, Version: 4},
 		},
 	}
 
@@ -207,9 +324,11 @@ func TestGetHttpSession(t *testing.T) {
 		request := httptest.NewRequest(http.MethodPost, "/api/session", strings.NewReader(""))
 		request.Header.Set("Content-Type", "application/json")
 		response := httptest.NewRecorder()
-		httpSvc.GetSession(response, request, test.sessionKey)
+		httpSvc.GetSession(response, request, test.sessionKey, test.params)
 
 		assert.True(t, response.Code == test.expectedStatus, fmt.Sprintf("Should respond with status code %d\n", test.expectedStatus))
+		assert.Equal(t, test.expectedExpiry, response.Header().Get("Session-Expires-At"), test.scenario)
+		assert.Equal(t, test.expectedETag, response.Header().Get("ETag"), test.scenario)
 
 		if test.expectedInvoked {
 			assert.True(t, getSessionHandler.invoked, "'Handle' should have been invoked")
@@ -286,3 +405,1003 @@ func TestDeleteHttpSession(t *testing.T) {
 	}
 
 }
+
+func TestGetHttpSessionFields(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario        string
+		expectedInvoked bool
+		expectedStatus  int
+		sessionKey      string
+		fields          []string
+		val             interface{}
+		err             error
+	}{
+		{
+			scenario:        "Should respond with bad request if SessionKey is empty",
+			expectedInvoked: false,
+			expectedStatus:  http.StatusBadRequest,
+			sessionKey:      "",
+			fields:          []string{"field"},
+		},
+		{
+			scenario:        "Should respond with bad request if no fields are requested",
+			expectedInvoked: false,
+			expectedStatus:  http.StatusBadRequest,
+			sessionKey:      "sessionKeyValue",
+		},
+		{
+			scenario:        "Should respond with not found if session does not exist",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusNotFound,
+			sessionKey:      "sessionKeyValue",
+			fields:          []string{"field"},
+			err:             session.ErrNotFound,
+		},
+		{
+			scenario:        "Should respond with not implemented if storage does not support fields",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusNotImplemented,
+			sessionKey:      "sessionKeyValue",
+			fields:          []string{"field"},
+			err:             session.ErrNotSupported,
+		},
+		{
+			scenario:        "Should respond with session fields",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusOK,
+			sessionKey:      "sessionKeyValue",
+			fields:          []string{"field"},
+			val:             map[string]interface{}{"field": "value"},
+		},
+	}
+
+	for _, test := range tests {
+
+		getSessionFieldsHandler := &GetSessionFieldsHandlerGrpc{
+			testExpectationsGrpc: testExpectationsGrpc{
+				handlerErr: test.err,
+				handlerVal: test.val,
+			},
+		}
+
+		testApp := handlers.Application{
+			Queries: handlers.Queries{
+				GetSessionFields: getSessionFieldsHandler,
+			},
+		}
+
+		httpSvc := service.NewHttpService(testApp)
+
+		request := httptest.NewRequest(http.MethodGet, "/api/session/key/fields", nil)
+		response := httptest.NewRecorder()
+		httpSvc.GetSessionFields(response, request, test.sessionKey, server.GetSessionFieldsParams{Field: test.fields})
+
+		assert.Equal(t, test.expectedStatus, response.Code, test.scenario)
+		assert.Equal(t, test.expectedInvoked, getSessionFieldsHandler.invoked, "'Handle' invocation should match")
+	}
+
+}
+
+func TestSetHttpSessionFields(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario        string
+		expectedInvoked bool
+		expectedStatus  int
+		expectedVersion int64
+		expectedETag    string
+		sessionKey      string
+		requestBody     io.Reader
+		params          server.SetSessionFieldsParams
+		err             error
+	}{
+		{
+			scenario:        "Should respond with bad request if SessionKey is empty",
+			expectedInvoked: false,
+			expectedStatus:  http.StatusBadRequest,
+			sessionKey:      "",
+			requestBody:     strings.NewReader(`{"field":"value"}`),
+		},
+		{
+			scenario:        "Should respond with bad request if If-Match is not a single ETag",
+			expectedInvoked: false,
+			expectedStatus:  http.StatusBadRequest,
+			sessionKey:      "sessionKeyValue",
+			requestBody:     strings.NewReader(`{"field":"value"}`),
+			params:          server.SetSessionFieldsParams{IfMatch: stringPtr("*")},
+		},
+		{
+			scenario:        "Should respond with precondition failed if session does not match If-Match",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusPreconditionFailed,
+			expectedVersion: 2,
+			sessionKey:      "sessionKeyValue",
+			requestBody:     strings.NewReader(`{"field":"value"}`),
+			params:          server.SetSessionFieldsParams{IfMatch: stringPtr(`"2"`)},
+			err:             fmt.Errorf("wrapped: %w", session.ErrPreconditionFailed),
+		},
+		{
+			scenario:        "Should respond with bad request if no fields are given",
+			expectedInvoked: false,
+			expectedStatus:  http.StatusBadRequest,
+			sessionKey:      "sessionKeyValue",
+			requestBody:     strings.NewReader(`{}`),
+		},
+		{
+			scenario:        "Should respond with not found if session does not exist",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusNotFound,
+			sessionKey:      "sessionKeyValue",
+			requestBody:     strings.NewReader(`{"field":"value"}`),
+			err:             session.ErrNotFound,
+		},
+		{
+			scenario:        "Should respond with internal server error if handler returns an error",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusInternalServerError,
+			sessionKey:      "sessionKeyValue",
+			requestBody:     strings.NewReader(`{"field":"value"}`),
+			err:             fmt.Errorf("Error from handler"),
+		},
+		{
+			scenario:        "Should respond with accepted and the ETag written if no errors are found",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusAccepted,
+			expectedETag:    `"4"`,
+			sessionKey:      "sessionKeyValue",
+			requestBody:     strings.NewReader(`{"field":"value"}`),
+		},
+	}
+
+	for _, test := range tests {
+
+		setSessionFieldsHandler := &SetSessionFieldsHandlerGrpc{
+			testExpectationsGrpc: testExpectationsGrpc{
+				handlerVal: int64(4),
+				handlerErr: test.err,
+			},
+		}
+
+		testApp := handlers.Application{
+			Commands: handlers.Commands{
+				SetSessionFields: setSessionFieldsHandler,
+			},
+		}
+
+		httpSvc := service.NewHttpService(testApp)
+
+		request := httptest.NewRequest(http.MethodPut, "/api/session/key/fields", test.requestBody)
+		request.Header.Set("Content-Type", "application/json")
+		response := httptest.NewRecorder()
+		httpSvc.SetSessionFields(response, request, test.sessionKey, test.params)
+
+		assert.Equal(t, test.expectedStatus, response.Code, test.scenario)
+		assert.Equal(t, test.expectedInvoked, setSessionFieldsHandler.invoked, "'Handle' invocation should match")
+		assert.Equal(t, test.expectedVersion, setSessionFieldsHandler.cmd.IfVersion, test.scenario)
+		assert.Equal(t, test.expectedETag, response.Header().Get("ETag"), test.scenario)
+	}
+
+}
+
+func TestPatchHttpSession(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario        string
+		expectedInvoked bool
+		expectedStatus  int
+		expectedCmd     command.PatchSession
+		expectedETag    string
+		sessionKey      string
+		contentType     string
+		requestBody     string
+		params          server.PatchSessionParams
+		err             error
+	}{
+		{
+			scenario:       "Should respond with bad request if SessionKey is empty",
+			expectedStatus: http.StatusBadRequest,
+			contentType:    "application/merge-patch+json",
+			requestBody:    `{"theme":"dark"}`,
+		},
+		{
+			scenario:       "Should respond with unsupported media type if body is not a patch",
+			expectedStatus: http.StatusUnsupportedMediaType,
+			sessionKey:     "sessionKeyValue",
+			contentType:    "application/json",
+			requestBody:    `{"theme":"dark"}`,
+		},
+		{
+			scenario:       "Should respond with bad request if merge patch is not an object",
+			expectedStatus: http.StatusBadRequest,
+			sessionKey:     "sessionKeyValue",
+			contentType:    "application/merge-patch+json",
+			requestBody:    `["theme"]`,
+		},
+		{
+			scenario:       "Should respond with bad request if json patch is malformed",
+			expectedStatus: http.StatusBadRequest,
+			sessionKey:     "sessionKeyValue",
+			contentType:    "application/json-patch+json",
+			requestBody:    `{"op":"add"}`,
+		},
+		{
+			scenario:       "Should respond with bad request if a json patch operation is missing its value",
+			expectedStatus: http.StatusBadRequest,
+			sessionKey:     "sessionKeyValue",
+			contentType:    "application/json-patch+json",
+			requestBody:    `[{"op":"remove","path":"/user"},{"op":"test","path":"/theme"}]`,
+		},
+		{
+			scenario:       "Should respond with bad request if If-Match is not an ETag",
+			expectedStatus: http.StatusBadRequest,
+			sessionKey:     "sessionKeyValue",
+			contentType:    "application/merge-patch+json",
+			requestBody:    `{"theme":"dark"}`,
+			params:         server.PatchSessionParams{IfMatch: stringPtr("*")},
+		},
+		{
+			scenario:        "Should respond with not found if session does not exist",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusNotFound,
+			expectedCmd:     command.PatchSession{Key: "sessionKeyValue", Patch: session.MergePatch{"theme": "dark"}},
+			sessionKey:      "sessionKeyValue",
+			contentType:     "application/merge-patch+json",
+			requestBody:     `{"theme":"dark"}`,
+			err:             session.ErrNotFound,
+		},
+		{
+			scenario:        "Should respond with precondition failed if the session does not match",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusPreconditionFailed,
+			expectedCmd:     command.PatchSession{Key: "sessionKeyValue", Patch: session.MergePatch{"theme": "dark"}, IfVersion: 3},
+			sessionKey:      "sessionKeyValue",
+			contentType:     "application/merge-patch+json",
+			requestBody:     `{"theme":"dark"}`,
+			params:          server.PatchSessionParams{IfMatch: stringPtr(`"3"`)},
+			err:             fmt.Errorf("wrapped: %w", session.ErrPreconditionFailed),
+		},
+		{
+			scenario:        "Should respond with conflict if a test operation fails",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusConflict,
+			expectedCmd: command.PatchSession{Key: "sessionKeyValue", Patch: session.JSONPatch{
+				{Op: "test", Path: "/theme", Value: nil},
+			}},
+			sessionKey:  "sessionKeyValue",
+			contentType: "application/json-patch+json",
+			requestBody: `[{"op":"test","path":"/theme","value":null}]`,
+			err:         fmt.Errorf("wrapped: %w", session.ErrConflict),
+		},
+		{
+			scenario:        "Should respond with accepted when merge patching",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusAccepted,
+			expectedCmd:     command.PatchSession{Key: "sessionKeyValue", Patch: session.MergePatch{"theme": "dark", "user": nil}},
+			sessionKey:      "sessionKeyValue",
+			expectedETag:    `"4"`,
+			contentType:     "application/merge-patch+json; charset=utf-8",
+			requestBody:     `{"theme":"dark","user":null}`,
+		},
+		{
+			scenario:        "Should respond with accepted when json patching",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusAccepted,
+			expectedCmd: command.PatchSession{Key: "sessionKeyValue", Patch: session.JSONPatch{
+				{Op: "add", Path: "/props/cart/-", Value: "pear"},
+				{Op: "move", From: "/theme", Path: "/props/theme"},
+			}},
+			expectedETag: `"4"`,
+			sessionKey:   "sessionKeyValue",
+			contentType:  "application/json-patch+json",
+			requestBody:  `[{"op":"add","path":"/props/cart/-","value":"pear"},{"op":"move","from":"/theme","path":"/props/theme"}]`,
+		},
+	}
+
+	for _, test := range tests {
+
+		patchSessionHandler := &PatchSessionHandlerGrpc{
+			testExpectationsGrpc: testExpectationsGrpc{
+				handlerVal: int64(4),
+				handlerErr: test.err,
+			},
+		}
+
+		testApp := handlers.Application{
+			Commands: handlers.Commands{
+				PatchSession: patchSessionHandler,
+			},
+		}
+
+		httpSvc := service.NewHttpService(testApp)
+
+		request := httptest.NewRequest(http.MethodPatch, "/api/session/key", strings.NewReader(test.requestBody))
+		request.Header.Set("Content-Type", test.contentType)
+		response := httptest.NewRecorder()
+		httpSvc.PatchSession(response, request, test.sessionKey, test.params)
+
+		assert.Equal(t, test.expectedStatus, response.Code, test.scenario)
+		assert.Equal(t, test.expectedInvoked, patchSessionHandler.invoked, "'Handle' invocation should match")
+		if test.expectedInvoked {
+			assert.Equal(t, test.expectedCmd, patchSessionHandler.cmd, test.scenario)
+		}
+		assert.Equal(t, test.expectedETag, response.Header().Get("ETag"), test.scenario)
+	}
+}
+
+func TestTouchHttpSession(t *testing.T) {
+	t.Parallel()
+
+	expiresAt := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		scenario          string
+		expectedInvoked   bool
+		expectedStatus    int
+		expectedTTL       time.Duration
+		expectedExpiresAt string
+		sessionKey        string
+		requestBody       string
+		handlerVal        interface{}
+		err               error
+	}{
+		{
+			scenario:        "Should respond with bad request if SessionKey is empty",
+			expectedInvoked: false,
+			expectedStatus:  http.StatusBadRequest,
+			sessionKey:      "",
+		},
+		{
+			scenario:        "Should respond with bad request if body is malformed",
+			expectedInvoked: false,
+			expectedStatus:  http.StatusBadRequest,
+			sessionKey:      "sessionKeyValue",
+			requestBody:     `{"ttl":`,
+		},
+		{
+			scenario:        "Should respond with bad request if ttl is negative",
+			expectedInvoked: false,
+			expectedStatus:  http.StatusBadRequest,
+			sessionKey:      "sessionKeyValue",
+			requestBody:     `{"ttl":-1}`,
+		},
+		{
+			scenario:        "Should respond with not found if session does not exist",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusNotFound,
+			sessionKey:      "sessionKeyValue",
+			err:             session.ErrNotFound,
+		},
+		{
+			scenario:          "Should respond with accepted and the expiry when touching for the session own ttl",
+			expectedInvoked:   true,
+			expectedStatus:    http.StatusAccepted,
+			expectedExpiresAt: "2023-01-02T03:04:05Z",
+			sessionKey:        "sessionKeyValue",
+			handlerVal:        expiresAt,
+		},
+		{
+			scenario:        "Should respond with accepted when touching for the requested ttl",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusAccepted,
+			expectedTTL:     time.Minute,
+			sessionKey:      "sessionKeyValue",
+			requestBody:     `{"ttl":60}`,
+		},
+	}
+
+	for _, test := range tests {
+
+		touchSessionHandler := &TouchSessionHandlerGrpc{
+			testExpectationsGrpc: testExpectationsGrpc{
+				handlerVal: test.handlerVal,
+				handlerErr: test.err,
+			},
+		}
+
+		testApp := handlers.Application{
+			Commands: handlers.Commands{
+				TouchSession: touchSessionHandler,
+			},
+		}
+
+		httpSvc := service.NewHttpService(testApp)
+
+		request := httptest.NewRequest(http.MethodPost, "/api/session/key/touch", strings.NewReader(test.requestBody))
+		if test.requestBody != "" {
+			request.Header.Set("Content-Type", "application/json")
+		}
+		response := httptest.NewRecorder()
+		httpSvc.TouchSession(response, request, test.sessionKey)
+
+		assert.Equal(t, test.expectedStatus, response.Code, test.scenario)
+		assert.Equal(t, test.expectedInvoked, touchSessionHandler.invoked, "'Handle' invocation should match")
+		assert.Equal(t, test.expectedTTL, touchSessionHandler.ttl, test.scenario)
+		assert.Equal(t, test.expectedExpiresAt, response.Header().Get("Session-Expires-At"), test.scenario)
+	}
+
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestHttpSessionValues(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario       string
+		method         string
+		target         string
+		ifMatch        string
+		requestBody    string
+		handlerVal     interface{}
+		err            error
+		expectedStatus int
+		expectedBody   string
+		expectedETag   string
+		expectedCmd    interface{}
+	}{
+		{
+			scenario:       "Should read the value at the pointer making up the rest of the path",
+			method:         http.MethodGet,
+			target:         "/session/key/values/props/cart",
+			handlerVal:     []interface{}{"apple", "pear"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `["apple","pear"]`,
+			expectedCmd:    query.GetSessionValue{Key: "key", Pointer: "/props/cart"},
+		},
+		{
+			scenario:       "Should respond with not found if there is no value at the pointer",
+			method:         http.MethodGet,
+			target:         "/session/key/values/props/theme",
+			err:            fmt.Errorf("%w: no value at '/props'", session.ErrNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedCmd:    query.GetSessionValue{Key: "key", Pointer: "/props/theme"},
+		},
+		{
+			scenario:       "Should respond with bad request if the pointer is not valid",
+			method:         http.MethodGet,
+			target:         "/session/key/values/props~2",
+			err:            fmt.Errorf("%w: pointer '/props~2' has an invalid escape", session.ErrInvalid),
+			expectedStatus: http.StatusBadRequest,
+			expectedCmd:    query.GetSessionValue{Key: "key", Pointer: "/props~2"},
+		},
+		{
+			scenario:       "Should read the whole data without a pointer",
+			method:         http.MethodGet,
+			target:         "/session/key/values",
+			handlerVal:     map[string]interface{}{"theme": "dark"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"theme":"dark"}`,
+			expectedCmd:    query.GetSessionValue{Key: "key", Pointer: ""},
+		},
+		{
+			scenario:       "Should read the whole data with just a trailing slash",
+			method:         http.MethodGet,
+			target:         "/session/key/values/",
+			handlerVal:     map[string]interface{}{"theme": "dark"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"theme":"dark"}`,
+			expectedCmd:    query.GetSessionValue{Key: "key", Pointer: ""},
+		},
+		{
+			scenario:       "Should unescape the pointer",
+			method:         http.MethodGet,
+			target:         "/session/key/values/props/some%20key%2Fpart",
+			handlerVal:     "value",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"value"`,
+			expectedCmd:    query.GetSessionValue{Key: "key", Pointer: "/props/some key/part"},
+		},
+		{
+			scenario:       "Should set the value at the pointer making up the rest of the path",
+			method:         http.MethodPut,
+			target:         "/session/key/values/props/a~1b",
+			ifMatch:        `"4"`,
+			requestBody:    `{"theme":"dark"}`,
+			handlerVal:     int64(5),
+			expectedStatus: http.StatusAccepted,
+			expectedETag:   `"5"`,
+			expectedCmd:    command.SetSessionValue{Key: "key", Pointer: "/props/a~1b", Value: map[string]interface{}{"theme": "dark"}, IfVersion: 4},
+		},
+		{
+			scenario:       "Should respond with bad request if the value is malformed",
+			method:         http.MethodPut,
+			target:         "/session/key/values/theme",
+			requestBody:    `{"theme"`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			scenario:       "Should respond with bad request if If-Match is not an ETag",
+			method:         http.MethodPut,
+			target:         "/session/key/values/theme",
+			ifMatch:        "*",
+			requestBody:    `"dark"`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			scenario:       "Should respond with not found if the parent of the value does not exist",
+			method:         http.MethodPut,
+			target:         "/session/key/values/props/theme",
+			requestBody:    `"dark"`,
+			err:            fmt.Errorf("%w: no value at '/props'", session.ErrNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedCmd:    command.SetSessionValue{Key: "key", Pointer: "/props/theme", Value: "dark"},
+		},
+		{
+			scenario:       "Should replace the whole data without a pointer",
+			method:         http.MethodPut,
+			target:         "/session/key/values",
+			requestBody:    `{"theme":"dark"}`,
+			handlerVal:     int64(5),
+			expectedStatus: http.StatusAccepted,
+			expectedETag:   `"5"`,
+			expectedCmd:    command.SetSessionValue{Key: "key", Pointer: "", Value: map[string]interface{}{"theme": "dark"}},
+		},
+		{
+			scenario:       "Should delete the value at the pointer making up the rest of the path",
+			method:         http.MethodDelete,
+			target:         "/session/key/values/props/cart/0",
+			handlerVal:     int64(5),
+			expectedStatus: http.StatusAccepted,
+			expectedETag:   `"5"`,
+			expectedCmd:    command.DeleteSessionValue{Key: "key", Pointer: "/props/cart/0"},
+		},
+		{
+			scenario:       "Should respond with precondition failed if the session does not match",
+			method:         http.MethodDelete,
+			target:         "/session/key/values/theme",
+			ifMatch:        `"2"`,
+			err:            session.ErrPreconditionFailed,
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedCmd:    command.DeleteSessionValue{Key: "key", Pointer: "/theme", IfVersion: 2},
+		},
+	}
+
+	for _, test := range tests {
+
+		expectations := testExpectationsGrpc{handlerVal: test.handlerVal, handlerErr: test.err}
+		getSessionValueHandler := &GetSessionValueHandlerGrpc{testExpectationsGrpc: expectations}
+		setSessionValueHandler := &SetSessionValueHandlerGrpc{testExpectationsGrpc: expectations}
+		deleteSessionValueHandler := &DeleteSessionValueHandlerGrpc{testExpectationsGrpc: expectations}
+
+		testApp := handlers.Application{
+			Commands: handlers.Commands{
+				SetSessionValue:    setSessionValueHandler,
+				DeleteSessionValue: deleteSessionValueHandler,
+			},
+			Queries: handlers.Queries{
+				GetSessionValue: getSessionValueHandler,
+			},
+		}
+
+		router := server.HandlerFromMuxWithValues(service.NewHttpService(testApp), chi.NewRouter())
+
+		request := httptest.NewRequest(test.method, test.target, strings.NewReader(test.requestBody))
+		request.Header.Set("Content-Type", "application/json")
+		if test.ifMatch != "" {
+			request.Header.Set("If-Match", test.ifMatch)
+		}
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+
+		assert.Equal(t, test.expectedStatus, response.Code, test.scenario)
+		if test.expectedBody != "" {
+			assert.JSONEq(t, test.expectedBody, response.Body.String(), test.scenario)
+		}
+		assert.Equal(t, test.expectedETag, response.Header().Get("ETag"), test.scenario)
+
+		switch test.method {
+		case http.MethodGet:
+			assert.Equal(t, test.expectedCmd != nil, getSessionValueHandler.invoked, test.scenario)
+			if test.expectedCmd != nil {
+				assert.Equal(t, test.expectedCmd, getSessionValueHandler.cmd, test.scenario)
+			}
+		case http.MethodPut:
+			assert.Equal(t, test.expectedCmd != nil, setSessionValueHandler.invoked, test.scenario)
+			if test.expectedCmd != nil {
+				assert.Equal(t, test.expectedCmd, setSessionValueHandler.cmd, test.scenario)
+			}
+		case http.MethodDelete:
+			assert.Equal(t, test.expectedCmd != nil, deleteSessionValueHandler.invoked, test.scenario)
+			if test.expectedCmd != nil {
+				assert.Equal(t, test.expectedCmd, deleteSessionValueHandler.cmd, test.scenario)
+			}
+		}
+	}
+}
+
+func TestIncrementHttpSessionCounter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario        string
+		target          string
+		requestBody     string
+		handlerVal      interface{}
+		err             error
+		expectedInvoked bool
+		expectedStatus  int
+		expectedBody    string
+		expectedCmd     command.IncrementSessionCounter
+	}{
+		{
+			scenario:       "Should respond with bad request if the body is malformed",
+			target:         "/session/key/counters/views",
+			requestBody:    `{"delta":"one"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			scenario:       "Should respond with bad request if the delta is not an integer",
+			target:         "/session/key/counters/views",
+			requestBody:    `{"delta":0.5}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			scenario:        "Should respond with bad request if the counter is not an integer",
+			target:          "/session/key/counters/user",
+			requestBody:     `{"delta":1}`,
+			err:             fmt.Errorf("%w: value at '/user' is not an integer", session.ErrInvalid),
+			expectedInvoked: true,
+			expectedStatus:  http.StatusBadRequest,
+			expectedCmd:     command.IncrementSessionCounter{Key: "key", Pointer: "/user", Delta: 1},
+		},
+		{
+			scenario:        "Should respond with not found if the session does not exist",
+			target:          "/session/key/counters/views",
+			requestBody:     `{"delta":1}`,
+			err:             session.ErrNotFound,
+			expectedInvoked: true,
+			expectedStatus:  http.StatusNotFound,
+			expectedCmd:     command.IncrementSessionCounter{Key: "key", Pointer: "/views", Delta: 1},
+		},
+		{
+			scenario:        "Should respond with the value the counter is left at",
+			target:          "/session/key/counters/stats/failed",
+			requestBody:     `{"delta":-1}`,
+			handlerVal:      int64(2),
+			expectedInvoked: true,
+			expectedStatus:  http.StatusOK,
+			expectedBody:    `{"value":2}`,
+			expectedCmd:     command.IncrementSessionCounter{Key: "key", Pointer: "/stats/failed", Delta: -1},
+		},
+		{
+			scenario:        "Should respond with the value of counters past the precision of a double",
+			target:          "/session/key/counters/stats/bytes",
+			requestBody:     `{"delta":9007199254740991}`,
+			handlerVal:      int64(9007199254740991),
+			expectedInvoked: true,
+			expectedStatus:  http.StatusOK,
+			expectedBody:    `{"value":9007199254740991}`,
+			expectedCmd:     command.IncrementSessionCounter{Key: "key", Pointer: "/stats/bytes", Delta: 9007199254740991},
+		},
+	}
+
+	for _, test := range tests {
+
+		incrementSessionCounterHandler := &IncrementSessionCounterHandlerGrpc{
+			testExpectationsGrpc: testExpectationsGrpc{handlerVal: test.handlerVal, handlerErr: test.err},
+		}
+
+		testApp := handlers.Application{
+			Commands: handlers.Commands{
+				IncrementSessionCounter: incrementSessionCounterHandler,
+			},
+		}
+
+		router := server.HandlerFromMuxWithValues(service.NewHttpService(testApp), chi.NewRouter())
+
+		request := httptest.NewRequest(http.MethodPost, test.target, strings.NewReader(test.requestBody))
+		request.Header.Set("Content-Type", "application/json")
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+
+		assert.Equal(t, test.expectedStatus, response.Code, test.scenario)
+		if test.expectedBody != "" {
+			assert.JSONEq(t, test.expectedBody, response.Body.String(), test.scenario)
+		}
+		assert.Equal(t, test.expectedInvoked, incrementSessionCounterHandler.invoked, test.scenario)
+		if test.expectedInvoked {
+			assert.Equal(t, test.expectedCmd, incrementSessionCounterHandler.cmd, test.scenario)
+		}
+	}
+}
+
+func TestSetHttpSessionShouldCreateSessionsWithoutKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario         string
+		expectedInvoked  bool
+		expectedStatus   int
+		expectedLocation string
+		expectedBody     string
+		requestBody      string
+		params           server.SetSessionParams
+		handlerVal       interface{}
+		err              error
+	}{
+		{
+			scenario:       "Should respond with bad request if If-Match is set",
+			expectedStatus: http.StatusBadRequest,
+			requestBody:    `{"sessionValue":{"value":"test"}}`,
+			params:         server.SetSessionParams{IfMatch: stringPtr("*")},
+		},
+		{
+			scenario:        "Should respond with conflict if every generated key is in use",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusConflict,
+			requestBody:     `{"sessionValue":{"value":"test"}}`,
+			err:             fmt.Errorf("wrapped: %w", session.ErrConflict),
+		},
+		{
+			scenario:         "Should respond with the generated key and its location",
+			expectedInvoked:  true,
+			expectedStatus:   http.StatusCreated,
+			expectedLocation: "/api/session/generated_Key-1",
+			expectedBody:     `{"sessionKey":"generated_Key-1"}`,
+			requestBody:      `{"sessionValue":{"value":"test"},"ttl":60}`,
+			params:           server.SetSessionParams{IfNoneMatch: stringPtr("*")},
+			handlerVal:       "generated_Key-1",
+		},
+	}
+
+	for _, test := range tests {
+
+		createSessionHandler := &CreateSessionHandlerGrpc{
+			testExpectationsGrpc: testExpectationsGrpc{
+				handlerVal: test.handlerVal,
+				handlerErr: test.err,
+			},
+		}
+		setSessionHandler := &SetSessionHandlerGrpc{}
+
+		httpSvc := service.NewHttpService(handlers.Application{
+			Commands: handlers.Commands{CreateSession: createSessionHandler, SetSession: setSessionHandler},
+		})
+
+		request := httptest.NewRequest(http.MethodPost, "/api/session", strings.NewReader(test.requestBody))
+		request.Header.Set("Content-Type", "application/json")
+		response := httptest.NewRecorder()
+		httpSvc.SetSession(response, request, test.params)
+
+		assert.Equal(t, test.expectedStatus, response.Code, test.scenario)
+		assert.Equal(t, test.expectedInvoked, createSessionHandler.invoked, test.scenario)
+		assert.False(t, setSessionHandler.invoked, test.scenario)
+		if test.expectedInvoked {
+			assert.Equal(t, session.Data{"value": "test"}, createSessionHandler.cmd.Value, test.scenario)
+		}
+		assert.Equal(t, test.expectedLocation, response.Header().Get("Location"), test.scenario)
+		if test.expectedBody != "" {
+			assert.JSONEq(t, test.expectedBody, response.Body.String(), test.scenario)
+		}
+	}
+}
+
+func TestRotateHttpSession(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario         string
+		expectedInvoked  bool
+		expectedStatus   int
+		expectedGrace    time.Duration
+		expectedLocation string
+		expectedBody     string
+		sessionId        string
+		requestBody      string
+		handlerVal       interface{}
+		err              error
+	}{
+		{
+			scenario:       "Should respond with bad request if sessionId is empty",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			scenario:       "Should respond with bad request if grace is negative",
+			expectedStatus: http.StatusBadRequest,
+			sessionId:      "oldKey",
+			requestBody:    `{"grace":-1}`,
+		},
+		{
+			scenario:        "Should respond with not found if session does not exist",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusNotFound,
+			sessionId:       "oldKey",
+			err:             fmt.Errorf("wrapped: %w", session.ErrNotFound),
+		},
+		{
+			scenario:        "Should respond with bad request if grace is over the maximum",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusBadRequest,
+			expectedGrace:   time.Hour,
+			sessionId:       "oldKey",
+			requestBody:     `{"grace":3600}`,
+			err:             fmt.Errorf("%w: grace period 1h0m0s is over the maximum of 1m0s", session.ErrInvalid),
+		},
+		{
+			scenario:         "Should respond with the new key and its location",
+			expectedInvoked:  true,
+			expectedStatus:   http.StatusCreated,
+			expectedGrace:    5 * time.Second,
+			expectedLocation: "/api/session/rotated_Key-1",
+			expectedBody:     `{"sessionKey":"rotated_Key-1"}`,
+			sessionId:        "oldKey",
+			requestBody:      `{"grace":5}`,
+			handlerVal:       "rotated_Key-1",
+		},
+	}
+
+	for _, test := range tests {
+
+		rotateSessionHandler := &RotateSessionHandlerGrpc{
+			testExpectationsGrpc: testExpectationsGrpc{
+				handlerVal: test.handlerVal,
+				handlerErr: test.err,
+			},
+		}
+
+		httpSvc := service.NewHttpService(handlers.Application{
+			Commands: handlers.Commands{RotateSession: rotateSessionHandler},
+		})
+
+		request := httptest.NewRequest(http.MethodPost, "/api/session/"+test.sessionId+"/rotate", strings.NewReader(test.requestBody))
+		request.Header.Set("Content-Type", "application/json")
+		response := httptest.NewRecorder()
+		httpSvc.RotateSession(response, request, test.sessionId)
+
+		assert.Equal(t, test.expectedStatus, response.Code, test.scenario)
+		assert.Equal(t, test.expectedInvoked, rotateSessionHandler.invoked, test.scenario)
+		if test.expectedInvoked {
+			assert.Equal(t, test.sessionId, rotateSessionHandler.cmd.Key, test.scenario)
+			assert.Equal(t, test.expectedGrace, rotateSessionHandler.cmd.Grace, test.scenario)
+		}
+		assert.Equal(t, test.expectedLocation, response.Header().Get("Location"), test.scenario)
+		if test.expectedBody != "" {
+			assert.JSONEq(t, test.expectedBody, response.Body.String(), test.scenario)
+		}
+	}
+}
+
+func TestGetHttpSubjectSessions(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		scenario        string
+		expectedInvoked bool
+		expectedStatus  int
+		expectedBody    string
+		subject         string
+		handlerVal      interface{}
+		err             error
+	}{
+		{
+			scenario:       "Should respond with bad request if subject is empty",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			scenario:        "Should respond with not implemented if the store cannot index sessions",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusNotImplemented,
+			subject:         "someUser",
+			err:             fmt.Errorf("wrapped: %w", session.ErrNotSupported),
+		},
+		{
+			scenario:        "Should respond with an empty list if the subject has no sessions",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusOK,
+			expectedBody:    `{"sessions":[]}`,
+			subject:         "someUser",
+		},
+		{
+			scenario:        "Should respond with the sessions of the subject",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusOK,
+			expectedBody:    `{"sessions":[{"sessionKey":"someKey","createdAt":"2022-06-01T10:00:00Z","updatedAt":"2022-06-01T10:00:00Z","expiresAt":"2022-06-01T11:00:00Z","version":2}]}`,
+			subject:         "someUser",
+			handlerVal: []*session.Session{{
+				ID:        "someKey",
+				CreatedAt: createdAt,
+				UpdatedAt: createdAt,
+				ExpiresAt: createdAt.Add(time.Hour),
+				Version:   2,
+				Subject:   "someUser",
+			}},
+		},
+	}
+
+	for _, test := range tests {
+
+		getSubjectSessionsHandler := &GetSubjectSessionsHandlerGrpc{
+			testExpectationsGrpc: testExpectationsGrpc{
+				handlerVal: test.handlerVal,
+				handlerErr: test.err,
+			},
+		}
+
+		httpSvc := service.NewHttpService(handlers.Application{
+			Queries: handlers.Queries{GetSubjectSessions: getSubjectSessionsHandler},
+		})
+
+		request := httptest.NewRequest(http.MethodGet, "/api/subject/"+test.subject+"/sessions", nil)
+		response := httptest.NewRecorder()
+		httpSvc.GetSubjectSessions(response, request, test.subject)
+
+		assert.Equal(t, test.expectedStatus, response.Code, test.scenario)
+		assert.Equal(t, test.expectedInvoked, getSubjectSessionsHandler.invoked, test.scenario)
+		if test.expectedInvoked {
+			assert.Equal(t, test.subject, getSubjectSessionsHandler.query.Subject, test.scenario)
+		}
+		if test.expectedBody != "" {
+			assert.JSONEq(t, test.expectedBody, response.Body.String(), test.scenario)
+		}
+	}
+}
+
+func TestDeleteHttpSubjectSessions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario        string
+		expectedInvoked bool
+		expectedStatus  int
+		expectedBody    string
+		subject         string
+		handlerVal      interface{}
+		err             error
+	}{
+		{
+			scenario:       "Should respond with bad request if subject is empty",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			scenario:        "Should respond with conflict if sessions keep being created concurrently",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusConflict,
+			subject:         "someUser",
+			handlerVal:      2,
+			err:             fmt.Errorf("wrapped: %w", session.ErrConflict),
+		},
+		{
+			scenario:        "Should respond with how many sessions were deleted",
+			expectedInvoked: true,
+			expectedStatus:  http.StatusOK,
+			expectedBody:    `{"deleted":3}`,
+			subject:         "someUser",
+			handlerVal:      3,
+		},
+	}
+
+	for _, test := range tests {
+
+		deleteSubjectSessionsHandler := &DeleteSubjectSessionsHandlerGrpc{
+			testExpectationsGrpc: testExpectationsGrpc{
+				handlerVal: test.handlerVal,
+				handlerErr: test.err,
+			},
+		}
+
+		httpSvc := service.NewHttpService(handlers.Application{
+			Commands: handlers.Commands{DeleteSubjectSessions: deleteSubjectSessionsHandler},
+		})
+
+		request := httptest.NewRequest(http.MethodDelete, "/api/subject/"+test.subject+"/sessions", nil)
+		response := httptest.NewRecorder()
+		httpSvc.DeleteSubjectSessions(response, request, test.subject)
+
+		assert.Equal(t, test.expectedStatus, response.Code, test.scenario)
+		assert.Equal(t, test.expectedInvoked, deleteSubjectSessionsHandler.invoked, test.scenario)
+		if test.expectedInvoked {
+			assert.Equal(t, test.subject, deleteSubjectSessionsHandler.cmd.Subject, test.scenario)
+		}
+		if test.expectedBody != "" {
+			assert.JSONEq(t, test.expectedBody, response.Body.String(), test.scenario)
+		}
+	}
+}
@@ -0,0 +1,529 @@
+package adapters
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+	bolt "go.etcd.io/bbolt"
+)
+
+var boltSessionsBucket = []byte("sessions")
+
+// boltSubjectsBucket holds a bucket per subject, mapping the IDs of its
+// sessions to when they expire.
+var boltSubjectsBucket = []byte("subjects")
+
+type boltRepository struct {
+	db      *bolt.DB
+	expires time.Duration
+	stop    chan struct{}
+	once    sync.Once
+}
+
+func init() {
+	Register("bolt", boltBackend)
+}
+
+func boltBackend(config Config) (Store, error) {
+	compactInterval, err := config.Seconds("CLEANUP_INTERVAL", 60)
+	if err != nil {
+		return nil, err
+	}
+	return openBoltRepository(config.String("PATH", "sessions.db"), config.Expires, compactInterval)
+}
+
+// NewBoltRepository returns a session repository backed by an embedded bbolt
+// file at path. Each entry is stored along with its expiry time, none when
+// expires is zero, and expired entries are compacted away every
+// compactInterval.
+func NewBoltRepository(path string, expires time.Duration, compactInterval time.Duration) Store {
+	r, err := openBoltRepository(path, expires, compactInterval)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func openBoltRepository(path string, expires time.Duration, compactInterval time.Duration) (*boltRepository, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("error '%s' when opening bolt db at '%s'", err, path)
+	}
+
+	r, err := newBoltRepository(db, expires)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if compactInterval > 0 {
+		go r.compactor(compactInterval)
+	}
+
+	return r, nil
+}
+
+func newBoltRepository(db *bolt.DB, expires time.Duration) (*boltRepository, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(boltSessionsBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(boltSubjectsBucket)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &boltRepository{db: db, expires: expires, stop: make(chan struct{})}, nil
+}
+
+func (r *boltRepository) Set(ctx context.Context, key string, value interface{}) error {
+	return r.SetWithTTL(ctx, key, value, r.expires)
+}
+
+// SetWithTTL stores the session for ttl, or for good when ttl is zero.
+func (r *boltRepository) SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+
+	val, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
+
+	entry := encodeBoltEntry(expiresAfter(time.Now(), ttl), val)
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltSessionsBucket).Put([]byte(key), entry)
+	})
+}
+
+func (r *boltRepository) Get(ctx context.Context, key string) (interface{}, error) {
+
+	var val string
+	found := false
+	err := r.db.View(func(tx *bolt.Tx) error {
+		entry := tx.Bucket(boltSessionsBucket).Get([]byte(key))
+		if entry == nil {
+			return nil
+		}
+
+		expiresAt, value := decodeBoltEntry(entry)
+		if boltLive(expiresAt, time.Now()) {
+			val, found = string(value), true
+		}
+		return nil
+	})
+
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", session.ErrNotFound
+	}
+	return val, nil
+}
+
+// Scan collects the keys of the live sessions before walking them, as onKey may
+// write sessions, which bolt does not allow within a read transaction.
+func (r *boltRepository) Scan(ctx context.Context, onKey func(key string) error) error {
+
+	var keys []string
+	now := time.Now()
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltSessionsBucket).ForEach(func(k, v []byte) error {
+			if expiresAt, _ := decodeBoltEntry(v); boltLive(expiresAt, now) {
+				keys = append(keys, string(k))
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := onKey(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *boltRepository) Delete(ctx context.Context, key string) (int64, error) {
+
+	var deleted int64
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltSessionsBucket)
+		entry := bucket.Get([]byte(key))
+		if entry == nil {
+			return nil
+		}
+
+		if expiresAt, _ := decodeBoltEntry(entry); boltLive(expiresAt, time.Now()) {
+			deleted = 1
+		}
+		return bucket.Delete([]byte(key))
+	})
+
+	return deleted, err
+}
+
+func (r *boltRepository) TTL(ctx context.Context, key string) (time.Duration, error) {
+
+	var ttl time.Duration
+	found := false
+	err := r.db.View(func(tx *bolt.Tx) error {
+		entry := tx.Bucket(boltSessionsBucket).Get([]byte(key))
+		if entry == nil {
+			return nil
+		}
+
+		expiresAt, _ := decodeBoltEntry(entry)
+		if now := time.Now(); expiresAt.IsZero() {
+			ttl, found = -1, true
+		} else if now.Before(expiresAt) {
+			ttl, found = expiresAt.Sub(now), true
+		}
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, session.ErrNotFound
+	}
+	return ttl, nil
+}
+
+func (r *boltRepository) Expire(ctx context.Context, key string, ttl time.Duration) error {
+
+	return r.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltSessionsBucket)
+		entry := bucket.Get([]byte(key))
+		if entry == nil {
+			return session.ErrNotFound
+		}
+
+		now := time.Now()
+		expiresAt, value := decodeBoltEntry(entry)
+		if !boltLive(expiresAt, now) {
+			return session.ErrNotFound
+		}
+		return bucket.Put([]byte(key), encodeBoltEntry(now.Add(ttl), string(value)))
+	})
+}
+
+func (r *boltRepository) SetIfAbsent(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+
+	val, err := encodeValue(value)
+	if err != nil {
+		return false, err
+	}
+	if ttl == 0 {
+		ttl = r.expires
+	}
+
+	stored := false
+	err = r.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltSessionsBucket)
+		now := time.Now()
+		if entry := bucket.Get([]byte(key)); entry != nil {
+			if expiresAt, _ := decodeBoltEntry(entry); boltLive(expiresAt, now) {
+				return nil
+			}
+		}
+
+		stored = true
+		return bucket.Put([]byte(key), encodeBoltEntry(expiresAfter(now, ttl), val))
+	})
+
+	return stored, err
+}
+
+func (r *boltRepository) Replace(ctx context.Context, key string, old, new interface{}, ttl time.Duration) (bool, error) {
+
+	oldVal, err := encodeValue(old)
+	if err != nil {
+		return false, err
+	}
+	newVal, err := encodeValue(new)
+	if err != nil {
+		return false, err
+	}
+	if ttl == 0 {
+		ttl = r.expires
+	}
+
+	replaced := false
+	err = r.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltSessionsBucket)
+		entry := bucket.Get([]byte(key))
+		if entry == nil {
+			return nil
+		}
+
+		now := time.Now()
+		expiresAt, value := decodeBoltEntry(entry)
+		if !boltLive(expiresAt, now) || string(value) != oldVal {
+			return nil
+		}
+
+		replaced = true
+		return bucket.Put([]byte(key), encodeBoltEntry(expiresAfter(now, ttl), newVal))
+	})
+
+	return replaced, err
+}
+
+func (r *boltRepository) DeleteIf(ctx context.Context, key string, old interface{}) (bool, error) {
+
+	oldVal, err := encodeValue(old)
+	if err != nil {
+		return false, err
+	}
+
+	deleted := false
+	err = r.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltSessionsBucket)
+		entry := bucket.Get([]byte(key))
+		if entry == nil {
+			return nil
+		}
+
+		expiresAt, value := decodeBoltEntry(entry)
+		if !boltLive(expiresAt, time.Now()) || string(value) != oldVal {
+			return nil
+		}
+
+		deleted = true
+		return bucket.Delete([]byte(key))
+	})
+
+	return deleted, err
+}
+
+// Increment increments the counter within a read-write transaction, which
+// bolt runs one at a time.
+func (r *boltRepository) Increment(ctx context.Context, key string, path session.Pointer, delta int64, updatedAt time.Time) (int64, error) {
+
+	var counter int64
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltSessionsBucket)
+		entry := bucket.Get([]byte(key))
+		if entry == nil {
+			return session.ErrNotFound
+		}
+
+		expiresAt, stored := decodeBoltEntry(entry)
+		if !boltLive(expiresAt, time.Now()) {
+			return session.ErrNotFound
+		}
+
+		incremented, value, err := incrementCounter(string(stored), path, delta, updatedAt)
+		if err != nil {
+			return err
+		}
+
+		counter = value
+		return bucket.Put([]byte(key), encodeBoltEntry(expiresAt, incremented))
+	})
+
+	return counter, err
+}
+
+func (r *boltRepository) IndexSession(ctx context.Context, subject, id string, expiresAt time.Time) error {
+
+	return r.db.Update(func(tx *bolt.Tx) error {
+		index, err := tx.Bucket(boltSubjectsBucket).CreateBucketIfNotExists([]byte(subject))
+		if err != nil {
+			return err
+		}
+		return index.Put([]byte(id), encodeBoltExpiry(expiresAt))
+	})
+}
+
+func (r *boltRepository) UnindexSessions(ctx context.Context, subject string, ids []string) error {
+
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return unindexBolt(tx, subject, ids)
+	})
+}
+
+func (r *boltRepository) IndexedSessions(ctx context.Context, subject string) (map[string]time.Time, error) {
+
+	indexed := make(map[string]time.Time)
+	err := r.db.View(func(tx *bolt.Tx) error {
+		index := tx.Bucket(boltSubjectsBucket).Bucket([]byte(subject))
+		if index == nil {
+			return nil
+		}
+		return index.ForEach(func(k, v []byte) error {
+			indexed[string(k)] = decodeBoltExpiry(v)
+			return nil
+		})
+	})
+
+	return indexed, err
+}
+
+func (r *boltRepository) ScanSubjects(ctx context.Context, onSubject func(subject string) error) error {
+
+	var subjects []string
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltSubjectsBucket).ForEach(func(k, v []byte) error {
+			subjects = append(subjects, string(k))
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, subject := range subjects {
+		if err := onSubject(subject); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops the background compaction and closes the data file.
+func (r *boltRepository) Close() error {
+	r.once.Do(func() { close(r.stop) })
+	return r.db.Close()
+}
+
+func (r *boltRepository) compactor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			deleted, err := r.compact(time.Now())
+			if err != nil {
+				logrus.WithError(err).Error("Failed to compact expired sessions")
+				continue
+			}
+			logrus.WithField("deleted", deleted).Debug("Expired sessions compacted")
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// compact deletes every entry that expired before now.
+func (r *boltRepository) compact(now time.Time) (int, error) {
+	deleted := 0
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltSessionsBucket)
+
+		var expired [][]byte
+		err := bucket.ForEach(func(k, v []byte) error {
+			if expiresAt, _ := decodeBoltEntry(v); !boltLive(expiresAt, now) {
+				expired = append(expired, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range expired {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		deleted = len(expired)
+		return compactBoltIndexes(tx, now)
+	})
+
+	return deleted, err
+}
+
+// compactBoltIndexes drops from the indexes of subjects the sessions due to
+// expire before now that are gone.
+func compactBoltIndexes(tx *bolt.Tx, now time.Time) error {
+
+	sessions := tx.Bucket(boltSessionsBucket)
+	expired := make(map[string][]string)
+	subjects := tx.Bucket(boltSubjectsBucket)
+	err := subjects.ForEach(func(subject, _ []byte) error {
+		return subjects.Bucket(subject).ForEach(func(k, v []byte) error {
+			expiresAt := decodeBoltExpiry(v)
+			if expiresAt.IsZero() || now.Before(expiresAt) || sessions.Get(k) != nil {
+				return nil
+			}
+			expired[string(subject)] = append(expired[string(subject)], string(k))
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	for subject, ids := range expired {
+		if err := unindexBolt(tx, subject, ids); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unindexBolt drops ids from the index of subject, and the index once it is
+// left empty.
+func unindexBolt(tx *bolt.Tx, subject string, ids []string) error {
+
+	subjects := tx.Bucket(boltSubjectsBucket)
+	index := subjects.Bucket([]byte(subject))
+	if index == nil {
+		return nil
+	}
+
+	for _, id := range ids {
+		if err := index.Delete([]byte(id)); err != nil {
+			return err
+		}
+	}
+	if k, _ := index.Cursor().First(); k == nil {
+		return subjects.DeleteBucket([]byte(subject))
+	}
+	return nil
+}
+
+// encodeBoltExpiry stores a zero expiry, which never comes, as zero.
+func encodeBoltExpiry(expiresAt time.Time) []byte {
+	encoded := make([]byte, 8)
+	if !expiresAt.IsZero() {
+		binary.BigEndian.PutUint64(encoded, uint64(expiresAt.UnixNano()))
+	}
+	return encoded
+}
+
+// boltLive tells whether an entry expiring at expiresAt, never when it is
+// zero, has not expired by now.
+func boltLive(expiresAt, now time.Time) bool {
+	return expiresAt.IsZero() || now.Before(expiresAt)
+}
+
+func decodeBoltExpiry(encoded []byte) time.Time {
+	if nanos := int64(binary.BigEndian.Uint64(encoded)); nanos != 0 {
+		return time.Unix(0, nanos)
+	}
+	return time.Time{}
+}
+
+// encodeBoltEntry prefixes value with when it expires, as encodeBoltExpiry
+// does.
+func encodeBoltEntry(expiresAt time.Time, value string) []byte {
+	return append(encodeBoltExpiry(expiresAt), value...)
+}
+
+func decodeBoltEntry(entry []byte) (time.Time, []byte) {
+	return decodeBoltExpiry(entry[:8]), entry[8:]
+}
@@ -0,0 +1,236 @@
+package adapters
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+func openBolt(t *testing.T, expires time.Duration) *boltRepository {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "sessions.db"), 0600, nil)
+	if err != nil {
+		t.Fatalf("cannot open bolt db %s", err)
+	}
+
+	repo, err := newBoltRepository(db, expires)
+	if err != nil {
+		t.Fatalf("cannot create bolt repository %s", err)
+	}
+	return repo
+}
+
+func TestShouldStoreDataInBolt(t *testing.T) {
+	t.Parallel()
+
+	repo := openBolt(t, time.Minute)
+	defer repo.Close()
+
+	sessionValue := `{"someSessionTest":"Value"}`
+	err := repo.Set(ctx, "someSessionTestKey", sessionValue)
+	assert.Nil(t, err, "Expect err is nil when storing session value")
+
+	val, err := repo.Get(ctx, "someSessionTestKey")
+	assert.Nil(t, err, "Expect err is nil when retrieving session value")
+	assert.True(t, val == sessionValue, "Expect session to be stored in bolt")
+}
+
+func TestShouldKeepDataWithoutExpiryInBolt(t *testing.T) {
+	t.Parallel()
+
+	repo := openBolt(t, 0)
+	defer repo.Close()
+
+	err := repo.Set(ctx, "somePersistentKey", `{"some":"Value"}`)
+	assert.Nil(t, err, "Expect err is nil when inserting session key")
+	stored, err := repo.SetIfAbsent(ctx, "otherPersistentKey", `{"some":"Value"}`, 0)
+	assert.Nil(t, err, "Expect err is nil when inserting session key")
+	assert.True(t, stored, "Expect session to be stored")
+
+	deleted, err := repo.compact(time.Now().Add(time.Hour))
+	assert.Nil(t, err, "Expect err is nil when compacting")
+	assert.Equal(t, 0, deleted, "Expect sessions without expiry not to be compacted")
+
+	for _, key := range []string{"somePersistentKey", "otherPersistentKey"} {
+		_, err = repo.Get(ctx, key)
+		assert.Nil(t, err, "Expect session stored without expiry to be returned")
+
+		ttl, err := repo.TTL(ctx, key)
+		assert.Nil(t, err, "Expect err is nil when reading the ttl")
+		assert.Less(t, ttl, time.Duration(0), "Expect session stored without expiry to never expire")
+	}
+}
+
+func TestShouldNotRetrieveDataFromBoltIfDataDoesntExist(t *testing.T) {
+	t.Parallel()
+
+	repo := openBolt(t, time.Minute)
+	defer repo.Close()
+
+	val, err := repo.Get(ctx, "thisSessionKeyShouldNotExist")
+
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect not found error for non existing session")
+	assert.True(t, val == "", "Expect session data not to be in bolt")
+}
+
+func TestShouldDeleteDataFromBolt(t *testing.T) {
+	t.Parallel()
+
+	repo := openBolt(t, time.Minute)
+	defer repo.Close()
+
+	err := repo.Set(ctx, "someDeleteTestKey", `{"someDeleteTest":"Value"}`)
+	assert.Nil(t, err, "Expect err is nil when inserting session key")
+
+	deleted, err := repo.Delete(ctx, "someDeleteTestKey")
+	assert.Nil(t, err, "Expect err is nil when deleting session key")
+	assert.Equal(t, int64(1), deleted, "Expect one key to have been deleted")
+
+	val, err := repo.Get(ctx, "someDeleteTestKey")
+	assert.True(t, val == "", "Expect session data to have been deleted from bolt")
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect not found error for deleted session")
+}
+
+func TestShouldScanLiveKeysInBolt(t *testing.T) {
+	t.Parallel()
+
+	repo := openBolt(t, time.Minute)
+	defer repo.Close()
+
+	for _, key := range []string{"someScanKey", "otherScanKey"} {
+		err := repo.Set(ctx, key, `{"some":"Value"}`)
+		assert.Nil(t, err, "Expect err is nil when inserting session key")
+	}
+	err := repo.SetWithTTL(ctx, "someExpiredKey", `{"some":"Value"}`, time.Millisecond)
+	assert.Nil(t, err, "Expect err is nil when inserting session key")
+	time.Sleep(5 * time.Millisecond)
+
+	var keys []string
+	err = repo.Scan(ctx, func(key string) error {
+		keys = append(keys, key)
+		return nil
+	})
+
+	assert.Nil(t, err, "Expect err is nil when scanning keys")
+	assert.ElementsMatch(t, []string{"someScanKey", "otherScanKey"}, keys, "Expect only live keys to be scanned")
+}
+
+func TestShouldChangeExpiryOfDataInBolt(t *testing.T) {
+	t.Parallel()
+
+	repo := openBolt(t, time.Minute)
+	defer repo.Close()
+
+	err := repo.Set(ctx, "someExpireKey", `{"some":"Value"}`)
+	assert.Nil(t, err, "Expect err is nil when inserting session key")
+
+	err = repo.Expire(ctx, "someExpireKey", time.Hour)
+	assert.Nil(t, err, "Expect err is nil when changing the expiry of a session")
+
+	ttl, err := repo.TTL(ctx, "someExpireKey")
+	assert.Nil(t, err, "Expect err is nil when reading the ttl of a session")
+	assert.True(t, ttl > 59*time.Minute && ttl <= time.Hour, "Expect session to be kept for the new ttl, got %s", ttl)
+
+	val, err := repo.Get(ctx, "someExpireKey")
+	assert.Nil(t, err, "Expect err is nil when reading an expired session")
+	assert.Equal(t, `{"some":"Value"}`, val, "Expect session value to be kept")
+
+	err = repo.Expire(ctx, "thisSessionKeyShouldNotExist", time.Hour)
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect not found error for non existing session")
+	_, err = repo.TTL(ctx, "thisSessionKeyShouldNotExist")
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect not found error for non existing session")
+}
+
+func TestShouldWriteDataConditionallyInBolt(t *testing.T) {
+	t.Parallel()
+
+	repo := openBolt(t, time.Minute)
+	defer repo.Close()
+
+	stored, err := repo.SetIfAbsent(ctx, "someConditionalKey", `{"data":"old"}`, 0)
+	assert.Nil(t, err, "Expect err is nil when storing an absent session")
+	assert.True(t, stored, "Expect absent session to be stored")
+
+	stored, err = repo.SetIfAbsent(ctx, "someConditionalKey", `{"data":"other"}`, 0)
+	assert.Nil(t, err, "Expect err is nil when storing an existing session")
+	assert.False(t, stored, "Expect existing session not to be stored again")
+
+	replaced, err := repo.Replace(ctx, "someConditionalKey", `{"data":"other"}`, `{"data":"new"}`, time.Hour)
+	assert.Nil(t, err, "Expect err is nil when replacing a session")
+	assert.False(t, replaced, "Expect session holding another value not to be replaced")
+
+	replaced, err = repo.Replace(ctx, "someConditionalKey", `{"data":"old"}`, `{"data":"new"}`, time.Hour)
+	assert.Nil(t, err, "Expect err is nil when replacing a session")
+	assert.True(t, replaced, "Expect session holding the old value to be replaced")
+
+	val, _ := repo.Get(ctx, "someConditionalKey")
+	assert.Equal(t, `{"data":"new"}`, val)
+
+	ttl, _ := repo.TTL(ctx, "someConditionalKey")
+	assert.True(t, ttl > 59*time.Minute && ttl <= time.Hour, "Expect session to be kept for the new ttl, got %s", ttl)
+}
+
+func TestShouldIncrementCountersInBolt(t *testing.T) {
+	t.Parallel()
+
+	repo := openBolt(t, time.Minute)
+	defer repo.Close()
+
+	err := repo.SetWithTTL(ctx, "someCounterKey", `{"big":12345678901234567,"__session":{"created":1,"updated":1,"version":3}}`, time.Hour)
+	assert.Nil(t, err, "Expect err is nil when storing session value")
+
+	counter, err := repo.Increment(ctx, "someCounterKey", session.Pointer{"views"}, 2, time.UnixMilli(1_000_000))
+	assert.Nil(t, err, "Expect err is nil when incrementing a counter")
+	assert.Equal(t, int64(2), counter, "Expect a missing counter to count from zero")
+
+	val, err := repo.Get(ctx, "someCounterKey")
+	assert.Nil(t, err, "Expect err is nil when retrieving session value")
+	assert.JSONEq(t, `{"big":12345678901234567,"views":2,"__session":{"created":1,"updated":1000000,"version":4}}`, val.(string))
+	assert.Contains(t, val.(string), "12345678901234567", "Expect other numbers to be stored exactly as they were")
+
+	ttl, err := repo.TTL(ctx, "someCounterKey")
+	assert.Nil(t, err, "Expect err is nil when reading the expiry of a session")
+	assert.Greater(t, ttl, time.Minute, "Expect session to keep its expiry")
+
+	_, err = repo.Increment(ctx, "thisSessionKeyShouldNotExist", session.Pointer{"views"}, 1, time.Now())
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect a missing session not to be incremented")
+}
+
+func TestShouldKeepDataInBoltAcrossRestarts(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "sessions.db")
+
+	repo := NewBoltRepository(path, time.Minute, 0).(*boltRepository)
+	repo.Set(ctx, "someSessionTestKey", `{"some":"Value"}`)
+	repo.Close()
+
+	repo = NewBoltRepository(path, time.Minute, 0).(*boltRepository)
+	defer repo.Close()
+
+	val, err := repo.Get(ctx, "someSessionTestKey")
+	assert.Nil(t, err, "Expect err is nil when retrieving session value after restart")
+	assert.True(t, val == `{"some":"Value"}`, "Expect session to survive a restart")
+}
+
+func TestCompactShouldDeleteExpiredDataFromBolt(t *testing.T) {
+	t.Parallel()
+
+	repo := openBolt(t, time.Minute)
+	defer repo.Close()
+
+	repo.Set(ctx, "first", "1")
+	repo.Set(ctx, "second", "2")
+	repo.Set(ctx, "third", "3")
+
+	deleted, err := repo.compact(time.Now())
+	assert.Nil(t, err, "Expect err is nil when compacting")
+	assert.Equal(t, 0, deleted, "Expect live sessions to be kept")
+
+	deleted, err = repo.compact(time.Now().Add(2 * time.Minute))
+	assert.Nil(t, err, "Expect err is nil when compacting")
+	assert.Equal(t, 3, deleted, "Expect expired sessions to be compacted")
+}
@@ -0,0 +1,260 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+)
+
+// ttlReader is implemented by repositories able to tell how long a session has
+// left before it expires. A negative duration means the session never expires.
+type ttlReader interface {
+	TTL(ctx context.Context, key string) (time.Duration, error)
+}
+
+// expirer is implemented by repositories able to change the time a session has
+// left without rewriting it.
+type expirer interface {
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+}
+
+// keyScanner is implemented by repositories able to walk every key they hold.
+// onKey may be called concurrently, e.g. once per node of a cluster.
+type keyScanner interface {
+	Scan(ctx context.Context, onKey func(key string) error) error
+}
+
+// ttlSetter is implemented by repositories able to store a session for a given
+// time instead of their default expiry.
+type ttlSetter interface {
+	SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+}
+
+// absentSetter is implemented by repositories able to store a session only when
+// its key is not in use, atomically. A zero ttl stands for the default expiry.
+type absentSetter interface {
+	SetIfAbsent(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
+}
+
+// primaryReader is implemented by repositories able to read a session from
+// where it is written, when they otherwise read it from copies which may lag
+// behind, such as replicas. Values to be replaced or deleted conditionally are
+// read this way, as they are compared against where they are written.
+type primaryReader interface {
+	GetPrimary(ctx context.Context, key string) (interface{}, error)
+}
+
+// getPrimary reads the session at key from where repo writes it, or as repo
+// reads it when it cannot tell.
+func getPrimary(ctx context.Context, repo Store, key string) (interface{}, error) {
+	if reader, ok := repo.(primaryReader); ok {
+		return reader.GetPrimary(ctx, key)
+	}
+	return repo.Get(ctx, key)
+}
+
+// maxSwapRetries bounds how many times swapFields starts over when the session
+// changes before its new value is stored.
+const maxSwapRetries = 10
+
+// valueReplacer is implemented by repositories able to replace the value of a
+// session only while it still holds old, atomically, storing the new value for
+// ttl instead of the time the session has left. A zero ttl stands for the
+// default expiry. It reports whether the value was replaced.
+type valueReplacer interface {
+	Replace(ctx context.Context, key string, old, new interface{}, ttl time.Duration) (bool, error)
+}
+
+// valueDeleter is implemented by repositories able to delete a session only
+// while it still holds old, atomically. It reports whether it was deleted.
+type valueDeleter interface {
+	DeleteIf(ctx context.Context, key string, old interface{}) (bool, error)
+}
+
+// incrementer is implemented by repositories able to add delta to the counter
+// path refers to within a stored session in a single atomic write, as
+// session.Increment does, keeping the time the session has left. The write
+// also counts in the metadata of the session, as updated at updatedAt. It
+// returns the value the counter is left at, and fails with
+// session.ErrNotSupported when the session is stored without metadata, or
+// session.ErrReadOnly when it was rotated.
+type incrementer interface {
+	Increment(ctx context.Context, key string, path session.Pointer, delta int64, updatedAt time.Time) (int64, error)
+}
+
+// subjectIndexer is implemented by repositories able to keep the sessions of
+// each subject in a set of its own, apart from the sessions, adding and
+// removing them one at a time so that writes of sessions of the same subject
+// never conflict. Sessions are indexed along with when they were last known to
+// expire, zero when they never do, which tells when to check whether they are
+// gone, as they may be touched since.
+type subjectIndexer interface {
+	IndexSession(ctx context.Context, subject, id string, expiresAt time.Time) error
+	UnindexSessions(ctx context.Context, subject string, ids []string) error
+	IndexedSessions(ctx context.Context, subject string) (map[string]time.Time, error)
+}
+
+// subjectScanner is implemented by subject indexers able to walk every subject
+// they index. onSubject may be called concurrently, as with keyScanner.
+type subjectScanner interface {
+	ScanSubjects(ctx context.Context, onSubject func(subject string) error) error
+}
+
+// fieldStore returns repo as a FieldStore, or session.ErrNotSupported when it
+// cannot work with single fields.
+func fieldStore(repo Store) (FieldStore, error) {
+	if fieldRepo, ok := repo.(FieldStore); ok {
+		return fieldRepo, nil
+	}
+	return nil, session.ErrNotSupported
+}
+
+// swapFields merges values into the session stored at key by repo, starting
+// over when the session changes before the merged value is stored. It lets
+// repositories transforming the stored values work with single fields.
+func swapFields(ctx context.Context, repo Store, key string, values map[string]interface{}) error {
+
+	replacer, ok := repo.(valueReplacer)
+	if !ok {
+		return session.ErrNotSupported
+	}
+
+	for i := 0; i < maxSwapRetries; i++ {
+		val, err := getPrimary(ctx, repo, key)
+		if err != nil {
+			return err
+		}
+
+		current, err := encodeValue(val)
+		if err != nil {
+			return err
+		}
+
+		merged, err := mergeFields(current, values)
+		if err != nil {
+			return err
+		}
+
+		ttl, err := timeLeft(ctx, repo, key)
+		if err != nil {
+			return err
+		}
+
+		swapped, err := replacer.Replace(ctx, key, current, merged, ttl)
+		if err != nil || swapped {
+			return err
+		}
+	}
+	return fmt.Errorf("session '%s' kept changing while its fields were set", key)
+}
+
+// timeLeft returns the time the session at key has left in repo, as the ttl to
+// replace it with. It is zero, the default expiry, when repo cannot tell, the
+// session never expires or it is gone, which replacing it then reports.
+func timeLeft(ctx context.Context, repo Store, key string) (time.Duration, error) {
+
+	reader, ok := repo.(ttlReader)
+	if !ok {
+		return 0, nil
+	}
+
+	ttl, err := reader.TTL(ctx, key)
+	switch {
+	case errors.Is(err, session.ErrNotSupported), errors.Is(err, session.ErrNotFound):
+		return 0, nil
+	case err != nil:
+		return 0, err
+	case ttl < 0:
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// incrementCounter adds delta to the counter path refers to within val, a
+// stored session, and counts the write in its metadata, for repositories
+// incrementing counters while they hold the session locked. It returns the
+// session as it is to be stored and the value the counter is left at.
+func incrementCounter(val string, path session.Pointer, delta int64, updatedAt time.Time) (string, int64, error) {
+
+	stored, counter, err := applyIncrement(val, path, delta, updatedAt)
+	if err != nil {
+		return "", 0, err
+	}
+
+	encoded, err := json.Marshal(stored)
+	if err != nil {
+		return "", 0, fmt.Errorf("error '%s' when encoding session", err)
+	}
+	return string(encoded), counter, nil
+}
+
+// applyIncrement is incrementCounter returning the session decoded. Numbers
+// are decoded as json.Number, so the ones the increment leaves alone are
+// stored again exactly as they were.
+func applyIncrement(val string, path session.Pointer, delta int64, updatedAt time.Time) (session.Data, int64, error) {
+
+	decoder := json.NewDecoder(strings.NewReader(val))
+	decoder.UseNumber()
+
+	var stored session.Data
+	if err := decoder.Decode(&stored); err != nil {
+		return nil, 0, fmt.Errorf("error '%s' when decoding session", err)
+	}
+
+	raw, ok := stored[metadataField]
+	if !ok {
+		return nil, 0, fmt.Errorf("%w: session is stored without metadata", session.ErrNotSupported)
+	}
+	metadata, err := decodeMetadata(raw)
+	if err != nil {
+		return nil, 0, err
+	}
+	if metadata.RotatedTo != "" {
+		return nil, 0, fmt.Errorf("%w: session was rotated to another key", session.ErrReadOnly)
+	}
+
+	stored, err = session.Increment{Path: path, Delta: delta}.Apply(stored)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	metadata.UpdatedAt = unixMilli(updatedAt)
+	metadata.Version++
+	stored[metadataField] = metadata
+
+	value, err := appended(stored, path).Get(stored)
+	if err != nil {
+		return nil, 0, err
+	}
+	counter, _ := session.Counter(value)
+	return stored, counter, nil
+}
+
+// appended returns path with the index of the last element of its array in
+// place of "-", which refers to the element appended to it once the increment
+// has been applied.
+func appended(data session.Data, path session.Pointer) session.Pointer {
+
+	last := len(path) - 1
+	if last < 0 || path[last] != "-" {
+		return path
+	}
+	parent, err := path[:last].Get(data)
+	if err != nil {
+		return path
+	}
+	elements, ok := parent.([]interface{})
+	if !ok || len(elements) == 0 {
+		return path
+	}
+
+	resolved := append(session.Pointer(nil), path...)
+	resolved[last] = strconv.Itoa(len(elements) - 1)
+	return resolved
+}
@@ -0,0 +1,388 @@
+package adapters
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	GzipCompression   = "gzip"
+	ZstdCompression   = "zstd"
+	SnappyCompression = "snappy"
+)
+
+// compressedField is the only top level field of a compressed session. Stored
+// values stay JSON objects, so every backend and storage mode can hold them.
+const compressedField = "__compressed"
+
+type compressedEnvelope struct {
+	Compressed *compressedPayload `json:"__compressed"`
+}
+
+type compressedPayload struct {
+	Format string `json:"format"`
+	Data   []byte `json:"data"`
+}
+
+// maxDecompressedSize bounds the size a stored session decompresses to, so a
+// small payload crafted to expand without end cannot exhaust the memory.
+const maxDecompressedSize = 16 << 20
+
+var errDecompressedTooLarge = fmt.Errorf("session decompresses to more than %d bytes", maxDecompressedSize)
+
+// compressionStats is published under /debug/vars. The ratio compares the size
+// of the compressed sessions as stored against their original size.
+var compressionStats = expvar.NewMap("session_compression")
+
+func init() {
+	compressionStats.Set("ratio", expvar.Func(func() interface{} {
+		original, stored := compressionStats.Get("original_bytes"), compressionStats.Get("stored_bytes")
+		if original == nil || stored == nil || original.(*expvar.Int).Value() == 0 {
+			return 0
+		}
+		return float64(stored.(*expvar.Int).Value()) / float64(original.(*expvar.Int).Value())
+	}))
+}
+
+// The zstd encoder and decoder are safe for concurrent use, they are built the
+// first time a zstd session goes through.
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+)
+
+func zstdCodec() (*zstd.Encoder, *zstd.Decoder) {
+	zstdOnce.Do(func() {
+		zstdEncoder, _ = zstd.NewWriter(nil)
+		zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecompressedSize))
+	})
+	return zstdEncoder, zstdDecoder
+}
+
+type compressedRepository struct {
+	next      Store
+	format    string
+	threshold int
+}
+
+// NewCompressedRepository compresses with format the sessions of at least
+// threshold bytes before handing them to next. Sessions are decompressed on the
+// way back whatever format they were compressed with, and the ones stored
+// uncompressed are returned as they are.
+func NewCompressedRepository(next Store, format string, threshold int) Store {
+	switch format {
+	case GzipCompression, ZstdCompression, SnappyCompression:
+	default:
+		panic(fmt.Sprintf("compression format '%s' not supported", format))
+	}
+	return &compressedRepository{next, format, threshold}
+}
+
+func (r *compressedRepository) Set(ctx context.Context, key string, value interface{}) error {
+
+	val, err := r.compress(value)
+	if err != nil {
+		return err
+	}
+	return r.next.Set(ctx, key, val)
+}
+
+func (r *compressedRepository) SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+
+	setter, ok := r.next.(ttlSetter)
+	if !ok {
+		return session.ErrNotSupported
+	}
+
+	val, err := r.compress(value)
+	if err != nil {
+		return err
+	}
+	return setter.SetWithTTL(ctx, key, val, ttl)
+}
+
+func (r *compressedRepository) Get(ctx context.Context, key string) (interface{}, error) {
+	return r.decompressed(r.next.Get(ctx, key))
+}
+
+func (r *compressedRepository) GetPrimary(ctx context.Context, key string) (interface{}, error) {
+	return r.decompressed(getPrimary(ctx, r.next, key))
+}
+
+// decompressed returns val, as read from the next repository, decompressed.
+func (r *compressedRepository) decompressed(val interface{}, err error) (interface{}, error) {
+	if err != nil {
+		return val, err
+	}
+
+	plain, err := r.decompress(val)
+	if err != nil {
+		return "", err
+	}
+	return plain, nil
+}
+
+func (r *compressedRepository) Delete(ctx context.Context, key string) (int64, error) {
+	return r.next.Delete(ctx, key)
+}
+
+func (r *compressedRepository) TTL(ctx context.Context, key string) (time.Duration, error) {
+
+	reader, ok := r.next.(ttlReader)
+	if !ok {
+		return 0, session.ErrNotSupported
+	}
+	return reader.TTL(ctx, key)
+}
+
+func (r *compressedRepository) Expire(ctx context.Context, key string, ttl time.Duration) error {
+
+	expirer, ok := r.next.(expirer)
+	if !ok {
+		return session.ErrNotSupported
+	}
+	return expirer.Expire(ctx, key, ttl)
+}
+
+// IndexSession and the other methods of the indexes of subjects go to the
+// next repository as they are, as the indexes hold no session data.
+func (r *compressedRepository) IndexSession(ctx context.Context, subject, id string, expiresAt time.Time) error {
+
+	indexer, ok := r.next.(subjectIndexer)
+	if !ok {
+		return session.ErrNotSupported
+	}
+	return indexer.IndexSession(ctx, subject, id, expiresAt)
+}
+
+func (r *compressedRepository) UnindexSessions(ctx context.Context, subject string, ids []string) error {
+
+	indexer, ok := r.next.(subjectIndexer)
+	if !ok {
+		return session.ErrNotSupported
+	}
+	return indexer.UnindexSessions(ctx, subject, ids)
+}
+
+func (r *compressedRepository) IndexedSessions(ctx context.Context, subject string) (map[string]time.Time, error) {
+
+	indexer, ok := r.next.(subjectIndexer)
+	if !ok {
+		return nil, session.ErrNotSupported
+	}
+	return indexer.IndexedSessions(ctx, subject)
+}
+
+func (r *compressedRepository) GetFields(ctx context.Context, key string, fields []string) (map[string]interface{}, error) {
+
+	val, err := r.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return pickFields(val.(string), fields)
+}
+
+func (r *compressedRepository) SetFields(ctx context.Context, key string, values map[string]interface{}) error {
+	return swapFields(ctx, r, key, values)
+}
+
+// Replace stores new at key for ttl while the session decompresses to old.
+func (r *compressedRepository) Replace(ctx context.Context, key string, old, new interface{}, ttl time.Duration) (bool, error) {
+
+	replacer, ok := r.next.(valueReplacer)
+	if !ok {
+		return false, session.ErrNotSupported
+	}
+
+	oldVal, err := encodeValue(old)
+	if err != nil {
+		return false, err
+	}
+
+	stored, err := getPrimary(ctx, r.next, key)
+	if errors.Is(err, session.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	plain, err := r.decompress(stored)
+	if err != nil || plain != oldVal {
+		return false, err
+	}
+
+	compressed, err := r.compress(new)
+	if err != nil {
+		return false, err
+	}
+	return replacer.Replace(ctx, key, stored, compressed, ttl)
+}
+
+func (r *compressedRepository) DeleteIf(ctx context.Context, key string, old interface{}) (bool, error) {
+
+	deleter, ok := r.next.(valueDeleter)
+	if !ok {
+		return false, session.ErrNotSupported
+	}
+
+	oldVal, err := encodeValue(old)
+	if err != nil {
+		return false, err
+	}
+
+	stored, err := getPrimary(ctx, r.next, key)
+	if errors.Is(err, session.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	plain, err := r.decompress(stored)
+	if err != nil || plain != oldVal {
+		return false, err
+	}
+	return deleter.DeleteIf(ctx, key, stored)
+}
+
+func (r *compressedRepository) SetIfAbsent(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+
+	setter, ok := r.next.(absentSetter)
+	if !ok {
+		return false, session.ErrNotSupported
+	}
+
+	val, err := r.compress(value)
+	if err != nil {
+		return false, err
+	}
+	return setter.SetIfAbsent(ctx, key, val, ttl)
+}
+
+// compress returns the value to store for value, left uncompressed when it is
+// below the threshold or compressing it would not make it any smaller.
+func (r *compressedRepository) compress(value interface{}) (string, error) {
+
+	val, err := encodeValue(value)
+	if err != nil {
+		return "", err
+	}
+
+	if len(val) < r.threshold {
+		compressionStats.Add("skipped", 1)
+		return val, nil
+	}
+
+	data, err := compressBytes(r.format, []byte(val))
+	if err != nil {
+		return "", err
+	}
+
+	compressed, err := json.Marshal(compressedEnvelope{&compressedPayload{Format: r.format, Data: data}})
+	if err != nil {
+		return "", err
+	}
+	if len(compressed) >= len(val) {
+		compressionStats.Add("incompressible", 1)
+		return val, nil
+	}
+
+	compressionStats.Add("compressed", 1)
+	compressionStats.Add("original_bytes", int64(len(val)))
+	compressionStats.Add("stored_bytes", int64(len(compressed)))
+	return string(compressed), nil
+}
+
+func (r *compressedRepository) decompress(value interface{}) (string, error) {
+
+	val, err := encodeValue(value)
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.Contains(val, compressedField) {
+		return val, nil
+	}
+
+	var envelope compressedEnvelope
+	if err := json.Unmarshal([]byte(val), &envelope); err != nil || envelope.Compressed == nil {
+		return val, nil
+	}
+
+	plain, err := decompressBytes(envelope.Compressed.Format, envelope.Compressed.Data)
+	if err != nil {
+		return "", fmt.Errorf("error '%s' when decompressing %s session", err, envelope.Compressed.Format)
+	}
+	return string(plain), nil
+}
+
+func compressBytes(format string, data []byte) ([]byte, error) {
+	switch format {
+	case GzipCompression:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case ZstdCompression:
+		encoder, _ := zstdCodec()
+		return encoder.EncodeAll(data, nil), nil
+	case SnappyCompression:
+		return snappy.Encode(nil, data), nil
+	default:
+		return nil, fmt.Errorf("compression format '%s' not supported", format)
+	}
+}
+
+func decompressBytes(format string, data []byte) ([]byte, error) {
+	switch format {
+	case GzipCompression:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		plain, err := io.ReadAll(io.LimitReader(r, maxDecompressedSize+1))
+		if err == nil && len(plain) > maxDecompressedSize {
+			return nil, errDecompressedTooLarge
+		}
+		return plain, err
+	case ZstdCompression:
+		_, decoder := zstdCodec()
+		plain, err := decoder.DecodeAll(data, nil)
+		if errors.Is(err, zstd.ErrDecoderSizeExceeded) {
+			return nil, errDecompressedTooLarge
+		}
+		return plain, err
+	case SnappyCompression:
+		size, err := snappy.DecodedLen(data)
+		if err != nil {
+			return nil, err
+		}
+		if size > maxDecompressedSize {
+			return nil, errDecompressedTooLarge
+		}
+		return snappy.Decode(nil, data)
+	default:
+		return nil, fmt.Errorf("compression format '%s' not supported", format)
+	}
+}
@@ -0,0 +1,136 @@
+package adapters
+
+import (
+	"expvar"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func largeSession() string {
+	return fmt.Sprintf(`{"cart":"%s"}`, strings.Repeat("someProduct,", 200))
+}
+
+func TestShouldCompressLargeSessions(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range []string{GzipCompression, ZstdCompression, SnappyCompression} {
+		memory := newMemoryCache(defaultMemoryShards, time.Minute, 0, 0)
+		compressed := NewCompressedRepository(memory, format, 1024)
+
+		err := compressed.Set(ctx, "someLargeKey", largeSession())
+		assert.Nil(t, err, "Expect err is nil when storing %s session", format)
+
+		stored, _ := memory.Get(ctx, "someLargeKey")
+		assert.Contains(t, stored, fmt.Sprintf(`"format":"%s"`, format), "Expect session to be stored compressed")
+		assert.Less(t, len(stored.(string)), len(largeSession()), "Expect stored session to be smaller")
+
+		val, err := compressed.Get(ctx, "someLargeKey")
+		assert.Nil(t, err, "Expect err is nil when retrieving %s session", format)
+		assert.Equal(t, largeSession(), val)
+
+		memory.Close()
+	}
+}
+
+func TestShouldStoreSmallAndIncompressibleSessionsAsTheyAre(t *testing.T) {
+	t.Parallel()
+
+	memory := newMemoryCache(defaultMemoryShards, time.Minute, 0, 0)
+	defer memory.Close()
+
+	compressed := NewCompressedRepository(memory, GzipCompression, 16)
+
+	compressed.Set(ctx, "someSmallKey", `{"a":1}`)
+	compressed.Set(ctx, "someIncompressibleKey", `{"data":"q8Zx2LmP0vTn7RwYb3Ke"}`)
+
+	for _, key := range []string{"someSmallKey", "someIncompressibleKey"} {
+		stored, _ := memory.Get(ctx, key)
+		val, err := compressed.Get(ctx, key)
+		assert.Nil(t, err, "Expect err is nil when retrieving session")
+		assert.Equal(t, stored, val, "Expect session '%s' to be stored uncompressed", key)
+	}
+
+	assert.NotNil(t, expvar.Get("session_compression").(*expvar.Map).Get("skipped"), "Expect skipped sessions to be counted")
+}
+
+func TestShouldReadSessionsCompressedWithAnyFormat(t *testing.T) {
+	t.Parallel()
+
+	memory := newMemoryCache(defaultMemoryShards, time.Minute, 0, 0)
+	defer memory.Close()
+
+	NewCompressedRepository(memory, SnappyCompression, 0).Set(ctx, "someSnappyKey", largeSession())
+	zstd := NewCompressedRepository(memory, ZstdCompression, 0)
+
+	val, err := zstd.Get(ctx, "someSnappyKey")
+	assert.Nil(t, err, "Expect err is nil when retrieving snappy session")
+	assert.Equal(t, largeSession(), val)
+
+	err = zstd.(*compressedRepository).SetFields(ctx, "someSnappyKey", map[string]interface{}{"age": 35})
+	assert.Nil(t, err, "Expect err is nil when setting session fields")
+
+	fields, err := zstd.(*compressedRepository).GetFields(ctx, "someSnappyKey", []string{"age"})
+	assert.Nil(t, err, "Expect err is nil when retrieving session fields")
+	assert.Equal(t, map[string]interface{}{"age": float64(35)}, fields)
+
+	stored, _ := memory.Get(ctx, "someSnappyKey")
+	assert.Contains(t, stored, `"format":"zstd"`, "Expect updated session to be compressed with the configured format")
+}
+
+func TestShouldRejectSessionsDecompressingOverTheMaximumSize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario string
+		format   string
+	}{
+		{scenario: "Should reject gzip sessions over the maximum size", format: GzipCompression},
+		{scenario: "Should reject zstd sessions over the maximum size", format: ZstdCompression},
+		{scenario: "Should reject snappy sessions over the maximum size", format: SnappyCompression},
+	}
+
+	bomb := make([]byte, maxDecompressedSize+1)
+	for _, test := range tests {
+		data, err := compressBytes(test.format, bomb)
+		assert.Nil(t, err, test.scenario)
+
+		_, err = decompressBytes(test.format, data)
+		assert.ErrorIs(t, err, errDecompressedTooLarge, test.scenario)
+
+		data, err = compressBytes(test.format, bomb[:maxDecompressedSize])
+		assert.Nil(t, err, test.scenario)
+
+		plain, err := decompressBytes(test.format, data)
+		assert.Nil(t, err, test.scenario)
+		assert.Len(t, plain, maxDecompressedSize, test.scenario)
+	}
+}
+
+func TestShouldCompressBeforeEncrypting(t *testing.T) {
+	t.Parallel()
+
+	memory := newMemoryCache(defaultMemoryShards, time.Minute, 0, 0)
+	defer memory.Close()
+
+	repo := NewCompressedRepository(NewEncryptedRepository(memory, testKeyring(t, "old", "old")), GzipCompression, 1024)
+
+	err := repo.Set(ctx, "someLargeKey", largeSession())
+	assert.Nil(t, err, "Expect err is nil when storing session")
+
+	stored, _ := memory.Get(ctx, "someLargeKey")
+	assert.Less(t, len(stored.(string)), len(largeSession()), "Expect encrypted session to be compressed")
+
+	val, err := repo.Get(ctx, "someLargeKey")
+	assert.Nil(t, err, "Expect err is nil when retrieving session")
+	assert.Equal(t, largeSession(), val)
+}
+
+func TestShouldPanicOnUnknownCompressionFormat(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() { NewCompressedRepository(nil, "lzma", 0) })
+}
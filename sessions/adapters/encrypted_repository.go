@@ -0,0 +1,317 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+)
+
+const reencryptProgressEvery = 1000
+
+// EncryptedRepository encrypts sessions with AES-GCM before handing them to the
+// next repository, and decrypts them on the way back.
+type EncryptedRepository struct {
+	next    Store
+	keyring *Keyring
+}
+
+func NewEncryptedRepository(next Store, keyring *Keyring) *EncryptedRepository {
+	return &EncryptedRepository{next: next, keyring: keyring}
+}
+
+func (r *EncryptedRepository) Set(ctx context.Context, key string, value interface{}) error {
+
+	encrypted, err := r.encrypt(key, value)
+	if err != nil {
+		return err
+	}
+	return r.next.Set(ctx, key, encrypted)
+}
+
+func (r *EncryptedRepository) SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+
+	setter, ok := r.next.(ttlSetter)
+	if !ok {
+		return session.ErrNotSupported
+	}
+
+	encrypted, err := r.encrypt(key, value)
+	if err != nil {
+		return err
+	}
+	return setter.SetWithTTL(ctx, key, encrypted, ttl)
+}
+
+func (r *EncryptedRepository) Get(ctx context.Context, key string) (interface{}, error) {
+	val, err := r.next.Get(ctx, key)
+	return r.decrypted(key, val, err)
+}
+
+func (r *EncryptedRepository) GetPrimary(ctx context.Context, key string) (interface{}, error) {
+	val, err := getPrimary(ctx, r.next, key)
+	return r.decrypted(key, val, err)
+}
+
+// decrypted returns val, as read from the next repository for key, decrypted.
+func (r *EncryptedRepository) decrypted(key string, val interface{}, err error) (interface{}, error) {
+	if err != nil {
+		return val, err
+	}
+
+	plain, _, err := r.decrypt(key, val)
+	if err != nil {
+		return "", err
+	}
+	return plain, nil
+}
+
+func (r *EncryptedRepository) Delete(ctx context.Context, key string) (int64, error) {
+	return r.next.Delete(ctx, key)
+}
+
+func (r *EncryptedRepository) TTL(ctx context.Context, key string) (time.Duration, error) {
+
+	reader, ok := r.next.(ttlReader)
+	if !ok {
+		return 0, session.ErrNotSupported
+	}
+	return reader.TTL(ctx, key)
+}
+
+func (r *EncryptedRepository) Expire(ctx context.Context, key string, ttl time.Duration) error {
+
+	expirer, ok := r.next.(expirer)
+	if !ok {
+		return session.ErrNotSupported
+	}
+	return expirer.Expire(ctx, key, ttl)
+}
+
+// IndexSession and the other methods of the indexes of subjects go to the
+// next repository as they are, as the indexes hold no session data.
+func (r *EncryptedRepository) IndexSession(ctx context.Context, subject, id string, expiresAt time.Time) error {
+
+	indexer, ok := r.next.(subjectIndexer)
+	if !ok {
+		return session.ErrNotSupported
+	}
+	return indexer.IndexSession(ctx, subject, id, expiresAt)
+}
+
+func (r *EncryptedRepository) UnindexSessions(ctx context.Context, subject string, ids []string) error {
+
+	indexer, ok := r.next.(subjectIndexer)
+	if !ok {
+		return session.ErrNotSupported
+	}
+	return indexer.UnindexSessions(ctx, subject, ids)
+}
+
+func (r *EncryptedRepository) IndexedSessions(ctx context.Context, subject string) (map[string]time.Time, error) {
+
+	indexer, ok := r.next.(subjectIndexer)
+	if !ok {
+		return nil, session.ErrNotSupported
+	}
+	return indexer.IndexedSessions(ctx, subject)
+}
+
+func (r *EncryptedRepository) GetFields(ctx context.Context, key string, fields []string) (map[string]interface{}, error) {
+
+	val, err := r.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return pickFields(val.(string), fields)
+}
+
+func (r *EncryptedRepository) SetFields(ctx context.Context, key string, values map[string]interface{}) error {
+	return swapFields(ctx, r, key, values)
+}
+
+// Replace stores new at key for ttl while the session decrypts to old.
+func (r *EncryptedRepository) Replace(ctx context.Context, key string, old, new interface{}, ttl time.Duration) (bool, error) {
+
+	replacer, ok := r.next.(valueReplacer)
+	if !ok {
+		return false, session.ErrNotSupported
+	}
+
+	oldVal, err := encodeValue(old)
+	if err != nil {
+		return false, err
+	}
+
+	stored, err := getPrimary(ctx, r.next, key)
+	if errors.Is(err, session.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	plain, _, err := r.decrypt(key, stored)
+	if err != nil || plain != oldVal {
+		return false, err
+	}
+
+	encrypted, err := r.encrypt(key, new)
+	if err != nil {
+		return false, err
+	}
+	return replacer.Replace(ctx, key, stored, encrypted, ttl)
+}
+
+func (r *EncryptedRepository) DeleteIf(ctx context.Context, key string, old interface{}) (bool, error) {
+
+	deleter, ok := r.next.(valueDeleter)
+	if !ok {
+		return false, session.ErrNotSupported
+	}
+
+	oldVal, err := encodeValue(old)
+	if err != nil {
+		return false, err
+	}
+
+	stored, err := getPrimary(ctx, r.next, key)
+	if errors.Is(err, session.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	plain, _, err := r.decrypt(key, stored)
+	if err != nil || plain != oldVal {
+		return false, err
+	}
+	return deleter.DeleteIf(ctx, key, stored)
+}
+
+func (r *EncryptedRepository) SetIfAbsent(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+
+	setter, ok := r.next.(absentSetter)
+	if !ok {
+		return false, session.ErrNotSupported
+	}
+
+	encrypted, err := r.encrypt(key, value)
+	if err != nil {
+		return false, err
+	}
+	return setter.SetIfAbsent(ctx, key, encrypted, ttl)
+}
+
+// CanReencrypt tells whether the next repository is able to list its sessions
+// and replace them atomically, which Reencrypt needs.
+func (r *EncryptedRepository) CanReencrypt() bool {
+	_, scans := r.next.(keyScanner)
+	_, replaces := r.next.(valueReplacer)
+	return scans && replaces
+}
+
+// Reencrypt walks every session of the next repository and encrypts again the
+// ones not sealed with the active key, keeping the time they have left. Once it
+// completes without failures the other keys can be removed from the keyring.
+func (r *EncryptedRepository) Reencrypt(ctx context.Context) error {
+
+	scanner, ok := r.next.(keyScanner)
+	if !ok {
+		return fmt.Errorf("repository %T cannot list its sessions", r.next)
+	}
+	replacer, ok := r.next.(valueReplacer)
+	if !ok {
+		return fmt.Errorf("repository %T cannot replace sessions atomically", r.next)
+	}
+
+	var scanned, reencrypted, skipped, failed int64
+	logProgress := func(msg string) {
+		logrus.WithFields(logrus.Fields{
+			"scanned":     atomic.LoadInt64(&scanned),
+			"reencrypted": atomic.LoadInt64(&reencrypted),
+			"skipped":     atomic.LoadInt64(&skipped),
+			"failed":      atomic.LoadInt64(&failed),
+		}).Info(msg)
+	}
+
+	err := scanner.Scan(ctx, func(key string) error {
+		if n := atomic.AddInt64(&scanned, 1); n%reencryptProgressEvery == 0 {
+			logProgress("Session re-encryption in progress")
+		}
+
+		swapped, err := r.reencrypt(ctx, replacer, key)
+		switch {
+		case err != nil:
+			atomic.AddInt64(&failed, 1)
+			logrus.WithError(err).WithField("key", key).Warn("Failed to re-encrypt session")
+		case swapped:
+			atomic.AddInt64(&reencrypted, 1)
+		default:
+			atomic.AddInt64(&skipped, 1)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logProgress("Session re-encryption completed")
+	return nil
+}
+
+// reencrypt seals the session at key with the active key. It leaves alone the
+// sessions already sealed with it, and the ones changed or deleted meanwhile,
+// as they are written with the active key anyway.
+func (r *EncryptedRepository) reencrypt(ctx context.Context, replacer valueReplacer, key string) (bool, error) {
+
+	stored, err := getPrimary(ctx, r.next, key)
+	if errors.Is(err, session.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	plain, keyID, err := r.decrypt(key, stored)
+	if err != nil {
+		return false, err
+	}
+	if keyID == r.keyring.active {
+		return false, nil
+	}
+
+	encrypted, err := r.keyring.encrypt(key, plain)
+	if err != nil {
+		return false, err
+	}
+
+	ttl, err := timeLeft(ctx, r.next, key)
+	if err != nil {
+		return false, err
+	}
+	return replacer.Replace(ctx, key, stored, encrypted, ttl)
+}
+
+func (r *EncryptedRepository) encrypt(key string, value interface{}) (string, error) {
+
+	val, err := encodeValue(value)
+	if err != nil {
+		return "", err
+	}
+	return r.keyring.encrypt(key, val)
+}
+
+func (r *EncryptedRepository) decrypt(key string, value interface{}) (string, string, error) {
+
+	val, err := encodeValue(value)
+	if err != nil {
+		return "", "", err
+	}
+	return r.keyring.decrypt(key, val)
+}
@@ -0,0 +1,209 @@
+package adapters
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	oldEncryptionKey = []byte("0123456789abcdef0123456789abcdef")
+	newEncryptionKey = []byte("fedcba9876543210fedcba9876543210")
+)
+
+func testKeyring(t *testing.T, active string, ids ...string) *Keyring {
+	all := map[string][]byte{"old": oldEncryptionKey, "new": newEncryptionKey}
+
+	keys := make(map[string][]byte, len(ids))
+	for _, id := range ids {
+		keys[id] = all[id]
+	}
+
+	keyring, err := NewKeyring(active, keys)
+	if err != nil {
+		t.Fatalf("cannot create keyring: %s", err)
+	}
+	return keyring
+}
+
+func TestShouldEncryptSessionValues(t *testing.T) {
+	t.Parallel()
+
+	memory := newMemoryCache(defaultMemoryShards, time.Minute, 0, 0)
+	defer memory.Close()
+
+	encrypted := NewEncryptedRepository(memory, testKeyring(t, "old", "old"))
+
+	err := encrypted.Set(ctx, "someEncryptedKey", `{"token":"secret"}`)
+	assert.Nil(t, err, "Expect err is nil when storing session value")
+
+	stored, _ := memory.Get(ctx, "someEncryptedKey")
+	assert.NotContains(t, stored, "secret", "Expect session to be stored encrypted")
+	assert.Contains(t, stored, `"key":"old"`, "Expect stored session to carry the key id")
+
+	val, err := encrypted.Get(ctx, "someEncryptedKey")
+	assert.Nil(t, err, "Expect err is nil when retrieving session value")
+	assert.Equal(t, `{"token":"secret"}`, val)
+
+	fields, err := encrypted.GetFields(ctx, "someEncryptedKey", []string{"token"})
+	assert.Nil(t, err, "Expect err is nil when retrieving session fields")
+	assert.Equal(t, map[string]interface{}{"token": "secret"}, fields)
+
+	err = encrypted.SetFields(ctx, "someEncryptedKey", map[string]interface{}{"age": 35})
+	assert.Nil(t, err, "Expect err is nil when setting session fields")
+
+	val, _ = encrypted.Get(ctx, "someEncryptedKey")
+	assert.JSONEq(t, `{"token":"secret","age":35}`, val.(string))
+}
+
+func TestShouldReadSessionsStoredBeforeEncryption(t *testing.T) {
+	t.Parallel()
+
+	memory := newMemoryCache(defaultMemoryShards, time.Minute, 0, 0)
+	defer memory.Close()
+
+	memory.Set(ctx, "somePlainKey", `{"data":"plain"}`)
+
+	val, err := NewEncryptedRepository(memory, testKeyring(t, "old", "old")).Get(ctx, "somePlainKey")
+	assert.Nil(t, err, "Expect err is nil when retrieving plain session")
+	assert.Equal(t, `{"data":"plain"}`, val)
+}
+
+func TestShouldRejectTamperedOrUnknownKeySessions(t *testing.T) {
+	t.Parallel()
+
+	memory := newMemoryCache(defaultMemoryShards, time.Minute, 0, 0)
+	defer memory.Close()
+
+	NewEncryptedRepository(memory, testKeyring(t, "old", "old")).Set(ctx, "someEncryptedKey", `{"token":"secret"}`)
+
+	_, err := NewEncryptedRepository(memory, testKeyring(t, "new", "new")).Get(ctx, "someEncryptedKey")
+	assert.ErrorContains(t, err, "unknown key 'old'")
+
+	stored, _ := memory.Get(ctx, "someEncryptedKey")
+	memory.Set(ctx, "someEncryptedKey", strings.Replace(stored.(string), `"key":"old"`, `"key":"new"`, 1))
+
+	_, err = NewEncryptedRepository(memory, testKeyring(t, "new", "old", "new")).Get(ctx, "someEncryptedKey")
+	assert.ErrorContains(t, err, "when decrypting session with key 'new'")
+}
+
+func TestShouldReencryptSessionsWithActiveKey(t *testing.T) {
+	t.Parallel()
+
+	memory := newMemoryCache(defaultMemoryShards, time.Minute, 0, 0)
+	defer memory.Close()
+
+	NewEncryptedRepository(memory, testKeyring(t, "old", "old")).Set(ctx, "someOldKey", `{"data":"old"}`)
+	memory.Set(ctx, "somePlainKey", `{"data":"plain"}`)
+
+	rotated := NewEncryptedRepository(memory, testKeyring(t, "new", "old", "new"))
+	rotated.Set(ctx, "someNewKey", `{"data":"new"}`)
+
+	val, err := rotated.Get(ctx, "someOldKey")
+	assert.Nil(t, err, "Expect sessions sealed with old keys to be readable")
+	assert.Equal(t, `{"data":"old"}`, val)
+
+	err = rotated.Reencrypt(ctx)
+	assert.Nil(t, err, "Expect err is nil when re-encrypting sessions")
+
+	retired := NewEncryptedRepository(memory, testKeyring(t, "new", "new"))
+	for key, expected := range map[string]string{
+		"someOldKey":   `{"data":"old"}`,
+		"somePlainKey": `{"data":"plain"}`,
+		"someNewKey":   `{"data":"new"}`,
+	} {
+		val, err := retired.Get(ctx, key)
+		assert.Nil(t, err, "Expect session '%s' to be readable with the active key only", key)
+		assert.Equal(t, expected, val)
+	}
+}
+
+func TestShouldLoadKeyringFromConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "new"), []byte(base64.StdEncoding.EncodeToString(newEncryptionKey)+"\n"), 0600)
+	os.Mkdir(filepath.Join(dir, "..data"), 0700)
+
+	keyring, err := KeyringFrom(Config{Prefix: "DB_", Lookup: lookupFrom(map[string]string{
+		"DB_ENCRYPTION_KEYS":       "old:" + base64.StdEncoding.EncodeToString(oldEncryptionKey),
+		"DB_ENCRYPTION_KEYS_DIR":   dir,
+		"DB_ENCRYPTION_ACTIVE_KEY": "new",
+	})})
+	assert.Nil(t, err, "Expect err is nil when loading keyring")
+	assert.Equal(t, "new", keyring.active)
+	assert.Len(t, keyring.aeads, 2)
+
+	keyring, err = KeyringFrom(Config{Prefix: "DB_", Lookup: lookupFrom(nil)})
+	assert.Nil(t, err, "Expect err is nil when no keys are configured")
+	assert.Nil(t, keyring, "Expect no keyring when no keys are configured")
+
+	tests := []struct {
+		scenario string
+		settings map[string]string
+		err      string
+	}{
+		{
+			scenario: "Should reject malformed key lists",
+			settings: map[string]string{"DB_ENCRYPTION_KEYS": "old"},
+			err:      "DB_ENCRYPTION_KEYS entries must look like id:base64key",
+		},
+		{
+			scenario: "Should reject keys of invalid size",
+			settings: map[string]string{"DB_ENCRYPTION_KEYS": "old:" + base64.StdEncoding.EncodeToString([]byte("short"))},
+			err:      "when loading encryption key 'old'",
+		},
+		{
+			scenario: "Should require the active key when several keys are configured",
+			settings: map[string]string{"DB_ENCRYPTION_KEYS": "old:" + base64.StdEncoding.EncodeToString(oldEncryptionKey) + ",new:" + base64.StdEncoding.EncodeToString(newEncryptionKey)},
+			err:      "active encryption key '' not found",
+		},
+	}
+
+	for _, test := range tests {
+		_, err := KeyringFrom(Config{Prefix: "DB_", Lookup: lookupFrom(test.settings)})
+		assert.ErrorContains(t, err, test.err, test.scenario)
+	}
+}
+
+func TestShouldRejectSessionsMovedToAnotherKey(t *testing.T) {
+	t.Parallel()
+
+	memory := newMemoryCache(defaultMemoryShards, time.Minute, 0, 0)
+	defer memory.Close()
+
+	encrypted := NewEncryptedRepository(memory, testKeyring(t, "old", "old"))
+	encrypted.Set(ctx, "someEncryptedKey", `{"token":"secret"}`)
+
+	stored, _ := memory.Get(ctx, "someEncryptedKey")
+	memory.Set(ctx, "someOtherKey", stored)
+
+	_, err := encrypted.Get(ctx, "someOtherKey")
+	assert.ErrorContains(t, err, "when decrypting session with key 'old'")
+}
+
+func TestShouldRejectSessionsSealedWithoutTheirKey(t *testing.T) {
+	t.Parallel()
+
+	memory := newMemoryCache(defaultMemoryShards, time.Minute, 0, 0)
+	defer memory.Close()
+
+	keyring := testKeyring(t, "old", "old")
+	aead := keyring.aeads["old"]
+	nonce := make([]byte, aead.NonceSize())
+	unbound, _ := json.Marshal(encryptedEnvelope{&encryptedPayload{
+		Key:  "old",
+		Data: aead.Seal(nonce, nonce, []byte(`{"data":"unbound"}`), []byte("old")),
+	}})
+	memory.Set(ctx, "someUnboundKey", string(unbound))
+
+	encrypted := NewEncryptedRepository(memory, keyring)
+	_, err := encrypted.Get(ctx, "someUnboundKey")
+	assert.ErrorContains(t, err, "when decrypting session with key 'old'", "Expect sessions not sealed along with their key to be rejected")
+}
@@ -0,0 +1,53 @@
+package adapters
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+)
+
+const defaultRingReplicas = 160
+
+// hashRing maps keys to nodes with consistent hashing: every node is placed on
+// the ring several times, and a key belongs to the first node found clockwise
+// from its own hash. Adding or removing a node only remaps the keys that fall
+// between that node's points and their predecessors.
+type hashRing struct {
+	replicas int
+	points   []uint32
+	nodes    map[uint32]string
+}
+
+func newHashRing(replicas int, nodes ...string) *hashRing {
+	r := &hashRing{replicas: replicas, nodes: make(map[uint32]string)}
+	for _, node := range nodes {
+		r.add(node)
+	}
+	return r
+}
+
+func (r *hashRing) add(node string) {
+	for i := 0; i < r.replicas; i++ {
+		point := crc32.ChecksumIEEE([]byte(node + "#" + strconv.Itoa(i)))
+		if _, ok := r.nodes[point]; ok {
+			continue
+		}
+		r.nodes[point] = node
+		r.points = append(r.points, point)
+	}
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+}
+
+// get returns the node owning key, or an empty string if the ring is empty.
+func (r *hashRing) get(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+
+	hash := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= hash })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.nodes[r.points[i]]
+}
@@ -0,0 +1,36 @@
+package adapters
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashRingShouldReturnEmptyNodeWhenEmpty(t *testing.T) {
+	t.Parallel()
+
+	ring := newHashRing(defaultRingReplicas)
+
+	assert.Equal(t, "", ring.get("someKey"), "Expect no node for an empty ring")
+}
+
+func TestHashRingShouldOnlyRemapAFractionOfKeysWhenANodeIsAdded(t *testing.T) {
+	t.Parallel()
+
+	before := newHashRing(defaultRingReplicas, "node1", "node2", "node3")
+	after := newHashRing(defaultRingReplicas, "node1", "node2", "node3", "node4")
+
+	keys := 10000
+	moved := 0
+	for i := 0; i < keys; i++ {
+		key := fmt.Sprintf("session-%d", i)
+		if before.get(key) != after.get(key) {
+			assert.Equal(t, "node4", after.get(key), "Expect keys to only move to the new node")
+			moved++
+		}
+	}
+
+	assert.Greater(t, moved, keys/8, "Expect the new node to take a share of the keys")
+	assert.Less(t, moved, keys/3, "Expect most keys to stay on their node")
+}
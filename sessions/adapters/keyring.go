@@ -0,0 +1,190 @@
+package adapters
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// encryptedField is the only top level field of an encrypted session. Stored
+// values stay JSON objects, so every backend and storage mode can hold them.
+const encryptedField = "__encrypted"
+
+type encryptedEnvelope struct {
+	Encrypted *encryptedPayload `json:"__encrypted"`
+}
+
+type encryptedPayload struct {
+	Key  string `json:"key"`
+	Data []byte `json:"data"`
+}
+
+// Keyring holds the AES keys sessions are encrypted with. New values are always
+// sealed with the active key, while any key of the ring can open them.
+type Keyring struct {
+	active string
+	aeads  map[string]cipher.AEAD
+}
+
+// NewKeyring builds a keyring from AES-128, AES-192 or AES-256 keys indexed by
+// their ID, the active one being used to encrypt.
+func NewKeyring(active string, keys map[string][]byte) (*Keyring, error) {
+	if _, ok := keys[active]; !ok {
+		return nil, fmt.Errorf("active encryption key '%s' not found", active)
+	}
+
+	k := &Keyring{active: active, aeads: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if id == "" || strings.ContainsAny(id, ":,") {
+			return nil, fmt.Errorf("encryption key id '%s' is not valid", id)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("error '%s' when loading encryption key '%s'", err, id)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		k.aeads[id] = aead
+	}
+	return k, nil
+}
+
+// KeyringFrom loads the keys listed in the ENCRYPTION_KEYS setting as id:base64
+// pairs, and the ones found in the ENCRYPTION_KEYS_DIR directory, where each
+// file is named after the ID of the base64 key it holds. It returns a nil
+// keyring when no key is configured.
+func KeyringFrom(config Config) (*Keyring, error) {
+	keys := make(map[string][]byte)
+
+	if inline := config.String("ENCRYPTION_KEYS", ""); inline != "" {
+		for _, pair := range strings.Split(inline, ",") {
+			id, encoded, ok := strings.Cut(strings.TrimSpace(pair), ":")
+			if !ok {
+				return nil, fmt.Errorf("%sENCRYPTION_KEYS entries must look like id:base64key", config.Prefix)
+			}
+			if err := addKey(keys, id, encoded); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if dir := config.String("ENCRYPTION_KEYS_DIR", ""); dir != "" {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("error '%s' when reading encryption keys from '%s'", err, dir)
+		}
+		for _, entry := range entries {
+			// Mounted secrets come with hidden entries next to the key files.
+			if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			encoded, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			if err := addKey(keys, entry.Name(), string(encoded)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	active := config.String("ENCRYPTION_ACTIVE_KEY", "")
+	if active == "" && len(keys) == 1 {
+		for id := range keys {
+			active = id
+		}
+	}
+	return NewKeyring(active, keys)
+}
+
+func addKey(keys map[string][]byte, id, encoded string) error {
+	if _, ok := keys[id]; ok {
+		return fmt.Errorf("encryption key '%s' configured twice", id)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return fmt.Errorf("error '%s' when decoding encryption key '%s'", err, id)
+	}
+	keys[id] = key
+	return nil
+}
+
+// encrypt seals val, the session stored at key, with the active key. The key ID
+// and the session key are authenticated along with the value, so a ciphertext
+// can neither be passed off as sealed by another key nor moved to another
+// session.
+func (k *Keyring) encrypt(key, val string) (string, error) {
+	aead := k.aeads[k.active]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	encrypted, err := json.Marshal(encryptedEnvelope{&encryptedPayload{
+		Key:  k.active,
+		Data: aead.Seal(nonce, nonce, []byte(val), additionalData(k.active, key)),
+	}})
+	if err != nil {
+		return "", err
+	}
+	return string(encrypted), nil
+}
+
+// decrypt opens val, a value sealed by encrypt for the session stored at key,
+// returning the ID of the key it was sealed with. Values stored before
+// encryption was enabled are returned as they are, with an empty key ID as they
+// should be sealed.
+func (k *Keyring) decrypt(key, val string) (string, string, error) {
+	payload, ok := encryptedValue(val)
+	if !ok {
+		return val, "", nil
+	}
+
+	aead, ok := k.aeads[payload.Key]
+	if !ok {
+		return "", payload.Key, fmt.Errorf("session encrypted with unknown key '%s'", payload.Key)
+	}
+	if len(payload.Data) < aead.NonceSize() {
+		return "", payload.Key, fmt.Errorf("encrypted session is too short")
+	}
+
+	nonce, sealed := payload.Data[:aead.NonceSize()], payload.Data[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, sealed, additionalData(payload.Key, key))
+	if err != nil {
+		return "", payload.Key, fmt.Errorf("error '%s' when decrypting session with key '%s'", err, payload.Key)
+	}
+	return string(plain), payload.Key, nil
+}
+
+// additionalData returns what is authenticated along with the session stored
+// at key sealed with keyID. Key IDs cannot contain ':'.
+func additionalData(keyID, key string) []byte {
+	return []byte(keyID + ":" + key)
+}
+
+func encryptedValue(val string) (*encryptedPayload, bool) {
+	if !strings.Contains(val, encryptedField) {
+		return nil, false
+	}
+
+	var envelope encryptedEnvelope
+	if err := json.Unmarshal([]byte(val), &envelope); err != nil || envelope.Encrypted == nil {
+		return nil, false
+	}
+	return envelope.Encrypted, true
+}
@@ -0,0 +1,276 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+)
+
+// memcacheMaxRelativeExpiration is the longest expiration memcached accepts as
+// a number of seconds, longer ones must be given as a unix timestamp.
+const memcacheMaxRelativeExpiration = 30 * 24 * time.Hour
+
+// memcacheMaxKeyLength is the longest key memcached accepts.
+const memcacheMaxKeyLength = 250
+
+type memcacheRepository struct {
+	client  *memcache.Client
+	expires time.Duration
+}
+
+func init() {
+	Register("memcached", memcachedBackend)
+}
+
+func memcachedBackend(config Config) (Store, error) {
+	addr := fmt.Sprintf("%s:%s", config.String("HOST", "localhost"), config.String("PORT", "11211"))
+	return newMemcacheRepository(config.List("ADDRS", addr), config.Expires)
+}
+
+// NewMemcacheRepository returns a session repository spreading sessions over
+// the given memcached servers with consistent hashing.
+func NewMemcacheRepository(servers []string, expires time.Duration) Store {
+	r, err := newMemcacheRepository(servers, expires)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func newMemcacheRepository(servers []string, expires time.Duration) (*memcacheRepository, error) {
+	selector, err := newMemcacheSelector(servers)
+	if err != nil {
+		return nil, err
+	}
+	return &memcacheRepository{memcache.NewFromSelector(selector), expires}, nil
+}
+
+func (r *memcacheRepository) Set(ctx context.Context, key string, value interface{}) error {
+	return r.SetWithTTL(ctx, key, value, r.expires)
+}
+
+func (r *memcacheRepository) SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+
+	if err := checkMemcacheKey(key); err != nil {
+		return err
+	}
+
+	val, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
+
+	return r.client.Set(&memcache.Item{
+		Key:        key,
+		Value:      []byte(val),
+		Expiration: memcacheExpiration(ttl, time.Now()),
+	})
+}
+
+func (r *memcacheRepository) Get(ctx context.Context, key string) (interface{}, error) {
+
+	if err := checkMemcacheKey(key); err != nil {
+		return "", err
+	}
+
+	item, err := r.client.Get(key)
+	if err == memcache.ErrCacheMiss {
+		return "", session.ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(item.Value), nil
+}
+
+func (r *memcacheRepository) Delete(ctx context.Context, key string) (int64, error) {
+
+	if err := checkMemcacheKey(key); err != nil {
+		return 0, err
+	}
+
+	err := r.client.Delete(key)
+	if err == memcache.ErrCacheMiss {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func (r *memcacheRepository) Expire(ctx context.Context, key string, ttl time.Duration) error {
+
+	if err := checkMemcacheKey(key); err != nil {
+		return err
+	}
+
+	err := r.client.Touch(key, memcacheExpiration(ttl, time.Now()))
+	if err == memcache.ErrCacheMiss {
+		return session.ErrNotFound
+	}
+	return err
+}
+
+func (r *memcacheRepository) SetIfAbsent(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+
+	if err := checkMemcacheKey(key); err != nil {
+		return false, err
+	}
+
+	val, err := encodeValue(value)
+	if err != nil {
+		return false, err
+	}
+	if ttl == 0 {
+		ttl = r.expires
+	}
+
+	err = r.client.Add(&memcache.Item{
+		Key:        key,
+		Value:      []byte(val),
+		Expiration: memcacheExpiration(ttl, time.Now()),
+	})
+	if err == memcache.ErrNotStored {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Replace reads the session with its CAS token, so it is only replaced when no
+// one wrote it in between.
+func (r *memcacheRepository) Replace(ctx context.Context, key string, old, new interface{}, ttl time.Duration) (bool, error) {
+
+	if err := checkMemcacheKey(key); err != nil {
+		return false, err
+	}
+
+	oldVal, err := encodeValue(old)
+	if err != nil {
+		return false, err
+	}
+	newVal, err := encodeValue(new)
+	if err != nil {
+		return false, err
+	}
+	if ttl == 0 {
+		ttl = r.expires
+	}
+
+	item, err := r.client.Get(key)
+	if err == memcache.ErrCacheMiss {
+		return false, nil
+	}
+	if err != nil || string(item.Value) != oldVal {
+		return false, err
+	}
+
+	item.Value = []byte(newVal)
+	item.Expiration = memcacheExpiration(ttl, time.Now())
+	err = r.client.CompareAndSwap(item)
+	if err == memcache.ErrCASConflict || err == memcache.ErrNotStored || err == memcache.ErrCacheMiss {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// DeleteIf expires the session with a compare and swap, as memcached has no
+// conditional delete.
+func (r *memcacheRepository) DeleteIf(ctx context.Context, key string, old interface{}) (bool, error) {
+
+	if err := checkMemcacheKey(key); err != nil {
+		return false, err
+	}
+
+	oldVal, err := encodeValue(old)
+	if err != nil {
+		return false, err
+	}
+
+	item, err := r.client.Get(key)
+	if err == memcache.ErrCacheMiss {
+		return false, nil
+	}
+	if err != nil || string(item.Value) != oldVal {
+		return false, err
+	}
+
+	// memcached expires items stored with a negative expiration right away.
+	item.Expiration = -1
+	err = r.client.CompareAndSwap(item)
+	if err == memcache.ErrCASConflict || err == memcache.ErrNotStored || err == memcache.ErrCacheMiss {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// checkMemcacheKey rejects the keys memcached cannot store, which the client
+// would otherwise fail on as any other error.
+func checkMemcacheKey(key string) error {
+	if len(key) > memcacheMaxKeyLength {
+		return fmt.Errorf("%w: key cannot be longer than %d bytes for memcached", session.ErrInvalid, memcacheMaxKeyLength)
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] == 0x7f {
+			return fmt.Errorf("%w: key cannot contain spaces or control characters for memcached", session.ErrInvalid)
+		}
+	}
+	return nil
+}
+
+// memcacheExpiration returns the expiration memcached is given for expires,
+// rounded up to whole seconds, as zero stands for never expiring.
+func memcacheExpiration(expires time.Duration, now time.Time) int32 {
+	if expires > memcacheMaxRelativeExpiration {
+		return int32(now.Add(expires).Unix())
+	}
+	if expires <= 0 {
+		return 0
+	}
+	return int32((expires + time.Second - 1) / time.Second)
+}
+
+// memcacheSelector picks the memcached server owning a key from a consistent
+// hash ring, so adding or removing a server only remaps a fraction of the keys.
+type memcacheSelector struct {
+	ring  *hashRing
+	addrs map[string]net.Addr
+}
+
+func newMemcacheSelector(servers []string) (*memcacheSelector, error) {
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("no memcached servers configured")
+	}
+
+	s := &memcacheSelector{
+		ring:  newHashRing(defaultRingReplicas),
+		addrs: make(map[string]net.Addr, len(servers)),
+	}
+	for _, server := range servers {
+		addr, err := net.ResolveTCPAddr("tcp", server)
+		if err != nil {
+			return nil, fmt.Errorf("error '%s' when resolving memcached server '%s'", err, server)
+		}
+		s.addrs[server] = addr
+		s.ring.add(server)
+	}
+
+	return s, nil
+}
+
+func (s *memcacheSelector) PickServer(key string) (net.Addr, error) {
+	return s.addrs[s.ring.get(key)], nil
+}
+
+func (s *memcacheSelector) Each(f func(net.Addr) error) error {
+	for _, addr := range s.addrs {
+		if err := f(addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package adapters
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeMemcached is an in-process stand-in for memcached which understands the
+// subset of the text protocol used by the repository.
+type fakeMemcached struct {
+	listener net.Listener
+	mu       sync.Mutex
+	items    map[string]fakeMemcachedItem
+}
+
+type fakeMemcachedItem struct {
+	flags     string
+	value     []byte
+	expiresAt time.Time
+}
+
+func startFakeMemcached(t *testing.T) *fakeMemcached {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("cannot start fake memcached %s", err)
+	}
+
+	m := &fakeMemcached{listener: listener, items: make(map[string]fakeMemcachedItem)}
+	go m.serve()
+	t.Cleanup(func() { listener.Close() })
+	return m
+}
+
+func (m *fakeMemcached) addr() string {
+	return m.listener.Addr().String()
+}
+
+func (m *fakeMemcached) len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.items)
+}
+
+func (m *fakeMemcached) serve() {
+	for {
+		conn, err := m.listener.Accept()
+		if err != nil {
+			return
+		}
+		go m.handle(conn)
+	}
+}
+
+func (m *fakeMemcached) handle(conn net.Conn) {
+	defer conn.Close()
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+
+	for {
+		line, err := rw.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "set":
+			size, _ := strconv.Atoi(fields[4])
+			data := make([]byte, size+2)
+			if _, err := io.ReadFull(rw, data); err != nil {
+				return
+			}
+			m.set(fields[1], fields[2], fields[3], data[:size])
+			rw.WriteString("STORED\r\n")
+		case "get", "gets":
+			for _, key := range fields[1:] {
+				if item, ok := m.get(key); ok {
+					fmt.Fprintf(rw, "VALUE %s %s %d 0\r\n%s\r\n", key, item.flags, len(item.value), item.value)
+				}
+			}
+			rw.WriteString("END\r\n")
+		case "delete":
+			if m.delete(fields[1]) {
+				rw.WriteString("DELETED\r\n")
+			} else {
+				rw.WriteString("NOT_FOUND\r\n")
+			}
+		default:
+			rw.WriteString("ERROR\r\n")
+		}
+		rw.Flush()
+	}
+}
+
+func (m *fakeMemcached) set(key, flags, exptime string, value []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seconds, _ := strconv.ParseInt(exptime, 10, 64)
+	expiresAt := time.Now().Add(time.Duration(seconds) * time.Second)
+	if seconds > int64(memcacheMaxRelativeExpiration/time.Second) {
+		expiresAt = time.Unix(seconds, 0)
+	}
+	m.items[key] = fakeMemcachedItem{flags, value, expiresAt}
+}
+
+func (m *fakeMemcached) get(key string) (fakeMemcachedItem, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.items[key]
+	if !ok || !time.Now().Before(item.expiresAt) {
+		return item, false
+	}
+	return item, true
+}
+
+func (m *fakeMemcached) delete(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.items[key]
+	delete(m.items, key)
+	return ok
+}
+
+func TestShouldStoreDataInMemcached(t *testing.T) {
+	t.Parallel()
+
+	server := startFakeMemcached(t)
+	repo := NewMemcacheRepository([]string{server.addr()}, time.Minute)
+
+	sessionValue := `{"someSessionTest":"Value"}`
+	err := repo.Set(ctx, "someSessionTestKey", sessionValue)
+	assert.Nil(t, err, "Expect err is nil when storing session value")
+
+	val, err := repo.Get(ctx, "someSessionTestKey")
+	assert.Nil(t, err, "Expect err is nil when retrieving session value")
+	assert.True(t, val == sessionValue, "Expect session to be stored in memcached")
+}
+
+func TestShouldNotRetrieveDataFromMemcachedIfDataDoesntExist(t *testing.T) {
+	t.Parallel()
+
+	server := startFakeMemcached(t)
+	repo := NewMemcacheRepository([]string{server.addr()}, time.Minute)
+
+	val, err := repo.Get(ctx, "thisSessionKeyShouldNotExist")
+
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect not found error for non existing session")
+	assert.True(t, val == "", "Expect session data not to be in memcached")
+}
+
+func TestShouldDeleteDataFromMemcached(t *testing.T) {
+	t.Parallel()
+
+	server := startFakeMemcached(t)
+	repo := NewMemcacheRepository([]string{server.addr()}, time.Minute)
+
+	err := repo.Set(ctx, "someDeleteTestKey", `{"someDeleteTest":"Value"}`)
+	assert.Nil(t, err, "Expect err is nil when inserting session key")
+
+	deleted, err := repo.Delete(ctx, "someDeleteTestKey")
+	assert.Nil(t, err, "Expect err is nil when deleting session key")
+	assert.Equal(t, int64(1), deleted, "Expect one key to have been deleted")
+
+	deleted, err = repo.Delete(ctx, "someDeleteTestKey")
+	assert.Nil(t, err, "Expect err is nil when deleting a missing session key")
+	assert.Equal(t, int64(0), deleted, "Expect no key to have been deleted")
+
+	val, err := repo.Get(ctx, "someDeleteTestKey")
+	assert.True(t, val == "", "Expect session data to have been deleted from memcached")
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect not found error for deleted session")
+}
+
+func TestShouldSpreadDataAcrossMemcachedServers(t *testing.T) {
+	t.Parallel()
+
+	first, second := startFakeMemcached(t), startFakeMemcached(t)
+	repo := NewMemcacheRepository([]string{first.addr(), second.addr()}, time.Minute)
+
+	for i := 0; i < 100; i++ {
+		err := repo.Set(ctx, fmt.Sprintf("session-%d", i), "value")
+		assert.Nil(t, err, "Expect err is nil when storing session value")
+	}
+
+	assert.Equal(t, 100, first.len()+second.len(), "Expect every session to be stored once")
+	assert.Greater(t, first.len(), 0, "Expect first server to own some sessions")
+	assert.Greater(t, second.len(), 0, "Expect second server to own some sessions")
+}
+
+func TestShouldRejectInvalidMemcachedKeys(t *testing.T) {
+	t.Parallel()
+
+	server := startFakeMemcached(t)
+	repo := NewMemcacheRepository([]string{server.addr()}, time.Minute)
+
+	for _, test := range []struct {
+		scenario string
+		key      string
+	}{
+		{scenario: "Key longer than 250 bytes", key: strings.Repeat("k", 251)},
+		{scenario: "Key with spaces", key: "some key"},
+		{scenario: "Key with control characters", key: "some\nkey"},
+	} {
+		err := repo.Set(ctx, test.key, "value")
+		assert.ErrorIs(t, err, session.ErrInvalid, "%s: Expect invalid error when storing session", test.scenario)
+
+		_, err = repo.Get(ctx, test.key)
+		assert.ErrorIs(t, err, session.ErrInvalid, "%s: Expect invalid error when retrieving session", test.scenario)
+
+		_, err = repo.Delete(ctx, test.key)
+		assert.ErrorIs(t, err, session.ErrInvalid, "%s: Expect invalid error when deleting session", test.scenario)
+	}
+
+	assert.Equal(t, 0, server.len(), "Expect no session to have been stored")
+}
+
+func TestMemcacheExpirationShouldUseTimestampForLongDurations(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	tests := []struct {
+		scenario   string
+		expires    time.Duration
+		expiration int32
+	}{
+		{
+			scenario:   "Should never expire without a duration",
+			expires:    0,
+			expiration: 0,
+		},
+		{
+			scenario:   "Should expire in a second rather than never under a second",
+			expires:    500 * time.Millisecond,
+			expiration: 1,
+		},
+		{
+			scenario:   "Should round durations up to whole seconds",
+			expires:    1500 * time.Millisecond,
+			expiration: 2,
+		},
+		{
+			scenario:   "Should use seconds for short durations",
+			expires:    time.Hour,
+			expiration: 3600,
+		},
+		{
+			scenario:   "Should use a timestamp for long durations",
+			expires:    365 * 24 * time.Hour,
+			expiration: int32(now.Add(365 * 24 * time.Hour).Unix()),
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expiration, memcacheExpiration(test.expires, now), test.scenario)
+	}
+}
@@ -0,0 +1,546 @@
+package adapters
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+)
+
+const defaultMemoryShards = 32
+
+type memoryCache struct {
+	expires  time.Duration
+	shards   []*memoryShard
+	subjects memorySubjects
+	stop     chan struct{}
+	once     sync.Once
+}
+
+// memorySubjects holds the index of every subject apart from the sessions, so
+// they are never evicted in their place.
+type memorySubjects struct {
+	mu      sync.Mutex
+	indexes map[string]map[string]time.Time
+}
+
+type memoryShard struct {
+	mu         sync.Mutex
+	items      map[string]*list.Element
+	lru        *list.List
+	maxEntries int
+}
+
+type memoryEntry struct {
+	key       string
+	value     string
+	expiresAt time.Time
+}
+
+func init() {
+	Register("memory", memoryBackend)
+}
+
+func memoryBackend(config Config) (Store, error) {
+	maxEntries, err := config.Int("MAX_ENTRIES", 0)
+	if err != nil {
+		return nil, err
+	}
+	if maxEntries < 0 {
+		return nil, fmt.Errorf("%sMAX_ENTRIES must not be negative, got %d", config.Prefix, maxEntries)
+	}
+
+	cleanup, err := config.Seconds("CLEANUP_INTERVAL", 60)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMemoryCache(config.Expires, maxEntries, cleanup), nil
+}
+
+// NewMemoryCache returns an in-process session repository. Entries expire after
+// the given duration, or never when it is zero, and are evicted by a background
+// janitor running every cleanupInterval. When maxEntries is greater than zero
+// the least recently used entries are dropped once the bound is reached.
+func NewMemoryCache(expires time.Duration, maxEntries int, cleanupInterval time.Duration) Store {
+	return newMemoryCache(defaultMemoryShards, expires, maxEntries, cleanupInterval)
+}
+
+func newMemoryCache(shards int, expires time.Duration, maxEntries int, cleanupInterval time.Duration) *memoryCache {
+	perShard := 0
+	if maxEntries > 0 {
+		perShard = (maxEntries + shards - 1) / shards
+	}
+
+	c := &memoryCache{
+		expires:  expires,
+		shards:   make([]*memoryShard, shards),
+		subjects: memorySubjects{indexes: make(map[string]map[string]time.Time)},
+		stop:     make(chan struct{}),
+	}
+	for i := range c.shards {
+		c.shards[i] = &memoryShard{
+			items:      make(map[string]*list.Element),
+			lru:        list.New(),
+			maxEntries: perShard,
+		}
+	}
+
+	if cleanupInterval > 0 {
+		go c.janitor(cleanupInterval)
+	}
+
+	return c
+}
+
+func (c *memoryCache) Set(ctx context.Context, key string, value interface{}) error {
+	return c.SetWithTTL(ctx, key, value, c.expires)
+}
+
+// SetWithTTL stores value under key for ttl instead of the default expiry, or
+// for good when ttl is zero.
+func (c *memoryCache) SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+
+	val, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
+
+	c.shard(key).set(key, val, expiresAfter(time.Now(), ttl))
+	return nil
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string) (interface{}, error) {
+
+	val, ok := c.shard(key).get(key, time.Now())
+	if !ok {
+		return "", session.ErrNotFound
+	}
+	return val, nil
+}
+
+func (c *memoryCache) Delete(ctx context.Context, key string) (int64, error) {
+
+	if c.shard(key).delete(key) {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (c *memoryCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+
+	now := time.Now()
+	expiresAt, ok := c.shard(key).expiry(key, now)
+	if !ok {
+		return 0, session.ErrNotFound
+	}
+	if expiresAt.IsZero() {
+		return -1, nil
+	}
+	return expiresAt.Sub(now), nil
+}
+
+func (c *memoryCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+
+	now := time.Now()
+	if !c.shard(key).expire(key, now, now.Add(ttl)) {
+		return session.ErrNotFound
+	}
+	return nil
+}
+
+func (c *memoryCache) SetIfAbsent(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+
+	val, err := encodeValue(value)
+	if err != nil {
+		return false, err
+	}
+
+	if ttl == 0 {
+		ttl = c.expires
+	}
+
+	now := time.Now()
+	return c.shard(key).setIfAbsent(key, val, now, expiresAfter(now, ttl)), nil
+}
+
+func (c *memoryCache) Scan(ctx context.Context, onKey func(key string) error) error {
+
+	for _, s := range c.shards {
+		for _, key := range s.keys(time.Now()) {
+			if err := onKey(key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *memoryCache) GetFields(ctx context.Context, key string, fields []string) (map[string]interface{}, error) {
+
+	val, ok := c.shard(key).get(key, time.Now())
+	if !ok {
+		return nil, session.ErrNotFound
+	}
+	return pickFields(val, fields)
+}
+
+func (c *memoryCache) SetFields(ctx context.Context, key string, values map[string]interface{}) error {
+
+	return c.shard(key).update(key, time.Now(), func(val string) (string, error) {
+		return mergeFields(val, values)
+	})
+}
+
+func (c *memoryCache) Replace(ctx context.Context, key string, old, new interface{}, ttl time.Duration) (bool, error) {
+
+	oldVal, err := encodeValue(old)
+	if err != nil {
+		return false, err
+	}
+	newVal, err := encodeValue(new)
+	if err != nil {
+		return false, err
+	}
+
+	if ttl == 0 {
+		ttl = c.expires
+	}
+
+	now := time.Now()
+	return c.shard(key).replace(key, oldVal, newVal, now, expiresAfter(now, ttl)), nil
+}
+
+// Increment holds the shard locked while it increments the counter.
+func (c *memoryCache) Increment(ctx context.Context, key string, path session.Pointer, delta int64, updatedAt time.Time) (int64, error) {
+
+	var counter int64
+	err := c.shard(key).update(key, time.Now(), func(val string) (string, error) {
+		incremented, value, err := incrementCounter(val, path, delta, updatedAt)
+		counter = value
+		return incremented, err
+	})
+	return counter, err
+}
+
+func (c *memoryCache) DeleteIf(ctx context.Context, key string, old interface{}) (bool, error) {
+
+	oldVal, err := encodeValue(old)
+	if err != nil {
+		return false, err
+	}
+	return c.shard(key).deleteIf(key, oldVal, time.Now()), nil
+}
+
+func (c *memoryCache) IndexSession(ctx context.Context, subject, id string, expiresAt time.Time) error {
+
+	c.subjects.mu.Lock()
+	defer c.subjects.mu.Unlock()
+
+	index, ok := c.subjects.indexes[subject]
+	if !ok {
+		index = make(map[string]time.Time)
+		c.subjects.indexes[subject] = index
+	}
+	index[id] = expiresAt
+	return nil
+}
+
+func (c *memoryCache) UnindexSessions(ctx context.Context, subject string, ids []string) error {
+
+	c.subjects.mu.Lock()
+	defer c.subjects.mu.Unlock()
+
+	c.subjects.unindex(subject, ids)
+	return nil
+}
+
+func (c *memoryCache) IndexedSessions(ctx context.Context, subject string) (map[string]time.Time, error) {
+
+	c.subjects.mu.Lock()
+	defer c.subjects.mu.Unlock()
+
+	index := make(map[string]time.Time, len(c.subjects.indexes[subject]))
+	for id, expiresAt := range c.subjects.indexes[subject] {
+		index[id] = expiresAt
+	}
+	return index, nil
+}
+
+func (c *memoryCache) ScanSubjects(ctx context.Context, onSubject func(subject string) error) error {
+
+	c.subjects.mu.Lock()
+	subjects := make([]string, 0, len(c.subjects.indexes))
+	for subject := range c.subjects.indexes {
+		subjects = append(subjects, subject)
+	}
+	c.subjects.mu.Unlock()
+
+	for _, subject := range subjects {
+		if err := onSubject(subject); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops the background janitor.
+func (c *memoryCache) Close() {
+	c.once.Do(func() { close(c.stop) })
+}
+
+func (c *memoryCache) shard(key string) *memoryShard {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return c.shards[h.Sum32()%uint32(len(c.shards))]
+}
+
+func (c *memoryCache) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			now := time.Now()
+			for _, s := range c.shards {
+				s.deleteExpired(now)
+			}
+			c.deleteExpiredIndexes(now)
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// deleteExpiredIndexes drops from the indexes of subjects the sessions due to
+// expire before now that are gone.
+func (c *memoryCache) deleteExpiredIndexes(now time.Time) {
+	c.subjects.mu.Lock()
+	defer c.subjects.mu.Unlock()
+
+	for subject, index := range c.subjects.indexes {
+		var expired []string
+		for id, expiresAt := range index {
+			if expiresAt.IsZero() || now.Before(expiresAt) {
+				continue
+			}
+			if _, ok := c.shard(id).expiry(id, now); !ok {
+				expired = append(expired, id)
+			}
+		}
+		c.subjects.unindex(subject, expired)
+	}
+}
+
+// unindex drops ids from the index of subject, and the index once it is left
+// empty. The caller must hold the lock.
+func (s *memorySubjects) unindex(subject string, ids []string) {
+	index, ok := s.indexes[subject]
+	if !ok {
+		return
+	}
+	for _, id := range ids {
+		delete(index, id)
+	}
+	if len(index) == 0 {
+		delete(s.indexes, subject)
+	}
+}
+
+// expiresAfter returns when an entry stored at now for ttl expires, zero when
+// ttl is zero and it never does.
+func expiresAfter(now time.Time, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(ttl)
+}
+
+// live tells whether the entry has not expired by now.
+func (e *memoryEntry) live(now time.Time) bool {
+	return e.expiresAt.IsZero() || now.Before(e.expiresAt)
+}
+
+func (s *memoryShard) set(key, value string, expiresAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.store(key, value, expiresAt)
+}
+
+func (s *memoryShard) setIfAbsent(key, value string, now, expiresAt time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[key]; ok && el.Value.(*memoryEntry).live(now) {
+		return false
+	}
+
+	s.store(key, value, expiresAt)
+	return true
+}
+
+// replace stores a live entry holding old again with value and expiresAt.
+func (s *memoryShard) replace(key, old, value string, now, expiresAt time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[key]
+	if !ok || !el.Value.(*memoryEntry).live(now) || el.Value.(*memoryEntry).value != old {
+		return false
+	}
+
+	s.store(key, value, expiresAt)
+	return true
+}
+
+// store inserts or replaces an entry, the caller must hold the shard lock.
+func (s *memoryShard) store(key, value string, expiresAt time.Time) {
+	if el, ok := s.items[key]; ok {
+		entry := el.Value.(*memoryEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		s.lru.MoveToFront(el)
+		return
+	}
+
+	s.items[key] = s.lru.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+
+	if s.maxEntries > 0 {
+		for s.lru.Len() > s.maxEntries {
+			s.removeElement(s.lru.Back())
+		}
+	}
+}
+
+func (s *memoryShard) get(key string, now time.Time) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[key]
+	if !ok {
+		return "", false
+	}
+
+	entry := el.Value.(*memoryEntry)
+	if !entry.live(now) {
+		s.removeElement(el)
+		return "", false
+	}
+
+	s.lru.MoveToFront(el)
+	return entry.value, true
+}
+
+// update replaces the value of a live entry with the result of fn, keeping its
+// expiry, while holding the shard lock.
+func (s *memoryShard) update(key string, now time.Time, fn func(val string) (string, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[key]
+	if !ok || !el.Value.(*memoryEntry).live(now) {
+		return session.ErrNotFound
+	}
+
+	entry := el.Value.(*memoryEntry)
+	val, err := fn(entry.value)
+	if err != nil {
+		return err
+	}
+
+	entry.value = val
+	s.lru.MoveToFront(el)
+	return nil
+}
+
+func (s *memoryShard) expiry(key string, now time.Time) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[key]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	entry := el.Value.(*memoryEntry)
+	return entry.expiresAt, entry.live(now)
+}
+
+// expire sets when a live entry expires.
+func (s *memoryShard) expire(key string, now, expiresAt time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[key]
+	if !ok || !el.Value.(*memoryEntry).live(now) {
+		return false
+	}
+
+	el.Value.(*memoryEntry).expiresAt = expiresAt
+	return true
+}
+
+// deleteIf removes the entry at key while it holds a value not expired yet.
+func (s *memoryShard) deleteIf(key, old string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[key]
+	if !ok || !el.Value.(*memoryEntry).live(now) || el.Value.(*memoryEntry).value != old {
+		return false
+	}
+
+	s.removeElement(el)
+	return true
+}
+
+func (s *memoryShard) delete(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[key]
+	if !ok {
+		return false
+	}
+
+	expired := !el.Value.(*memoryEntry).live(time.Now())
+	s.removeElement(el)
+	return !expired
+}
+
+func (s *memoryShard) keys(now time.Time) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(s.items))
+	for key, el := range s.items {
+		if el.Value.(*memoryEntry).live(now) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (s *memoryShard) deleteExpired(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for el := s.lru.Back(); el != nil; {
+		prev := el.Prev()
+		if !el.Value.(*memoryEntry).live(now) {
+			s.removeElement(el)
+		}
+		el = prev
+	}
+}
+
+func (s *memoryShard) removeElement(el *list.Element) {
+	s.lru.Remove(el)
+	delete(s.items, el.Value.(*memoryEntry).key)
+}
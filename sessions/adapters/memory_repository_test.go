@@ -0,0 +1,266 @@
+package adapters
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldStoreDataInMemory(t *testing.T) {
+	t.Parallel()
+
+	memory := newMemoryCache(defaultMemoryShards, time.Minute, 0, 0)
+	defer memory.Close()
+
+	sessionKey := "someSessionTestKey"
+	sessionValue := `{"someSessionTest":"Value"}`
+	err := memory.Set(ctx, sessionKey, sessionValue)
+	if err != nil {
+		t.Errorf("got error when storing session value in memory %s\n", err)
+	}
+
+	val, err := memory.Get(ctx, sessionKey)
+	if err != nil {
+		t.Errorf("got error when retrieving session value from memory %s\n", err)
+	}
+
+	assert.True(t, val == sessionValue, "Expect session to be stored in memory")
+}
+
+func TestShouldNotRetrieveDataFromMemoryIfDataDoesntExist(t *testing.T) {
+	t.Parallel()
+
+	memory := newMemoryCache(defaultMemoryShards, time.Minute, 0, 0)
+	defer memory.Close()
+
+	val, err := memory.Get(ctx, "thisSessionKeyShouldNotExist")
+
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect not found error for non existing session")
+	assert.True(t, val == "", "Expect session data not to be in memory")
+}
+
+func TestShouldDeleteDataFromMemory(t *testing.T) {
+	t.Parallel()
+
+	memory := newMemoryCache(defaultMemoryShards, time.Minute, 0, 0)
+	defer memory.Close()
+
+	sessionKey := "someDeleteTestKey"
+	err := memory.Set(ctx, sessionKey, `{"someDeleteTest":"Value"}`)
+	assert.Nil(t, err, "Expect err is nil when inserting session key")
+
+	deleted, err := memory.Delete(ctx, sessionKey)
+	assert.Nil(t, err, "Expect err is nil when deleting session key")
+	assert.Equal(t, int64(1), deleted, "Expect one key to have been deleted")
+
+	val, err := memory.Get(ctx, sessionKey)
+	assert.True(t, val == "", "Expect session data to have been deleted from memory")
+	assert.NotNil(t, err, "Expect error for deleted session")
+}
+
+func TestShouldExpireDataInMemory(t *testing.T) {
+	t.Parallel()
+
+	memory := newMemoryCache(defaultMemoryShards, 10*time.Millisecond, 0, 0)
+	defer memory.Close()
+
+	err := memory.Set(ctx, "someExpiringKey", `{"some":"Value"}`)
+	assert.Nil(t, err, "Expect err is nil when inserting session key")
+
+	time.Sleep(20 * time.Millisecond)
+
+	_, err = memory.Get(ctx, "someExpiringKey")
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect expired session not to be returned")
+}
+
+func TestShouldKeepDataWithoutExpiryInMemory(t *testing.T) {
+	t.Parallel()
+
+	memory := newMemoryCache(defaultMemoryShards, 0, 0, 0)
+	defer memory.Close()
+
+	err := memory.Set(ctx, "somePersistentKey", `{"some":"Value"}`)
+	assert.Nil(t, err, "Expect err is nil when inserting session key")
+	err = memory.SetWithTTL(ctx, "otherPersistentKey", `{"some":"Value"}`, 0)
+	assert.Nil(t, err, "Expect err is nil when inserting session key")
+	stored, err := memory.SetIfAbsent(ctx, "absentPersistentKey", `{"some":"Value"}`, 0)
+	assert.Nil(t, err, "Expect err is nil when inserting session key")
+	assert.True(t, stored, "Expect session to be stored")
+
+	for _, s := range memory.shards {
+		s.deleteExpired(time.Now().Add(time.Hour))
+	}
+	for _, key := range []string{"somePersistentKey", "otherPersistentKey", "absentPersistentKey"} {
+		_, err = memory.Get(ctx, key)
+		assert.Nil(t, err, "Expect session stored without expiry to be returned")
+
+		ttl, err := memory.TTL(ctx, key)
+		assert.Nil(t, err, "Expect err is nil when reading the ttl")
+		assert.Less(t, ttl, time.Duration(0), "Expect session stored without expiry to never expire")
+	}
+
+	replaced, err := memory.Replace(ctx, "somePersistentKey", `{"some":"Value"}`, `{"some":"Other"}`, 0)
+	assert.Nil(t, err, "Expect err is nil when replacing session value")
+	assert.True(t, replaced, "Expect a session without expiry to be replaced")
+}
+
+func TestShouldChangeExpiryOfDataInMemory(t *testing.T) {
+	t.Parallel()
+
+	memory := newMemoryCache(defaultMemoryShards, 10*time.Millisecond, 0, 0)
+	defer memory.Close()
+
+	err := memory.Set(ctx, "someExpiringKey", `{"some":"Value"}`)
+	assert.Nil(t, err, "Expect err is nil when inserting session key")
+
+	err = memory.Expire(ctx, "someExpiringKey", time.Minute)
+	assert.Nil(t, err, "Expect err is nil when changing the expiry of a session")
+
+	time.Sleep(20 * time.Millisecond)
+
+	val, err := memory.Get(ctx, "someExpiringKey")
+	assert.Nil(t, err, "Expect session to outlive its original expiry")
+	assert.Equal(t, `{"some":"Value"}`, val)
+
+	err = memory.Expire(ctx, "thisSessionKeyShouldNotExist", time.Minute)
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect not found error for non existing session")
+}
+
+func TestJanitorShouldEvictExpiredData(t *testing.T) {
+	t.Parallel()
+
+	memory := newMemoryCache(1, 10*time.Millisecond, 0, 5*time.Millisecond)
+	defer memory.Close()
+
+	memory.Set(ctx, "someExpiringKey", `{"some":"Value"}`)
+
+	assert.Eventually(t, func() bool {
+		shard := memory.shards[0]
+		shard.mu.Lock()
+		defer shard.mu.Unlock()
+		return len(shard.items) == 0
+	}, time.Second, 5*time.Millisecond, "Expect janitor to evict expired entries")
+}
+
+func TestShouldEvictLeastRecentlyUsedDataWhenFull(t *testing.T) {
+	t.Parallel()
+
+	memory := newMemoryCache(1, time.Minute, 2, 0)
+	defer memory.Close()
+
+	memory.Set(ctx, "first", "1")
+	memory.Set(ctx, "second", "2")
+	memory.Get(ctx, "first")
+	memory.Set(ctx, "third", "3")
+
+	_, err := memory.Get(ctx, "second")
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect least recently used key to be evicted")
+
+	for _, key := range []string{"first", "third"} {
+		_, err := memory.Get(ctx, key)
+		assert.Nil(t, err, fmt.Sprintf("Expect key '%s' to be kept", key))
+	}
+}
+
+func TestShouldHandleConcurrentAccessInMemory(t *testing.T) {
+	t.Parallel()
+
+	memory := newMemoryCache(defaultMemoryShards, time.Minute, 0, 0)
+	defer memory.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			// Every goroutine owns a key, and shares another with four others.
+			own, shared := fmt.Sprintf("own-%d", i), fmt.Sprintf("shared-%d", i%10)
+			assert.Nil(t, memory.Set(ctx, own, fmt.Sprint(i)), "Expect err is nil when storing an own key")
+			assert.Nil(t, memory.Set(ctx, shared, fmt.Sprint(i)), "Expect err is nil when storing a shared key")
+
+			val, err := memory.Get(ctx, own)
+			assert.Nil(t, err, "Expect err is nil when reading an own key")
+			assert.Equal(t, fmt.Sprint(i), val, "Expect own key not to be changed by others")
+
+			val, err = memory.Get(ctx, shared)
+			if err != nil {
+				assert.ErrorIs(t, err, session.ErrNotFound, "Expect a shared key to be found or deleted")
+			} else {
+				var writer int
+				_, scanErr := fmt.Sscan(val.(string), &writer)
+				assert.Nil(t, scanErr, "Expect a shared key to hold a value as stored")
+				assert.Equal(t, i%10, writer%10, "Expect a shared key to hold a value one of its writers stored")
+			}
+
+			_, err = memory.Delete(ctx, shared)
+			assert.Nil(t, err, "Expect err is nil when deleting a shared key")
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 50; i++ {
+		val, err := memory.Get(ctx, fmt.Sprintf("own-%d", i))
+		assert.Nil(t, err, "Expect every own key to be kept")
+		assert.Equal(t, fmt.Sprint(i), val)
+	}
+	for i := 0; i < 10; i++ {
+		_, err := memory.Get(ctx, fmt.Sprintf("shared-%d", i))
+		assert.ErrorIs(t, err, session.ErrNotFound, "Expect every shared key to end deleted")
+	}
+}
+
+func TestShouldReplaceInMemory(t *testing.T) {
+	t.Parallel()
+
+	memory := newMemoryCache(defaultMemoryShards, time.Minute, 0, 0)
+	defer memory.Close()
+
+	memory.SetWithTTL(ctx, "someReplaceKey", `{"data":"old"}`, 30*time.Second)
+
+	replaced, err := memory.Replace(ctx, "someReplaceKey", `{"data":"other"}`, `{"data":"new"}`, time.Hour)
+	assert.Nil(t, err, "Expect err is nil when replacing")
+	assert.False(t, replaced, "Expect session not to be replaced when it holds another value")
+
+	replaced, err = memory.Replace(ctx, "someReplaceKey", `{"data":"old"}`, `{"data":"new"}`, time.Hour)
+	assert.Nil(t, err, "Expect err is nil when replacing")
+	assert.True(t, replaced, "Expect session to be replaced when it holds the old value")
+
+	val, _ := memory.Get(ctx, "someReplaceKey")
+	assert.Equal(t, `{"data":"new"}`, val)
+
+	ttl, _ := memory.TTL(ctx, "someReplaceKey")
+	assert.Greater(t, ttl, time.Minute, "Expect session to be stored for the given ttl")
+
+	replaced, err = memory.Replace(ctx, "thisSessionKeyShouldNotExist", `{"data":"old"}`, `{"data":"new"}`, 0)
+	assert.Nil(t, err, "Expect err is nil when replacing a missing session")
+	assert.False(t, replaced, "Expect missing session not to be replaced")
+}
+
+func TestShouldDeleteIfInMemory(t *testing.T) {
+	t.Parallel()
+
+	memory := newMemoryCache(defaultMemoryShards, time.Minute, 0, 0)
+	defer memory.Close()
+
+	memory.SetWithTTL(ctx, "someDeleteKey", `{"data":"old"}`, 30*time.Second)
+
+	deleted, err := memory.DeleteIf(ctx, "someDeleteKey", `{"data":"other"}`)
+	assert.Nil(t, err, "Expect err is nil when deleting")
+	assert.False(t, deleted, "Expect session not to be deleted when it holds another value")
+
+	deleted, err = memory.DeleteIf(ctx, "someDeleteKey", `{"data":"old"}`)
+	assert.Nil(t, err, "Expect err is nil when deleting")
+	assert.True(t, deleted, "Expect session to be deleted when it holds the old value")
+
+	_, err = memory.Get(ctx, "someDeleteKey")
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect deleted session not to be found")
+
+	deleted, err = memory.DeleteIf(ctx, "someDeleteKey", `{"data":"old"}`)
+	assert.Nil(t, err, "Expect err is nil when deleting a missing session")
+	assert.False(t, deleted, "Expect missing session not to be deleted")
+}
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS sessions (
+    key        text PRIMARY KEY,
+    value      jsonb NOT NULL,
+    expires_at timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS sessions_expires_at_idx ON sessions (expires_at);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS session_subjects (
+    subject    text NOT NULL,
+    key        text NOT NULL,
+    expires_at timestamptz,
+    PRIMARY KEY (subject, key)
+);
+
+CREATE INDEX IF NOT EXISTS session_subjects_expires_at_idx ON session_subjects (expires_at);
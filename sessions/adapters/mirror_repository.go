@@ -0,0 +1,585 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+)
+
+const mirrorProgressEvery = 1000
+
+// mirrorTombstoneTTL is how long deletes are remembered, far longer than it
+// takes to copy a session read before the delete.
+const mirrorTombstoneTTL = time.Minute
+
+// mirrorStats is published under /debug/vars, with the progress of the copy of
+// sessions as `progress`.
+var mirrorStats = expvar.NewMap("session_mirror")
+
+// MirrorProgress counts the sessions handled by MirrorRepository.CopyAll.
+type MirrorProgress struct {
+	Scanned int64
+	Copied  int64
+	Skipped int64
+	Failed  int64
+	Done    bool
+}
+
+// MirrorRepository moves sessions from an old repository to a new one without
+// downtime. Writes go to both, reads are served by the new repository falling
+// back to the old one, and sessions found only in the old repository are copied
+// across with the time they have left.
+//
+// Sessions are deleted from the old repository before the new one, and a copy
+// only stays while the old repository still holds the session once written,
+// so a copy of a session read before another instance deleted it does not
+// bring it back. Deleted sessions are remembered for a while as well, sparing
+// copies of the sessions this instance deleted.
+type MirrorRepository struct {
+	old        Store
+	new        Store
+	scanned    int64
+	copied     int64
+	skipped    int64
+	failed     int64
+	done       int32
+	tombstones *memoryCache
+}
+
+func NewMirrorRepository(old, new Store) *MirrorRepository {
+	return &MirrorRepository{
+		old:        old,
+		new:        new,
+		tombstones: newMemoryCache(defaultMemoryShards, mirrorTombstoneTTL, 0, mirrorTombstoneTTL),
+	}
+}
+
+func (m *MirrorRepository) Set(ctx context.Context, key string, value interface{}) error {
+
+	if err := m.new.Set(ctx, key, value); err != nil {
+		return err
+	}
+	return m.old.Set(ctx, key, value)
+}
+
+func (m *MirrorRepository) SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+
+	newSetter, ok := m.new.(ttlSetter)
+	if !ok {
+		return session.ErrNotSupported
+	}
+	oldSetter, ok := m.old.(ttlSetter)
+	if !ok {
+		return session.ErrNotSupported
+	}
+
+	if err := newSetter.SetWithTTL(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	return oldSetter.SetWithTTL(ctx, key, value, ttl)
+}
+
+func (m *MirrorRepository) Get(ctx context.Context, key string) (interface{}, error) {
+	return m.read(ctx, key, func(ctx context.Context, repo Store, key string) (interface{}, error) {
+		return repo.Get(ctx, key)
+	})
+}
+
+// GetPrimary is Get reading the session from where each repository writes it.
+func (m *MirrorRepository) GetPrimary(ctx context.Context, key string) (interface{}, error) {
+	return m.read(ctx, key, getPrimary)
+}
+
+// read reads the session from the new repository with get, or from the old one
+// copying it to the new one when only the old one holds it.
+func (m *MirrorRepository) read(ctx context.Context, key string, get func(ctx context.Context, repo Store, key string) (interface{}, error)) (interface{}, error) {
+
+	val, err := get(ctx, m.new, key)
+	if !errors.Is(err, session.ErrNotFound) {
+		return val, err
+	}
+
+	val, err = get(ctx, m.old, key)
+	if err != nil {
+		return val, err
+	}
+
+	if _, err := m.copy(ctx, key, val); err != nil {
+		logrus.WithError(err).WithField("key", key).Warn("Failed to copy session to the new repository")
+	}
+	return val, nil
+}
+
+// Delete deletes the session from the old repository first, so copies racing
+// it either find it missing there or are written before it is deleted from
+// the new one.
+func (m *MirrorRepository) Delete(ctx context.Context, key string) (int64, error) {
+
+	m.tombstones.Set(ctx, key, "")
+	deletedOld, err := m.old.Delete(ctx, key)
+	if err != nil {
+		return deletedOld, err
+	}
+
+	deletedNew, err := m.new.Delete(ctx, key)
+	if deletedOld > deletedNew {
+		return deletedOld, err
+	}
+	return deletedNew, err
+}
+
+// TTL tells the time the session has left in the new repository, or in the
+// old one when it has not been copied yet.
+func (m *MirrorRepository) TTL(ctx context.Context, key string) (time.Duration, error) {
+
+	newReader, ok := m.new.(ttlReader)
+	if !ok {
+		return 0, session.ErrNotSupported
+	}
+
+	ttl, err := newReader.TTL(ctx, key)
+	if !errors.Is(err, session.ErrNotFound) {
+		return ttl, err
+	}
+
+	oldReader, ok := m.old.(ttlReader)
+	if !ok {
+		return 0, err
+	}
+	return oldReader.TTL(ctx, key)
+}
+
+// Expire changes the time the session has left in both repositories, as long
+// as either holds it.
+func (m *MirrorRepository) Expire(ctx context.Context, key string, ttl time.Duration) error {
+
+	newExpirer, ok := m.new.(expirer)
+	if !ok {
+		return session.ErrNotSupported
+	}
+	oldExpirer, ok := m.old.(expirer)
+	if !ok {
+		return session.ErrNotSupported
+	}
+
+	newErr := newExpirer.Expire(ctx, key, ttl)
+	if newErr != nil && !errors.Is(newErr, session.ErrNotFound) {
+		return newErr
+	}
+
+	oldErr := oldExpirer.Expire(ctx, key, ttl)
+	if oldErr != nil && !errors.Is(oldErr, session.ErrNotFound) {
+		return oldErr
+	}
+
+	if newErr != nil && oldErr != nil {
+		return session.ErrNotFound
+	}
+	return nil
+}
+
+// SetIfAbsent stores value in both repositories, unless either holds a session
+// for key.
+func (m *MirrorRepository) SetIfAbsent(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+
+	newSetter, ok := m.new.(absentSetter)
+	if !ok {
+		return false, session.ErrNotSupported
+	}
+
+	// Reading the session copies it from the old repository when it is only there.
+	if _, err := m.GetPrimary(ctx, key); !errors.Is(err, session.ErrNotFound) {
+		return false, err
+	}
+
+	stored, err := newSetter.SetIfAbsent(ctx, key, value, ttl)
+	if err != nil || !stored {
+		return stored, err
+	}
+	return true, m.setOld(ctx, key, value, ttl)
+}
+
+// Replace checks old against the new repository, which holds the session once
+// it has been read, and then writes value to the old one too.
+func (m *MirrorRepository) Replace(ctx context.Context, key string, old, new interface{}, ttl time.Duration) (bool, error) {
+
+	newReplacer, ok := m.new.(valueReplacer)
+	if !ok {
+		return false, session.ErrNotSupported
+	}
+
+	if _, err := m.GetPrimary(ctx, key); err != nil {
+		if errors.Is(err, session.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	replaced, err := newReplacer.Replace(ctx, key, old, new, ttl)
+	if err != nil || !replaced {
+		return replaced, err
+	}
+	return true, m.setOld(ctx, key, new, ttl)
+}
+
+func (m *MirrorRepository) DeleteIf(ctx context.Context, key string, old interface{}) (bool, error) {
+
+	newDeleter, ok := m.new.(valueDeleter)
+	if !ok {
+		return false, session.ErrNotSupported
+	}
+
+	if _, err := m.GetPrimary(ctx, key); err != nil {
+		if errors.Is(err, session.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	m.tombstones.Set(ctx, key, "")
+	deleted, err := newDeleter.DeleteIf(ctx, key, old)
+	if err != nil || !deleted {
+		return deleted, err
+	}
+	if _, err := m.old.Delete(ctx, key); err != nil {
+		return true, err
+	}
+	// A copy written between both deletes found the session in the old
+	// repository, so it is deleted again.
+	_, err = newDeleter.DeleteIf(ctx, key, old)
+	return true, err
+}
+
+// Increment increments the counter in the new repository, once the session has
+// been copied there, and then in the old one too, as long as it holds the
+// session.
+func (m *MirrorRepository) Increment(ctx context.Context, key string, path session.Pointer, delta int64, updatedAt time.Time) (int64, error) {
+
+	newIncrementer, ok := m.new.(incrementer)
+	if !ok {
+		return 0, session.ErrNotSupported
+	}
+	oldIncrementer, ok := m.old.(incrementer)
+	if !ok {
+		return 0, session.ErrNotSupported
+	}
+
+	// Reading the session copies it from the old repository when it is only there.
+	if _, err := m.GetPrimary(ctx, key); err != nil {
+		return 0, err
+	}
+
+	counter, err := newIncrementer.Increment(ctx, key, path, delta, updatedAt)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := oldIncrementer.Increment(ctx, key, path, delta, updatedAt); err != nil && !errors.Is(err, session.ErrNotFound) {
+		return 0, err
+	}
+	return counter, nil
+}
+
+// IndexSession indexes the session in both repositories.
+func (m *MirrorRepository) IndexSession(ctx context.Context, subject, id string, expiresAt time.Time) error {
+
+	newIndexer, oldIndexer, err := m.indexers()
+	if err != nil {
+		return err
+	}
+
+	if err := newIndexer.IndexSession(ctx, subject, id, expiresAt); err != nil {
+		return err
+	}
+	return oldIndexer.IndexSession(ctx, subject, id, expiresAt)
+}
+
+func (m *MirrorRepository) UnindexSessions(ctx context.Context, subject string, ids []string) error {
+
+	newIndexer, oldIndexer, err := m.indexers()
+	if err != nil {
+		return err
+	}
+
+	if err := newIndexer.UnindexSessions(ctx, subject, ids); err != nil {
+		return err
+	}
+	return oldIndexer.UnindexSessions(ctx, subject, ids)
+}
+
+// IndexedSessions merges the index of subject held by the old repository into
+// the one held by the new, as long as CopyAll has not copied it.
+func (m *MirrorRepository) IndexedSessions(ctx context.Context, subject string) (map[string]time.Time, error) {
+
+	newIndexer, oldIndexer, err := m.indexers()
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := newIndexer.IndexedSessions(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+	oldIndex, err := oldIndexer.IndexedSessions(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+	for id, expiresAt := range oldIndex {
+		if _, ok := index[id]; !ok {
+			index[id] = expiresAt
+		}
+	}
+	return index, nil
+}
+
+func (m *MirrorRepository) indexers() (subjectIndexer, subjectIndexer, error) {
+
+	newIndexer, ok := m.new.(subjectIndexer)
+	if !ok {
+		return nil, nil, session.ErrNotSupported
+	}
+	oldIndexer, ok := m.old.(subjectIndexer)
+	if !ok {
+		return nil, nil, session.ErrNotSupported
+	}
+	return newIndexer, oldIndexer, nil
+}
+
+func (m *MirrorRepository) GetFields(ctx context.Context, key string, fields []string) (map[string]interface{}, error) {
+
+	fieldRepo, err := fieldStore(m.new)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := fieldRepo.GetFields(ctx, key, fields)
+	if !errors.Is(err, session.ErrNotFound) {
+		return values, err
+	}
+
+	val, err := m.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := encodeValue(val)
+	if err != nil {
+		return nil, err
+	}
+	return pickFields(encoded, fields)
+}
+
+func (m *MirrorRepository) SetFields(ctx context.Context, key string, values map[string]interface{}) error {
+
+	fieldRepo, err := fieldStore(m.new)
+	if err != nil {
+		return err
+	}
+
+	err = fieldRepo.SetFields(ctx, key, values)
+	if errors.Is(err, session.ErrNotFound) {
+		// Reading the session copies it from the old repository when it is only there.
+		if _, err := m.GetPrimary(ctx, key); err != nil {
+			return err
+		}
+		err = fieldRepo.SetFields(ctx, key, values)
+	}
+	if err != nil {
+		return err
+	}
+
+	oldFieldRepo, err := fieldStore(m.old)
+	if err != nil {
+		return nil
+	}
+	if err := oldFieldRepo.SetFields(ctx, key, values); err != nil && !errors.Is(err, session.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// CanCopyAll tells whether the old repository is able to list its sessions,
+// which CopyAll needs.
+func (m *MirrorRepository) CanCopyAll() bool {
+	_, ok := m.old.(keyScanner)
+	return ok
+}
+
+// CopyAll walks every session of the old repository and copies the ones the
+// new repository does not hold yet, and then the indexes of subjects. Progress
+// is logged as it goes and published under /debug/vars, and once it returns
+// without error the new repository can be used on its own.
+func (m *MirrorRepository) CopyAll(ctx context.Context) error {
+
+	scanner, ok := m.old.(keyScanner)
+	if !ok {
+		return fmt.Errorf("%w: old repository %T cannot list its sessions", session.ErrNotSupported, m.old)
+	}
+	mirrorStats.Set("progress", expvar.Func(func() interface{} { return m.Progress() }))
+
+	err := scanner.Scan(ctx, func(key string) error {
+		if scanned := atomic.AddInt64(&m.scanned, 1); scanned%mirrorProgressEvery == 0 {
+			m.logProgress("Session copy in progress")
+		}
+
+		val, err := m.old.Get(ctx, key)
+		if errors.Is(err, session.ErrNotFound) {
+			atomic.AddInt64(&m.skipped, 1)
+			return nil
+		}
+		if err != nil {
+			atomic.AddInt64(&m.failed, 1)
+			logrus.WithError(err).WithField("key", key).Warn("Failed to read session from the old repository")
+			return nil
+		}
+
+		copied, err := m.copy(ctx, key, val)
+		switch {
+		case err != nil:
+			atomic.AddInt64(&m.failed, 1)
+			logrus.WithError(err).WithField("key", key).Warn("Failed to copy session to the new repository")
+		case copied:
+			atomic.AddInt64(&m.copied, 1)
+		default:
+			atomic.AddInt64(&m.skipped, 1)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := m.copyIndexes(ctx); err != nil {
+		return err
+	}
+
+	atomic.StoreInt32(&m.done, 1)
+	m.logProgress("Session copy completed")
+	return nil
+}
+
+// copyIndexes adds the sessions in the indexes of subjects held by the old
+// repository to the ones held by the new, when both index subjects.
+func (m *MirrorRepository) copyIndexes(ctx context.Context) error {
+
+	scanner, ok := m.old.(subjectScanner)
+	if !ok {
+		return nil
+	}
+	newIndexer, oldIndexer, err := m.indexers()
+	if err != nil {
+		return nil
+	}
+
+	return scanner.ScanSubjects(ctx, func(subject string) error {
+		index, err := oldIndexer.IndexedSessions(ctx, subject)
+		if err != nil {
+			logrus.WithError(err).WithField("subject", subject).Warn("Failed to read index of subject from the old repository")
+			return nil
+		}
+		for id, expiresAt := range index {
+			if err := newIndexer.IndexSession(ctx, subject, id, expiresAt); err != nil {
+				logrus.WithError(err).WithField("subject", subject).Warn("Failed to copy index of subject to the new repository")
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+// Progress counts the sessions CopyAll has handled so far.
+func (m *MirrorRepository) Progress() MirrorProgress {
+	return MirrorProgress{
+		Scanned: atomic.LoadInt64(&m.scanned),
+		Copied:  atomic.LoadInt64(&m.copied),
+		Skipped: atomic.LoadInt64(&m.skipped),
+		Failed:  atomic.LoadInt64(&m.failed),
+		Done:    atomic.LoadInt32(&m.done) == 1,
+	}
+}
+
+func (m *MirrorRepository) logProgress(msg string) {
+	progress := m.Progress()
+	logrus.WithFields(logrus.Fields{
+		"scanned": progress.Scanned,
+		"copied":  progress.Copied,
+		"skipped": progress.Skipped,
+		"failed":  progress.Failed,
+	}).Info(msg)
+}
+
+// copy stores val in the new repository with the time the session has left in
+// the old one, unless the new repository already holds a session for key or it
+// was deleted lately. A copy racing a delete, from this instance or another
+// one, is deleted again.
+func (m *MirrorRepository) copy(ctx context.Context, key string, val interface{}) (bool, error) {
+
+	if m.deleted(ctx, key) {
+		return false, nil
+	}
+
+	copied, err := m.store(ctx, key, val)
+	if err != nil || !copied {
+		return copied, err
+	}
+	if !m.deleted(ctx, key) {
+		_, err := getPrimary(ctx, m.old, key)
+		if !errors.Is(err, session.ErrNotFound) {
+			return true, err
+		}
+	}
+
+	if deleter, ok := m.new.(valueDeleter); ok {
+		_, err = deleter.DeleteIf(ctx, key, val)
+	} else {
+		_, err = m.new.Delete(ctx, key)
+	}
+	return false, err
+}
+
+// store writes val to the new repository unless it holds a session for key.
+func (m *MirrorRepository) store(ctx context.Context, key string, val interface{}) (bool, error) {
+
+	var ttl time.Duration
+	if reader, ok := m.old.(ttlReader); ok {
+		remaining, err := reader.TTL(ctx, key)
+		if errors.Is(err, session.ErrNotFound) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if remaining > 0 {
+			ttl = remaining
+		}
+	}
+
+	if setter, ok := m.new.(absentSetter); ok {
+		return setter.SetIfAbsent(ctx, key, val, ttl)
+	}
+
+	if _, err := m.new.Get(ctx, key); !errors.Is(err, session.ErrNotFound) {
+		return false, err
+	}
+	return true, m.new.Set(ctx, key, val)
+}
+
+// deleted tells whether the session was deleted lately.
+func (m *MirrorRepository) deleted(ctx context.Context, key string) bool {
+	_, err := m.tombstones.Get(ctx, key)
+	return err == nil
+}
+
+// setOld writes value to the old repository for ttl, or for its default expiry
+// when ttl is zero, so it keeps up with conditional writes to the new one.
+func (m *MirrorRepository) setOld(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+
+	if setter, ok := m.old.(ttlSetter); ok && ttl > 0 {
+		return setter.SetWithTTL(ctx, key, value, ttl)
+	}
+	return m.old.Set(ctx, key, value)
+}
@@ -0,0 +1,186 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMirrorRepositoryShouldWriteToBothRepositories(t *testing.T) {
+	t.Parallel()
+
+	old, new := newMemoryCache(1, time.Minute, 0, 0), newMemoryCache(1, time.Minute, 0, 0)
+	mirror := NewMirrorRepository(old, new)
+
+	err := mirror.Set(ctx, "someKey", "someValue")
+	assert.Nil(t, err, "Expect err is nil when storing session value")
+
+	for _, repo := range []Store{old, new} {
+		val, err := repo.Get(ctx, "someKey")
+		assert.Nil(t, err, "Expect session to be stored in both repositories")
+		assert.True(t, val == "someValue", "Expect stored session value")
+	}
+
+	deleted, err := mirror.Delete(ctx, "someKey")
+	assert.Nil(t, err, "Expect err is nil when deleting session")
+	assert.Equal(t, int64(1), deleted, "Expect session to have been deleted")
+
+	for _, repo := range []Store{old, new} {
+		_, err := repo.Get(ctx, "someKey")
+		assert.ErrorIs(t, err, session.ErrNotFound, "Expect session to be deleted from both repositories")
+	}
+}
+
+func TestMirrorRepositoryShouldCopySessionsOnRead(t *testing.T) {
+	t.Parallel()
+
+	old, new := newMemoryCache(1, time.Minute, 0, 0), newMemoryCache(1, time.Hour, 0, 0)
+	mirror := NewMirrorRepository(old, new)
+
+	old.Set(ctx, "someKey", "someValue")
+
+	val, err := mirror.Get(ctx, "someKey")
+	assert.Nil(t, err, "Expect session to be read from the old repository")
+	assert.True(t, val == "someValue", "Expect stored session value")
+
+	val, err = new.Get(ctx, "someKey")
+	assert.Nil(t, err, "Expect session to have been copied to the new repository")
+	assert.True(t, val == "someValue", "Expect copied session value")
+
+	ttl, _ := new.TTL(ctx, "someKey")
+	assert.LessOrEqual(t, ttl, time.Minute, "Expect copied session to keep its remaining ttl")
+}
+
+func TestMirrorRepositoryShouldExpireSessionsHeldByEitherRepository(t *testing.T) {
+	t.Parallel()
+
+	old, new := newMemoryCache(1, time.Minute, 0, 0), newMemoryCache(1, time.Minute, 0, 0)
+	mirror := NewMirrorRepository(old, new)
+
+	old.Set(ctx, "someKey", "someValue")
+
+	err := mirror.Expire(ctx, "someKey", time.Hour)
+	assert.Nil(t, err, "Expect session only held by the old repository to be expired")
+
+	ttl, err := mirror.TTL(ctx, "someKey")
+	assert.Nil(t, err, "Expect ttl to be read from the old repository")
+	assert.Greater(t, ttl, time.Minute, "Expect session to be kept for the new ttl")
+
+	err = mirror.Expire(ctx, "thisSessionKeyShouldNotExist", time.Hour)
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect not found error when neither repository holds the session")
+}
+
+func TestMirrorRepositoryShouldPreferTheNewRepository(t *testing.T) {
+	t.Parallel()
+
+	old, new := newMemoryCache(1, time.Minute, 0, 0), newMemoryCache(1, time.Minute, 0, 0)
+	mirror := NewMirrorRepository(old, new)
+
+	old.Set(ctx, "someKey", "oldValue")
+	new.Set(ctx, "someKey", "newValue")
+
+	val, err := mirror.Get(ctx, "someKey")
+	assert.Nil(t, err, "Expect err is nil when retrieving session value")
+	assert.True(t, val == "newValue", "Expect session value from the new repository")
+}
+
+func TestMirrorRepositoryShouldCopyAllSessions(t *testing.T) {
+	t.Parallel()
+
+	old, new := newMemoryCache(4, time.Minute, 0, 0), newMemoryCache(4, time.Hour, 0, 0)
+	mirror := NewMirrorRepository(old, new)
+
+	for i := 0; i < 10; i++ {
+		old.Set(ctx, fmt.Sprintf("key-%d", i), "oldValue")
+	}
+	new.Set(ctx, "key-0", "newValue")
+
+	err := mirror.CopyAll(ctx)
+	assert.Nil(t, err, "Expect err is nil when copying sessions")
+
+	progress := mirror.Progress()
+	assert.Equal(t, MirrorProgress{Scanned: 10, Copied: 9, Skipped: 1, Done: true}, progress)
+
+	val, _ := new.Get(ctx, "key-0")
+	assert.True(t, val == "newValue", "Expect copy not to overwrite newer sessions")
+
+	val, _ = new.Get(ctx, "key-9")
+	assert.True(t, val == "oldValue", "Expect session to have been copied")
+}
+
+func TestMirrorRepositoryShouldNotCopySessionsDeletedWhileBeingRead(t *testing.T) {
+	t.Parallel()
+
+	old := &racingRepository{memoryCache: newMemoryCache(1, time.Minute, 0, 0)}
+	new := newMemoryCache(1, time.Minute, 0, 0)
+	mirror := NewMirrorRepository(old, new)
+
+	old.Set(ctx, "someKey", "someValue")
+	old.onGet = func() { mirror.Delete(ctx, "someKey") }
+
+	mirror.Get(ctx, "someKey")
+
+	_, err := new.Get(ctx, "someKey")
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect session deleted while being read not to be copied")
+
+	old.Set(ctx, "otherKey", "someValue")
+	old.onGet = func() { mirror.Delete(ctx, "otherKey") }
+
+	err = mirror.CopyAll(ctx)
+	assert.Nil(t, err, "Expect err is nil when copying sessions")
+
+	_, err = new.Get(ctx, "otherKey")
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect session deleted while being copied not to be copied")
+}
+
+// expiringRepository runs onTTL once after the time a session has left is
+// read, as if another write got in right after.
+type expiringRepository struct {
+	*memoryCache
+	onTTL func()
+}
+
+func (r *expiringRepository) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := r.memoryCache.TTL(ctx, key)
+	if r.onTTL != nil {
+		onTTL := r.onTTL
+		r.onTTL = nil
+		onTTL()
+	}
+	return ttl, err
+}
+
+func TestMirrorRepositoryShouldNotCopySessionsDeletedByAnotherInstanceWhileBeingRead(t *testing.T) {
+	t.Parallel()
+
+	old := &expiringRepository{memoryCache: newMemoryCache(1, time.Minute, 0, 0)}
+	new := newMemoryCache(1, time.Minute, 0, 0)
+	mirror := NewMirrorRepository(old, new)
+	other := NewMirrorRepository(old, new)
+
+	old.Set(ctx, "someKey", "someValue")
+	old.onTTL = func() { other.Delete(ctx, "someKey") }
+
+	mirror.Get(ctx, "someKey")
+
+	_, err := new.Get(ctx, "someKey")
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect session deleted by another instance while being read not to be copied")
+	_, err = mirror.Get(ctx, "someKey")
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect session deleted by another instance not to come back")
+}
+
+func TestMirrorRepositoryShouldNotCopyAllFromRepositoriesUnableToListSessions(t *testing.T) {
+	t.Parallel()
+
+	old := struct{ Store }{newMemoryCache(1, time.Minute, 0, 0)}
+	mirror := NewMirrorRepository(old, newMemoryCache(1, time.Minute, 0, 0))
+
+	assert.False(t, mirror.CanCopyAll(), "Expect repository unable to list sessions not to be copied")
+
+	err := mirror.CopyAll(ctx)
+	assert.ErrorIs(t, err, session.ErrNotSupported, "Expect copying all sessions not to be supported")
+}
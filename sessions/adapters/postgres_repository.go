@@ -0,0 +1,567 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// migrationsLockID identifies the advisory lock taken while migrations run, so
+// replicas starting at the same time apply them only once.
+const migrationsLockID = 7263541
+
+//go:embed migrations/postgres/*.sql
+var postgresMigrations embed.FS
+
+type postgresRepository struct {
+	db        *sql.DB
+	expires   time.Duration
+	batchSize int
+	stop      chan struct{}
+	once      sync.Once
+}
+
+func init() {
+	Register("postgres", postgresBackend)
+}
+
+func postgresBackend(config Config) (Store, error) {
+	reapInterval, err := config.Seconds("CLEANUP_INTERVAL", 60)
+	if err != nil {
+		return nil, err
+	}
+	batchSize, err := config.Int("CLEANUP_BATCH", 1000)
+	if err != nil {
+		return nil, err
+	}
+	if batchSize <= 0 {
+		return nil, fmt.Errorf("%sCLEANUP_BATCH must be positive, got %d", config.Prefix, batchSize)
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(config.String("USER", "postgres"), config.String("PASSWORD", "")),
+		Host:     fmt.Sprintf("%s:%s", config.String("HOST", "localhost"), config.String("PORT", "5432")),
+		Path:     config.String("NAME", "sessions"),
+		RawQuery: url.Values{"sslmode": {config.String("SSLMODE", "disable")}}.Encode(),
+	}
+	return newPostgresRepository(dsn.String(), config.Expires, reapInterval, batchSize)
+}
+
+// NewPostgresRepository returns a session repository storing sessions in the
+// Postgres database reachable through dsn. Pending schema migrations are
+// applied before it is returned, and expired sessions are deleted by a
+// background reaper every reapInterval, at most batchSize rows at a time.
+func NewPostgresRepository(dsn string, expires time.Duration, reapInterval time.Duration, batchSize int) Store {
+	r, err := newPostgresRepository(dsn, expires, reapInterval, batchSize)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func newPostgresRepository(dsn string, expires time.Duration, reapInterval time.Duration, batchSize int) (*postgresRepository, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migratePostgres(context.Background(), db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error '%s' when applying postgres migrations", err)
+	}
+
+	r := &postgresRepository{
+		db:        db,
+		expires:   expires,
+		batchSize: batchSize,
+		stop:      make(chan struct{}),
+	}
+
+	if reapInterval > 0 {
+		go r.reaper(reapInterval)
+	}
+
+	return r, nil
+}
+
+func (r *postgresRepository) Set(ctx context.Context, key string, value interface{}) error {
+	return r.SetWithTTL(ctx, key, value, r.expires)
+}
+
+// SetWithTTL stores the session for ttl, or with an infinite expiry when ttl is
+// zero, which the queries of live sessions take as never expiring.
+func (r *postgresRepository) SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+
+	val, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO sessions (key, value, expires_at)
+		VALUES ($1, $2::jsonb, CASE WHEN $3::bigint > 0 THEN now() + $3::bigint * interval '1 millisecond' ELSE 'infinity' END)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, expires_at = EXCLUDED.expires_at`,
+		key, val, ttl.Milliseconds(),
+	)
+	return err
+}
+
+func (r *postgresRepository) Get(ctx context.Context, key string) (interface{}, error) {
+
+	var val string
+	err := r.db.QueryRowContext(ctx,
+		`SELECT value::text FROM sessions WHERE key = $1 AND expires_at > now()`,
+		key,
+	).Scan(&val)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", session.ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return val, nil
+}
+
+// Scan walks the live sessions in key order, batchSize keys at a time, reading
+// each batch before walking it so onKey may write sessions.
+func (r *postgresRepository) Scan(ctx context.Context, onKey func(key string) error) error {
+
+	after := ""
+	for {
+		keys, err := r.scanBatch(ctx, after)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			if err := onKey(key); err != nil {
+				return err
+			}
+		}
+		if len(keys) < r.batchSize {
+			return nil
+		}
+		after = keys[len(keys)-1]
+	}
+}
+
+// scanBatch reads the keys of up to batchSize live sessions after the key
+// given.
+func (r *postgresRepository) scanBatch(ctx context.Context, after string) ([]string, error) {
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT key FROM sessions WHERE key > $1 AND expires_at > now() ORDER BY key LIMIT $2`,
+		after, r.batchSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]string, 0, r.batchSize)
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func (r *postgresRepository) Delete(ctx context.Context, key string) (int64, error) {
+
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM sessions WHERE key = $1 AND expires_at > now()`,
+		key,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *postgresRepository) TTL(ctx context.Context, key string) (time.Duration, error) {
+
+	var ms float64
+	err := r.db.QueryRowContext(ctx,
+		`SELECT CASE WHEN expires_at = 'infinity' THEN -1 ELSE EXTRACT(EPOCH FROM expires_at - now()) * 1000 END
+		FROM sessions WHERE key = $1 AND expires_at > now()`,
+		key,
+	).Scan(&ms)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, session.ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	if ms < 0 {
+		return -1, nil
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+func (r *postgresRepository) Expire(ctx context.Context, key string, ttl time.Duration) error {
+
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE sessions SET expires_at = now() + $2 * interval '1 millisecond'
+		WHERE key = $1 AND expires_at > now()`,
+		key, ttl.Milliseconds(),
+	)
+	if err != nil {
+		return err
+	}
+
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return session.ErrNotFound
+	}
+	return nil
+}
+
+// SetIfAbsent inserts the session, or takes over the row of an expired one the
+// reaper has not deleted yet.
+func (r *postgresRepository) SetIfAbsent(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+
+	val, err := encodeValue(value)
+	if err != nil {
+		return false, err
+	}
+	if ttl == 0 {
+		ttl = r.expires
+	}
+
+	res, err := r.db.ExecContext(ctx,
+		`INSERT INTO sessions (key, value, expires_at)
+		VALUES ($1, $2::jsonb, CASE WHEN $3::bigint > 0 THEN now() + $3::bigint * interval '1 millisecond' ELSE 'infinity' END)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, expires_at = EXCLUDED.expires_at
+		WHERE sessions.expires_at <= now()`,
+		key, val, ttl.Milliseconds(),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	stored, err := res.RowsAffected()
+	return stored > 0, err
+}
+
+// Replace relies on jsonb equality, so old matches the stored session whatever
+// the order of its fields.
+func (r *postgresRepository) Replace(ctx context.Context, key string, old, new interface{}, ttl time.Duration) (bool, error) {
+
+	oldVal, err := encodeValue(old)
+	if err != nil {
+		return false, err
+	}
+	newVal, err := encodeValue(new)
+	if err != nil {
+		return false, err
+	}
+	if ttl == 0 {
+		ttl = r.expires
+	}
+
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE sessions SET value = $3::jsonb,
+		expires_at = CASE WHEN $4::bigint > 0 THEN now() + $4::bigint * interval '1 millisecond' ELSE 'infinity' END
+		WHERE key = $1 AND expires_at > now() AND value = $2::jsonb`,
+		key, oldVal, newVal, ttl.Milliseconds(),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	replaced, err := res.RowsAffected()
+	return replaced > 0, err
+}
+
+func (r *postgresRepository) DeleteIf(ctx context.Context, key string, old interface{}) (bool, error) {
+
+	oldVal, err := encodeValue(old)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM sessions WHERE key = $1 AND expires_at > now() AND value = $2::jsonb`,
+		key, oldVal,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	deleted, err := res.RowsAffected()
+	return deleted > 0, err
+}
+
+// Increment locks the row of the session while it checks the increment, then
+// sets the counter and the metadata of the session with jsonb_set, leaving the
+// rest of the session as it is.
+func (r *postgresRepository) Increment(ctx context.Context, key string, path session.Pointer, delta int64, updatedAt time.Time) (int64, error) {
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var val string
+	err = tx.QueryRowContext(ctx,
+		`SELECT value::text FROM sessions WHERE key = $1 AND expires_at > now() FOR UPDATE`,
+		key,
+	).Scan(&val)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, session.ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	stored, counter, err := applyIncrement(val, path, delta, updatedAt)
+	if err != nil {
+		return 0, err
+	}
+	metadata, err := json.Marshal(stored[metadataField])
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE sessions SET value = jsonb_set(jsonb_set(value, $2::text[], $3::jsonb, true), $4::text[], $5::jsonb)
+		WHERE key = $1`,
+		key, pq.Array([]string(appended(stored, path))), strconv.FormatInt(counter, 10), pq.Array([]string{metadataField}), string(metadata),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return counter, tx.Commit()
+}
+
+// IndexSession records when the session expires in session_subjects, as null
+// when it never does.
+func (r *postgresRepository) IndexSession(ctx context.Context, subject, id string, expiresAt time.Time) error {
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO session_subjects (subject, key, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (subject, key) DO UPDATE SET expires_at = EXCLUDED.expires_at`,
+		subject, id, sql.NullTime{Time: expiresAt, Valid: !expiresAt.IsZero()},
+	)
+	return err
+}
+
+func (r *postgresRepository) UnindexSessions(ctx context.Context, subject string, ids []string) error {
+
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM session_subjects WHERE subject = $1 AND key = ANY($2)`,
+		subject, pq.Array(ids),
+	)
+	return err
+}
+
+func (r *postgresRepository) IndexedSessions(ctx context.Context, subject string) (map[string]time.Time, error) {
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT key, expires_at FROM session_subjects WHERE subject = $1`,
+		subject,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	index := make(map[string]time.Time)
+	for rows.Next() {
+		var id string
+		var expiresAt sql.NullTime
+		if err := rows.Scan(&id, &expiresAt); err != nil {
+			return nil, err
+		}
+		index[id] = expiresAt.Time
+	}
+	return index, rows.Err()
+}
+
+func (r *postgresRepository) ScanSubjects(ctx context.Context, onSubject func(subject string) error) error {
+
+	rows, err := r.db.QueryContext(ctx, `SELECT DISTINCT subject FROM session_subjects`)
+	if err != nil {
+		return err
+	}
+
+	var subjects []string
+	for rows.Next() {
+		var subject string
+		if err := rows.Scan(&subject); err != nil {
+			rows.Close()
+			return err
+		}
+		subjects = append(subjects, subject)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, subject := range subjects {
+		if err := onSubject(subject); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops the background reaper and closes the database.
+func (r *postgresRepository) Close() error {
+	r.once.Do(func() { close(r.stop) })
+	return r.db.Close()
+}
+
+func (r *postgresRepository) reaper(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			deleted, err := r.reap(context.Background())
+			if err != nil {
+				logrus.WithError(err).Error("Failed to delete expired sessions")
+				continue
+			}
+			logrus.WithField("deleted", deleted).Debug("Expired sessions deleted")
+
+			if _, err := r.reapIndexes(context.Background()); err != nil {
+				logrus.WithError(err).Error("Failed to drop expired sessions from the indexes of subjects")
+			}
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// reap deletes expired sessions in batches until none are left.
+func (r *postgresRepository) reap(ctx context.Context) (int64, error) {
+	var total int64
+
+	for {
+		res, err := r.db.ExecContext(ctx,
+			`DELETE FROM sessions WHERE key IN (
+				SELECT key FROM sessions WHERE expires_at <= now()
+				ORDER BY expires_at LIMIT $1 FOR UPDATE SKIP LOCKED
+			)`,
+			r.batchSize,
+		)
+		if err != nil {
+			return total, err
+		}
+
+		deleted, err := res.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+
+		total += deleted
+		if deleted < int64(r.batchSize) {
+			return total, nil
+		}
+	}
+}
+
+// reapIndexes drops from the indexes of subjects the sessions due to expire
+// by now that are gone.
+func (r *postgresRepository) reapIndexes(ctx context.Context) (int64, error) {
+
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM session_subjects i WHERE i.expires_at <= now()
+		AND NOT EXISTS (SELECT 1 FROM sessions WHERE key = i.key AND expires_at > now())`,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// migratePostgres applies, in file name order, the embedded migrations that
+// have not been recorded in schema_migrations yet. They all run in a single
+// transaction holding the migrations lock, taken before anything else so that
+// replicas starting at the same time do not race on creating schema_migrations.
+func migratePostgres(ctx context.Context, db *sql.DB) error {
+
+	files, err := postgresMigrations.ReadDir("migrations/postgres")
+	if err != nil {
+		return err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name() < files[j].Name() })
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, migrationsLockID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    text PRIMARY KEY,
+			applied_at timestamptz NOT NULL DEFAULT now()
+		)`,
+	); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		script, err := postgresMigrations.ReadFile(path.Join("migrations/postgres", file.Name()))
+		if err != nil {
+			return err
+		}
+
+		if err := applyMigration(ctx, tx, file.Name(), string(script)); err != nil {
+			return fmt.Errorf("migration %s: %w", file.Name(), err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func applyMigration(ctx context.Context, tx *sql.Tx, version, script string) error {
+
+	var applied bool
+	if err := tx.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`,
+		version,
+	).Scan(&applied); err != nil {
+		return err
+	}
+	if applied {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, version)
+	return err
+}
@@ -0,0 +1,357 @@
+package adapters
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockPostgres(t *testing.T) (*postgresRepository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cannot create sql mock %s", err)
+	}
+
+	return &postgresRepository{db: db, expires: time.Minute, batchSize: 2, stop: make(chan struct{})}, mock
+}
+
+func TestShouldStoreDataInPostgres(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := mockPostgres(t)
+	defer repo.Close()
+
+	sessionValue := `{"someSessionTest":"Value"}`
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO sessions`)).
+		WithArgs("someSessionTestKey", sessionValue, int64(60000)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT value::text FROM sessions WHERE key = $1 AND expires_at > now()`)).
+		WithArgs("someSessionTestKey").
+		WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(sessionValue))
+
+	err := repo.Set(ctx, "someSessionTestKey", sessionValue)
+	assert.Nil(t, err, "Expect err is nil when storing session value")
+
+	val, err := repo.Get(ctx, "someSessionTestKey")
+	assert.Nil(t, err, "Expect err is nil when retrieving session value")
+	assert.True(t, val == sessionValue, "Expect session to be stored in postgres")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestShouldNotRetrieveDataFromPostgresIfDataDoesntExist(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := mockPostgres(t)
+	defer repo.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT value::text FROM sessions`)).
+		WithArgs("thisSessionKeyShouldNotExist").
+		WillReturnError(sql.ErrNoRows)
+
+	val, err := repo.Get(ctx, "thisSessionKeyShouldNotExist")
+
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect not found error for non existing session")
+	assert.True(t, val == "", "Expect session data not to be in postgres")
+}
+
+func TestShouldDeleteDataFromPostgres(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := mockPostgres(t)
+	defer repo.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM sessions WHERE key = $1`)).
+		WithArgs("someDeleteTestKey").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	deleted, err := repo.Delete(ctx, "someDeleteTestKey")
+
+	assert.Nil(t, err, "Expect err is nil when deleting session key")
+	assert.Equal(t, int64(1), deleted, "Expect one session to have been deleted")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestShouldChangeExpiryOfDataInPostgres(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := mockPostgres(t)
+	defer repo.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE sessions SET expires_at = now() + $2 * interval '1 millisecond'`)).
+		WithArgs("someExpireKey", int64(3600000)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`EXTRACT(EPOCH FROM expires_at - now()) * 1000 END`)).
+		WithArgs("someExpireKey").
+		WillReturnRows(sqlmock.NewRows([]string{"ttl"}).AddRow(3599500.0))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE sessions SET expires_at`)).
+		WithArgs("thisSessionKeyShouldNotExist", int64(3600000)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.Expire(ctx, "someExpireKey", time.Hour)
+	assert.Nil(t, err, "Expect err is nil when changing the expiry of a session")
+
+	ttl, err := repo.TTL(ctx, "someExpireKey")
+	assert.Nil(t, err, "Expect err is nil when reading the ttl of a session")
+	assert.Equal(t, 3599500*time.Millisecond, ttl)
+
+	err = repo.Expire(ctx, "thisSessionKeyShouldNotExist", time.Hour)
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect not found error for non existing session")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestShouldKeepDataWithoutExpiryInPostgres(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := mockPostgres(t)
+	defer repo.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta(`ELSE 'infinity' END`)).
+		WithArgs("somePersistentKey", `{"data":"value"}`, int64(0)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`CASE WHEN expires_at = 'infinity' THEN -1`)).
+		WithArgs("somePersistentKey").
+		WillReturnRows(sqlmock.NewRows([]string{"ttl"}).AddRow(-1.0))
+
+	err := repo.SetWithTTL(ctx, "somePersistentKey", `{"data":"value"}`, 0)
+	assert.Nil(t, err, "Expect err is nil when storing a session without expiry")
+
+	ttl, err := repo.TTL(ctx, "somePersistentKey")
+	assert.Nil(t, err, "Expect err is nil when reading the ttl of a session")
+	assert.Less(t, ttl, time.Duration(0), "Expect a session without expiry to never expire")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestShouldWriteDataConditionallyInPostgres(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := mockPostgres(t)
+	defer repo.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta(`WHERE sessions.expires_at <= now()`)).
+		WithArgs("someConditionalKey", `{"data":"old"}`, int64(60000)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`WHERE sessions.expires_at <= now()`)).
+		WithArgs("someConditionalKey", `{"data":"other"}`, int64(60000)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE sessions SET value = $3::jsonb`)).
+		WithArgs("someConditionalKey", `{"data":"old"}`, `{"data":"new"}`, int64(3600000)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE sessions SET value = $3::jsonb`)).
+		WithArgs("someConditionalKey", `{"data":"old"}`, `{"data":"newer"}`, int64(60000)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	stored, err := repo.SetIfAbsent(ctx, "someConditionalKey", `{"data":"old"}`, 0)
+	assert.Nil(t, err, "Expect err is nil when storing an absent session")
+	assert.True(t, stored, "Expect absent session to be stored")
+
+	stored, err = repo.SetIfAbsent(ctx, "someConditionalKey", `{"data":"other"}`, 0)
+	assert.Nil(t, err, "Expect err is nil when storing an existing session")
+	assert.False(t, stored, "Expect existing session not to be stored again")
+
+	replaced, err := repo.Replace(ctx, "someConditionalKey", `{"data":"old"}`, `{"data":"new"}`, time.Hour)
+	assert.Nil(t, err, "Expect err is nil when replacing a session")
+	assert.True(t, replaced, "Expect session holding the old value to be replaced")
+
+	replaced, err = repo.Replace(ctx, "someConditionalKey", `{"data":"old"}`, `{"data":"newer"}`, 0)
+	assert.Nil(t, err, "Expect err is nil when replacing a session")
+	assert.False(t, replaced, "Expect session holding another value not to be replaced")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestShouldIncrementCountersInPostgres(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := mockPostgres(t)
+	defer repo.Close()
+
+	selectQuery := regexp.QuoteMeta(`SELECT value::text FROM sessions WHERE key = $1 AND expires_at > now() FOR UPDATE`)
+	updateQuery := regexp.QuoteMeta(`UPDATE sessions SET value = jsonb_set(jsonb_set(value, $2::text[], $3::jsonb, true), $4::text[], $5::jsonb)`)
+	sessionValue := `{"list": [1], "stats": {"ratio": 0.5}, "__session": {"created": 1, "updated": 1, "version": 3}}`
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectQuery).
+		WithArgs("someCounterKey").
+		WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(sessionValue))
+	mock.ExpectExec(updateQuery).
+		WithArgs("someCounterKey", pq.Array([]string{"list", "1"}), "5", pq.Array([]string{"__session"}), `{"created":1,"updated":1000000,"version":4}`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectQuery).
+		WithArgs("someCounterKey").
+		WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(sessionValue))
+	mock.ExpectRollback()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectQuery).
+		WithArgs("thisSessionKeyShouldNotExist").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	counter, err := repo.Increment(ctx, "someCounterKey", session.Pointer{"list", "-"}, 5, time.UnixMilli(1_000_000))
+	assert.Nil(t, err, "Expect err is nil when incrementing a counter")
+	assert.Equal(t, int64(5), counter, "Expect the counter to be appended to the array")
+
+	_, err = repo.Increment(ctx, "someCounterKey", session.Pointer{"stats", "ratio"}, 1, time.UnixMilli(1_000_000))
+	assert.ErrorIs(t, err, session.ErrInvalid, "Expect a number that is not an integer not to be incremented")
+
+	_, err = repo.Increment(ctx, "thisSessionKeyShouldNotExist", session.Pointer{"views"}, 1, time.UnixMilli(1_000_000))
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect a missing session not to be incremented")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestScanShouldPageThroughLiveKeysInBatches(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := mockPostgres(t)
+	defer repo.Close()
+
+	scanQuery := regexp.QuoteMeta(`SELECT key FROM sessions WHERE key > $1 AND expires_at > now() ORDER BY key LIMIT $2`)
+	mock.ExpectQuery(scanQuery).WithArgs("", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("a").AddRow("b"))
+	mock.ExpectQuery(scanQuery).WithArgs("b", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("c"))
+
+	var keys []string
+	err := repo.Scan(ctx, func(key string) error {
+		keys = append(keys, key)
+		return nil
+	})
+
+	assert.Nil(t, err, "Expect err is nil when scanning keys")
+	assert.Equal(t, []string{"a", "b", "c"}, keys, "Expect every live key to be scanned")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestReapShouldDeleteExpiredSessionsInBatches(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := mockPostgres(t)
+	defer repo.Close()
+
+	reapQuery := regexp.QuoteMeta(`DELETE FROM sessions WHERE key IN (`)
+	mock.ExpectExec(reapQuery).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(reapQuery).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(reapQuery).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	deleted, err := repo.reap(ctx)
+
+	assert.Nil(t, err, "Expect err is nil when reaping sessions")
+	assert.Equal(t, int64(5), deleted, "Expect all expired sessions to have been deleted")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestShouldIndexSessionsBySubjectInPostgres(t *testing.T) {
+	t.Parallel()
+
+	repo, mock := mockPostgres(t)
+	defer repo.Close()
+
+	expiresAt := time.UnixMilli(1_000_000)
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO session_subjects (subject, key, expires_at)`)).
+		WithArgs("someUser", "someSessionKey", expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO session_subjects (subject, key, expires_at)`)).
+		WithArgs("someUser", "otherSessionKey", nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT key, expires_at FROM session_subjects WHERE subject = $1`)).
+		WithArgs("someUser").
+		WillReturnRows(sqlmock.NewRows([]string{"key", "expires_at"}).
+			AddRow("someSessionKey", expiresAt).
+			AddRow("otherSessionKey", nil))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM session_subjects WHERE subject = $1 AND key = ANY($2)`)).
+		WithArgs("someUser", pq.Array([]string{"someSessionKey"})).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM session_subjects i WHERE i.expires_at <= now()`)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	err := repo.IndexSession(ctx, "someUser", "someSessionKey", expiresAt)
+	assert.Nil(t, err, "Expect err is nil when indexing a session")
+	err = repo.IndexSession(ctx, "someUser", "otherSessionKey", time.Time{})
+	assert.Nil(t, err, "Expect err is nil when indexing a session that never expires")
+
+	index, err := repo.IndexedSessions(ctx, "someUser")
+	assert.Nil(t, err, "Expect err is nil when reading the index of a subject")
+	assert.Equal(t, map[string]time.Time{"someSessionKey": expiresAt, "otherSessionKey": {}}, index)
+
+	err = repo.UnindexSessions(ctx, "someUser", []string{"someSessionKey"})
+	assert.Nil(t, err, "Expect err is nil when dropping sessions from the index of a subject")
+
+	dropped, err := repo.reapIndexes(ctx)
+	assert.Nil(t, err, "Expect err is nil when reaping the indexes of subjects")
+	assert.Equal(t, int64(3), dropped, "Expect expired sessions gone to be dropped from the indexes")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestMigratePostgresShouldSkipAppliedMigrations(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cannot create sql mock %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock($1)`)).
+		WithArgs(migrationsLockID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS schema_migrations`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS`)).
+		WithArgs("0001_create_sessions.sql").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS`)).
+		WithArgs("0002_create_session_subjects.sql").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectCommit()
+
+	err = migratePostgres(ctx, db)
+
+	assert.Nil(t, err, "Expect err is nil when migrations were already applied")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestMigratePostgresShouldApplyPendingMigrations(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("cannot create sql mock %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_xact_lock($1)`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS schema_migrations`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS`)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS sessions`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO schema_migrations (version) VALUES ($1)`)).
+		WithArgs("0001_create_sessions.sql").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT EXISTS`)).
+		WithArgs("0002_create_session_subjects.sql").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS session_subjects`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO schema_migrations (version) VALUES ($1)`)).
+		WithArgs("0002_create_session_subjects.sql").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = migratePostgres(ctx, db)
+
+	assert.Nil(t, err, "Expect err is nil when applying migrations")
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
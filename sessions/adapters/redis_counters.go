@@ -0,0 +1,297 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+)
+
+// incrementScript increments a counter of the session at KEYS[1] on the server,
+// so concurrent writes cannot get in between reading the counter and writing
+// it back. The JSON of the session is edited in place rather than decoded and
+// encoded again, which cjson does not do losslessly: the rest of the session
+// is stored again exactly as it was.
+//
+// ARGV holds the storage, the metadata field, the delta, the update time in
+// Unix milliseconds, the bound of counters and then the tokens of the pointer.
+// It answers the outcome, along with the value of the counter when it is "ok".
+var incrementScript = redis.NewScript(`
+local function skipws(s, i)
+	return string.find(s, '[^ \t\r\n]', i) or #s + 1
+end
+
+local function skipstring(s, i)
+	while true do
+		local j = string.find(s, '["\\]', i + 1)
+		if not j then
+			return #s + 1
+		end
+		if string.sub(s, j, j) == '"' then
+			return j + 1
+		end
+		i = j + 1
+	end
+end
+
+-- skipvalue returns where the value starting at i ends.
+local function skipvalue(s, i)
+	local c = string.sub(s, i, i)
+	if c == '"' then
+		return skipstring(s, i)
+	end
+	if c ~= '{' and c ~= '[' then
+		return string.find(s, '[,}%]%s]', i) or #s + 1
+	end
+	local depth = 0
+	while true do
+		local j = string.find(s, '[%[%]{}"]', i)
+		if not j then
+			return #s + 1
+		end
+		c = string.sub(s, j, j)
+		if c == '"' then
+			i = skipstring(s, j)
+		else
+			if c == '{' or c == '[' then
+				depth = depth + 1
+			else
+				depth = depth - 1
+			end
+			i = j + 1
+			if depth == 0 then
+				return i
+			end
+		end
+	end
+end
+
+-- child returns where the value of the member or element token of the object
+-- or array starting at i starts and ends, or nil, where the container closes
+-- and how many entries it holds when there is none.
+local function child(s, i, token)
+	local open = string.sub(s, i, i)
+	local close = open == '{' and '}' or ']'
+	local n = 0
+	i = skipws(s, i + 1)
+	if string.sub(s, i, i) == close then
+		return nil, i, 0
+	end
+	while true do
+		local name = tostring(n)
+		if open == '{' then
+			local e = skipstring(s, i)
+			name = cjson.decode(string.sub(s, i, e - 1))
+			i = skipws(s, skipws(s, e) + 1)
+		end
+		local e = skipvalue(s, i)
+		if name == token then
+			return i, e
+		end
+		n = n + 1
+		i = skipws(s, e)
+		if string.sub(s, i, i) ~= ',' then
+			return nil, i, n
+		end
+		i = skipws(s, i + 1)
+	end
+end
+
+-- rotated tells whether the object starting at i within s, the session or its
+-- metadata, holds the key the session was rotated to, along the tokens of path.
+local function rotated(s, i, path)
+	for k = 1, #path do
+		i = skipws(s, i)
+		if string.sub(s, i, i) ~= '{' then
+			return false
+		end
+		i = child(s, i, path[k])
+		if not i then
+			return false
+		end
+	end
+	return true
+end
+
+-- edit replaces the value path refers to within s with what fn returns for
+-- it, or adds it when its parent is there, as the add operation of JSON Patch
+-- does. It returns the outcome, s edited and the value written.
+local function edit(s, path, fn)
+	if #path == 0 then
+		local outcome, value = fn(s)
+		return outcome, value, value
+	end
+	local i = skipws(s, 1)
+	for k = 1, #path do
+		local open = string.sub(s, i, i)
+		if open ~= '{' and open ~= '[' then
+			return 'notfound'
+		end
+		local token = path[k]
+		local from, to, entries = child(s, i, token)
+		if k < #path then
+			if not from then
+				return 'notfound'
+			end
+			i = from
+		elseif from then
+			local outcome, value = fn(string.sub(s, from, to - 1))
+			if outcome ~= 'ok' then
+				return outcome
+			end
+			return 'ok', string.sub(s, 1, from - 1) .. value .. string.sub(s, to), value
+		elseif open == '[' and token ~= '-' and token ~= tostring(entries) then
+			return 'notfound'
+		else
+			local outcome, value = fn(nil)
+			if outcome ~= 'ok' then
+				return outcome
+			end
+			local entry = value
+			if open == '{' then
+				entry = cjson.encode(token) .. ':' .. value
+			end
+			if entries > 0 then
+				entry = ',' .. entry
+			end
+			return 'ok', string.sub(s, 1, to - 1) .. entry .. string.sub(s, to), value
+		end
+	end
+end
+
+local key, hash, metadataField = KEYS[1], ARGV[1] == 'hash', ARGV[2]
+local delta, updated, max = tonumber(ARGV[3]), ARGV[4], tonumber(ARGV[5])
+local path = {}
+for k = 6, #ARGV do
+	path[#path + 1] = ARGV[k]
+end
+
+local function add(current)
+	local counter = 0
+	if current then
+		counter = tonumber(current)
+		if not string.find(current, '^%-?%d') or not counter or counter ~= math.floor(counter) or math.abs(counter) > max then
+			return 'notinteger'
+		end
+	end
+	counter = counter + delta
+	if math.abs(counter) > max then
+		return 'range'
+	end
+	if counter == 0 then
+		counter = 0
+	end
+	return 'ok', string.format('%.0f', counter)
+end
+
+local function count(current)
+	return 'ok', string.format('%.0f', (tonumber(current) or 0) + 1)
+end
+
+local function stamp()
+	return 'ok', updated
+end
+
+if hash then
+	local metadata = redis.call('HGET', key, metadataField)
+	if not metadata then
+		if redis.call('EXISTS', key) == 0 then
+			return {'missing'}
+		end
+		return {'nometa'}
+	end
+	if rotated(metadata, 1, {'rotated'}) then
+		return {'rotated'}
+	end
+	local outcome
+	outcome, metadata = edit(metadata, {'version'}, count)
+	if outcome ~= 'ok' then
+		return {'nometa'}
+	end
+	outcome, metadata = edit(metadata, {'updated'}, stamp)
+	if outcome ~= 'ok' then
+		return {'nometa'}
+	end
+
+	local field = table.remove(path, 1)
+	local value = redis.call('HGET', key, field) or nil
+	if not value and #path > 0 then
+		return {'notfound'}
+	end
+	local counter
+	outcome, value, counter = edit(value, path, add)
+	if outcome ~= 'ok' then
+		return {outcome}
+	end
+	redis.call('HSET', key, field, value)
+	redis.call('HSET', key, metadataField, metadata)
+	return {'ok', counter}
+end
+
+local s = redis.call('GET', key)
+if not s then
+	return {'missing'}
+end
+if rotated(s, 1, {metadataField, 'rotated'}) then
+	return {'rotated'}
+end
+local outcome, counter
+outcome, s = edit(s, {metadataField, 'version'}, count)
+if outcome ~= 'ok' then
+	return {'nometa'}
+end
+outcome, s = edit(s, {metadataField, 'updated'}, stamp)
+if outcome ~= 'ok' then
+	return {'nometa'}
+end
+outcome, s, counter = edit(s, path, add)
+if outcome ~= 'ok' then
+	return {outcome}
+end
+
+local ttl = redis.call('PTTL', key)
+if ttl > 0 then
+	redis.call('SET', key, s, 'PX', ttl)
+else
+	redis.call('SET', key, s)
+end
+return {'ok', counter}
+`)
+
+// Increment runs incrementScript, which keeps the time the session has left.
+func (c *redisCache) Increment(ctx context.Context, key string, path session.Pointer, delta int64, updatedAt time.Time) (int64, error) {
+
+	args := []interface{}{c.config.Storage, metadataField, delta, unixMilli(updatedAt), int64(session.MaxCounter)}
+	for _, token := range path {
+		args = append(args, token)
+	}
+
+	c.writes.record(key)
+	res, err := incrementScript.Run(ctx, c.client, []string{key}, args...).Slice()
+	if err != nil {
+		return 0, err
+	}
+
+	outcome, _ := res[0].(string)
+	switch outcome {
+	case "ok":
+		counter, _ := res[1].(string)
+		return strconv.ParseInt(counter, 10, 64)
+	case "missing":
+		return 0, session.ErrNotFound
+	case "nometa":
+		return 0, fmt.Errorf("%w: session is stored without metadata", session.ErrNotSupported)
+	case "rotated":
+		return 0, fmt.Errorf("%w: session was rotated to another key", session.ErrReadOnly)
+	case "notfound":
+		return 0, fmt.Errorf("%w: no value at '%s'", session.ErrNotFound, path)
+	case "notinteger":
+		return 0, fmt.Errorf("%w: value at '%s' is not an integer", session.ErrInvalid, path)
+	case "range":
+		return 0, fmt.Errorf("%w: value at '%s' would be out of range", session.ErrInvalid, path)
+	}
+	return 0, fmt.Errorf("unexpected outcome %v when incrementing counter of session", res)
+}
@@ -0,0 +1,173 @@
+package adapters
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldIncrementCountersInRedis(t *testing.T) {
+
+	for _, storage := range []string{RedisStringStorage, RedisHashStorage} {
+		setup()
+
+		cache.config.Storage = storage
+		cache.expires = time.Minute
+
+		err := cache.Set(ctx, "someCounterKey", `{"stats":{"failed":2,"ratio":0.5},"list":[1],"empty":{},"big":12345678901234567,"__session":{"created":1,"updated":1,"version":3}}`)
+		assert.Nil(t, err, "Expect err is nil when storing session value")
+		err = cache.Set(ctx, "someLegacyKey", `{"views":1}`)
+		assert.Nil(t, err, "Expect err is nil when storing session value")
+		err = cache.Set(ctx, "someRotatedKey", `{"views":1,"__session":{"created":1,"updated":1,"version":3,"rotated":"someCounterKey","grace":2}}`)
+		assert.Nil(t, err, "Expect err is nil when storing session value")
+
+		updatedAt := time.UnixMilli(1_000_000)
+		increments := []struct {
+			scenario    string
+			key         string
+			path        session.Pointer
+			delta       int64
+			expectedVal int64
+			expectedErr error
+		}{
+			{
+				scenario:    "Should decrement a nested counter",
+				key:         "someCounterKey",
+				path:        session.Pointer{"stats", "failed"},
+				delta:       -1,
+				expectedVal: 1,
+			},
+			{
+				scenario:    "Should count a missing counter from zero",
+				key:         "someCounterKey",
+				path:        session.Pointer{"views"},
+				delta:       3,
+				expectedVal: 3,
+			},
+			{
+				scenario:    "Should add a counter to an empty object",
+				key:         "someCounterKey",
+				path:        session.Pointer{"empty", "views"},
+				delta:       1,
+				expectedVal: 1,
+			},
+			{
+				scenario:    "Should append a counter to an array",
+				key:         "someCounterKey",
+				path:        session.Pointer{"list", "-"},
+				delta:       5,
+				expectedVal: 5,
+			},
+			{
+				scenario:    "Should increment an element of an array",
+				key:         "someCounterKey",
+				path:        session.Pointer{"list", "0"},
+				delta:       1,
+				expectedVal: 2,
+			},
+			{
+				scenario:    "Should not increment a number that is not an integer",
+				key:         "someCounterKey",
+				path:        session.Pointer{"stats", "ratio"},
+				delta:       1,
+				expectedErr: session.ErrInvalid,
+			},
+			{
+				scenario:    "Should not increment a counter out of range",
+				key:         "someCounterKey",
+				path:        session.Pointer{"views"},
+				delta:       session.MaxCounter,
+				expectedErr: session.ErrInvalid,
+			},
+			{
+				scenario:    "Should not increment a counter whose parent does not exist",
+				key:         "someCounterKey",
+				path:        session.Pointer{"missing", "views"},
+				delta:       1,
+				expectedErr: session.ErrNotFound,
+			},
+			{
+				scenario:    "Should not append past the end of an array",
+				key:         "someCounterKey",
+				path:        session.Pointer{"list", "5"},
+				delta:       1,
+				expectedErr: session.ErrNotFound,
+			},
+			{
+				scenario:    "Should not increment a counter of a missing session",
+				key:         "thisSessionKeyShouldNotExist",
+				path:        session.Pointer{"views"},
+				delta:       1,
+				expectedErr: session.ErrNotFound,
+			},
+			{
+				scenario:    "Should leave sessions without metadata to be patched",
+				key:         "someLegacyKey",
+				path:        session.Pointer{"views"},
+				delta:       1,
+				expectedErr: session.ErrNotSupported,
+			},
+			{
+				scenario:    "Should not increment a counter of a rotated session",
+				key:         "someRotatedKey",
+				path:        session.Pointer{"views"},
+				delta:       1,
+				expectedErr: session.ErrReadOnly,
+			},
+		}
+
+		for _, test := range increments {
+			val, err := cache.Increment(ctx, test.key, test.path, test.delta, updatedAt)
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr, "%s with %s storage", test.scenario, storage)
+			} else {
+				assert.Nil(t, err, "%s with %s storage", test.scenario, storage)
+				assert.Equal(t, test.expectedVal, val, "%s with %s storage", test.scenario, storage)
+			}
+		}
+
+		val, err := cache.Get(ctx, "someCounterKey")
+		assert.Nil(t, err, "Expect err is nil when retrieving session value")
+		assert.JSONEq(t, `{"stats":{"failed":1,"ratio":0.5},"views":3,"list":[2,5],"empty":{"views":1},"big":12345678901234567,"__session":{"created":1,"updated":1000000,"version":8}}`, val.(string), "Expect counters to be incremented with %s storage", storage)
+		assert.Contains(t, val.(string), "12345678901234567", "Expect other numbers to be stored exactly as they were with %s storage", storage)
+		assert.Equal(t, time.Minute, redisServer.TTL("someCounterKey"), "Expect session to keep its expiry with %s storage", storage)
+
+		teardown()
+	}
+}
+
+func TestShouldNotLoseConcurrentIncrementsInRedis(t *testing.T) {
+
+	for _, storage := range []string{RedisStringStorage, RedisHashStorage} {
+		setup()
+
+		cache.config.Storage = storage
+		cache.expires = time.Minute
+		repo := NewSessionRepository(&cache, time.Minute, session.LifetimePolicy{})
+
+		created := &session.Session{ID: "someCounterKey", Data: session.Data{"user": "someUser"}}
+		err := repo.Set(ctx, created, session.SetOptions{})
+		assert.Nil(t, err, "Expect err is nil when storing a session")
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := repo.Increment(ctx, "someCounterKey", session.Pointer{"views"}, 1)
+				assert.Nil(t, err, "Expect err is nil when incrementing a counter concurrently with %s storage", storage)
+			}()
+		}
+		wg.Wait()
+
+		stored, err := repo.Get(ctx, "someCounterKey")
+		assert.Nil(t, err, "Expect err is nil when reading a session")
+		assert.Equal(t, float64(50), stored.Data["views"], "Expect no concurrent increment to be lost with %s storage", storage)
+		assert.Equal(t, int64(51), stored.Version, "Expect every increment to count as a write with %s storage", storage)
+
+		teardown()
+	}
+}
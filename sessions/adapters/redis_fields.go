@@ -0,0 +1,225 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+)
+
+func (c *redisCache) hashStorage() bool {
+	return c.config.Storage == RedisHashStorage
+}
+
+func (c *redisCache) GetFields(ctx context.Context, key string, fields []string) (map[string]interface{}, error) {
+
+	if !c.hashStorage() {
+		var val string
+		err := c.readFrom(key, func(client redis.Cmdable) error {
+			var err error
+			val, err = c.read(ctx, client, key)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		return pickFields(val, fields)
+	}
+
+	var values *redis.SliceCmd
+	err := c.readFrom(key, func(client redis.Cmdable) error {
+		var exists *redis.IntCmd
+		_, err := client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			exists = pipe.Exists(ctx, key)
+			values = pipe.HMGet(ctx, key, fields...)
+			return nil
+		})
+		if err == nil && exists.Val() == 0 {
+			return session.ErrNotFound
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{}, len(fields))
+	for i, raw := range values.Val() {
+		str, ok := raw.(string)
+		if !ok {
+			continue
+		}
+
+		var value interface{}
+		if err := json.Unmarshal([]byte(str), &value); err != nil {
+			return nil, fmt.Errorf("field '%s' of session is not valid JSON: %w", fields[i], err)
+		}
+		result[fields[i]] = value
+	}
+	return result, nil
+}
+
+func (c *redisCache) SetFields(ctx context.Context, key string, values map[string]interface{}) error {
+
+	c.writes.record(key)
+
+	if c.hashStorage() {
+		encoded, err := encodeHashFields(values)
+		if err != nil {
+			return err
+		}
+
+		return c.watch(ctx, key, func(tx *redis.Tx) error {
+			exists, err := tx.Exists(ctx, key).Result()
+			if err != nil {
+				return err
+			}
+			if exists == 0 {
+				return session.ErrNotFound
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.HMSet(ctx, key, encoded)
+				return nil
+			})
+			return err
+		})
+	}
+
+	return c.watch(ctx, key, func(tx *redis.Tx) error {
+		val, err := tx.Get(ctx, key).Result()
+		if err == redis.Nil {
+			return session.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		merged, err := mergeFields(val, values)
+		if err != nil {
+			return err
+		}
+
+		ttl, err := tx.PTTL(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if ttl < 0 {
+			ttl = 0
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, merged, ttl)
+			return nil
+		})
+		return err
+	})
+}
+
+// setHash replaces the hash stored at key with the top level fields of value.
+// An empty session leaves no hash behind, as Redis does not keep empty hashes.
+func (c *redisCache) setHash(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+
+	fields, err := hashFields(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		if len(fields) > 0 {
+			pipe.HMSet(ctx, key, fields)
+			if ttl > 0 {
+				pipe.PExpire(ctx, key, ttl)
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+func (c *redisCache) setHashIfAbsent(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+
+	fields, err := hashFields(value)
+	if err != nil {
+		return false, err
+	}
+
+	stored := false
+	err = c.watch(ctx, key, func(tx *redis.Tx) error {
+		exists, err := tx.Exists(ctx, key).Result()
+		if err != nil || exists == 1 {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			if len(fields) > 0 {
+				pipe.HMSet(ctx, key, fields)
+				pipe.PExpire(ctx, key, ttl)
+			}
+			return nil
+		})
+		stored = err == nil
+		return err
+	})
+	return stored, err
+}
+
+func getHash(ctx context.Context, client redis.Cmdable, key string) (string, error) {
+
+	fields, err := client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return "", err
+	}
+	if len(fields) == 0 {
+		return "", session.ErrNotFound
+	}
+
+	raw := make(map[string]json.RawMessage, len(fields))
+	for field, value := range fields {
+		raw[field] = json.RawMessage(value)
+	}
+
+	val, err := json.Marshal(raw)
+	if err != nil {
+		return "", err
+	}
+	return string(val), nil
+}
+
+// hashFields splits a JSON object session value into its top level fields,
+// each one holding the JSON encoding of its value.
+func hashFields(value interface{}) (map[string]interface{}, error) {
+
+	val, err := encodeValue(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(val), &raw); err != nil {
+		return nil, fmt.Errorf("hash storage requires JSON object sessions: %w", err)
+	}
+
+	fields := make(map[string]interface{}, len(raw))
+	for field, value := range raw {
+		fields[field] = string(value)
+	}
+	return fields, nil
+}
+
+func encodeHashFields(values map[string]interface{}) (map[string]interface{}, error) {
+
+	fields := make(map[string]interface{}, len(values))
+	for field, value := range values {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		fields[field] = string(encoded)
+	}
+	return fields, nil
+}
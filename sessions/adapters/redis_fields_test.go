@@ -0,0 +1,91 @@
+package adapters
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldStoreDataAsRedisHash(t *testing.T) {
+	setup()
+	defer teardown()
+
+	cache.config.Storage = RedisHashStorage
+	cache.expires = time.Minute
+
+	err := cache.Set(ctx, "someHashKey", `{"age":35,"data":"someData","props":{"someProp":"someValue"}}`)
+	assert.Nil(t, err, "Expect err is nil when storing session value")
+
+	assert.Equal(t, "35", redisServer.HGet("someHashKey", "age"))
+	assert.Equal(t, `"someData"`, redisServer.HGet("someHashKey", "data"))
+	assert.Equal(t, `{"someProp":"someValue"}`, redisServer.HGet("someHashKey", "props"))
+	assert.Equal(t, time.Minute, redisServer.TTL("someHashKey"))
+
+	val, err := cache.Get(ctx, "someHashKey")
+	assert.Nil(t, err, "Expect err is nil when retrieving session value")
+	assert.JSONEq(t, `{"age":35,"data":"someData","props":{"someProp":"someValue"}}`, val.(string))
+
+	_, err = cache.Get(ctx, "thisSessionKeyShouldNotExist")
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect not found error for non existing session")
+}
+
+func TestShouldGetAndSetFieldsInRedis(t *testing.T) {
+
+	for _, storage := range []string{RedisStringStorage, RedisHashStorage} {
+		setup()
+
+		cache.config.Storage = storage
+		cache.expires = time.Minute
+
+		err := cache.Set(ctx, "someFieldsKey", `{"age":35,"data":"someData","props":{"someProp":"someValue"}}`)
+		assert.Nil(t, err, "Expect err is nil when storing session value")
+
+		err = cache.SetFields(ctx, "someFieldsKey", map[string]interface{}{
+			"age":   36,
+			"props": map[string]interface{}{"other": true},
+		})
+		assert.Nil(t, err, "Expect err is nil when setting session fields with %s storage", storage)
+
+		fields, err := cache.GetFields(ctx, "someFieldsKey", []string{"age", "props", "missing"})
+		assert.Nil(t, err, "Expect err is nil when getting session fields with %s storage", storage)
+		assert.Equal(t, map[string]interface{}{
+			"age":   float64(36),
+			"props": map[string]interface{}{"other": true},
+		}, fields)
+
+		val, _ := cache.Get(ctx, "someFieldsKey")
+		assert.JSONEq(t, `{"age":36,"data":"someData","props":{"other":true}}`, val.(string))
+
+		ttl, _ := cache.TTL(ctx, "someFieldsKey")
+		assert.Equal(t, time.Minute, ttl, "Expect setting fields to keep the session ttl with %s storage", storage)
+
+		err = cache.SetFields(ctx, "thisSessionKeyShouldNotExist", map[string]interface{}{"age": 1})
+		assert.ErrorIs(t, err, session.ErrNotFound, "Expect not found error with %s storage", storage)
+
+		_, err = cache.GetFields(ctx, "thisSessionKeyShouldNotExist", []string{"age"})
+		assert.ErrorIs(t, err, session.ErrNotFound, "Expect not found error with %s storage", storage)
+
+		teardown()
+	}
+}
+
+func TestShouldGetAndSetFieldsInMemory(t *testing.T) {
+	t.Parallel()
+
+	memory := newMemoryCache(1, time.Minute, 0, 0)
+	defer memory.Close()
+
+	memory.Set(ctx, "someFieldsKey", `{"age":35,"data":"someData"}`)
+
+	err := memory.SetFields(ctx, "someFieldsKey", map[string]interface{}{"age": 36})
+	assert.Nil(t, err, "Expect err is nil when setting session fields")
+
+	fields, err := memory.GetFields(ctx, "someFieldsKey", []string{"age"})
+	assert.Nil(t, err, "Expect err is nil when getting session fields")
+	assert.Equal(t, map[string]interface{}{"age": float64(36)}, fields)
+
+	err = memory.SetFields(ctx, "thisSessionKeyShouldNotExist", map[string]interface{}{"age": 1})
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect not found error for non existing session")
+}
@@ -0,0 +1,44 @@
+package adapters
+
+import (
+	"context"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type redisInvalidator struct {
+	client  redis.UniversalClient
+	channel string
+}
+
+// NewRedisInvalidator returns an Invalidator broadcasting session keys over the
+// given Redis pub/sub channel.
+func NewRedisInvalidator(config RedisConfig, channel string) Invalidator {
+	if config.Mode == RedisSharded && len(config.Addrs) > 0 {
+		// Invalidations only need one instance every replica listens to.
+		config.Mode, config.Addrs = RedisStandalone, config.Addrs[:1]
+	}
+
+	client, err := newRedisClient(config)
+	if err != nil {
+		panic(err)
+	}
+	return &redisInvalidator{client, channel}
+}
+
+func (i *redisInvalidator) Publish(ctx context.Context, key string) error {
+	return i.client.Publish(ctx, i.channel, key).Err()
+}
+
+// Subscribe listens to the channel in the background. The subscription is
+// re-established by the client after a disconnection, invalidations published
+// in between are lost and local copies only go away when they expire.
+func (i *redisInvalidator) Subscribe(onInvalidate func(key string)) {
+	pubsub := i.client.Subscribe(context.Background(), i.channel)
+
+	go func() {
+		for msg := range pubsub.Channel() {
+			onInvalidate(msg.Payload)
+		}
+	}()
+}
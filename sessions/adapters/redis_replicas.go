@@ -0,0 +1,113 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+)
+
+func newRedisReplicas(config RedisConfig) ([]*redis.Client, error) {
+
+	if len(config.ReplicaAddrs) == 0 {
+		return nil, nil
+	}
+	if config.Mode != "" && config.Mode != RedisStandalone {
+		return nil, fmt.Errorf("redis replicas are only supported in standalone mode")
+	}
+
+	replicas := make([]*redis.Client, 0, len(config.ReplicaAddrs))
+	for _, addr := range config.ReplicaAddrs {
+		replicas = append(replicas, redis.NewClient(&redis.Options{
+			Addr:     addr,
+			Password: config.Password,
+			DB:       config.DB,
+		}))
+	}
+	return replicas, nil
+}
+
+// replica returns the replica key is read from, picking them in turn. It
+// returns nil when there are none or key has just been written by this
+// instance, as the replicas may not have caught up with it yet.
+func (c *redisCache) replica(key string) *redis.Client {
+
+	if len(c.replicas) == 0 || c.writes.recent(key) {
+		return nil
+	}
+	n := atomic.AddUint32(&c.nextReplica, 1)
+	return c.replicas[int(n)%len(c.replicas)]
+}
+
+// readFrom runs read against a replica, or against the primary when key is
+// not read from replicas or the replica fails, so a replica going down does
+// not fail session reads.
+func (c *redisCache) readFrom(key string, read func(client redis.Cmdable) error) error {
+
+	replica := c.replica(key)
+	if replica == nil {
+		return read(c.client)
+	}
+
+	err := read(replica)
+	if err == nil || errors.Is(err, session.ErrNotFound) {
+		return err
+	}
+	return read(c.client)
+}
+
+// recentWrites tracks the keys written within the last window. A nil
+// recentWrites tracks nothing.
+type recentWrites struct {
+	mu        sync.Mutex
+	window    time.Duration
+	until     map[string]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func newRecentWrites(window time.Duration) *recentWrites {
+	if window <= 0 {
+		return nil
+	}
+	return &recentWrites{window: window, until: make(map[string]time.Time), now: time.Now}
+}
+
+func (w *recentWrites) record(key string) {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.now()
+	w.until[key] = now.Add(w.window)
+
+	// Expired keys are dropped once per window, so tracking costs no more
+	// than the keys written within the last two windows.
+	if now.Sub(w.lastSweep) >= w.window {
+		for k, until := range w.until {
+			if !now.Before(until) {
+				delete(w.until, k)
+			}
+		}
+		w.lastSweep = now
+	}
+}
+
+func (w *recentWrites) recent(key string) bool {
+	if w == nil {
+		return false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	until, ok := w.until[key]
+	return ok && w.now().Before(until)
+}
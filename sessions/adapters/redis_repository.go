@@ -2,32 +2,210 @@ package adapters
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
 )
 
+const (
+	RedisStandalone = "standalone"
+	RedisSentinel   = "sentinel"
+	RedisCluster    = "cluster"
+	RedisSharded    = "sharded"
+)
+
+const (
+	RedisStringStorage = "string"
+	RedisHashStorage   = "hash"
+)
+
+// redisMaxTxRetries bounds how many times an optimistic transaction is retried
+// when the watched session changes before it commits.
+const redisMaxTxRetries = 10
+
+// RedisConfig describes how to reach Redis. Addrs holds the server address in
+// standalone mode, the sentinel addresses in sentinel mode, the seed nodes in
+// cluster mode and the independent instances sessions are spread over in
+// sharded mode. Storage selects whether sessions are kept as JSON strings
+// or as hashes with one field per top level session field.
+//
+// In standalone mode sessions can be read from the replicas at ReplicaAddrs,
+// while they are always written to the primary. A session written by this
+// instance is read back from the primary for ReadYourWrites, so that its
+// caller does not miss a write the replicas have not caught up with yet.
+type RedisConfig struct {
+	Mode             string
+	Addrs            []string
+	ReplicaAddrs     []string
+	ReadYourWrites   time.Duration
+	MasterName       string
+	SentinelPassword string
+	DB               int
+	Password         string
+	Storage          string
+}
+
 type redisCache struct {
-	host     string
-	db       int
-	password string
-	expires  time.Duration
-	client   *redis.Client
-}
-
-func NewRedisCache(host string, db int, password string, expires time.Duration) session.Repository {
-	client := redis.NewClient(&redis.Options{
-		Addr:     host,
-		Password: password,
-		DB:       db,
-	})
-	return &redisCache{host, db, password, expires, client}
+	config      RedisConfig
+	expires     time.Duration
+	client      redis.UniversalClient
+	replicas    []*redis.Client
+	nextReplica uint32
+	writes      *recentWrites
+}
+
+func init() {
+	Register("redis", redisBackend)
+}
+
+func redisBackend(config Config) (Store, error) {
+	redisConfig, err := RedisConfigFrom(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if redisConfig.Mode != RedisSharded {
+		return newRedisCache(redisConfig, config.Expires)
+	}
+
+	sharded, err := newShardedRedisCache(redisConfig, config.List("REBALANCE_FROM", ""), config.Expires)
+	if err != nil {
+		return nil, err
+	}
+	if rebalance, _ := strconv.ParseBool(config.String("REBALANCE", "false")); rebalance {
+		go func() {
+			if err := sharded.Rebalance(context.Background()); err != nil {
+				logrus.WithError(err).Error("Failed to rebalance sessions")
+			}
+		}()
+	}
+	return sharded, nil
+}
+
+// RedisConfigFrom reads the Redis settings from config.
+func RedisConfigFrom(config Config) (RedisConfig, error) {
+	addr := fmt.Sprintf("%s:%s", config.String("HOST", "localhost"), config.String("PORT", "6379"))
+
+	db, err := config.Int("ID", 0)
+	if err != nil {
+		return RedisConfig{}, err
+	}
+
+	readYourWrites, err := config.Int("READ_YOUR_WRITES_MS", 0)
+	if err != nil {
+		return RedisConfig{}, err
+	}
+
+	return RedisConfig{
+		Mode:             config.String("MODE", RedisStandalone),
+		Addrs:            config.List("ADDRS", addr),
+		ReplicaAddrs:     config.List("REPLICA_ADDRS", ""),
+		ReadYourWrites:   time.Duration(readYourWrites) * time.Millisecond,
+		MasterName:       config.String("SENTINEL_MASTER", ""),
+		SentinelPassword: config.String("SENTINEL_PASSWORD", ""),
+		DB:               db,
+		Password:         config.String("PASSWORD", ""),
+		Storage:          config.String("STORAGE", RedisStringStorage),
+	}, nil
+}
+
+func NewRedisCache(config RedisConfig, expires time.Duration) Store {
+	var c Store
+	var err error
+	if config.Mode == RedisSharded {
+		c, err = newShardedRedisCache(config, nil, expires)
+	} else {
+		c, err = newRedisCache(config, expires)
+	}
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func newRedisCache(config RedisConfig, expires time.Duration) (*redisCache, error) {
+	switch config.Storage {
+	case "", RedisStringStorage, RedisHashStorage:
+	default:
+		return nil, fmt.Errorf("redis storage '%s' not supported", config.Storage)
+	}
+
+	replicas, err := newRedisReplicas(config)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := newRedisClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &redisCache{
+		config:   config,
+		expires:  expires,
+		client:   client,
+		replicas: replicas,
+		writes:   newRecentWrites(config.ReadYourWrites),
+	}, nil
+}
+
+func newRedisClient(config RedisConfig) (redis.UniversalClient, error) {
+
+	if len(config.Addrs) == 0 {
+		return nil, fmt.Errorf("no redis addresses configured")
+	}
+
+	switch config.Mode {
+	case "", RedisStandalone:
+		return redis.NewClient(&redis.Options{
+			Addr:     config.Addrs[0],
+			Password: config.Password,
+			DB:       config.DB,
+		}), nil
+	case RedisSentinel:
+		if config.MasterName == "" {
+			return nil, fmt.Errorf("redis sentinel mode requires a master name")
+		}
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       config.MasterName,
+			SentinelAddrs:    config.Addrs,
+			SentinelPassword: config.SentinelPassword,
+			Password:         config.Password,
+			DB:               config.DB,
+		}), nil
+	case RedisSharded:
+		return nil, fmt.Errorf("redis sharded mode uses a client per instance")
+	case RedisCluster:
+		if config.DB != 0 {
+			return nil, fmt.Errorf("redis cluster mode only supports db 0, got %d", config.DB)
+		}
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:    config.Addrs,
+			Password: config.Password,
+		}), nil
+	default:
+		return nil, fmt.Errorf("redis mode '%s' not supported", config.Mode)
+	}
 }
 
 func (c *redisCache) Set(ctx context.Context, key string, value interface{}) error {
+	return c.SetWithTTL(ctx, key, value, c.expires)
+}
+
+func (c *redisCache) SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
 
-	_, err := c.client.Set(ctx, key, value, c.expires).Result()
+	c.writes.record(key)
+
+	if c.hashStorage() {
+		return c.setHash(ctx, key, value, ttl)
+	}
+
+	_, err := c.client.Set(ctx, key, value, ttl).Result()
 	if err != nil {
 		return err
 	}
@@ -36,12 +214,207 @@ func (c *redisCache) Set(ctx context.Context, key string, value interface{}) err
 
 func (c *redisCache) Get(ctx context.Context, key string) (interface{}, error) {
 
-	val, err := c.client.Get(ctx, key).Result()
+	var val string
+	err := c.readFrom(key, func(client redis.Cmdable) error {
+		var err error
+		val, err = c.read(ctx, client, key)
+		return err
+	})
 	return val, err
 }
 
-func (c redisCache) Delete(ctx context.Context, key string) (int64, error) {
+// GetPrimary reads the session from the primary, never from a replica.
+func (c *redisCache) GetPrimary(ctx context.Context, key string) (interface{}, error) {
+	return c.read(ctx, c.client, key)
+}
+
+func (c *redisCache) Delete(ctx context.Context, key string) (int64, error) {
+
+	c.writes.record(key)
 
 	val, err := c.client.Del(ctx, key).Result()
 	return val, err
 }
+
+func (c *redisCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+
+	ttl, err := c.client.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	// PTTL answers -2 for missing keys and -1 for keys without expiry.
+	if ttl == -2 {
+		return 0, session.ErrNotFound
+	}
+	return ttl, nil
+}
+
+func (c *redisCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+
+	ok, err := c.client.PExpire(ctx, key, ttl).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return session.ErrNotFound
+	}
+	return nil
+}
+
+func (c *redisCache) SetIfAbsent(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+
+	if ttl == 0 {
+		ttl = c.expires
+	}
+
+	c.writes.record(key)
+	if c.hashStorage() {
+		return c.setHashIfAbsent(ctx, key, value, ttl)
+	}
+	return c.client.SetNX(ctx, key, value, ttl).Result()
+}
+
+func (c *redisCache) Replace(ctx context.Context, key string, old, new interface{}, ttl time.Duration) (bool, error) {
+
+	if ttl == 0 {
+		ttl = c.expires
+	}
+	return c.compareAndSet(ctx, key, old, new, ttl)
+}
+
+// compareAndSet stores new at key for ttl while it holds old, in an optimistic
+// transaction.
+func (c *redisCache) compareAndSet(ctx context.Context, key string, old, new interface{}, ttl time.Duration) (bool, error) {
+
+	oldVal, err := encodeValue(old)
+	if err != nil {
+		return false, err
+	}
+
+	c.writes.record(key)
+	swapped := false
+	err = c.watch(ctx, key, func(tx *redis.Tx) error {
+		val, err := c.read(ctx, tx, key)
+		if err == session.ErrNotFound {
+			return nil
+		}
+		if err != nil || val != oldVal {
+			return err
+		}
+
+		var fields map[string]interface{}
+		if c.hashStorage() {
+			if fields, err = hashFields(new); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			if !c.hashStorage() {
+				pipe.Set(ctx, key, new, ttl)
+				return nil
+			}
+			pipe.Del(ctx, key)
+			if len(fields) > 0 {
+				pipe.HMSet(ctx, key, fields)
+				if ttl > 0 {
+					pipe.PExpire(ctx, key, ttl)
+				}
+			}
+			return nil
+		})
+		swapped = err == nil
+		return err
+	})
+	return swapped, err
+}
+
+func (c *redisCache) DeleteIf(ctx context.Context, key string, old interface{}) (bool, error) {
+
+	oldVal, err := encodeValue(old)
+	if err != nil {
+		return false, err
+	}
+	return c.deleteIf(ctx, key, oldVal)
+}
+
+// deleteIf deletes the session at key while it still holds val.
+func (c *redisCache) deleteIf(ctx context.Context, key string, val string) (bool, error) {
+
+	c.writes.record(key)
+
+	deleted := false
+	err := c.watch(ctx, key, func(tx *redis.Tx) error {
+		current, err := c.read(ctx, tx, key)
+		if err == session.ErrNotFound {
+			return nil
+		}
+		if err != nil || current != val {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, key)
+			return nil
+		})
+		deleted = err == nil
+		return err
+	})
+	return deleted, err
+}
+
+// read returns the session at key as stored by Set, whatever the storage.
+func (c *redisCache) read(ctx context.Context, client redis.Cmdable, key string) (string, error) {
+
+	if c.hashStorage() {
+		return getHash(ctx, client, key)
+	}
+
+	val, err := client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return "", session.ErrNotFound
+	}
+	return val, err
+}
+
+// Scan walks every session key of the database, or of every master node in
+// cluster mode, leaving out the sets of subjects.
+func (c *redisCache) Scan(ctx context.Context, onKey func(key string) error) error {
+
+	onSession := func(key string) error {
+		if strings.HasPrefix(key, subjectIndexPrefix) {
+			return nil
+		}
+		return onKey(key)
+	}
+
+	if cluster, ok := c.client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return scanKeys(ctx, node, "", onSession)
+		})
+	}
+	return scanKeys(ctx, c.client, "", onSession)
+}
+
+// scanKeys walks the keys matching match, or every key when it is empty.
+func scanKeys(ctx context.Context, client redis.Cmdable, match string, onKey func(key string) error) error {
+	iter := client.Scan(ctx, 0, match, 500).Iterator()
+	for iter.Next(ctx) {
+		if err := onKey(iter.Val()); err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
+
+// watch runs fn in an optimistic transaction watching key, retrying it when
+// key is modified before the transaction commits.
+func (c *redisCache) watch(ctx context.Context, key string, fn func(tx *redis.Tx) error) error {
+	for i := 0; i < redisMaxTxRetries; i++ {
+		err := c.client.Watch(ctx, fn, key)
+		if err != redis.TxFailedErr {
+			return err
+		}
+	}
+	return redis.TxFailedErr
+}
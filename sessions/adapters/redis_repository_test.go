@@ -3,9 +3,11 @@ package adapters
 import (
 	"context"
 	"testing"
+	"time"
 
-	"github.com/alicebob/miniredis"
+	"github.com/alicebob/miniredis/v2"
 	"github.com/go-redis/redis/v8"
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -84,3 +86,273 @@ func setup() {
 func teardown() {
 	redisServer.Close()
 }
+
+func TestNewRedisClientShouldBuildClientForMode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario        string
+		config          RedisConfig
+		isErrorExpected bool
+		expectedClient  interface{}
+	}{
+		{
+			scenario:       "Should build a single node client by default",
+			config:         RedisConfig{Addrs: []string{"localhost:6379"}},
+			expectedClient: &redis.Client{},
+		},
+		{
+			scenario:       "Should build a failover client in sentinel mode",
+			config:         RedisConfig{Mode: RedisSentinel, MasterName: "master", Addrs: []string{"s1:26379", "s2:26379"}},
+			expectedClient: &redis.Client{},
+		},
+		{
+			scenario:       "Should build a cluster client in cluster mode",
+			config:         RedisConfig{Mode: RedisCluster, Addrs: []string{"n1:6379", "n2:6379"}},
+			expectedClient: &redis.ClusterClient{},
+		},
+		{
+			scenario:        "Should fail if no addresses are given",
+			config:          RedisConfig{Mode: RedisStandalone},
+			isErrorExpected: true,
+		},
+		{
+			scenario:        "Should fail if sentinel mode has no master name",
+			config:          RedisConfig{Mode: RedisSentinel, Addrs: []string{"s1:26379"}},
+			isErrorExpected: true,
+		},
+		{
+			scenario:        "Should fail if cluster mode selects a db",
+			config:          RedisConfig{Mode: RedisCluster, Addrs: []string{"n1:6379"}, DB: 1},
+			isErrorExpected: true,
+		},
+		{
+			scenario:        "Should fail if a single client is asked for sharded mode",
+			config:          RedisConfig{Mode: RedisSharded, Addrs: []string{"r1:6379", "r2:6379"}},
+			isErrorExpected: true,
+		},
+		{
+			scenario:        "Should fail if mode is unknown",
+			config:          RedisConfig{Mode: "unknown", Addrs: []string{"n1:6379"}},
+			isErrorExpected: true,
+		},
+	}
+
+	for _, test := range tests {
+		client, err := newRedisClient(test.config)
+
+		if test.isErrorExpected {
+			assert.NotNil(t, err, test.scenario)
+			continue
+		}
+
+		assert.Nil(t, err, test.scenario)
+		assert.IsType(t, test.expectedClient, client, test.scenario)
+		client.Close()
+	}
+}
+
+func TestShouldReturnRemainingTTLFromRedis(t *testing.T) {
+	setup()
+	defer teardown()
+
+	cache.expires = time.Minute
+	cache.Set(ctx, "someTTLKey", "value")
+
+	ttl, err := cache.TTL(ctx, "someTTLKey")
+	assert.Nil(t, err, "Expect err is nil when reading the ttl of a session")
+	assert.Equal(t, time.Minute, ttl, "Expect remaining ttl of the session")
+
+	_, err = cache.TTL(ctx, "thisSessionKeyShouldNotExist")
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect not found error for non existing session")
+}
+
+func TestShouldExpireSessionInRedis(t *testing.T) {
+	setup()
+	defer teardown()
+
+	cache.expires = time.Minute
+	cache.Set(ctx, "someExpireKey", "value")
+
+	err := cache.Expire(ctx, "someExpireKey", time.Hour)
+	assert.Nil(t, err, "Expect err is nil when changing the expiry of a session")
+
+	ttl, err := cache.TTL(ctx, "someExpireKey")
+	assert.Nil(t, err, "Expect err is nil when reading the ttl of a session")
+	assert.Equal(t, time.Hour, ttl, "Expect session to be kept for the new ttl")
+
+	val, err := cache.Get(ctx, "someExpireKey")
+	assert.Nil(t, err, "Expect err is nil when reading an expired session")
+	assert.Equal(t, "value", val, "Expect session value to be kept")
+
+	err = cache.Expire(ctx, "thisSessionKeyShouldNotExist", time.Hour)
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect not found error for non existing session")
+}
+
+func TestShouldScanAndSetAbsentKeysInRedis(t *testing.T) {
+	setup()
+	defer teardown()
+
+	cache.expires = time.Minute
+	stored, err := cache.SetIfAbsent(ctx, "someScanKey", "first", 0)
+	assert.Nil(t, err, "Expect err is nil when storing an absent session")
+	assert.True(t, stored, "Expect absent session to be stored")
+
+	stored, err = cache.SetIfAbsent(ctx, "someScanKey", "second", 0)
+	assert.Nil(t, err, "Expect err is nil when storing an existing session")
+	assert.False(t, stored, "Expect existing session not to be overwritten")
+
+	var keys []string
+	err = cache.Scan(ctx, func(key string) error {
+		keys = append(keys, key)
+		return nil
+	})
+	assert.Nil(t, err, "Expect err is nil when scanning keys")
+	assert.Equal(t, []string{"someScanKey"}, keys)
+}
+
+func TestShouldReplaceInRedis(t *testing.T) {
+
+	for _, storage := range []string{RedisStringStorage, RedisHashStorage} {
+		setup()
+
+		cache.config.Storage = storage
+		cache.expires = time.Minute
+
+		err := cache.Set(ctx, "someReplaceKey", `{"data":"old"}`)
+		assert.Nil(t, err, "Expect err is nil when storing session value")
+
+		replaced, err := cache.Replace(ctx, "someReplaceKey", `{"data":"other"}`, `{"data":"new"}`, time.Hour)
+		assert.Nil(t, err, "Expect err is nil when replacing with %s storage", storage)
+		assert.False(t, replaced, "Expect session not to be replaced when it holds another value")
+
+		replaced, err = cache.Replace(ctx, "someReplaceKey", `{"data":"old"}`, `{"data":"new"}`, time.Hour)
+		assert.Nil(t, err, "Expect err is nil when replacing with %s storage", storage)
+		assert.True(t, replaced, "Expect session to be replaced when it holds the old value")
+
+		val, _ := cache.Get(ctx, "someReplaceKey")
+		assert.JSONEq(t, `{"data":"new"}`, val.(string))
+		assert.Equal(t, time.Hour, redisServer.TTL("someReplaceKey"), "Expect session to be stored for the given ttl")
+
+		replaced, err = cache.Replace(ctx, "someReplaceKey", `{"data":"new"}`, `{"data":"newer"}`, 0)
+		assert.Nil(t, err, "Expect err is nil when replacing with %s storage", storage)
+		assert.True(t, replaced, "Expect session to be replaced when it holds the old value")
+		assert.Equal(t, time.Minute, redisServer.TTL("someReplaceKey"), "Expect session to be stored for the default expiry")
+
+		replaced, err = cache.Replace(ctx, "thisSessionKeyShouldNotExist", `{"data":"old"}`, `{"data":"new"}`, 0)
+		assert.Nil(t, err, "Expect err is nil when replacing a missing session")
+		assert.False(t, replaced, "Expect missing session not to be replaced")
+
+		teardown()
+	}
+}
+
+func TestShouldDeleteIfInRedis(t *testing.T) {
+
+	for _, storage := range []string{RedisStringStorage, RedisHashStorage} {
+		setup()
+
+		cache.config.Storage = storage
+
+		err := cache.Set(ctx, "someDeleteKey", `{"data":"old"}`)
+		assert.Nil(t, err, "Expect err is nil when storing session value")
+
+		deleted, err := cache.DeleteIf(ctx, "someDeleteKey", `{"data":"other"}`)
+		assert.Nil(t, err, "Expect err is nil when deleting with %s storage", storage)
+		assert.False(t, deleted, "Expect session not to be deleted when it holds another value")
+
+		deleted, err = cache.DeleteIf(ctx, "someDeleteKey", `{"data":"old"}`)
+		assert.Nil(t, err, "Expect err is nil when deleting with %s storage", storage)
+		assert.True(t, deleted, "Expect session to be deleted when it holds the old value")
+		assert.False(t, redisServer.Exists("someDeleteKey"), "Expect deleted session not to be stored")
+
+		deleted, err = cache.DeleteIf(ctx, "someDeleteKey", `{"data":"old"}`)
+		assert.Nil(t, err, "Expect err is nil when deleting a missing session")
+		assert.False(t, deleted, "Expect missing session not to be deleted")
+
+		teardown()
+	}
+}
+
+func TestShouldReadFromRedisReplicas(t *testing.T) {
+	primary, replica := mockRedis(), mockRedis()
+	defer primary.Close()
+	defer replica.Close()
+
+	c, err := newRedisCache(RedisConfig{
+		Addrs:          []string{primary.Addr()},
+		ReplicaAddrs:   []string{replica.Addr()},
+		ReadYourWrites: time.Minute,
+	}, time.Minute)
+	assert.Nil(t, err, "Expect err is nil when building a cache with replicas")
+
+	now := time.Now()
+	c.writes.now = func() time.Time { return now }
+
+	err = c.Set(ctx, "someReplicaKey", `{"data":"primary"}`)
+	assert.Nil(t, err, "Expect err is nil when storing session value")
+	assert.True(t, primary.Exists("someReplicaKey"), "Expect session to be written to the primary")
+	assert.False(t, replica.Exists("someReplicaKey"), "Expect session not to be written to the replica")
+
+	val, err := c.Get(ctx, "someReplicaKey")
+	assert.Nil(t, err, "Expect err is nil when reading a session just written")
+	assert.Equal(t, `{"data":"primary"}`, val, "Expect session just written to be read from the primary")
+
+	replica.Set("someReplicaKey", `{"data":"replica"}`)
+	now = now.Add(time.Minute)
+
+	val, err = c.Get(ctx, "someReplicaKey")
+	assert.Nil(t, err, "Expect err is nil when reading from the replica")
+	assert.Equal(t, `{"data":"replica"}`, val, "Expect session to be read from the replica once the window is over")
+
+	fields, err := c.GetFields(ctx, "someReplicaKey", []string{"data"})
+	assert.Nil(t, err, "Expect err is nil when reading fields from the replica")
+	assert.Equal(t, map[string]interface{}{"data": "replica"}, fields)
+
+	_, err = c.Get(ctx, "thisSessionKeyShouldNotExist")
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect missing session to be reported by the replica")
+
+	replica.Close()
+	val, err = c.Get(ctx, "someReplicaKey")
+	assert.Nil(t, err, "Expect err is nil when the replica is down")
+	assert.Equal(t, `{"data":"primary"}`, val, "Expect session to be read from the primary when the replica is down")
+}
+
+func TestShouldWriteSessionsConditionallyAgainstTheRedisPrimary(t *testing.T) {
+	primary, replica := mockRedis(), mockRedis()
+	defer primary.Close()
+	defer replica.Close()
+
+	c, err := newRedisCache(RedisConfig{
+		Addrs:        []string{primary.Addr()},
+		ReplicaAddrs: []string{replica.Addr()},
+	}, time.Minute)
+	assert.Nil(t, err, "Expect err is nil when building a cache with replicas")
+	repo := NewSessionRepository(c, time.Minute, session.LifetimePolicy{})
+
+	err = repo.Set(ctx, &session.Session{ID: "someReplicaKey", Data: session.Data{"n": 1}}, session.SetOptions{})
+	assert.Nil(t, err, "Expect err is nil when storing a session")
+	replica.Set("someReplicaKey", `{"n":0,"__session":{"created":1,"updated":1,"version":1}}`)
+
+	updated := &session.Session{ID: "someReplicaKey", Data: session.Data{"n": 2}}
+	err = repo.Set(ctx, updated, session.SetOptions{})
+	assert.Nil(t, err, "Expect a lagging replica not to make writes conflict")
+	assert.Equal(t, int64(2), updated.Version, "Expect the version to be counted from the primary")
+
+	_, err = repo.Patch(ctx, "someReplicaKey", session.MergePatch{"n": 3}, 0)
+	assert.Nil(t, err, "Expect a lagging replica not to make patches conflict")
+
+	val, err := c.GetPrimary(ctx, "someReplicaKey")
+	assert.Nil(t, err, "Expect err is nil when reading from the primary")
+	assert.Contains(t, val, `"n":3`, "Expect the session to be written to the primary")
+}
+
+func TestShouldOnlyAllowRedisReplicasInStandaloneMode(t *testing.T) {
+	t.Parallel()
+
+	_, err := newRedisCache(RedisConfig{Mode: RedisCluster, Addrs: []string{"n1:6379"}, ReplicaAddrs: []string{"r1:6379"}}, time.Minute)
+	assert.NotNil(t, err, "Expect replicas to be rejected in cluster mode")
+
+	_, err = newShardedRedisCache(RedisConfig{Mode: RedisSharded, Addrs: []string{"n1:6379"}, ReplicaAddrs: []string{"r1:6379"}}, nil, time.Minute)
+	assert.NotNil(t, err, "Expect replicas to be rejected in sharded mode")
+}
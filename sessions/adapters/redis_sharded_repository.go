@@ -0,0 +1,294 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+)
+
+const rebalanceProgressEvery = 1000
+
+// shardedRedisCache spreads sessions over independent Redis instances, routing
+// every key to its instance with a consistent hash ring.
+type shardedRedisCache struct {
+	ring  *hashRing
+	nodes map[string]*redisCache
+	// retired holds the instances removed from the ring, which Rebalance
+	// drains but no session is routed to.
+	retired map[string]*redisCache
+}
+
+func newShardedRedisCache(config RedisConfig, retired []string, expires time.Duration) (*shardedRedisCache, error) {
+
+	if len(config.Addrs) == 0 {
+		return nil, fmt.Errorf("no redis addresses configured")
+	}
+	if len(config.ReplicaAddrs) > 0 {
+		return nil, fmt.Errorf("redis replicas are only supported in standalone mode")
+	}
+
+	c := &shardedRedisCache{
+		ring:    newHashRing(defaultRingReplicas),
+		nodes:   make(map[string]*redisCache, len(config.Addrs)),
+		retired: make(map[string]*redisCache, len(retired)),
+	}
+
+	for _, addr := range config.Addrs {
+		node, err := newRedisNode(config, addr, expires, c.nodes)
+		if err != nil {
+			return nil, err
+		}
+		c.nodes[addr] = node
+		c.ring.add(addr)
+	}
+
+	for _, addr := range retired {
+		if addr == "" {
+			continue
+		}
+		if _, ok := c.nodes[addr]; ok {
+			return nil, fmt.Errorf("redis instance '%s' is both in use and retired", addr)
+		}
+		node, err := newRedisNode(config, addr, expires, c.retired)
+		if err != nil {
+			return nil, err
+		}
+		c.retired[addr] = node
+	}
+
+	return c, nil
+}
+
+func newRedisNode(config RedisConfig, addr string, expires time.Duration, nodes map[string]*redisCache) (*redisCache, error) {
+	if _, ok := nodes[addr]; ok {
+		return nil, fmt.Errorf("redis instance '%s' configured twice", addr)
+	}
+
+	config.Mode = RedisStandalone
+	config.Addrs = []string{addr}
+	return newRedisCache(config, expires)
+}
+
+func (c *shardedRedisCache) node(key string) *redisCache {
+	return c.nodes[c.ring.get(key)]
+}
+
+func (c *shardedRedisCache) Set(ctx context.Context, key string, value interface{}) error {
+	return c.node(key).Set(ctx, key, value)
+}
+
+func (c *shardedRedisCache) SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return c.node(key).SetWithTTL(ctx, key, value, ttl)
+}
+
+func (c *shardedRedisCache) Get(ctx context.Context, key string) (interface{}, error) {
+	return c.node(key).Get(ctx, key)
+}
+
+func (c *shardedRedisCache) GetPrimary(ctx context.Context, key string) (interface{}, error) {
+	return c.node(key).GetPrimary(ctx, key)
+}
+
+func (c *shardedRedisCache) Delete(ctx context.Context, key string) (int64, error) {
+	return c.node(key).Delete(ctx, key)
+}
+
+func (c *shardedRedisCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return c.node(key).TTL(ctx, key)
+}
+
+func (c *shardedRedisCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	return c.node(key).Expire(ctx, key, ttl)
+}
+
+func (c *shardedRedisCache) SetIfAbsent(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	return c.node(key).SetIfAbsent(ctx, key, value, ttl)
+}
+
+func (c *shardedRedisCache) Replace(ctx context.Context, key string, old, new interface{}, ttl time.Duration) (bool, error) {
+	return c.node(key).Replace(ctx, key, old, new, ttl)
+}
+
+func (c *shardedRedisCache) DeleteIf(ctx context.Context, key string, old interface{}) (bool, error) {
+	return c.node(key).DeleteIf(ctx, key, old)
+}
+
+func (c *shardedRedisCache) Increment(ctx context.Context, key string, path session.Pointer, delta int64, updatedAt time.Time) (int64, error) {
+	return c.node(key).Increment(ctx, key, path, delta, updatedAt)
+}
+
+func (c *shardedRedisCache) GetFields(ctx context.Context, key string, fields []string) (map[string]interface{}, error) {
+	return c.node(key).GetFields(ctx, key, fields)
+}
+
+func (c *shardedRedisCache) SetFields(ctx context.Context, key string, values map[string]interface{}) error {
+	return c.node(key).SetFields(ctx, key, values)
+}
+
+// IndexSession and the other methods of the index of subject go to the
+// instance its key is routed to.
+func (c *shardedRedisCache) IndexSession(ctx context.Context, subject, id string, expiresAt time.Time) error {
+	return c.node(subjectIndexKey(subject)).IndexSession(ctx, subject, id, expiresAt)
+}
+
+func (c *shardedRedisCache) UnindexSessions(ctx context.Context, subject string, ids []string) error {
+	return c.node(subjectIndexKey(subject)).UnindexSessions(ctx, subject, ids)
+}
+
+func (c *shardedRedisCache) IndexedSessions(ctx context.Context, subject string) (map[string]time.Time, error) {
+	return c.node(subjectIndexKey(subject)).IndexedSessions(ctx, subject)
+}
+
+// ScanSubjects walks the subjects indexed by every instance in use.
+func (c *shardedRedisCache) ScanSubjects(ctx context.Context, onSubject func(subject string) error) error {
+
+	for _, node := range c.nodes {
+		if err := node.ScanSubjects(ctx, onSubject); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Scan walks every key of every instance in use.
+func (c *shardedRedisCache) Scan(ctx context.Context, onKey func(key string) error) error {
+
+	for _, node := range c.nodes {
+		if err := node.Scan(ctx, onKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rebalance walks the sessions of every instance, retired ones included, and
+// moves the ones held by an instance the ring no longer routes them to, along
+// with the time they have left, and so does it with the indexes of subjects.
+// Until a session is moved it cannot be read.
+func (c *shardedRedisCache) Rebalance(ctx context.Context) error {
+
+	var scanned, moved, skipped, failed int64
+	logProgress := func(msg string) {
+		logrus.WithFields(logrus.Fields{
+			"scanned": atomic.LoadInt64(&scanned),
+			"moved":   atomic.LoadInt64(&moved),
+			"skipped": atomic.LoadInt64(&skipped),
+			"failed":  atomic.LoadInt64(&failed),
+		}).Info(msg)
+	}
+
+	rebalance := func(addr string, from *redisCache) error {
+		err := from.ScanSubjects(ctx, func(subject string) error {
+			owner := c.ring.get(subjectIndexKey(subject))
+			if owner == addr {
+				return nil
+			}
+
+			if err := c.moveIndex(ctx, subject, from, c.nodes[owner]); err != nil {
+				atomic.AddInt64(&failed, 1)
+				logrus.WithError(err).WithFields(logrus.Fields{"subject": subject, "from": addr, "to": owner}).Warn("Failed to move index of subject")
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return from.Scan(ctx, func(key string) error {
+			if n := atomic.AddInt64(&scanned, 1); n%rebalanceProgressEvery == 0 {
+				logProgress("Session rebalancing in progress")
+			}
+
+			owner := c.ring.get(key)
+			if owner == addr {
+				return nil
+			}
+
+			ok, err := c.move(ctx, key, from, c.nodes[owner])
+			switch {
+			case err != nil:
+				atomic.AddInt64(&failed, 1)
+				logrus.WithError(err).WithFields(logrus.Fields{"key": key, "from": addr, "to": owner}).Warn("Failed to move session")
+			case ok:
+				atomic.AddInt64(&moved, 1)
+			default:
+				atomic.AddInt64(&skipped, 1)
+			}
+			return nil
+		})
+	}
+
+	for addr, node := range c.nodes {
+		if err := rebalance(addr, node); err != nil {
+			return err
+		}
+	}
+	for addr, node := range c.retired {
+		if err := rebalance(addr, node); err != nil {
+			return err
+		}
+	}
+
+	logProgress("Session rebalancing completed")
+	return nil
+}
+
+// moveIndex adds the sessions in the index of subject held by one instance to
+// the one held by another, which the ring routes new sessions to meanwhile,
+// and then drops the former.
+func (c *shardedRedisCache) moveIndex(ctx context.Context, subject string, from, to *redisCache) error {
+
+	index, err := from.IndexedSessions(ctx, subject)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(index))
+	for id, expiresAt := range index {
+		if err := to.IndexSession(ctx, subject, id, expiresAt); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+	return from.UnindexSessions(ctx, subject, ids)
+}
+
+// move copies the session at key from one instance to another, unless the
+// latter already holds a newer one written through the ring, and then drops it
+// from the former as long as it has not changed meanwhile.
+func (c *shardedRedisCache) move(ctx context.Context, key string, from, to *redisCache) (bool, error) {
+
+	val, err := from.read(ctx, from.client, key)
+	if errors.Is(err, session.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	ttl, err := from.TTL(ctx, key)
+	if errors.Is(err, session.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if ttl < 0 {
+		ttl = 0
+	}
+
+	copied, err := to.SetIfAbsent(ctx, key, val, ttl)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := from.deleteIf(ctx, key, val); err != nil {
+		return false, err
+	}
+	return copied, nil
+}
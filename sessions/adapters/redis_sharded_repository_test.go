@@ -0,0 +1,133 @@
+package adapters
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockRedisInstances(n int) ([]*miniredis.Miniredis, []string) {
+	servers := make([]*miniredis.Miniredis, n)
+	addrs := make([]string, n)
+	for i := range servers {
+		servers[i] = mockRedis()
+		addrs[i] = servers[i].Addr()
+	}
+	return servers, addrs
+}
+
+func closeRedisInstances(servers []*miniredis.Miniredis) {
+	for _, s := range servers {
+		s.Close()
+	}
+}
+
+func TestShouldSpreadSessionsOverRedisInstances(t *testing.T) {
+	t.Parallel()
+
+	servers, addrs := mockRedisInstances(3)
+	defer closeRedisInstances(servers)
+
+	sharded, err := newShardedRedisCache(RedisConfig{Addrs: addrs}, nil, time.Minute)
+	assert.Nil(t, err, "Expect err is nil when building sharded cache")
+
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("someShardedKey%d", i)
+		assert.Nil(t, sharded.Set(ctx, key, `{"data":"value"}`), "Expect err is nil when storing session")
+
+		for j, s := range servers {
+			assert.Equal(t, addrs[j] == sharded.ring.get(key), s.Exists(key), "Expect session to be stored by its owner only")
+		}
+
+		val, err := sharded.Get(ctx, key)
+		assert.Nil(t, err, "Expect err is nil when retrieving session")
+		assert.Equal(t, `{"data":"value"}`, val)
+	}
+
+	for _, s := range servers {
+		assert.NotEmpty(t, s.Keys(), "Expect every instance to hold some sessions")
+	}
+
+	keys := 0
+	sharded.Scan(ctx, func(key string) error {
+		keys++
+		return nil
+	})
+	assert.Equal(t, 100, keys, "Expect scan to walk every instance")
+}
+
+func TestShouldRebalanceSessionsWhenInstancesChange(t *testing.T) {
+	t.Parallel()
+
+	servers, addrs := mockRedisInstances(4)
+	defer closeRedisInstances(servers)
+
+	before, _ := newShardedRedisCache(RedisConfig{Addrs: addrs[:3]}, nil, time.Minute)
+	for i := 0; i < 200; i++ {
+		before.Set(ctx, fmt.Sprintf("someRebalanceKey%d", i), fmt.Sprintf(`{"n":%d}`, i))
+	}
+
+	tests := []struct {
+		scenario string
+		addrs    []string
+		retired  []string
+	}{
+		{
+			scenario: "Should move sessions to an added instance",
+			addrs:    addrs,
+		},
+		{
+			scenario: "Should drain a removed instance",
+			addrs:    []string{addrs[0], addrs[1], addrs[3]},
+			retired:  []string{addrs[2]},
+		},
+	}
+
+	for _, test := range tests {
+		after, err := newShardedRedisCache(RedisConfig{Addrs: test.addrs}, test.retired, time.Minute)
+		assert.Nil(t, err, test.scenario)
+
+		remapped := 0
+		for i := 0; i < 200; i++ {
+			if _, err := after.Get(ctx, fmt.Sprintf("someRebalanceKey%d", i)); err != nil {
+				remapped++
+			}
+		}
+		assert.Less(t, remapped, 100, "Expect only a fraction of sessions to be remapped. %s", test.scenario)
+
+		err = after.Rebalance(ctx)
+		assert.Nil(t, err, test.scenario)
+
+		total := 0
+		for _, s := range servers {
+			total += len(s.Keys())
+		}
+		assert.Equal(t, 200, total, "Expect every session to be held once. %s", test.scenario)
+
+		for i := 0; i < 200; i++ {
+			key := fmt.Sprintf("someRebalanceKey%d", i)
+			val, err := after.Get(ctx, key)
+			assert.Nil(t, err, "Expect session '%s' to be readable after rebalancing. %s", key, test.scenario)
+			assert.Equal(t, fmt.Sprintf(`{"n":%d}`, i), val)
+
+			ttl, _ := after.TTL(ctx, key)
+			assert.Equal(t, time.Minute, ttl, "Expect session to keep its ttl. %s", test.scenario)
+		}
+	}
+}
+
+func TestShouldRejectInvalidShardedConfig(t *testing.T) {
+	t.Parallel()
+
+	_, err := newShardedRedisCache(RedisConfig{}, nil, time.Minute)
+	assert.ErrorContains(t, err, "no redis addresses configured")
+
+	_, err = newShardedRedisCache(RedisConfig{Addrs: []string{"r1:6379", "r1:6379"}}, nil, time.Minute)
+	assert.ErrorContains(t, err, "configured twice")
+
+	_, err = newShardedRedisCache(RedisConfig{Addrs: []string{"r1:6379"}}, []string{"r1:6379"}, time.Minute)
+	assert.ErrorContains(t, err, "both in use and retired")
+}
@@ -0,0 +1,79 @@
+package adapters
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// IndexSession adds the session to a sorted set per subject, scored with when
+// it expires in Unix milliseconds, zero when it never does. The set is never
+// given an expiry, so it is not evicted before the sessions it holds.
+func (c *redisCache) IndexSession(ctx context.Context, subject, id string, expiresAt time.Time) error {
+	return c.client.ZAdd(ctx, subjectIndexKey(subject), &redis.Z{Score: float64(unixMilli(expiresAt)), Member: id}).Err()
+}
+
+// UnindexSessions removes the sessions from the set of subject, which Redis
+// deletes once it is left empty.
+func (c *redisCache) UnindexSessions(ctx context.Context, subject string, ids []string) error {
+
+	members := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		members = append(members, id)
+	}
+	if len(members) == 0 {
+		return nil
+	}
+	return c.client.ZRem(ctx, subjectIndexKey(subject), members...).Err()
+}
+
+// IndexedSessions reads the set of subject from the primary, never from a
+// replica, so sessions indexed just before are found.
+func (c *redisCache) IndexedSessions(ctx context.Context, subject string) (map[string]time.Time, error) {
+
+	members, err := c.client.ZRangeWithScores(ctx, subjectIndexKey(subject), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]time.Time, len(members))
+	for _, member := range members {
+		id, _ := member.Member.(string)
+		index[id] = fromUnixMilli(int64(member.Score))
+	}
+	return index, nil
+}
+
+// ScanSubjects walks the sets of subjects, on every master node in cluster
+// mode.
+func (c *redisCache) ScanSubjects(ctx context.Context, onSubject func(subject string) error) error {
+
+	onKey := func(key string) error {
+		if !strings.HasPrefix(key, subjectIndexPrefix) {
+			return nil
+		}
+		return onSubject(strings.TrimPrefix(key, subjectIndexPrefix))
+	}
+	match := escapeGlob(subjectIndexPrefix) + "*"
+
+	if cluster, ok := c.client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return scanKeys(ctx, node, match, onKey)
+		})
+	}
+	return scanKeys(ctx, c.client, match, onKey)
+}
+
+// escapeGlob escapes the characters SCAN MATCH patterns give a meaning to.
+func escapeGlob(s string) string {
+	var escaped strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\^`, r) {
+			escaped.WriteByte('\\')
+		}
+		escaped.WriteRune(r)
+	}
+	return escaped.String()
+}
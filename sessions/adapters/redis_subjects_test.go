@@ -0,0 +1,48 @@
+package adapters
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldIndexSessionsBySubjectInRedis(t *testing.T) {
+	setup()
+	defer teardown()
+
+	expiresAt := time.UnixMilli(1_000_000)
+	err := cache.IndexSession(ctx, "someUser", "someSessionKey", expiresAt)
+	assert.Nil(t, err, "Expect err is nil when indexing a session")
+	err = cache.IndexSession(ctx, "someUser", "otherSessionKey", time.Time{})
+	assert.Nil(t, err, "Expect err is nil when indexing a session that never expires")
+	err = cache.IndexSession(ctx, "other*User", "someSessionKey", expiresAt)
+	assert.Nil(t, err, "Expect err is nil when indexing a session")
+	err = cache.Set(ctx, "someSessionKey", `{"n":1}`)
+	assert.Nil(t, err, "Expect err is nil when storing a session")
+
+	index, err := cache.IndexedSessions(ctx, "someUser")
+	assert.Nil(t, err, "Expect err is nil when reading the index of a subject")
+	assert.Equal(t, map[string]time.Time{"someSessionKey": expiresAt, "otherSessionKey": {}}, index)
+
+	subjects := make([]string, 0)
+	err = cache.ScanSubjects(ctx, func(subject string) error {
+		subjects = append(subjects, subject)
+		return nil
+	})
+	assert.Nil(t, err, "Expect err is nil when walking the subjects")
+	assert.ElementsMatch(t, []string{"someUser", "other*User"}, subjects)
+
+	keys := make([]string, 0)
+	err = cache.Scan(ctx, func(key string) error {
+		keys = append(keys, key)
+		return nil
+	})
+	assert.Nil(t, err, "Expect err is nil when walking the sessions")
+	assert.Equal(t, []string{"someSessionKey"}, keys, "Expect the indexes to be left out of the sessions")
+
+	err = cache.UnindexSessions(ctx, "someUser", []string{"someSessionKey", "otherSessionKey"})
+	assert.Nil(t, err, "Expect err is nil when dropping sessions from the index of a subject")
+	assert.False(t, redisServer.Exists(subjectIndexKey("someUser")), "Expect an empty index to be deleted")
+	assert.Equal(t, time.Duration(0), redisServer.TTL(subjectIndexKey("other*User")), "Expect indexes not to expire")
+}
@@ -0,0 +1,120 @@
+package adapters
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Factory builds a session repository from its configuration. It returns an
+// error when the configuration is not valid or the backend cannot be set up.
+type Factory func(config Config) (Store, error)
+
+// Config is what a Factory builds a repository from: the default expiry of
+// sessions and the backend settings, named after Prefix followed by the name
+// of each setting (e.g. MEMORY_DB_HOST).
+type Config struct {
+	Prefix  string
+	Expires time.Duration
+	// Lookup reads a setting by its full name, it defaults to the environment.
+	Lookup func(key string) (string, bool)
+}
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+// Register makes a backend available under name. It panics when factory is nil
+// or name is already registered.
+func Register(name string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if factory == nil {
+		panic(fmt.Sprintf("db type '%s' registered without a factory", name))
+	}
+	if _, ok := factories[name]; ok {
+		panic(fmt.Sprintf("db type '%s' registered twice", name))
+	}
+	factories[name] = factory
+}
+
+// Backends returns the sorted names of the registered backends.
+func Backends() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open builds the repository of the backend registered under name.
+func Open(name string, config Config) (Store, error) {
+	factoriesMu.RLock()
+	factory, ok := factories[name]
+	factoriesMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("db type '%s' not supported, available db types are: %s", name, strings.Join(Backends(), ", "))
+	}
+
+	repo, err := factory(config)
+	if err != nil {
+		return nil, fmt.Errorf("error '%s' when setting up '%s' db", err, name)
+	}
+	return repo, nil
+}
+
+// String returns the value of setting name, or defaultValue when it is not set.
+func (c Config) String(name, defaultValue string) string {
+	lookup := c.Lookup
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+
+	if val, ok := lookup(c.Prefix + name); ok && val != "" {
+		return val
+	}
+	return defaultValue
+}
+
+// List returns the comma separated values of setting name, leaving out the
+// empty ones.
+func (c Config) List(name, defaultValue string) []string {
+	var values []string
+	for _, val := range strings.Split(c.String(name, defaultValue), ",") {
+		if val != "" {
+			values = append(values, val)
+		}
+	}
+	return values
+}
+
+func (c Config) Int(name string, defaultValue int) (int, error) {
+	val, err := strconv.Atoi(c.String(name, strconv.Itoa(defaultValue)))
+	if err != nil {
+		return 0, fmt.Errorf("error '%s' when parsing %s%s to integer value", err, c.Prefix, name)
+	}
+	return val, nil
+}
+
+// Seconds returns setting name as a duration given in seconds.
+func (c Config) Seconds(name string, defaultValue int) (time.Duration, error) {
+	val, err := c.Int(name, defaultValue)
+	if err != nil {
+		return 0, err
+	}
+	if val < 0 {
+		return 0, fmt.Errorf("%s%s must not be negative, got %d", c.Prefix, name, val)
+	}
+	return time.Duration(val) * time.Second, nil
+}
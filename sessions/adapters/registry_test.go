@@ -0,0 +1,131 @@
+package adapters
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func lookupFrom(settings map[string]string) func(key string) (string, bool) {
+	return func(key string) (string, bool) {
+		val, ok := settings[key]
+		return val, ok
+	}
+}
+
+func TestShouldListRegisteredBackends(t *testing.T) {
+	t.Parallel()
+
+	assert.Subset(t, Backends(), []string{"bolt", "memcached", "memory", "postgres", "redis"}, "Adapters should register themselves")
+}
+
+func TestShouldPanicWhenRegisteringBackendTwice(t *testing.T) {
+	t.Parallel()
+
+	Register("registry-test", func(config Config) (Store, error) { return nil, nil })
+
+	assert.Panics(t, func() {
+		Register("registry-test", func(config Config) (Store, error) { return nil, nil })
+	}, "Registering a name twice should panic")
+}
+
+func TestShouldListAvailableBackendsWhenOpeningUnknownBackend(t *testing.T) {
+	t.Parallel()
+
+	_, err := Open("unknown", Config{Lookup: lookupFrom(nil)})
+
+	assert.ErrorContains(t, err, "db type 'unknown' not supported")
+	assert.ErrorContains(t, err, "memory, postgres, redis")
+}
+
+func TestShouldOpenBackendFromConfig(t *testing.T) {
+	t.Parallel()
+
+	repo, err := Open("memory", Config{
+		Prefix:  "DB_",
+		Expires: time.Minute,
+		Lookup:  lookupFrom(map[string]string{"DB_MAX_ENTRIES": "10", "DB_CLEANUP_INTERVAL": "0"}),
+	})
+	assert.Nil(t, err, "Should open memory backend")
+
+	memory := repo.(*memoryCache)
+	defer memory.Close()
+
+	assert.Equal(t, time.Minute, memory.expires, "Expiry should be taken from config")
+	assert.Equal(t, 1, memory.shards[0].maxEntries, "Max entries should be spread over shards")
+}
+
+func TestShouldValidateBackendConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario string
+		dbType   string
+		settings map[string]string
+		err      string
+	}{
+		{
+			scenario: "Should reject non numeric settings",
+			dbType:   "memory",
+			settings: map[string]string{"DB_MAX_ENTRIES": "many"},
+			err:      "when parsing DB_MAX_ENTRIES to integer value",
+		},
+		{
+			scenario: "Should reject negative intervals",
+			dbType:   "memory",
+			settings: map[string]string{"DB_CLEANUP_INTERVAL": "-1"},
+			err:      "DB_CLEANUP_INTERVAL must not be negative",
+		},
+		{
+			scenario: "Should reject unknown redis storage",
+			dbType:   "redis",
+			settings: map[string]string{"DB_STORAGE": "list"},
+			err:      "redis storage 'list' not supported",
+		},
+		{
+			scenario: "Should reject redis sentinel without master name",
+			dbType:   "redis",
+			settings: map[string]string{"DB_MODE": RedisSentinel},
+			err:      "redis sentinel mode requires a master name",
+		},
+		{
+			scenario: "Should reject non positive postgres cleanup batches",
+			dbType:   "postgres",
+			settings: map[string]string{"DB_CLEANUP_BATCH": "0"},
+			err:      "DB_CLEANUP_BATCH must be positive",
+		},
+	}
+
+	for _, test := range tests {
+		_, err := Open(test.dbType, Config{Prefix: "DB_", Lookup: lookupFrom(test.settings)})
+		assert.ErrorContains(t, err, test.err, test.scenario)
+		assert.ErrorContains(t, err, fmt.Sprintf("'%s' db", test.dbType), test.scenario)
+	}
+}
+
+func TestShouldReadRedisConfig(t *testing.T) {
+	t.Parallel()
+
+	config, err := RedisConfigFrom(Config{
+		Prefix: "DB_",
+		Lookup: lookupFrom(map[string]string{
+			"DB_HOST":                "redis",
+			"DB_ID":                  "2",
+			"DB_STORAGE":             RedisHashStorage,
+			"DB_REPLICA_ADDRS":       "replica1:6379,replica2:6379",
+			"DB_READ_YOUR_WRITES_MS": "1500",
+		}),
+	})
+	assert.Nil(t, err, "Should read redis config")
+
+	assert.Equal(t, RedisConfig{
+		Mode:           RedisStandalone,
+		Addrs:          []string{"redis:6379"},
+		ReplicaAddrs:   []string{"replica1:6379", "replica2:6379"},
+		ReadYourWrites: 1500 * time.Millisecond,
+		DB:             2,
+		Storage:        RedisHashStorage,
+	}, config, "Redis config should match")
+}
@@ -0,0 +1,403 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+)
+
+// ResilienceConfig tunes NewResilientRepository. A zero Timeout leaves
+// operations without deadline, and a zero BreakerThreshold disables the
+// circuit breaker.
+type ResilienceConfig struct {
+	// Timeout bounds every attempt of an operation.
+	Timeout time.Duration
+	// Retries is how many times an idempotent operation is attempted again
+	// after failing, waiting a random time up to Backoff, doubled on every
+	// retry and capped by MaxBackoff.
+	Retries    int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// BreakerThreshold is the number of consecutive failures that opens the
+	// breaker. Operations fail fast while it is open, and after BreakerOpen a
+	// single one goes through to probe the backend.
+	BreakerThreshold int
+	BreakerOpen      time.Duration
+}
+
+type resilientRepository struct {
+	next    Store
+	config  ResilienceConfig
+	breaker *circuitBreaker
+}
+
+// NewResilientRepository guards next with per attempt timeouts, retries of the
+// idempotent operations and a circuit breaker. Operations fail with
+// session.ErrUnavailable while the breaker is open, or when next cannot be
+// reached.
+func NewResilientRepository(next Store, config ResilienceConfig) Store {
+	return &resilientRepository{
+		next:    next,
+		config:  config,
+		breaker: newCircuitBreaker(config.BreakerThreshold, config.BreakerOpen),
+	}
+}
+
+func (r *resilientRepository) Set(ctx context.Context, key string, value interface{}) error {
+	return r.do(ctx, true, func(ctx context.Context) error {
+		return r.next.Set(ctx, key, value)
+	})
+}
+
+func (r *resilientRepository) SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+
+	setter, ok := r.next.(ttlSetter)
+	if !ok {
+		return session.ErrNotSupported
+	}
+
+	return r.do(ctx, true, func(ctx context.Context) error {
+		return setter.SetWithTTL(ctx, key, value, ttl)
+	})
+}
+
+func (r *resilientRepository) Get(ctx context.Context, key string) (interface{}, error) {
+	var val interface{} = ""
+	err := r.do(ctx, true, func(ctx context.Context) error {
+		var err error
+		val, err = r.next.Get(ctx, key)
+		return err
+	})
+	return val, err
+}
+
+func (r *resilientRepository) GetPrimary(ctx context.Context, key string) (interface{}, error) {
+	var val interface{} = ""
+	err := r.do(ctx, true, func(ctx context.Context) error {
+		var err error
+		val, err = getPrimary(ctx, r.next, key)
+		return err
+	})
+	return val, err
+}
+
+func (r *resilientRepository) Delete(ctx context.Context, key string) (int64, error) {
+	var deleted int64
+	err := r.do(ctx, true, func(ctx context.Context) error {
+		var err error
+		deleted, err = r.next.Delete(ctx, key)
+		return err
+	})
+	return deleted, err
+}
+
+func (r *resilientRepository) TTL(ctx context.Context, key string) (time.Duration, error) {
+	reader, ok := r.next.(ttlReader)
+	if !ok {
+		return 0, session.ErrNotSupported
+	}
+
+	var ttl time.Duration
+	err := r.do(ctx, true, func(ctx context.Context) error {
+		var err error
+		ttl, err = reader.TTL(ctx, key)
+		return err
+	})
+	return ttl, err
+}
+
+func (r *resilientRepository) Expire(ctx context.Context, key string, ttl time.Duration) error {
+
+	expirer, ok := r.next.(expirer)
+	if !ok {
+		return session.ErrNotSupported
+	}
+
+	return r.do(ctx, true, func(ctx context.Context) error {
+		return expirer.Expire(ctx, key, ttl)
+	})
+}
+
+// SetIfAbsent is not retried, as an attempt that timed out may have stored the
+// session already.
+func (r *resilientRepository) SetIfAbsent(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	setter, ok := r.next.(absentSetter)
+	if !ok {
+		return false, session.ErrNotSupported
+	}
+
+	var stored bool
+	err := r.do(ctx, false, func(ctx context.Context) error {
+		var err error
+		stored, err = setter.SetIfAbsent(ctx, key, value, ttl)
+		return err
+	})
+	return stored, err
+}
+
+// Replace is not retried either, an attempt that timed out may have replaced
+// the session already.
+func (r *resilientRepository) Replace(ctx context.Context, key string, old, new interface{}, ttl time.Duration) (bool, error) {
+	replacer, ok := r.next.(valueReplacer)
+	if !ok {
+		return false, session.ErrNotSupported
+	}
+
+	var replaced bool
+	err := r.do(ctx, false, func(ctx context.Context) error {
+		var err error
+		replaced, err = replacer.Replace(ctx, key, old, new, ttl)
+		return err
+	})
+	return replaced, err
+}
+
+func (r *resilientRepository) DeleteIf(ctx context.Context, key string, old interface{}) (bool, error) {
+	deleter, ok := r.next.(valueDeleter)
+	if !ok {
+		return false, session.ErrNotSupported
+	}
+
+	var deleted bool
+	err := r.do(ctx, false, func(ctx context.Context) error {
+		var err error
+		deleted, err = deleter.DeleteIf(ctx, key, old)
+		return err
+	})
+	return deleted, err
+}
+
+// Increment is not retried, an attempt that timed out may have incremented the
+// counter already.
+func (r *resilientRepository) Increment(ctx context.Context, key string, path session.Pointer, delta int64, updatedAt time.Time) (int64, error) {
+	incrementer, ok := r.next.(incrementer)
+	if !ok {
+		return 0, session.ErrNotSupported
+	}
+
+	var counter int64
+	err := r.do(ctx, false, func(ctx context.Context) error {
+		var err error
+		counter, err = incrementer.Increment(ctx, key, path, delta, updatedAt)
+		return err
+	})
+	return counter, err
+}
+
+func (r *resilientRepository) IndexSession(ctx context.Context, subject, id string, expiresAt time.Time) error {
+	indexer, ok := r.next.(subjectIndexer)
+	if !ok {
+		return session.ErrNotSupported
+	}
+
+	return r.do(ctx, true, func(ctx context.Context) error {
+		return indexer.IndexSession(ctx, subject, id, expiresAt)
+	})
+}
+
+func (r *resilientRepository) UnindexSessions(ctx context.Context, subject string, ids []string) error {
+	indexer, ok := r.next.(subjectIndexer)
+	if !ok {
+		return session.ErrNotSupported
+	}
+
+	return r.do(ctx, true, func(ctx context.Context) error {
+		return indexer.UnindexSessions(ctx, subject, ids)
+	})
+}
+
+func (r *resilientRepository) IndexedSessions(ctx context.Context, subject string) (map[string]time.Time, error) {
+	indexer, ok := r.next.(subjectIndexer)
+	if !ok {
+		return nil, session.ErrNotSupported
+	}
+
+	var index map[string]time.Time
+	err := r.do(ctx, true, func(ctx context.Context) error {
+		var err error
+		index, err = indexer.IndexedSessions(ctx, subject)
+		return err
+	})
+	return index, err
+}
+
+func (r *resilientRepository) GetFields(ctx context.Context, key string, fields []string) (map[string]interface{}, error) {
+	fieldRepo, err := fieldStore(r.next)
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]interface{}
+	err = r.do(ctx, true, func(ctx context.Context) error {
+		var err error
+		values, err = fieldRepo.GetFields(ctx, key, fields)
+		return err
+	})
+	return values, err
+}
+
+func (r *resilientRepository) SetFields(ctx context.Context, key string, values map[string]interface{}) error {
+	fieldRepo, err := fieldStore(r.next)
+	if err != nil {
+		return err
+	}
+
+	return r.do(ctx, true, func(ctx context.Context) error {
+		return fieldRepo.SetFields(ctx, key, values)
+	})
+}
+
+// do runs op, attempting it again after a backend failure when it is
+// idempotent. Errors reporting the outcome of an operation, like a missing
+// session, are not failures of the backend.
+func (r *resilientRepository) do(ctx context.Context, idempotent bool, op func(ctx context.Context) error) error {
+
+	attempts := 1
+	if idempotent {
+		attempts += r.config.Retries
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if err := r.wait(ctx, attempt); err != nil {
+				return err
+			}
+		}
+
+		if !r.breaker.allow() {
+			return fmt.Errorf("%w: circuit breaker open", session.ErrUnavailable)
+		}
+
+		err = r.attempt(ctx, op)
+		if ctx.Err() != nil {
+			// The caller gave up, which says nothing about the backend.
+			r.breaker.abandon()
+			return err
+		}
+		if !isBackendFailure(err) {
+			r.breaker.record(false)
+			return err
+		}
+		r.breaker.record(true)
+	}
+
+	if isUnreachable(err) {
+		return fmt.Errorf("%w: %s", session.ErrUnavailable, err)
+	}
+	return err
+}
+
+func (r *resilientRepository) attempt(ctx context.Context, op func(ctx context.Context) error) error {
+	if r.config.Timeout <= 0 {
+		return op(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.config.Timeout)
+	defer cancel()
+	return op(ctx)
+}
+
+// wait sleeps before the given retry, for a random time up to the exponential
+// backoff so that replicas retrying together spread their attempts.
+func (r *resilientRepository) wait(ctx context.Context, retry int) error {
+	backoff := r.config.Backoff << (retry - 1)
+	if r.config.MaxBackoff > 0 && (backoff > r.config.MaxBackoff || backoff <= 0) {
+		backoff = r.config.MaxBackoff
+	}
+	if backoff <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(time.Duration(rand.Int63n(int64(backoff))))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func isBackendFailure(err error) bool {
+	return err != nil &&
+		!errors.Is(err, session.ErrNotFound) &&
+		!errors.Is(err, session.ErrNotSupported) &&
+		!errors.Is(err, session.ErrInvalid) &&
+		!errors.Is(err, session.ErrPreconditionFailed) &&
+		!errors.Is(err, session.ErrConflict) &&
+		!errors.Is(err, session.ErrReadOnly)
+}
+
+// isUnreachable tells whether err comes from not getting an answer from the
+// backend in time, rather than from the backend rejecting the operation.
+func isUnreachable(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.EOF) ||
+		errors.As(err, &netErr)
+}
+
+// circuitBreaker opens after threshold consecutive failures. Once open, it
+// lets a single call through every openTimeout to probe the backend, closing
+// again as soon as one succeeds.
+type circuitBreaker struct {
+	mu          sync.Mutex
+	threshold   int
+	openTimeout time.Duration
+	failures    int
+	openUntil   time.Time
+	probing     bool
+	now         func() time.Time
+}
+
+func newCircuitBreaker(threshold int, openTimeout time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, openTimeout: openTimeout, now: time.Now}
+}
+
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.threshold <= 0 || b.failures < b.threshold {
+		return true
+	}
+	if b.probing || b.now().Before(b.openUntil) {
+		return false
+	}
+
+	b.probing = true
+	return true
+}
+
+// abandon gives up a call without telling how it went.
+func (b *circuitBreaker) abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *circuitBreaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if !failed {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.threshold > 0 && b.failures >= b.threshold {
+		b.openUntil = b.now().Add(b.openTimeout)
+	}
+}
@@ -0,0 +1,182 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyRepository fails the first failures calls with err, or hangs them until
+// their context is done when err is nil.
+type flakyRepository struct {
+	*memoryCache
+	failures int32
+	calls    int32
+	err      error
+}
+
+func (r *flakyRepository) fail(ctx context.Context) error {
+	if atomic.AddInt32(&r.calls, 1) > atomic.LoadInt32(&r.failures) {
+		return nil
+	}
+	if r.err != nil {
+		return r.err
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (r *flakyRepository) Get(ctx context.Context, key string) (interface{}, error) {
+	if err := r.fail(ctx); err != nil {
+		return "", err
+	}
+	return r.memoryCache.Get(ctx, key)
+}
+
+func (r *flakyRepository) SetIfAbsent(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	if err := r.fail(ctx); err != nil {
+		return false, err
+	}
+	return r.memoryCache.SetIfAbsent(ctx, key, value, ttl)
+}
+
+func newFlakyRepository(failures int32, err error) *flakyRepository {
+	backend := &flakyRepository{memoryCache: newMemoryCache(1, time.Minute, 0, 0), failures: failures, err: err}
+	backend.memoryCache.Set(ctx, "someKey", "someValue")
+	return backend
+}
+
+var errConnectionRefused = &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+func TestResilientRepositoryShouldRetryIdempotentOperations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scenario      string
+		failures      int32
+		err           error
+		expectedErr   error
+		expectedCalls int32
+	}{
+		{
+			scenario:      "Should succeed after failed attempts",
+			failures:      2,
+			err:           errConnectionRefused,
+			expectedCalls: 3,
+		},
+		{
+			scenario:      "Should succeed after timed out attempts",
+			failures:      2,
+			expectedCalls: 3,
+		},
+		{
+			scenario:      "Should report unreachable backend once retries are exhausted",
+			failures:      5,
+			err:           errConnectionRefused,
+			expectedErr:   session.ErrUnavailable,
+			expectedCalls: 3,
+		},
+		{
+			scenario:      "Should report other errors as they are once retries are exhausted",
+			failures:      5,
+			err:           errors.New("WRONGTYPE"),
+			expectedCalls: 3,
+		},
+	}
+
+	for _, test := range tests {
+		backend := newFlakyRepository(test.failures, test.err)
+		repo := NewResilientRepository(backend, ResilienceConfig{
+			Timeout: 10 * time.Millisecond,
+			Retries: 2,
+			Backoff: time.Millisecond,
+		})
+
+		val, err := repo.Get(ctx, "someKey")
+		switch {
+		case test.expectedErr != nil:
+			assert.ErrorIs(t, err, test.expectedErr, test.scenario)
+		case test.failures > int32(2):
+			assert.ErrorIs(t, err, test.err, test.scenario)
+			assert.NotErrorIs(t, err, session.ErrUnavailable, test.scenario)
+		default:
+			assert.Nil(t, err, test.scenario)
+			assert.Equal(t, "someValue", val, test.scenario)
+		}
+		assert.Equal(t, test.expectedCalls, backend.calls, test.scenario)
+	}
+}
+
+func TestResilientRepositoryShouldNotRetryNonIdempotentOperations(t *testing.T) {
+	t.Parallel()
+
+	backend := newFlakyRepository(1, errConnectionRefused)
+	repo := NewResilientRepository(backend, ResilienceConfig{Retries: 2})
+
+	_, err := repo.(absentSetter).SetIfAbsent(ctx, "otherKey", "someValue", 0)
+	assert.ErrorIs(t, err, session.ErrUnavailable)
+	assert.Equal(t, int32(1), backend.calls, "Expect a single attempt")
+}
+
+func TestResilientRepositoryShouldNotCountMissingSessionsAsFailures(t *testing.T) {
+	t.Parallel()
+
+	backend := newFlakyRepository(0, nil)
+	repo := NewResilientRepository(backend, ResilienceConfig{Retries: 2, BreakerThreshold: 1, BreakerOpen: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		_, err := repo.Get(ctx, "thisSessionKeyShouldNotExist")
+		assert.ErrorIs(t, err, session.ErrNotFound)
+	}
+	assert.Equal(t, int32(3), backend.calls, "Expect missing sessions not to be retried nor to open the breaker")
+}
+
+func TestResilientRepositoryShouldFailFastWhileBreakerIsOpen(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	backend := newFlakyRepository(3, errConnectionRefused)
+	repo := NewResilientRepository(backend, ResilienceConfig{BreakerThreshold: 3, BreakerOpen: time.Minute}).(*resilientRepository)
+	repo.breaker.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		repo.Get(ctx, "someKey")
+	}
+
+	_, err := repo.Get(ctx, "someKey")
+	assert.ErrorIs(t, err, session.ErrUnavailable, "Expect open breaker to fail fast")
+	assert.ErrorContains(t, err, "circuit breaker open")
+	assert.Equal(t, int32(3), backend.calls, "Expect open breaker not to reach the backend")
+
+	now = now.Add(time.Minute)
+	val, err := repo.Get(ctx, "someKey")
+	assert.Nil(t, err, "Expect a probe to go through once the breaker has been open long enough")
+	assert.Equal(t, "someValue", val)
+
+	_, err = repo.Get(ctx, "someKey")
+	assert.Nil(t, err, "Expect successful probe to close the breaker")
+}
+
+func TestCircuitBreakerShouldLetASingleProbeThrough(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	breaker := newCircuitBreaker(1, time.Second)
+	breaker.now = func() time.Time { return now }
+
+	breaker.record(true)
+	assert.False(t, breaker.allow(), "Expect breaker to open")
+
+	now = now.Add(time.Second)
+	assert.True(t, breaker.allow(), "Expect a probe once open long enough")
+	assert.False(t, breaker.allow(), "Expect a single probe at a time")
+
+	breaker.record(true)
+	assert.False(t, breaker.allow(), "Expect failed probe to open the breaker again")
+}
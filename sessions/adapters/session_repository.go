@@ -0,0 +1,855 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+)
+
+// metadataField holds the metadata of a session next to its data, so stored
+// sessions stay JSON objects whose other top level fields are the session
+// fields, and field level reads and writes keep working.
+const metadataField = session.ReservedPrefix + "session"
+
+// sessionMetadata is stored with times and durations as Unix milliseconds. The
+// expiry of a session is not part of it, as touching the session changes it
+// without rewriting the session; it is read from the store instead.
+type sessionMetadata struct {
+	CreatedAt int64  `json:"created"`
+	UpdatedAt int64  `json:"updated"`
+	NotBefore int64  `json:"nbf,omitempty"`
+	TTL       int64  `json:"ttl,omitempty"`
+	Version   int64  `json:"version"`
+	Subject   string `json:"sub,omitempty"`
+	RotatedTo string `json:"rotated,omitempty"`
+	GraceEnds int64  `json:"grace,omitempty"`
+}
+
+type sessionRepository struct {
+	store    Store
+	expires  time.Duration
+	lifetime session.LifetimePolicy
+	now      func() time.Time
+}
+
+// NewSessionRepository keeps sessions in store, encoded as JSON objects along
+// with their metadata. Sessions expire after expires, or never when it is zero,
+// and are never kept past the absolute lifetime of the policy.
+func NewSessionRepository(store Store, expires time.Duration, lifetime session.LifetimePolicy) session.Repository {
+	return &sessionRepository{store: store, expires: expires, lifetime: lifetime, now: time.Now}
+}
+
+// Set reads the session it replaces to keep its creation time and count its
+// version, and only writes it while the store still holds what was read, when
+// the store can tell. A session past its deadline is replaced by a new one.
+func (r *sessionRepository) Set(ctx context.Context, s *session.Session, opts session.SetOptions) error {
+
+	if err := session.ValidateID(s.ID); err != nil {
+		return err
+	}
+	if err := s.Data.Validate(); err != nil {
+		return err
+	}
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	for i := 0; i < maxSwapRetries; i++ {
+		stored, err := r.trySet(ctx, s, opts)
+		if err != nil {
+			return err
+		}
+		if stored != nil {
+			*s = *stored
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: gave up writing session after %d attempts", session.ErrConflict, maxSwapRetries)
+}
+
+// trySet makes one attempt at writing s, returning nil when the session changed
+// since it was read.
+func (r *sessionRepository) trySet(ctx context.Context, s *session.Session, opts session.SetOptions) (*session.Session, error) {
+
+	now := r.now()
+	stored := session.Session{
+		ID:        s.ID,
+		Data:      s.Data,
+		CreatedAt: now,
+		UpdatedAt: now,
+		NotBefore: opts.NotBefore,
+		TTL:       r.expires,
+		Version:   1,
+		Subject:   s.Subject,
+	}
+	if opts.TTL > 0 {
+		stored.TTL = opts.TTL
+	}
+
+	raw, current, err := r.readRaw(ctx, s.ID)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil {
+		if deadline := r.lifetime.Deadline(current); !deadline.IsZero() && !now.Before(deadline) {
+			current = nil
+		}
+	}
+	if err := opts.Check(current); err != nil {
+		return nil, err
+	}
+	if current != nil {
+		if err := current.CheckWritable(); err != nil {
+			return nil, err
+		}
+		if opts.KeepSubject {
+			stored.Subject = current.Subject
+		}
+		// Sessions stored without a creation time are taken as created now,
+		// so their absolute lifetime starts counting.
+		if !current.CreatedAt.IsZero() {
+			stored.CreatedAt = current.CreatedAt
+		}
+		stored.Version = current.Version + 1
+	}
+
+	deadline := r.lifetime.Deadline(&stored)
+	if !deadline.IsZero() && !stored.NotBefore.Before(deadline) {
+		return nil, fmt.Errorf("%w: session would not be valid before its absolute lifetime ends", session.ErrInvalid)
+	}
+
+	ttl, kept := opts.TTL, false
+	if opts.KeepTTL && current != nil {
+		remaining, err := r.remaining(ctx, s.ID)
+		if err != nil && !errors.Is(err, session.ErrNotFound) {
+			return nil, err
+		}
+		// Sessions that expired meanwhile, or never expire, are written as new.
+		if remaining > 0 {
+			ttl, kept = remaining, true
+			if current.TTL > 0 {
+				stored.TTL = current.TTL
+			}
+		}
+	}
+
+	expiry := ttl
+	if expiry == 0 {
+		expiry = r.expires
+	}
+	if wait := stored.NotBefore.Sub(now); wait > 0 && expiry > 0 && !kept {
+		expiry += wait
+	}
+	if !deadline.IsZero() {
+		if left := deadline.Sub(now); expiry <= 0 || left < expiry {
+			expiry = left
+		}
+	}
+	if expiry > 0 {
+		stored.ExpiresAt = now.Add(expiry)
+	}
+
+	val, err := encodeSession(&stored)
+	if err != nil {
+		return nil, err
+	}
+	if ttl == 0 && expiry == r.expires {
+		expiry = 0
+	}
+	if stored.Subject != "" {
+		if err := r.indexSession(ctx, stored.Subject, s.ID, stored.ExpiresAt); err != nil {
+			return nil, err
+		}
+	}
+
+	written, err := r.writeIf(ctx, s.ID, raw, val, expiry, opts.Conditional())
+	if err != nil || !written {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// readRaw returns the session stored under id both as stored and decoded, or
+// nil for both when there is none. It is read from where the store writes it,
+// as it is the value the session is then written in place of.
+func (r *sessionRepository) readRaw(ctx context.Context, id string) (interface{}, *session.Session, error) {
+
+	raw, err := getPrimary(ctx, r.store, id)
+	if errors.Is(err, session.ErrNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current, err := decodeSession(id, raw)
+	if errors.Is(err, session.ErrNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return raw, current, nil
+}
+
+// writeIf stores val for ttl in place of old, the session as it was read, or
+// only when there is no session when old is nil. Stores unable to write
+// conditionally get a blind write, unless the caller set conditions.
+func (r *sessionRepository) writeIf(ctx context.Context, id string, old interface{}, val string, ttl time.Duration, conditional bool) (bool, error) {
+
+	if old == nil {
+		if setter, ok := r.store.(absentSetter); ok {
+			return setter.SetIfAbsent(ctx, id, val, ttl)
+		}
+	} else if replacer, ok := r.store.(valueReplacer); ok {
+		return replacer.Replace(ctx, id, old, val, ttl)
+	}
+
+	if conditional {
+		return false, session.ErrNotSupported
+	}
+	return true, r.write(ctx, id, val, ttl)
+}
+
+// write stores val for ttl, or for the default expiry of the store when ttl
+// is zero.
+func (r *sessionRepository) write(ctx context.Context, id string, val string, ttl time.Duration) error {
+
+	if ttl <= 0 {
+		return r.store.Set(ctx, id, val)
+	}
+
+	setter, ok := r.store.(ttlSetter)
+	if !ok {
+		return session.ErrNotSupported
+	}
+	return setter.SetWithTTL(ctx, id, val, ttl)
+}
+
+// Get fills in the expiry of the session from the time it has left in the
+// store, when the store is able to tell, and its deadline.
+func (r *sessionRepository) Get(ctx context.Context, id string) (*session.Session, error) {
+
+	if err := session.ValidateID(id); err != nil {
+		return nil, err
+	}
+
+	s, err := r.read(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := r.now()
+	if err := r.lifetime.Check(s, now); err != nil {
+		return nil, err
+	}
+
+	if err := r.fillExpiry(ctx, s, now); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// fillExpiry sets the expiry of s as read at now.
+func (r *sessionRepository) fillExpiry(ctx context.Context, s *session.Session, now time.Time) error {
+
+	remaining, err := r.remaining(ctx, s.ID)
+	switch {
+	case errors.Is(err, session.ErrNotSupported):
+	case err != nil:
+		return err
+	case remaining > 0:
+		s.ExpiresAt = now.Add(remaining)
+	}
+
+	s.ExpiresAt = r.lifetime.Expiry(s)
+	return nil
+}
+
+// Touch reads the session to check it is valid and to find its own TTL. The
+// session is never kept past its deadline. Sessions stored without a creation
+// time are written back as created now, so their absolute lifetime applies.
+func (r *sessionRepository) Touch(ctx context.Context, id string, ttl time.Duration) (time.Time, error) {
+
+	if err := session.ValidateID(id); err != nil {
+		return time.Time{}, err
+	}
+
+	expirer, ok := r.store.(expirer)
+	if !ok {
+		return time.Time{}, session.ErrNotSupported
+	}
+
+	for i := 0; i < maxSwapRetries; i++ {
+		expiresAt, touched, err := r.tryTouch(ctx, expirer, id, ttl)
+		if err != nil || touched {
+			return expiresAt, err
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: gave up touching session after %d attempts", session.ErrConflict, maxSwapRetries)
+}
+
+// tryTouch makes one attempt at touching the session, reporting false when it
+// changed since it was read. It returns when the session expires.
+func (r *sessionRepository) tryTouch(ctx context.Context, expirer expirer, id string, ttl time.Duration) (time.Time, bool, error) {
+
+	raw, current, err := r.readRaw(ctx, id)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if current == nil {
+		return time.Time{}, false, session.ErrNotFound
+	}
+
+	now := r.now()
+	if err := r.lifetime.Check(current, now); err != nil {
+		return time.Time{}, false, err
+	}
+	if err := current.CheckWritable(); err != nil {
+		return time.Time{}, false, err
+	}
+
+	backfill := current.CreatedAt.IsZero() && r.lifetime.Absolute > 0
+	if backfill {
+		current.CreatedAt = now
+	}
+
+	if ttl == 0 {
+		if ttl = current.TTL; ttl == 0 {
+			ttl = r.expires
+		}
+	}
+	if deadline := r.lifetime.Deadline(current); !deadline.IsZero() {
+		if left := deadline.Sub(now); ttl <= 0 || left < ttl {
+			ttl = left
+		}
+	}
+	if ttl <= 0 {
+		// The session never expires, there is nothing to extend.
+		return time.Time{}, true, nil
+	}
+
+	if backfill {
+		val, err := encodeSession(current)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		written, err := r.writeIf(ctx, id, raw, val, ttl, false)
+		if err != nil || !written {
+			return time.Time{}, false, err
+		}
+		return now.Add(ttl), true, nil
+	}
+
+	if err := expirer.Expire(ctx, id, ttl); err != nil {
+		return time.Time{}, false, err
+	}
+	return now.Add(ttl), true, nil
+}
+
+// Patch applies patch to the session read, and writes it back for the time it
+// has left while the store still holds what was read. When the store cannot
+// tell the time left, the session is kept for its own TTL again.
+func (r *sessionRepository) Patch(ctx context.Context, id string, patch session.Patch, ifVersion int64) (int64, error) {
+	patched, err := r.patch(ctx, id, patch, ifVersion)
+	if err != nil {
+		return 0, err
+	}
+	return patched.Version, nil
+}
+
+// Increment has the store increment the counter in place when it can, which
+// concurrent writes cannot fail, once the session is found valid. It patches
+// the session with the increment otherwise, as with sessions stored without
+// metadata, so it is only written while no other write got in since it was
+// read.
+func (r *sessionRepository) Increment(ctx context.Context, id string, path session.Pointer, delta int64) (int64, error) {
+
+	if err := session.ValidateID(id); err != nil {
+		return 0, err
+	}
+	if len(path) > 0 {
+		if err := session.ValidateField(path[0]); err != nil {
+			return 0, err
+		}
+	}
+
+	if incrementer, ok := r.store.(incrementer); ok && len(path) > 0 {
+		_, current, err := r.readRaw(ctx, id)
+		if err != nil {
+			return 0, err
+		}
+		if current == nil {
+			return 0, session.ErrNotFound
+		}
+
+		now := r.now()
+		if err := r.lifetime.Check(current, now); err != nil {
+			return 0, err
+		}
+		if err := current.CheckWritable(); err != nil {
+			return 0, err
+		}
+
+		counter, err := incrementer.Increment(ctx, id, path, delta, now)
+		if !errors.Is(err, session.ErrNotSupported) {
+			return counter, err
+		}
+	}
+
+	patched, err := r.patch(ctx, id, session.Increment{Path: path, Delta: delta}, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := appended(patched.Data, path).Get(patched.Data)
+	if err != nil {
+		return 0, err
+	}
+	counter, _ := session.Counter(value)
+	return counter, nil
+}
+
+// patch makes attempts at patching the session until one is not overtaken by
+// another write, and returns the session as written.
+func (r *sessionRepository) patch(ctx context.Context, id string, patch session.Patch, ifVersion int64) (*session.Session, error) {
+
+	if err := session.ValidateID(id); err != nil {
+		return nil, err
+	}
+
+	replacer, ok := r.store.(valueReplacer)
+	if !ok {
+		return nil, session.ErrNotSupported
+	}
+
+	opts := session.SetOptions{IfVersion: ifVersion}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < maxSwapRetries; i++ {
+		s, patched, err := r.tryPatch(ctx, replacer, id, patch, opts)
+		if err != nil || patched {
+			return s, err
+		}
+	}
+	return nil, fmt.Errorf("%w: gave up patching session after %d attempts", session.ErrConflict, maxSwapRetries)
+}
+
+// tryPatch makes one attempt at patching the session, reporting false when it
+// changed since it was read. It returns the session as patched.
+func (r *sessionRepository) tryPatch(ctx context.Context, replacer valueReplacer, id string, patch session.Patch, opts session.SetOptions) (*session.Session, bool, error) {
+
+	raw, current, err := r.readRaw(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	if current == nil {
+		return nil, false, session.ErrNotFound
+	}
+
+	now := r.now()
+	if err := r.lifetime.Check(current, now); err != nil {
+		return nil, false, err
+	}
+	if err := opts.Check(current); err != nil {
+		return nil, false, err
+	}
+	if err := current.CheckWritable(); err != nil {
+		return nil, false, err
+	}
+
+	data, err := patch.Apply(current.Data)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := data.Validate(); err != nil {
+		return nil, false, err
+	}
+
+	ttl, err := r.remaining(ctx, id)
+	switch {
+	case errors.Is(err, session.ErrNotSupported):
+		// The expiry of the session restarts, so does the one it is indexed
+		// with.
+		ttl = current.TTL
+		if current.Subject != "" {
+			if err := r.indexSession(ctx, current.Subject, id, r.expiry(current, ttl, now)); err != nil {
+				return nil, false, err
+			}
+		}
+	case errors.Is(err, session.ErrNotFound):
+		// The session expired meanwhile, the next attempt finds it missing.
+		return nil, false, nil
+	case err != nil:
+		return nil, false, err
+	case ttl < 0:
+		ttl = 0
+	}
+
+	current.Data = data
+	current.UpdatedAt = now
+	current.Version++
+
+	val, err := encodeSession(current)
+	if err != nil {
+		return nil, false, err
+	}
+	replaced, err := replacer.Replace(ctx, id, raw, val, ttl)
+	return current, replaced, err
+}
+
+// Rotate writes the session read under newID only when it is not in use, then
+// replaces the old one with its read-only copy for the grace period, or
+// deletes it, while the store still holds what was read, starting over when
+// it does not. The session keeps its creation time, so its
+// absolute lifetime is not restarted.
+func (r *sessionRepository) Rotate(ctx context.Context, id, newID string, grace time.Duration) (*session.Session, error) {
+
+	if grace < 0 {
+		return nil, fmt.Errorf("%w: grace period cannot be negative", session.ErrInvalid)
+	}
+	if id == newID {
+		return nil, fmt.Errorf("%w: session cannot be rotated to the same key", session.ErrInvalid)
+	}
+	if err := session.ValidateID(id); err != nil {
+		return nil, err
+	}
+	if err := session.ValidateID(newID); err != nil {
+		return nil, err
+	}
+
+	setter, ok := r.store.(absentSetter)
+	if !ok {
+		return nil, session.ErrNotSupported
+	}
+	replacer, ok := r.store.(valueReplacer)
+	if !ok {
+		return nil, session.ErrNotSupported
+	}
+	deleter, ok := r.store.(valueDeleter)
+	if !ok {
+		return nil, session.ErrNotSupported
+	}
+
+	for i := 0; i < maxSwapRetries; i++ {
+		rotated, err := r.tryRotate(ctx, setter, replacer, deleter, id, newID, grace)
+		if err != nil || rotated != nil {
+			return rotated, err
+		}
+	}
+	return nil, fmt.Errorf("%w: gave up rotating session after %d attempts", session.ErrConflict, maxSwapRetries)
+}
+
+// tryRotate makes one attempt at rotating the session, returning nil when it
+// changed since it was read.
+func (r *sessionRepository) tryRotate(ctx context.Context, setter absentSetter, replacer valueReplacer, deleter valueDeleter, id, newID string, grace time.Duration) (*session.Session, error) {
+
+	raw, current, err := r.readRaw(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, session.ErrNotFound
+	}
+
+	now := r.now()
+	if err := r.lifetime.Check(current, now); err != nil {
+		return nil, err
+	}
+	if err := current.CheckWritable(); err != nil {
+		return nil, err
+	}
+
+	ttl, err := r.remaining(ctx, id)
+	switch {
+	case errors.Is(err, session.ErrNotSupported):
+		ttl = current.TTL
+	case errors.Is(err, session.ErrNotFound):
+		// The session expired meanwhile, the next attempt finds it missing.
+		return nil, nil
+	case err != nil:
+		return nil, err
+	case ttl < 0:
+		ttl = 0
+	}
+
+	rotated := *current
+	rotated.ID = newID
+	rotated.UpdatedAt = now
+	rotated.Version++
+	if ttl > 0 {
+		rotated.ExpiresAt = now.Add(ttl)
+	}
+	rotated.ExpiresAt = r.lifetime.Expiry(&rotated)
+
+	if rotated.Subject != "" {
+		if err := r.indexSession(ctx, rotated.Subject, newID, rotated.ExpiresAt); err != nil {
+			return nil, err
+		}
+	}
+
+	val, err := encodeSession(&rotated)
+	if err != nil {
+		return nil, err
+	}
+
+	// During the grace period the old key holds a read-only copy pointing to
+	// the new one, which is neither extended nor kept past it.
+	var tombstone string
+	if grace > 0 {
+		if ttl > 0 && ttl < grace {
+			grace = ttl
+		}
+		old := *current
+		old.RotatedTo = newID
+		old.GraceEnds = now.Add(grace)
+		old.UpdatedAt = now
+		old.Version++
+		if tombstone, err = encodeSession(&old); err != nil {
+			return nil, err
+		}
+	}
+
+	written, err := setter.SetIfAbsent(ctx, newID, val, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !written {
+		return nil, fmt.Errorf("%w: session key '%s' is in use", session.ErrPreconditionFailed, newID)
+	}
+
+	var invalidated bool
+	if grace > 0 {
+		invalidated, err = replacer.Replace(ctx, id, raw, tombstone, grace)
+	} else {
+		invalidated, err = deleter.DeleteIf(ctx, id, raw)
+	}
+	if err != nil || !invalidated {
+		// The session changed under its old key, the copy is stale.
+		if _, delErr := r.store.Delete(ctx, newID); delErr != nil && err == nil {
+			err = delErr
+		}
+		return nil, err
+	}
+
+	// The old key is gone without a grace period, so is its place in the index.
+	if indexer, ok := r.store.(subjectIndexer); ok && grace == 0 && current.Subject != "" {
+		r.unindex(ctx, indexer, current.Subject, []string{id})
+	}
+	return &rotated, nil
+}
+
+// read returns the session stored under id, without its expiry and whether
+// it is valid or not.
+func (r *sessionRepository) read(ctx context.Context, id string) (*session.Session, error) {
+
+	val, err := r.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSession(id, val)
+}
+
+// expiry returns when a session written at now for ttl, or for the default
+// expiry when ttl is zero, expires, zero when it never does.
+func (r *sessionRepository) expiry(s *session.Session, ttl time.Duration, now time.Time) time.Time {
+	if ttl == 0 {
+		ttl = r.expires
+	}
+	expiring := *s
+	expiring.ExpiresAt = time.Time{}
+	if ttl > 0 {
+		expiring.ExpiresAt = now.Add(ttl)
+	}
+	return r.lifetime.Expiry(&expiring)
+}
+
+// remaining returns the time the session has left in the store, negative when
+// it never expires.
+func (r *sessionRepository) remaining(ctx context.Context, id string) (time.Duration, error) {
+
+	reader, ok := r.store.(ttlReader)
+	if !ok {
+		return 0, session.ErrNotSupported
+	}
+	return reader.TTL(ctx, id)
+}
+
+func (r *sessionRepository) Delete(ctx context.Context, id string) error {
+	if err := session.ValidateID(id); err != nil {
+		return err
+	}
+	_, err := r.store.Delete(ctx, id)
+	return err
+}
+
+// GetFields reads the metadata of the session along with fields, to check the
+// session is valid.
+func (r *sessionRepository) GetFields(ctx context.Context, id string, fields []string) (session.Data, error) {
+
+	if err := session.ValidateID(id); err != nil {
+		return nil, err
+	}
+
+	for _, field := range fields {
+		if err := session.ValidateField(field); err != nil {
+			return nil, err
+		}
+	}
+
+	fieldStore, err := fieldStore(r.store)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := fieldStore.GetFields(ctx, id, append(fields[:len(fields):len(fields)], metadataField))
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := r.checkMetadata(id, values)
+	if err != nil {
+		return nil, err
+	}
+	if metadata != nil {
+		delete(values, metadataField)
+	}
+	return values, nil
+}
+
+// SetFields patches the session with values, so it is only written while no
+// other write got in since it was read, keeping the time it has left.
+func (r *sessionRepository) SetFields(ctx context.Context, id string, values session.Data, ifVersion int64) (int64, error) {
+
+	if err := values.Validate(); err != nil {
+		return 0, err
+	}
+
+	return r.Patch(ctx, id, session.SetFields(values), ifVersion)
+}
+
+// checkMetadata decodes the metadata held by the fields of the session stored
+// under id, and checks the session is valid. It returns nil metadata for
+// sessions stored without it.
+func (r *sessionRepository) checkMetadata(id string, fields map[string]interface{}) (*sessionMetadata, error) {
+
+	raw, ok := fields[metadataField]
+	if !ok {
+		return nil, nil
+	}
+
+	metadata, err := decodeMetadata(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.lifetime.Check(metadata.session(id, nil), r.now()); err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
+
+// encodeSession returns the JSON object stored for s.
+func encodeSession(s *session.Session) (string, error) {
+
+	stored := make(map[string]interface{}, len(s.Data)+1)
+	for field, value := range s.Data {
+		stored[field] = value
+	}
+	stored[metadataField] = sessionMetadata{
+		CreatedAt: unixMilli(s.CreatedAt),
+		UpdatedAt: unixMilli(s.UpdatedAt),
+		NotBefore: unixMilli(s.NotBefore),
+		TTL:       s.TTL.Milliseconds(),
+		Version:   s.Version,
+		Subject:   s.Subject,
+		RotatedTo: s.RotatedTo,
+		GraceEnds: unixMilli(s.GraceEnds),
+	}
+
+	val, err := json.Marshal(stored)
+	if err != nil {
+		return "", fmt.Errorf("error '%s' when encoding session", err)
+	}
+	return string(val), nil
+}
+
+// decodeSession reads the session stored under id as val. Sessions stored
+// without metadata are returned with zero timestamps, TTL and version.
+func decodeSession(id string, val interface{}) (*session.Session, error) {
+
+	str, err := encodeValue(val)
+	if err != nil {
+		return nil, err
+	}
+	if str == "" {
+		return nil, session.ErrNotFound
+	}
+
+	var data session.Data
+	if err := json.Unmarshal([]byte(str), &data); err != nil {
+		return nil, fmt.Errorf("error '%s' when decoding session", err)
+	}
+
+	var metadata sessionMetadata
+	if raw, ok := data[metadataField]; ok {
+		if metadata, err = decodeMetadata(raw); err != nil {
+			return nil, err
+		}
+		delete(data, metadataField)
+	}
+	if data == nil {
+		data = session.Data{}
+	}
+
+	return metadata.session(id, data), nil
+}
+
+// session returns the session with the metadata m and data.
+func (m sessionMetadata) session(id string, data session.Data) *session.Session {
+	return &session.Session{
+		ID:        id,
+		Data:      data,
+		CreatedAt: fromUnixMilli(m.CreatedAt),
+		UpdatedAt: fromUnixMilli(m.UpdatedAt),
+		NotBefore: fromUnixMilli(m.NotBefore),
+		TTL:       time.Duration(m.TTL) * time.Millisecond,
+		Version:   m.Version,
+		Subject:   m.Subject,
+		RotatedTo: m.RotatedTo,
+		GraceEnds: fromUnixMilli(m.GraceEnds),
+	}
+}
+
+// decodeMetadata reads the metadata of a session out of its decoded JSON.
+func decodeMetadata(raw interface{}) (sessionMetadata, error) {
+
+	var metadata sessionMetadata
+	encoded, err := json.Marshal(raw)
+	if err == nil {
+		err = json.Unmarshal(encoded, &metadata)
+	}
+	if err != nil {
+		return sessionMetadata{}, fmt.Errorf("error '%s' when decoding session metadata", err)
+	}
+	return metadata, nil
+}
+
+func unixMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+func fromUnixMilli(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
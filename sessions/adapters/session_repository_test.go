@@ -0,0 +1,775 @@
+package adapters
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSessionRepository(now *time.Time) (*sessionRepository, *memoryCache) {
+	store := newMemoryCache(defaultMemoryShards, time.Minute, 0, 0)
+	repo := NewSessionRepository(store, time.Minute, session.LifetimePolicy{}).(*sessionRepository)
+	repo.now = func() time.Time { return *now }
+	return repo, store
+}
+
+func TestShouldKeepSessionMetadataAcrossWrites(t *testing.T) {
+	t.Parallel()
+
+	now := time.UnixMilli(1_000_000)
+	repo, store := newTestSessionRepository(&now)
+	defer store.Close()
+
+	created := &session.Session{ID: "someSessionKey", Data: session.Data{"user": "someUser"}}
+	err := repo.Set(ctx, created, session.SetOptions{})
+	assert.Nil(t, err, "Expect err is nil when storing a session")
+	assert.Equal(t, int64(1), created.Version, "Expect a new session to be at version 1")
+	assert.Equal(t, now, created.CreatedAt)
+	assert.Equal(t, now.Add(time.Minute), created.ExpiresAt)
+
+	stored, err := repo.Get(ctx, "someSessionKey")
+	assert.Nil(t, err, "Expect err is nil when reading a session")
+	assert.WithinDuration(t, created.ExpiresAt, stored.ExpiresAt, time.Second, "Expect expiry to be read from the store")
+	stored.ExpiresAt = created.ExpiresAt
+	assert.Equal(t, created, stored, "Expect session to be read as stored")
+
+	createdAt := now
+	now = now.Add(time.Second)
+
+	updated := &session.Session{ID: "someSessionKey", Data: session.Data{"user": "otherUser"}}
+	err = repo.Set(ctx, updated, session.SetOptions{})
+	assert.Nil(t, err, "Expect err is nil when replacing a session")
+	assert.Equal(t, int64(2), updated.Version, "Expect version to count the writes")
+	assert.Equal(t, createdAt, updated.CreatedAt, "Expect creation time to be kept")
+	assert.Equal(t, now, updated.UpdatedAt)
+
+	now = now.Add(time.Second)
+	_, err = repo.SetFields(ctx, "someSessionKey", session.Data{"theme": "dark"}, 0)
+	assert.Nil(t, err, "Expect err is nil when setting fields")
+
+	stored, err = repo.Get(ctx, "someSessionKey")
+	assert.Nil(t, err, "Expect err is nil when reading a session")
+	assert.Equal(t, session.Data{"user": "otherUser", "theme": "dark"}, stored.Data)
+	assert.Equal(t, int64(3), stored.Version, "Expect setting fields to count as a write")
+	assert.Equal(t, now, stored.UpdatedAt)
+	assert.Equal(t, createdAt, stored.CreatedAt)
+
+	fields, err := repo.GetFields(ctx, "someSessionKey", []string{"theme", "missing"})
+	assert.Nil(t, err, "Expect err is nil when getting fields")
+	assert.Equal(t, session.Data{"theme": "dark"}, fields, "Expect only the data fields held to be returned")
+}
+
+func TestShouldReadSessionsStoredWithoutMetadata(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	repo, store := newTestSessionRepository(&now)
+	defer store.Close()
+
+	err := store.Set(ctx, "someSessionKey", `{"user":"someUser"}`)
+	assert.Nil(t, err, "Expect err is nil when storing session value")
+
+	stored, err := repo.Get(ctx, "someSessionKey")
+	assert.Nil(t, err, "Expect err is nil when reading a session without metadata")
+	assert.WithinDuration(t, now.Add(time.Minute), stored.ExpiresAt, time.Second, "Expect expiry to be read from the store")
+	stored.ExpiresAt = time.Time{}
+	assert.Equal(t, &session.Session{ID: "someSessionKey", Data: session.Data{"user": "someUser"}}, stored)
+
+	_, err = repo.Get(ctx, "thisSessionKeyShouldNotExist")
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect missing session to be reported")
+
+	err = store.Set(ctx, "someBrokenKey", `not json`)
+	assert.Nil(t, err, "Expect err is nil when storing session value")
+	_, err = repo.Get(ctx, "someBrokenKey")
+	assert.NotNil(t, err, "Expect a session that is not JSON to fail decoding")
+}
+
+func TestShouldRejectReservedSessionFields(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	repo, store := newTestSessionRepository(&now)
+	defer store.Close()
+
+	err := repo.Set(ctx, &session.Session{ID: "someSessionKey", Data: session.Data{"__session": "forged"}}, session.SetOptions{})
+	assert.ErrorIs(t, err, session.ErrInvalid, "Expect reserved fields to be rejected when storing a session")
+
+	err = repo.Set(ctx, &session.Session{ID: "someSessionKey", Data: session.Data{"user": "someUser"}}, session.SetOptions{})
+	assert.Nil(t, err, "Expect err is nil when storing a session")
+
+	_, err = repo.SetFields(ctx, "someSessionKey", session.Data{"__encrypted": "forged"}, 0)
+	assert.ErrorIs(t, err, session.ErrInvalid, "Expect reserved fields to be rejected when setting fields")
+
+	_, err = repo.GetFields(ctx, "someSessionKey", []string{"__session"})
+	assert.ErrorIs(t, err, session.ErrInvalid, "Expect reserved fields to be rejected when getting fields")
+}
+
+func TestShouldStoreSessionForRequestedTTL(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	repo, store := newTestSessionRepository(&now)
+	defer store.Close()
+
+	s := &session.Session{ID: "someSessionKey", Data: session.Data{"user": "someUser"}}
+	err := repo.Set(ctx, s, session.SetOptions{TTL: 10 * time.Second})
+	assert.Nil(t, err, "Expect err is nil when storing a session for a given ttl")
+	assert.Equal(t, now.Add(10*time.Second), s.ExpiresAt, "Expect expiry to follow the requested ttl")
+
+	ttl, err := store.TTL(ctx, "someSessionKey")
+	assert.Nil(t, err, "Expect err is nil when reading the ttl")
+	assert.True(t, ttl > 9*time.Second && ttl <= 10*time.Second, "Expect session to be stored for the requested ttl, got %s", ttl)
+}
+
+func TestShouldTouchSessionWithoutRewritingIt(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	repo, store := newTestSessionRepository(&now)
+	defer store.Close()
+
+	s := &session.Session{ID: "someSessionKey", Data: session.Data{"user": "someUser"}}
+	err := repo.Set(ctx, s, session.SetOptions{TTL: 10 * time.Second})
+	assert.Nil(t, err, "Expect err is nil when storing a session")
+
+	expiresAt, err := repo.Touch(ctx, "someSessionKey", time.Hour)
+	assert.Nil(t, err, "Expect err is nil when touching a session for a given ttl")
+	assert.Equal(t, now.Add(time.Hour), expiresAt)
+
+	ttl, err := store.TTL(ctx, "someSessionKey")
+	assert.Nil(t, err, "Expect err is nil when reading the ttl")
+	assert.True(t, ttl > 59*time.Minute, "Expect session to be kept for the touched ttl, got %s", ttl)
+
+	expiresAt, err = repo.Touch(ctx, "someSessionKey", 0)
+	assert.Nil(t, err, "Expect err is nil when touching a session for its own ttl")
+	assert.Equal(t, now.Add(10*time.Second), expiresAt, "Expect session to be touched for the ttl it was stored with")
+
+	stored, err := repo.Get(ctx, "someSessionKey")
+	assert.Nil(t, err, "Expect err is nil when reading a touched session")
+	assert.Equal(t, s.Version, stored.Version, "Expect touching not to count as a write")
+	assert.Equal(t, 10*time.Second, stored.TTL)
+	assert.WithinDuration(t, expiresAt, stored.ExpiresAt, time.Second, "Expect expiry to follow the touch")
+
+	_, err = repo.Touch(ctx, "thisSessionKeyShouldNotExist", 0)
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect missing session to be reported")
+	_, err = repo.Touch(ctx, "thisSessionKeyShouldNotExist", time.Hour)
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect missing session to be reported")
+}
+
+func TestShouldKeepSessionTTLWhenAsked(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	repo, store := newTestSessionRepository(&now)
+	defer store.Close()
+
+	err := repo.Set(ctx, &session.Session{ID: "someSessionKey", Data: session.Data{"user": "someUser"}}, session.SetOptions{TTL: 10 * time.Second})
+	assert.Nil(t, err, "Expect err is nil when storing a session")
+
+	updated := &session.Session{ID: "someSessionKey", Data: session.Data{"user": "otherUser"}}
+	err = repo.Set(ctx, updated, session.SetOptions{TTL: time.Hour, KeepTTL: true})
+	assert.Nil(t, err, "Expect err is nil when replacing a session keeping its ttl")
+	assert.Equal(t, 10*time.Second, updated.TTL, "Expect the session to keep its own ttl")
+	assert.WithinDuration(t, now.Add(10*time.Second), updated.ExpiresAt, time.Second, "Expect expiry not to be restarted")
+
+	ttl, err := store.TTL(ctx, "someSessionKey")
+	assert.Nil(t, err, "Expect err is nil when reading the ttl")
+	assert.True(t, ttl <= 10*time.Second, "Expect session to keep the time it had left, got %s", ttl)
+
+	created := &session.Session{ID: "otherSessionKey", Data: session.Data{"user": "someUser"}}
+	err = repo.Set(ctx, created, session.SetOptions{TTL: time.Hour, KeepTTL: true})
+	assert.Nil(t, err, "Expect err is nil when creating a session keeping its ttl")
+	assert.Equal(t, now.Add(time.Hour), created.ExpiresAt, "Expect a new session to be stored for the requested ttl")
+}
+
+func TestShouldKeepSessionSubjectWhenAsked(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	repo, store := newTestSessionRepository(&now)
+	defer store.Close()
+
+	err := repo.Set(ctx, &session.Session{ID: "someSessionKey", Subject: "someUser"}, session.SetOptions{})
+	assert.Nil(t, err, "Expect err is nil when storing a session")
+
+	updated := &session.Session{ID: "someSessionKey", Data: session.Data{"n": 1}}
+	err = repo.Set(ctx, updated, session.SetOptions{KeepSubject: true})
+	assert.Nil(t, err, "Expect err is nil when replacing a session keeping its subject")
+	assert.Equal(t, "someUser", updated.Subject, "Expect the session to keep its subject")
+
+	stored, err := repo.Get(ctx, "someSessionKey")
+	assert.Nil(t, err, "Expect err is nil when reading a session")
+	assert.Equal(t, "someUser", stored.Subject, "Expect the subject to be stored again")
+
+	err = repo.Set(ctx, &session.Session{ID: "someSessionKey"}, session.SetOptions{})
+	assert.Nil(t, err, "Expect err is nil when replacing a session clearing its subject")
+	stored, err = repo.Get(ctx, "someSessionKey")
+	assert.Nil(t, err, "Expect err is nil when reading a session")
+	assert.Empty(t, stored.Subject, "Expect the subject to be cleared")
+
+	created := &session.Session{ID: "otherSessionKey", Subject: "someUser"}
+	err = repo.Set(ctx, created, session.SetOptions{KeepSubject: true})
+	assert.Nil(t, err, "Expect err is nil when creating a session keeping its subject")
+	assert.Equal(t, "someUser", created.Subject, "Expect a new session to belong to the subject given")
+}
+
+func TestShouldNotKeepSessionsPastTheirAbsoluteLifetime(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	repo, store := newTestSessionRepository(&now)
+	defer store.Close()
+	repo.lifetime = session.LifetimePolicy{Idle: true, Absolute: 90 * time.Second}
+
+	s := &session.Session{ID: "someSessionKey", Data: session.Data{"user": "someUser"}}
+	err := repo.Set(ctx, s, session.SetOptions{})
+	assert.Nil(t, err, "Expect err is nil when storing a session")
+	assert.Equal(t, now.Add(time.Minute), s.ExpiresAt, "Expect expiry to follow the ttl while before the deadline")
+
+	now = now.Add(50 * time.Second)
+	expiresAt, err := repo.Touch(ctx, "someSessionKey", 0)
+	assert.Nil(t, err, "Expect err is nil when touching a session")
+	assert.WithinDuration(t, s.CreatedAt.Add(90*time.Second), expiresAt, time.Millisecond, "Expect touch not to extend the session past its deadline")
+
+	ttl, err := store.TTL(ctx, "someSessionKey")
+	assert.Nil(t, err, "Expect err is nil when reading the ttl")
+	assert.True(t, ttl <= 40*time.Second, "Expect session to be stored up to its deadline, got %s", ttl)
+
+	stored, err := repo.Get(ctx, "someSessionKey")
+	assert.Nil(t, err, "Expect err is nil when reading a session before its deadline")
+	assert.WithinDuration(t, expiresAt, stored.ExpiresAt, time.Second, "Expect the effective expiry to be reported")
+
+	now = now.Add(40 * time.Second)
+	_, err = repo.Get(ctx, "someSessionKey")
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect a session past its deadline not to be found")
+	_, err = repo.Touch(ctx, "someSessionKey", time.Hour)
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect a session past its deadline not to be touched")
+	_, err = repo.GetFields(ctx, "someSessionKey", []string{"user"})
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect fields of a session past its deadline not to be found")
+
+	replaced := &session.Session{ID: "someSessionKey", Data: session.Data{"user": "otherUser"}}
+	err = repo.Set(ctx, replaced, session.SetOptions{})
+	assert.Nil(t, err, "Expect err is nil when replacing a session past its deadline")
+	assert.Equal(t, now, replaced.CreatedAt, "Expect a session past its deadline to be replaced by a new one")
+	assert.Equal(t, int64(1), replaced.Version)
+}
+
+func TestShouldStartAbsoluteLifetimeOfSessionsStoredWithoutCreationTime(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	repo, store := newTestSessionRepository(&now)
+	defer store.Close()
+	repo.lifetime = session.LifetimePolicy{Absolute: 90 * time.Second}
+
+	store.Set(ctx, "someTouchedKey", `{"user":"someUser"}`)
+	store.Set(ctx, "someWrittenKey", `{"user":"someUser"}`)
+
+	expiresAt, err := repo.Touch(ctx, "someTouchedKey", time.Hour)
+	assert.Nil(t, err, "Expect err is nil when touching a session stored without metadata")
+	assert.Equal(t, now.Add(90*time.Second), expiresAt, "Expect touch not to extend the session past its deadline")
+
+	written := &session.Session{ID: "someWrittenKey", Data: session.Data{"user": "otherUser"}}
+	err = repo.Set(ctx, written, session.SetOptions{TTL: time.Hour})
+	assert.Nil(t, err, "Expect err is nil when writing a session stored without metadata")
+	assert.Equal(t, now.Add(90*time.Second), written.ExpiresAt, "Expect write not to keep the session past its deadline")
+
+	now = now.Add(90 * time.Second)
+	for _, id := range []string{"someTouchedKey", "someWrittenKey"} {
+		_, err = repo.Get(ctx, id)
+		assert.ErrorIs(t, err, session.ErrNotFound, "Expect session '%s' not to be found past its deadline", id)
+	}
+}
+
+func TestShouldNotFindSessionsBeforeTheyAreValid(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	repo, store := newTestSessionRepository(&now)
+	defer store.Close()
+
+	notBefore := now.Add(time.Hour)
+	s := &session.Session{ID: "someSessionKey", Data: session.Data{"user": "someUser"}}
+	err := repo.Set(ctx, s, session.SetOptions{NotBefore: notBefore})
+	assert.Nil(t, err, "Expect err is nil when storing a session valid in the future")
+	assert.Equal(t, notBefore.Add(time.Minute), s.ExpiresAt, "Expect ttl to count from when the session becomes valid")
+
+	_, err = repo.Get(ctx, "someSessionKey")
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect a session not valid yet not to be found")
+	_, err = repo.GetFields(ctx, "someSessionKey", []string{"user"})
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect fields of a session not valid yet not to be found")
+	_, err = repo.SetFields(ctx, "someSessionKey", session.Data{"theme": "dark"}, 0)
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect a session not valid yet not to be updated")
+	_, err = repo.Touch(ctx, "someSessionKey", 0)
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect a session not valid yet not to be touched")
+
+	now = notBefore
+	stored, err := repo.Get(ctx, "someSessionKey")
+	assert.Nil(t, err, "Expect err is nil when reading a session once valid")
+	assert.Equal(t, notBefore.UnixMilli(), stored.NotBefore.UnixMilli())
+
+	fields, err := repo.GetFields(ctx, "someSessionKey", []string{"user"})
+	assert.Nil(t, err, "Expect err is nil when reading fields of a session once valid")
+	assert.Equal(t, session.Data{"user": "someUser"}, fields)
+
+	repo.lifetime = session.LifetimePolicy{Absolute: time.Hour}
+	err = repo.Set(ctx, &session.Session{ID: "otherSessionKey"}, session.SetOptions{NotBefore: now.Add(2 * time.Hour)})
+	assert.ErrorIs(t, err, session.ErrInvalid, "Expect a session that would never be valid to be rejected")
+}
+
+func TestShouldOnlyWriteSessionsWhenConditionsHold(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	repo, store := newTestSessionRepository(&now)
+	defer store.Close()
+
+	err := repo.Set(ctx, &session.Session{ID: "someSessionKey", Data: session.Data{}}, session.SetOptions{UpdateOnly: true})
+	assert.ErrorIs(t, err, session.ErrPreconditionFailed, "Expect a missing session not to be updated")
+
+	err = repo.Set(ctx, &session.Session{ID: "someSessionKey", Data: session.Data{}}, session.SetOptions{IfVersion: 1})
+	assert.ErrorIs(t, err, session.ErrPreconditionFailed, "Expect a missing session not to match a version")
+
+	created := &session.Session{ID: "someSessionKey", Data: session.Data{"user": "someUser"}}
+	err = repo.Set(ctx, created, session.SetOptions{CreateOnly: true})
+	assert.Nil(t, err, "Expect err is nil when creating a session")
+	assert.Equal(t, int64(1), created.Version)
+
+	err = repo.Set(ctx, &session.Session{ID: "someSessionKey", Data: session.Data{}}, session.SetOptions{CreateOnly: true})
+	assert.ErrorIs(t, err, session.ErrPreconditionFailed, "Expect an existing session not to be created again")
+
+	err = repo.Set(ctx, &session.Session{ID: "someSessionKey", Data: session.Data{}}, session.SetOptions{IfVersion: 2})
+	assert.ErrorIs(t, err, session.ErrPreconditionFailed, "Expect a session at another version not to be written")
+
+	updated := &session.Session{ID: "someSessionKey", Data: session.Data{"user": "otherUser"}}
+	err = repo.Set(ctx, updated, session.SetOptions{IfVersion: 1})
+	assert.Nil(t, err, "Expect err is nil when the session is at the expected version")
+	assert.Equal(t, int64(2), updated.Version)
+
+	err = repo.Set(ctx, &session.Session{ID: "someSessionKey", Data: session.Data{}}, session.SetOptions{IfVersion: 1})
+	assert.ErrorIs(t, err, session.ErrPreconditionFailed, "Expect a stale version not to be written")
+
+	stored, err := repo.Get(ctx, "someSessionKey")
+	assert.Nil(t, err, "Expect err is nil when reading a session")
+	assert.Equal(t, session.Data{"user": "otherUser"}, stored.Data, "Expect failed writes to leave the session alone")
+
+	err = repo.Set(ctx, &session.Session{ID: "someSessionKey", Data: session.Data{}}, session.SetOptions{CreateOnly: true, IfVersion: 2})
+	assert.ErrorIs(t, err, session.ErrInvalid, "Expect conditions that cannot hold together to be rejected")
+}
+
+// racingStore changes the session under the repository between its read and
+// its write, as many times as told to.
+type racingStore struct {
+	*memoryCache
+	races int
+}
+
+func (s *racingStore) Replace(ctx context.Context, key string, old, new interface{}, ttl time.Duration) (bool, error) {
+	if s.races > 0 {
+		s.races--
+		val, _ := s.memoryCache.Get(ctx, key)
+		str := val.(string)
+		last := strings.LastIndex(str, "}")
+		s.memoryCache.Set(ctx, key, str[:last]+`,"racer":true}`)
+	}
+	return s.memoryCache.Replace(ctx, key, old, new, ttl)
+}
+
+func TestShouldRetrySessionWritesLosingARace(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	memory := newMemoryCache(defaultMemoryShards, time.Minute, 0, 0)
+	defer memory.Close()
+	store := &racingStore{memoryCache: memory, races: 1}
+	repo := NewSessionRepository(store, time.Minute, session.LifetimePolicy{}).(*sessionRepository)
+	repo.now = func() time.Time { return now }
+
+	err := repo.Set(ctx, &session.Session{ID: "someSessionKey", Data: session.Data{}}, session.SetOptions{})
+	assert.Nil(t, err, "Expect err is nil when storing a session")
+
+	updated := &session.Session{ID: "someSessionKey", Data: session.Data{"user": "someUser"}}
+	err = repo.Set(ctx, updated, session.SetOptions{})
+	assert.Nil(t, err, "Expect a write losing a race to be tried again")
+	assert.Equal(t, int64(2), updated.Version)
+
+	store.races = maxSwapRetries
+	err = repo.Set(ctx, &session.Session{ID: "someSessionKey", Data: session.Data{}}, session.SetOptions{})
+	assert.ErrorIs(t, err, session.ErrConflict, "Expect a write to give up when it keeps losing the race")
+}
+
+func TestShouldSetSessionFieldsWithoutLosingConcurrentWrites(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	memory := newMemoryCache(defaultMemoryShards, time.Minute, 0, 0)
+	defer memory.Close()
+	store := &racingStore{memoryCache: memory}
+	repo := NewSessionRepository(store, time.Minute, session.LifetimePolicy{}).(*sessionRepository)
+	repo.now = func() time.Time { return now }
+
+	err := repo.Set(ctx, &session.Session{ID: "someSessionKey", Data: session.Data{"user": "someUser"}}, session.SetOptions{})
+	assert.Nil(t, err, "Expect err is nil when storing a session")
+
+	_, err = repo.SetFields(ctx, "someSessionKey", session.Data{"theme": "dark"}, 2)
+	assert.ErrorIs(t, err, session.ErrPreconditionFailed, "Expect fields not to be set on a session at another version")
+
+	store.races = 1
+	_, err = repo.SetFields(ctx, "someSessionKey", session.Data{"theme": "dark"}, 0)
+	assert.Nil(t, err, "Expect fields losing a race to be set again")
+
+	stored, err := repo.Get(ctx, "someSessionKey")
+	assert.Nil(t, err, "Expect err is nil when reading a session")
+	assert.Equal(t, session.Data{"user": "someUser", "theme": "dark", "racer": true}, stored.Data, "Expect the racing write to be kept")
+	assert.Equal(t, int64(2), stored.Version)
+
+	version, err := repo.SetFields(ctx, "someSessionKey", session.Data{"theme": "light"}, 2)
+	assert.Nil(t, err, "Expect fields to be set on a session at the expected version")
+	assert.Equal(t, int64(3), version, "Expect the version written to be returned")
+
+	_, err = repo.SetFields(ctx, "missingSessionKey", session.Data{"theme": "light"}, 0)
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect fields not to be set on a missing session")
+}
+
+func TestShouldPatchSessions(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	repo, store := newTestSessionRepository(&now)
+	defer store.Close()
+
+	_, err := repo.Patch(ctx, "someSessionKey", session.MergePatch{"user": "someUser"}, 0)
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect a missing session not to be patched")
+
+	created := &session.Session{ID: "someSessionKey", Data: session.Data{
+		"user":  "someUser",
+		"props": map[string]interface{}{"cart": []interface{}{"apple"}, "theme": "light"},
+	}}
+	err = repo.Set(ctx, created, session.SetOptions{TTL: 30 * time.Second})
+	assert.Nil(t, err, "Expect err is nil when storing a session")
+
+	version, err := repo.Patch(ctx, "someSessionKey", session.MergePatch{
+		"user":  nil,
+		"props": map[string]interface{}{"theme": "dark"},
+	}, 1)
+	assert.Nil(t, err, "Expect err is nil when merge patching a session")
+	assert.Equal(t, int64(2), version, "Expect the version written to be returned")
+
+	version, err = repo.Patch(ctx, "someSessionKey", session.JSONPatch{
+		{Op: "test", Path: "/props/theme", Value: "dark"},
+		{Op: "add", Path: "/props/cart/-", Value: "pear"},
+		{Op: "copy", From: "/props/cart", Path: "/saved"},
+		{Op: "replace", Path: "/props/cart/0", Value: "plum"},
+		{Op: "move", From: "/props/theme", Path: "/theme"},
+	}, 0)
+	assert.Nil(t, err, "Expect err is nil when json patching a session")
+	assert.Equal(t, int64(3), version, "Expect the version written to be returned")
+
+	stored, err := repo.Get(ctx, "someSessionKey")
+	assert.Nil(t, err, "Expect err is nil when reading a session")
+	assert.Equal(t, session.Data{
+		"props": map[string]interface{}{"cart": []interface{}{"plum", "pear"}},
+		"saved": []interface{}{"apple", "pear"},
+		"theme": "dark",
+	}, stored.Data)
+	assert.Equal(t, int64(3), stored.Version, "Expect patches to count as writes")
+	assert.WithinDuration(t, now.Add(30*time.Second), stored.ExpiresAt, time.Second, "Expect the session to keep the time it has left")
+
+	tests := []struct {
+		scenario    string
+		patch       session.Patch
+		ifVersion   int64
+		expectedErr error
+	}{
+		{
+			scenario:    "Should not patch a session at another version",
+			patch:       session.MergePatch{"theme": "light"},
+			ifVersion:   2,
+			expectedErr: session.ErrPreconditionFailed,
+		},
+		{
+			scenario:    "Should not patch a session failing a test",
+			patch:       session.JSONPatch{{Op: "test", Path: "/theme", Value: "light"}, {Op: "remove", Path: "/theme"}},
+			expectedErr: session.ErrConflict,
+		},
+		{
+			scenario:    "Should not patch a session with a path it does not hold",
+			patch:       session.JSONPatch{{Op: "remove", Path: "/theme"}, {Op: "replace", Path: "/missing/value", Value: 1}},
+			expectedErr: session.ErrInvalid,
+		},
+		{
+			scenario:    "Should not patch a session with an unknown operation",
+			patch:       session.JSONPatch{{Op: "increment", Path: "/theme"}},
+			expectedErr: session.ErrInvalid,
+		},
+		{
+			scenario:    "Should not patch reserved fields",
+			patch:       session.MergePatch{metadataField: map[string]interface{}{"version": 1}},
+			expectedErr: session.ErrInvalid,
+		},
+		{
+			scenario:    "Should not replace the session with something else than an object",
+			patch:       session.JSONPatch{{Op: "replace", Path: "", Value: "someValue"}},
+			expectedErr: session.ErrInvalid,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := repo.Patch(ctx, "someSessionKey", test.patch, test.ifVersion)
+		assert.ErrorIs(t, err, test.expectedErr, test.scenario)
+	}
+
+	unchanged, err := repo.Get(ctx, "someSessionKey")
+	assert.Nil(t, err, "Expect err is nil when reading a session")
+	assert.Equal(t, stored.Data, unchanged.Data, "Expect failed patches to leave the session alone")
+	assert.Equal(t, stored.Version, unchanged.Version, "Expect failed patches to leave the session alone")
+}
+
+func TestShouldEditSessionValues(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	repo, store := newTestSessionRepository(&now)
+	defer store.Close()
+
+	created := &session.Session{ID: "someSessionKey", Data: session.Data{
+		"user":  "someUser",
+		"props": map[string]interface{}{"cart": []interface{}{"apple"}},
+	}}
+	err := repo.Set(ctx, created, session.SetOptions{})
+	assert.Nil(t, err, "Expect err is nil when storing a session")
+
+	edits := []session.Patch{
+		session.SetValue{Path: session.Pointer{"props", "cart", "0"}, Value: "plum"},
+		session.SetValue{Path: session.Pointer{"props", "cart", "-"}, Value: "pear"},
+		session.SetValue{Path: session.Pointer{"props", "theme"}, Value: "dark"},
+		session.RemoveValue{Path: session.Pointer{"user"}},
+	}
+	for _, edit := range edits {
+		_, err := repo.Patch(ctx, "someSessionKey", edit, 0)
+		assert.Nil(t, err, "Expect err is nil when editing a value")
+	}
+
+	stored, err := repo.Get(ctx, "someSessionKey")
+	assert.Nil(t, err, "Expect err is nil when reading a session")
+	assert.Equal(t, session.Data{
+		"props": map[string]interface{}{"cart": []interface{}{"plum", "pear"}, "theme": "dark"},
+	}, stored.Data)
+
+	missing := []session.Patch{
+		session.SetValue{Path: session.Pointer{"missing", "value"}, Value: 1},
+		session.SetValue{Path: session.Pointer{"props", "cart", "5"}, Value: 1},
+		session.RemoveValue{Path: session.Pointer{"user"}},
+	}
+	for _, edit := range missing {
+		_, err := repo.Patch(ctx, "someSessionKey", edit, 0)
+		assert.ErrorIs(t, err, session.ErrNotFound, "Expect values out of reach not to be found")
+	}
+
+	unchanged, err := repo.Get(ctx, "someSessionKey")
+	assert.Nil(t, err, "Expect err is nil when reading a session")
+	assert.Equal(t, stored.Data, unchanged.Data, "Expect failed edits to leave the session alone")
+	assert.Equal(t, stored.Version, unchanged.Version, "Expect failed edits to leave the session alone")
+}
+
+func TestShouldIncrementSessionCounters(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	repo, store := newTestSessionRepository(&now)
+	defer store.Close()
+
+	_, err := repo.Increment(ctx, "someSessionKey", session.Pointer{"views"}, 1)
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect a missing session not to be incremented")
+
+	created := &session.Session{ID: "someSessionKey", Data: session.Data{
+		"user":  "someUser",
+		"ratio": 0.5,
+		"stats": map[string]interface{}{"failed": 2},
+	}}
+	err = repo.Set(ctx, created, session.SetOptions{})
+	assert.Nil(t, err, "Expect err is nil when storing a session")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.Increment(ctx, "someSessionKey", session.Pointer{"views"}, 1)
+			assert.Nil(t, err, "Expect err is nil when incrementing a counter concurrently")
+		}()
+	}
+	wg.Wait()
+
+	value, err := repo.Increment(ctx, "someSessionKey", session.Pointer{"stats", "failed"}, -1)
+	assert.Nil(t, err, "Expect err is nil when decrementing a counter")
+	assert.Equal(t, int64(1), value, "Expect the counter to be left decremented")
+
+	stored, err := repo.Get(ctx, "someSessionKey")
+	assert.Nil(t, err, "Expect err is nil when reading a session")
+	assert.Equal(t, float64(5), stored.Data["views"], "Expect no concurrent increment to be lost")
+	assert.Equal(t, int64(7), stored.Version, "Expect increments to count as writes")
+
+	tests := []struct {
+		scenario    string
+		path        session.Pointer
+		delta       int64
+		expectedErr error
+	}{
+		{
+			scenario:    "Should not increment a value that is not a number",
+			path:        session.Pointer{"user"},
+			delta:       1,
+			expectedErr: session.ErrInvalid,
+		},
+		{
+			scenario:    "Should not increment a number that is not an integer",
+			path:        session.Pointer{"ratio"},
+			delta:       1,
+			expectedErr: session.ErrInvalid,
+		},
+		{
+			scenario:    "Should not increment the metadata of the session",
+			path:        session.Pointer{"__session", "version"},
+			delta:       1,
+			expectedErr: session.ErrInvalid,
+		},
+		{
+			scenario:    "Should not increment a counter whose parent does not exist",
+			path:        session.Pointer{"missing", "views"},
+			delta:       1,
+			expectedErr: session.ErrNotFound,
+		},
+		{
+			scenario:    "Should not increment a counter out of range",
+			path:        session.Pointer{"views"},
+			delta:       session.MaxCounter,
+			expectedErr: session.ErrInvalid,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := repo.Increment(ctx, "someSessionKey", test.path, test.delta)
+		assert.ErrorIs(t, err, test.expectedErr, test.scenario)
+	}
+
+	err = store.Set(ctx, "someLegacyKey", `{"views":[1]}`)
+	assert.Nil(t, err, "Expect err is nil when storing a session without metadata")
+
+	value, err = repo.Increment(ctx, "someLegacyKey", session.Pointer{"views", "-"}, 2)
+	assert.Nil(t, err, "Expect sessions without metadata to be patched instead")
+	assert.Equal(t, int64(2), value, "Expect the counter to be appended to the array")
+
+	value, err = repo.Increment(ctx, "someLegacyKey", session.Pointer{"views", "-"}, 3)
+	assert.Nil(t, err, "Expect err is nil when incrementing a counter in place")
+	assert.Equal(t, int64(3), value, "Expect the counter to be appended to the array")
+
+	legacy, err := repo.Get(ctx, "someLegacyKey")
+	assert.Nil(t, err, "Expect err is nil when reading a session")
+	assert.Equal(t, []interface{}{float64(1), float64(2), float64(3)}, legacy.Data["views"], "Expect both counters to be appended")
+	assert.Equal(t, int64(2), legacy.Version, "Expect increments to count as writes")
+}
+
+func TestShouldRotateSessions(t *testing.T) {
+	t.Parallel()
+
+	now := time.UnixMilli(1_000_000)
+	repo, store := newTestSessionRepository(&now)
+	defer store.Close()
+
+	_, err := repo.Rotate(ctx, "someSessionKey", "someRotatedKey", 0)
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect a missing session not to be rotated")
+
+	created := &session.Session{ID: "someSessionKey", Data: session.Data{"user": "someUser"}}
+	err = repo.Set(ctx, created, session.SetOptions{TTL: 30 * time.Second})
+	assert.Nil(t, err, "Expect err is nil when storing a session")
+
+	now = now.Add(time.Second)
+	rotated, err := repo.Rotate(ctx, "someSessionKey", "someRotatedKey", 0)
+	assert.Nil(t, err, "Expect err is nil when rotating a session")
+	assert.Equal(t, "someRotatedKey", rotated.ID)
+	assert.Equal(t, created.Data, rotated.Data, "Expect data to be moved to the new key")
+	assert.Equal(t, created.CreatedAt, rotated.CreatedAt, "Expect creation time to be kept")
+	assert.Equal(t, int64(2), rotated.Version, "Expect rotation to count as a write")
+
+	_, err = repo.Get(ctx, "someSessionKey")
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect the old key to stop working without grace period")
+
+	ttl, err := store.TTL(ctx, "someRotatedKey")
+	assert.Nil(t, err, "Expect err is nil when reading the ttl of the new key")
+	assert.LessOrEqual(t, ttl, 30*time.Second, "Expect the time left to be moved to the new key")
+	assert.Greater(t, ttl, 20*time.Second, "Expect the time left to be moved to the new key")
+
+	graced, err := repo.Rotate(ctx, "someRotatedKey", "someGracedKey", 5*time.Second)
+	assert.Nil(t, err, "Expect err is nil when rotating a session with a grace period")
+
+	old, err := repo.Get(ctx, "someRotatedKey")
+	assert.Nil(t, err, "Expect the old key to keep working during the grace period")
+	assert.Equal(t, rotated.Data, old.Data, "Expect the old key to keep the data of the session")
+	assert.Equal(t, "someGracedKey", old.RotatedTo, "Expect the old key to point to the new one")
+	assert.Equal(t, now.Add(5*time.Second), old.GraceEnds, "Expect the old key to be kept for the grace period")
+	assert.False(t, old.ExpiresAt.After(old.GraceEnds), "Expect the old key to expire with the grace period")
+	ttl, _ = store.TTL(ctx, "someRotatedKey")
+	assert.LessOrEqual(t, ttl, 5*time.Second, "Expect the old key to be kept for the grace period")
+
+	_, err = repo.Touch(ctx, "someRotatedKey", time.Minute)
+	assert.ErrorIs(t, err, session.ErrReadOnly, "Expect the old key not to be touched")
+	err = repo.Set(ctx, &session.Session{ID: "someRotatedKey", Data: session.Data{"user": "otherUser"}}, session.SetOptions{})
+	assert.ErrorIs(t, err, session.ErrReadOnly, "Expect the old key not to be written")
+	_, err = repo.Patch(ctx, "someRotatedKey", session.SetFields(session.Data{"user": "otherUser"}), 0)
+	assert.ErrorIs(t, err, session.ErrReadOnly, "Expect the old key not to be patched")
+	_, err = repo.SetFields(ctx, "someRotatedKey", session.Data{"user": "otherUser"}, 0)
+	assert.ErrorIs(t, err, session.ErrReadOnly, "Expect the fields of the old key not to be written")
+	_, err = repo.Increment(ctx, "someRotatedKey", session.Pointer{"views"}, 1)
+	assert.ErrorIs(t, err, session.ErrReadOnly, "Expect the counters of the old key not to be incremented")
+	_, err = repo.Rotate(ctx, "someRotatedKey", "someOtherKey", 0)
+	assert.ErrorIs(t, err, session.ErrReadOnly, "Expect the old key not to be rotated again")
+	ttl, _ = store.TTL(ctx, "someRotatedKey")
+	assert.LessOrEqual(t, ttl, 5*time.Second, "Expect the old key not to be kept past the grace period")
+
+	stored, err := repo.Get(ctx, "someGracedKey")
+	assert.Nil(t, err, "Expect err is nil when reading the rotated session")
+	assert.Equal(t, graced.Version, stored.Version)
+	assert.Empty(t, stored.RotatedTo, "Expect the new key to be writable")
+
+	now = now.Add(5 * time.Second)
+	_, err = repo.Get(ctx, "someRotatedKey")
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect the old key to stop working once the grace period is over")
+	_, err = repo.Get(ctx, "someGracedKey")
+	assert.Nil(t, err, "Expect the new key to keep working once the grace period is over")
+
+	store.Set(ctx, "someKeyInUse", `{}`)
+	_, err = repo.Rotate(ctx, "someGracedKey", "someKeyInUse", 0)
+	assert.ErrorIs(t, err, session.ErrPreconditionFailed, "Expect a session not to be rotated to a key in use")
+	_, err = repo.Get(ctx, "someGracedKey")
+	assert.Nil(t, err, "Expect a failed rotation to leave the session alone")
+
+	tests := []struct {
+		scenario    string
+		newID       string
+		grace       time.Duration
+		expectedErr error
+	}{
+		{
+			scenario:    "Should not rotate a session to the same key",
+			newID:       "someGracedKey",
+			expectedErr: session.ErrInvalid,
+		},
+		{
+			scenario:    "Should not rotate a session with a negative grace period",
+			newID:       "someOtherKey",
+			grace:       -time.Second,
+			expectedErr: session.ErrInvalid,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := repo.Rotate(ctx, "someGracedKey", test.newID, test.grace)
+		assert.ErrorIs(t, err, test.expectedErr, test.scenario)
+	}
+}
@@ -0,0 +1,23 @@
+package adapters
+
+import (
+	"context"
+)
+
+// Store keeps encoded sessions under their keys. Sessions are JSON objects,
+// given as any value encodeValue accepts and returned as strings, and missing
+// keys are reported with session.ErrNotFound. The repositories of this package
+// are stores, most of them wrapping another one, and NewSessionRepository
+// turns the outermost of them into a session.Repository.
+type Store interface {
+	Set(ctx context.Context, key string, value interface{}) error
+	Get(ctx context.Context, key string) (interface{}, error)
+	Delete(ctx context.Context, key string) (int64, error)
+}
+
+// FieldStore is implemented by stores able to read and write some of the top
+// level fields of a session without touching the rest of it.
+type FieldStore interface {
+	GetFields(ctx context.Context, key string, fields []string) (map[string]interface{}, error)
+	SetFields(ctx context.Context, key string, values map[string]interface{}) error
+}
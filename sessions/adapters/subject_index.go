@@ -0,0 +1,204 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+)
+
+// subjectIndexPrefix starts the keys stores sharing a single keyspace with the
+// sessions index the sessions of each subject under. Session keys cannot use
+// it, as they cannot start with the reserved key prefix.
+const subjectIndexPrefix = session.ReservedKeyPrefix + "subject:"
+
+// subjectIndexKey returns the key the sessions of subject are indexed under.
+func subjectIndexKey(subject string) string {
+	return subjectIndexPrefix + subject
+}
+
+// The index of a subject may hold sessions that were deleted or given to
+// another subject since, which are dropped once found, but never misses a
+// session of its subject: sessions are indexed before being written. Sessions
+// found missing are only dropped once they were due to expire, as they may be
+// indexed but not written yet.
+
+// indexSession records in the index of subject that the session id expires at
+// expiresAt, zero when it never does, then drops the sessions of the index
+// found gone meanwhile.
+func (r *sessionRepository) indexSession(ctx context.Context, subject, id string, expiresAt time.Time) error {
+
+	indexer, ok := r.store.(subjectIndexer)
+	if !ok {
+		return session.ErrNotSupported
+	}
+
+	if err := indexer.IndexSession(ctx, subject, id, expiresAt); err != nil {
+		return err
+	}
+
+	// Dropping stale sessions is best effort, those left are dropped the next
+	// time they are found.
+	index, err := indexer.IndexedSessions(ctx, subject)
+	if err != nil {
+		return nil
+	}
+	now := r.now()
+	stale := make([]string, 0)
+	for indexed, expiry := range index {
+		if indexed == id || expiry.IsZero() || now.Before(expiry) {
+			continue
+		}
+		if _, s, err := r.readRaw(ctx, indexed); err == nil && isStale(subject, s, expiry, now) {
+			stale = append(stale, indexed)
+		}
+	}
+	r.unindex(ctx, indexer, subject, stale)
+	return nil
+}
+
+// isStale tells whether s, the session read for an entry of the index of
+// subject due to expire at expiry, or nil when it is missing, no longer
+// belongs in the index.
+func isStale(subject string, s *session.Session, expiry, now time.Time) bool {
+	if s == nil {
+		return !expiry.IsZero() && !now.Before(expiry)
+	}
+	return s.Subject != subject
+}
+
+// unindex drops ids from the index of subject, as best effort.
+func (r *sessionRepository) unindex(ctx context.Context, indexer subjectIndexer, subject string, ids []string) {
+	if len(ids) > 0 {
+		indexer.UnindexSessions(ctx, subject, ids)
+	}
+}
+
+// GetSubjectSessions reads every session in the index of subject, leaving out
+// those that are not valid or were rotated. Those that are gone, or belong to
+// another subject now, are dropped from the index.
+func (r *sessionRepository) GetSubjectSessions(ctx context.Context, subject string) ([]*session.Session, error) {
+
+	if subject == "" {
+		return nil, fmt.Errorf("%w: subject cannot be empty", session.ErrInvalid)
+	}
+
+	indexer, ok := r.store.(subjectIndexer)
+	if !ok {
+		return nil, session.ErrNotSupported
+	}
+
+	index, err := indexer.IndexedSessions(ctx, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	now := r.now()
+	sessions := make([]*session.Session, 0, len(index))
+	stale := make([]string, 0)
+	for id, expiry := range index {
+		_, s, err := r.readRaw(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if isStale(subject, s, expiry, now) {
+			stale = append(stale, id)
+			continue
+		}
+		if s == nil || s.Subject != subject || s.RotatedTo != "" || r.lifetime.Check(s, now) != nil {
+			continue
+		}
+		if err := r.fillExpiry(ctx, s, now); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	r.unindex(ctx, indexer, subject, stale)
+
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].CreatedAt.Equal(sessions[j].CreatedAt) {
+			return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+		}
+		return sessions[i].ID < sessions[j].ID
+	})
+	return sessions, nil
+}
+
+// DeleteSubjectSessions deletes the sessions in the index of subject that
+// still belong to it, dropping them from the index, then reads the index again
+// to delete the sessions indexed meanwhile, until there are none. Sessions
+// rotated and still readable are deleted too, but not counted.
+func (r *sessionRepository) DeleteSubjectSessions(ctx context.Context, subject string) (int, error) {
+
+	if subject == "" {
+		return 0, fmt.Errorf("%w: subject cannot be empty", session.ErrInvalid)
+	}
+
+	indexer, ok := r.store.(subjectIndexer)
+	if !ok {
+		return 0, session.ErrNotSupported
+	}
+	deleter, ok := r.store.(valueDeleter)
+	if !ok {
+		return 0, session.ErrNotSupported
+	}
+
+	deleted := 0
+	seen := make(map[string]bool)
+	for i := 0; i < maxSwapRetries; i++ {
+		index, err := indexer.IndexedSessions(ctx, subject)
+		if err != nil {
+			return deleted, err
+		}
+
+		now := r.now()
+		fresh := false
+		handled := make([]string, 0, len(index))
+		for id, expiry := range index {
+			if seen[id] {
+				continue
+			}
+			seen[id], fresh = true, true
+
+			owned, current, err := r.deleteOwned(ctx, deleter, subject, id)
+			if err != nil {
+				return deleted, err
+			}
+			if owned && current.RotatedTo == "" {
+				deleted++
+			}
+			if owned || isStale(subject, current, expiry, now) {
+				handled = append(handled, id)
+			}
+		}
+
+		r.unindex(ctx, indexer, subject, handled)
+		if !fresh {
+			return deleted, nil
+		}
+	}
+	return deleted, fmt.Errorf("%w: gave up deleting sessions of subject after %d attempts", session.ErrConflict, maxSwapRetries)
+}
+
+// deleteOwned deletes the session id while it belongs to subject, reporting
+// whether it did, along with the session read, nil when there is none.
+func (r *sessionRepository) deleteOwned(ctx context.Context, deleter valueDeleter, subject, id string) (bool, *session.Session, error) {
+
+	for i := 0; i < maxSwapRetries; i++ {
+		raw, current, err := r.readRaw(ctx, id)
+		if err != nil {
+			return false, nil, err
+		}
+		if current == nil || current.Subject != subject {
+			return false, current, nil
+		}
+
+		deleted, err := deleter.DeleteIf(ctx, id, raw)
+		if err != nil || deleted {
+			return deleted, current, err
+		}
+	}
+	return false, nil, fmt.Errorf("%w: gave up deleting session after %d attempts", session.ErrConflict, maxSwapRetries)
+}
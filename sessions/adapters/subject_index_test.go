@@ -0,0 +1,175 @@
+package adapters
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShouldIndexSessionsBySubject(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	repo, store := newTestSessionRepository(&now)
+	defer store.Close()
+
+	for _, s := range []*session.Session{
+		{ID: "firstKey", Data: session.Data{"n": 1}, Subject: "someUser"},
+		{ID: "secondKey", Data: session.Data{"n": 2}, Subject: "someUser"},
+		{ID: "otherKey", Data: session.Data{"n": 3}, Subject: "otherUser"},
+		{ID: "anonymousKey", Data: session.Data{"n": 4}},
+	} {
+		err := repo.Set(ctx, s, session.SetOptions{})
+		assert.Nil(t, err, "Expect err is nil when storing a session")
+		now = now.Add(time.Second)
+	}
+
+	sessions, err := repo.GetSubjectSessions(ctx, "someUser")
+	assert.Nil(t, err, "Expect err is nil when listing the sessions of a subject")
+	assert.Equal(t, []string{"firstKey", "secondKey"}, sessionIDs(sessions), "Expect sessions of the subject, oldest first")
+	assert.Equal(t, "someUser", sessions[0].Subject)
+	assert.False(t, sessions[0].ExpiresAt.IsZero(), "Expect expiry of listed sessions to be read")
+
+	index, err := store.IndexedSessions(ctx, "someUser")
+	assert.Nil(t, err, "Expect the index to be stored")
+	assert.ElementsMatch(t, []string{"firstKey", "secondKey"}, indexIDs(index), "Expect the index to hold the sessions of the subject")
+	_, err = store.Get(ctx, subjectIndexKey("someUser"))
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect the index to be kept apart from the sessions")
+
+	err = repo.Set(ctx, &session.Session{ID: "secondKey", Data: session.Data{"n": 2}}, session.SetOptions{})
+	assert.Nil(t, err, "Expect err is nil when giving up the subject of a session")
+	rotated, err := repo.Rotate(ctx, "firstKey", "rotatedKey", time.Minute)
+	assert.Nil(t, err, "Expect err is nil when rotating a session of a subject")
+	assert.Equal(t, "someUser", rotated.Subject, "Expect a rotated session to keep its subject")
+
+	sessions, err = repo.GetSubjectSessions(ctx, "someUser")
+	assert.Nil(t, err, "Expect err is nil when listing the sessions of a subject")
+	assert.Equal(t, []string{"rotatedKey"}, sessionIDs(sessions), "Expect sessions no longer of the subject to be left out")
+
+	index, _ = store.IndexedSessions(ctx, "someUser")
+	assert.ElementsMatch(t, []string{"firstKey", "rotatedKey"}, indexIDs(index), "Expect stale sessions to be dropped from the index, but not rotated ones")
+
+	deleted, err := repo.DeleteSubjectSessions(ctx, "someUser")
+	assert.Nil(t, err, "Expect err is nil when deleting the sessions of a subject")
+	assert.Equal(t, 1, deleted)
+
+	for _, id := range []string{"rotatedKey", "firstKey"} {
+		_, err = repo.Get(ctx, id)
+		assert.ErrorIs(t, err, session.ErrNotFound, "Expect sessions of the subject to be deleted, rotated ones included")
+	}
+	index, _ = store.IndexedSessions(ctx, "someUser")
+	assert.Empty(t, index, "Expect the index of the subject to be emptied")
+	for _, id := range []string{"secondKey", "otherKey", "anonymousKey"} {
+		_, err = repo.Get(ctx, id)
+		assert.Nil(t, err, "Expect sessions of other subjects to be left alone")
+	}
+
+	sessions, err = repo.GetSubjectSessions(ctx, "someUser")
+	assert.Nil(t, err, "Expect err is nil when listing the sessions of a subject without any")
+	assert.Empty(t, sessions)
+
+	tests := []struct {
+		scenario    string
+		run         func() error
+		expectedErr error
+	}{
+		{
+			scenario: "Should not store a session under a reserved key",
+			run: func() error {
+				return repo.Set(ctx, &session.Session{ID: subjectIndexKey("otherUser")}, session.SetOptions{})
+			},
+			expectedErr: session.ErrInvalid,
+		},
+		{
+			scenario: "Should not read an index as a session",
+			run: func() error {
+				_, err := repo.Get(ctx, subjectIndexKey("otherUser"))
+				return err
+			},
+			expectedErr: session.ErrInvalid,
+		},
+		{
+			scenario: "Should store a session whose key only starts like a reserved one",
+			run: func() error {
+				return repo.Set(ctx, &session.Session{ID: "__someSessionKey"}, session.SetOptions{})
+			},
+		},
+		{
+			scenario: "Should not list the sessions of an empty subject",
+			run: func() error {
+				_, err := repo.GetSubjectSessions(ctx, "")
+				return err
+			},
+			expectedErr: session.ErrInvalid,
+		},
+		{
+			scenario: "Should not delete the sessions of an empty subject",
+			run: func() error {
+				_, err := repo.DeleteSubjectSessions(ctx, "")
+				return err
+			},
+			expectedErr: session.ErrInvalid,
+		},
+	}
+
+	for _, test := range tests {
+		err := test.run()
+		if test.expectedErr != nil {
+			assert.ErrorIs(t, err, test.expectedErr, test.scenario)
+		} else {
+			assert.Nil(t, err, test.scenario)
+		}
+	}
+}
+
+func TestShouldDropExpiredSessionsFromSubjectIndex(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	repo, store := newTestSessionRepository(&now)
+	defer store.Close()
+
+	err := repo.Set(ctx, &session.Session{ID: "shortKey", Subject: "someUser"}, session.SetOptions{TTL: time.Second})
+	assert.Nil(t, err, "Expect err is nil when storing a session")
+	err = repo.Set(ctx, &session.Session{ID: "touchedKey", Subject: "someUser"}, session.SetOptions{TTL: time.Second})
+	assert.Nil(t, err, "Expect err is nil when storing a session")
+	err = repo.Set(ctx, &session.Session{ID: "longKey", Subject: "someUser"}, session.SetOptions{TTL: time.Hour})
+	assert.Nil(t, err, "Expect err is nil when storing a session")
+
+	before, _ := store.IndexedSessions(ctx, "someUser")
+	_, err = repo.Touch(ctx, "touchedKey", time.Hour)
+	assert.Nil(t, err, "Expect err is nil when touching a session")
+	after, _ := store.IndexedSessions(ctx, "someUser")
+	assert.Equal(t, before, after, "Expect touching a session to leave the index alone")
+
+	now = now.Add(2 * time.Second)
+	_, err = store.Delete(ctx, "shortKey")
+	assert.Nil(t, err, "Expect err is nil when a session goes")
+	err = repo.Set(ctx, &session.Session{ID: "newKey", Subject: "someUser"}, session.SetOptions{})
+	assert.Nil(t, err, "Expect err is nil when storing a session")
+
+	index, _ := store.IndexedSessions(ctx, "someUser")
+	assert.ElementsMatch(t, []string{"touchedKey", "longKey", "newKey"}, indexIDs(index), "Expect expired sessions gone to be dropped from the index")
+
+	sessions, err := repo.GetSubjectSessions(ctx, "someUser")
+	assert.Nil(t, err, "Expect err is nil when listing the sessions of a subject")
+	assert.Equal(t, []string{"longKey", "touchedKey", "newKey"}, sessionIDs(sessions), "Expect touched sessions to be listed past their first expiry")
+}
+
+func sessionIDs(sessions []*session.Session) []string {
+	ids := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+func indexIDs(index map[string]time.Time) []string {
+	ids := make([]string, 0, len(index))
+	for id := range index {
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -0,0 +1,353 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+)
+
+// Invalidator broadcasts the keys of changed sessions to every replica, so they
+// can drop their local copies.
+type Invalidator interface {
+	Publish(ctx context.Context, key string) error
+	Subscribe(onInvalidate func(key string))
+}
+
+// tieredGenerations is the number of generation counters keys are spread over.
+const tieredGenerations = 256
+
+type tieredRepository struct {
+	next        Store
+	local       *memoryCache
+	ttl         time.Duration
+	invalidator Invalidator
+
+	// generations count the changes to the keys hashing to each of them, so a
+	// read from next is only kept locally when no change happened meanwhile.
+	mu          sync.Mutex
+	generations [tieredGenerations]uint64
+}
+
+// NewTieredRepository keeps up to size sessions read from next in a local LRU
+// for at most ttl, or for the time the session has left in next if shorter.
+// Local copies are dropped whenever the invalidator reports a change, including
+// the changes made through this repository.
+func NewTieredRepository(next Store, invalidator Invalidator, size int, ttl time.Duration) Store {
+	r := &tieredRepository{
+		next:        next,
+		local:       newMemoryCache(defaultMemoryShards, ttl, size, ttl),
+		ttl:         ttl,
+		invalidator: invalidator,
+	}
+
+	invalidator.Subscribe(func(key string) {
+		r.drop(context.Background(), key)
+	})
+
+	return r
+}
+
+func (r *tieredRepository) Set(ctx context.Context, key string, value interface{}) error {
+
+	if err := r.next.Set(ctx, key, value); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, key)
+	return nil
+}
+
+func (r *tieredRepository) SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+
+	setter, ok := r.next.(ttlSetter)
+	if !ok {
+		return session.ErrNotSupported
+	}
+
+	if err := setter.SetWithTTL(ctx, key, value, ttl); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, key)
+	return nil
+}
+
+func (r *tieredRepository) Get(ctx context.Context, key string) (interface{}, error) {
+
+	if val, err := r.local.Get(ctx, key); err == nil {
+		return val, nil
+	}
+
+	generation := r.generation(key)
+	val, err := r.next.Get(ctx, key)
+	if err != nil {
+		return val, err
+	}
+
+	if ttl := r.localTTL(ctx, key); ttl > 0 {
+		r.fill(ctx, key, val, ttl, generation)
+	}
+
+	return val, nil
+}
+
+// GetPrimary reads the session from the next repository, never from the local
+// copy, which may be stale.
+func (r *tieredRepository) GetPrimary(ctx context.Context, key string) (interface{}, error) {
+	return getPrimary(ctx, r.next, key)
+}
+
+func (r *tieredRepository) Delete(ctx context.Context, key string) (int64, error) {
+
+	deleted, err := r.next.Delete(ctx, key)
+	if err != nil {
+		return deleted, err
+	}
+
+	r.invalidate(ctx, key)
+	return deleted, nil
+}
+
+func (r *tieredRepository) TTL(ctx context.Context, key string) (time.Duration, error) {
+
+	reader, ok := r.next.(ttlReader)
+	if !ok {
+		return 0, session.ErrNotSupported
+	}
+	return reader.TTL(ctx, key)
+}
+
+// Expire expires the local copy along with the session, by the configured
+// local ttl at the latest. Copies on other replicas are left alone.
+func (r *tieredRepository) Expire(ctx context.Context, key string, ttl time.Duration) error {
+
+	expirer, ok := r.next.(expirer)
+	if !ok {
+		return session.ErrNotSupported
+	}
+	if err := expirer.Expire(ctx, key, ttl); err != nil {
+		return err
+	}
+
+	if ttl > r.ttl {
+		ttl = r.ttl
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.generations[generationSlot(key)]++
+	if ttl > 0 {
+		r.local.Expire(ctx, key, ttl)
+	} else {
+		r.local.Delete(ctx, key)
+	}
+	return nil
+}
+
+// SetIfAbsent drops the local copy when the session exists, for the same
+// reason as Replace.
+func (r *tieredRepository) SetIfAbsent(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+
+	setter, ok := r.next.(absentSetter)
+	if !ok {
+		return false, session.ErrNotSupported
+	}
+
+	stored, err := setter.SetIfAbsent(ctx, key, value, ttl)
+	if err != nil {
+		return false, err
+	}
+	if !stored {
+		r.drop(ctx, key)
+		return false, nil
+	}
+
+	r.invalidate(ctx, key)
+	return true, nil
+}
+
+// Replace drops the local copy when the session does not hold old, as the
+// caller may have read it from a stale local copy.
+func (r *tieredRepository) Replace(ctx context.Context, key string, old, new interface{}, ttl time.Duration) (bool, error) {
+
+	replacer, ok := r.next.(valueReplacer)
+	if !ok {
+		return false, session.ErrNotSupported
+	}
+
+	replaced, err := replacer.Replace(ctx, key, old, new, ttl)
+	if err != nil {
+		return false, err
+	}
+	if !replaced {
+		r.drop(ctx, key)
+		return false, nil
+	}
+
+	r.invalidate(ctx, key)
+	return true, nil
+}
+
+func (r *tieredRepository) DeleteIf(ctx context.Context, key string, old interface{}) (bool, error) {
+
+	deleter, ok := r.next.(valueDeleter)
+	if !ok {
+		return false, session.ErrNotSupported
+	}
+
+	deleted, err := deleter.DeleteIf(ctx, key, old)
+	if err != nil {
+		return false, err
+	}
+	if !deleted {
+		r.drop(ctx, key)
+		return false, nil
+	}
+
+	r.invalidate(ctx, key)
+	return true, nil
+}
+
+func (r *tieredRepository) Increment(ctx context.Context, key string, path session.Pointer, delta int64, updatedAt time.Time) (int64, error) {
+
+	incrementer, ok := r.next.(incrementer)
+	if !ok {
+		return 0, session.ErrNotSupported
+	}
+
+	counter, err := incrementer.Increment(ctx, key, path, delta, updatedAt)
+	if err != nil {
+		r.drop(ctx, key)
+		return 0, err
+	}
+
+	r.invalidate(ctx, key)
+	return counter, nil
+}
+
+// IndexSession and the other methods of the indexes of subjects go to the
+// next repository, the indexes are never copied locally.
+func (r *tieredRepository) IndexSession(ctx context.Context, subject, id string, expiresAt time.Time) error {
+
+	indexer, ok := r.next.(subjectIndexer)
+	if !ok {
+		return session.ErrNotSupported
+	}
+	return indexer.IndexSession(ctx, subject, id, expiresAt)
+}
+
+func (r *tieredRepository) UnindexSessions(ctx context.Context, subject string, ids []string) error {
+
+	indexer, ok := r.next.(subjectIndexer)
+	if !ok {
+		return session.ErrNotSupported
+	}
+	return indexer.UnindexSessions(ctx, subject, ids)
+}
+
+func (r *tieredRepository) IndexedSessions(ctx context.Context, subject string) (map[string]time.Time, error) {
+
+	indexer, ok := r.next.(subjectIndexer)
+	if !ok {
+		return nil, session.ErrNotSupported
+	}
+	return indexer.IndexedSessions(ctx, subject)
+}
+
+func (r *tieredRepository) GetFields(ctx context.Context, key string, fields []string) (map[string]interface{}, error) {
+
+	if val, err := r.local.Get(ctx, key); err == nil {
+		return pickFields(val.(string), fields)
+	}
+
+	fieldRepo, err := fieldStore(r.next)
+	if err != nil {
+		return nil, err
+	}
+	return fieldRepo.GetFields(ctx, key, fields)
+}
+
+func (r *tieredRepository) SetFields(ctx context.Context, key string, values map[string]interface{}) error {
+
+	fieldRepo, err := fieldStore(r.next)
+	if err != nil {
+		return err
+	}
+
+	if err := fieldRepo.SetFields(ctx, key, values); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, key)
+	return nil
+}
+
+// localTTL caps the configured local ttl by the time the session has left in
+// the next repository, when it is able to tell.
+func (r *tieredRepository) localTTL(ctx context.Context, key string) time.Duration {
+	reader, ok := r.next.(ttlReader)
+	if !ok {
+		return r.ttl
+	}
+
+	remaining, err := reader.TTL(ctx, key)
+	if errors.Is(err, session.ErrNotSupported) {
+		return r.ttl
+	}
+	if err != nil {
+		return 0
+	}
+	if remaining >= 0 && remaining < r.ttl {
+		return remaining
+	}
+	return r.ttl
+}
+
+// generation returns the current generation of key, to be given to fill.
+func (r *tieredRepository) generation(key string) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.generations[generationSlot(key)]
+}
+
+// fill keeps val as the local copy of key unless key may have changed since
+// generation was taken, in which case val may be older than the change.
+func (r *tieredRepository) fill(ctx context.Context, key string, val interface{}, ttl time.Duration, generation uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.generations[generationSlot(key)] == generation {
+		r.local.SetWithTTL(ctx, key, val, ttl)
+	}
+}
+
+// drop deletes the local copy of key, and keeps reads of key already under way
+// from filling it back.
+func (r *tieredRepository) drop(ctx context.Context, key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.generations[generationSlot(key)]++
+	r.local.Delete(ctx, key)
+}
+
+func (r *tieredRepository) invalidate(ctx context.Context, key string) {
+	r.drop(ctx, key)
+
+	if err := r.invalidator.Publish(ctx, key); err != nil {
+		logrus.WithError(err).WithField("key", key).Warn("Failed to publish session invalidation")
+	}
+}
+
+func generationSlot(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return h.Sum32() % tieredGenerations
+}
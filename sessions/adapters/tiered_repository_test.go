@@ -0,0 +1,163 @@
+package adapters
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/stretchr/testify/assert"
+)
+
+// testInvalidationBus delivers invalidations synchronously to every subscriber.
+type testInvalidationBus struct {
+	mu          sync.Mutex
+	subscribers []func(key string)
+}
+
+func (b *testInvalidationBus) Publish(ctx context.Context, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, onInvalidate := range b.subscribers {
+		onInvalidate(key)
+	}
+	return nil
+}
+
+func (b *testInvalidationBus) Subscribe(onInvalidate func(key string)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subscribers = append(b.subscribers, onInvalidate)
+}
+
+type countingRepository struct {
+	*memoryCache
+	gets int
+}
+
+func (r *countingRepository) Get(ctx context.Context, key string) (interface{}, error) {
+	r.gets++
+	return r.memoryCache.Get(ctx, key)
+}
+
+func TestTieredRepositoryShouldServeReadsLocally(t *testing.T) {
+	t.Parallel()
+
+	backend := &countingRepository{memoryCache: newMemoryCache(1, time.Minute, 0, 0)}
+	repo := NewTieredRepository(backend, &testInvalidationBus{}, 10, time.Minute)
+
+	repo.Set(ctx, "someKey", "someValue")
+	for i := 0; i < 3; i++ {
+		val, err := repo.Get(ctx, "someKey")
+		assert.Nil(t, err, "Expect err is nil when retrieving session value")
+		assert.True(t, val == "someValue", "Expect stored session value")
+	}
+
+	assert.Equal(t, 1, backend.gets, "Expect only the first read to reach the backend")
+}
+
+func TestTieredRepositoryShouldDropLocalCopiesOnInvalidation(t *testing.T) {
+	t.Parallel()
+
+	backend := newMemoryCache(1, time.Minute, 0, 0)
+	bus := &testInvalidationBus{}
+	replicaA := NewTieredRepository(backend, bus, 10, time.Minute)
+	replicaB := NewTieredRepository(backend, bus, 10, time.Minute)
+
+	replicaA.Set(ctx, "someKey", "first")
+	replicaA.Get(ctx, "someKey")
+
+	replicaB.Set(ctx, "someKey", "second")
+	val, _ := replicaA.Get(ctx, "someKey")
+	assert.True(t, val == "second", "Expect replica to read the value written by another replica")
+
+	replicaB.Delete(ctx, "someKey")
+	_, err := replicaA.Get(ctx, "someKey")
+	assert.ErrorIs(t, err, session.ErrNotFound, "Expect replica not to read a session deleted by another replica")
+}
+
+func TestTieredRepositoryShouldCapLocalTTLByRemainingTTL(t *testing.T) {
+	t.Parallel()
+
+	backend := newMemoryCache(1, 20*time.Millisecond, 0, 0)
+	repo := NewTieredRepository(backend, &testInvalidationBus{}, 10, time.Minute).(*tieredRepository)
+
+	repo.Set(ctx, "someKey", "someValue")
+	repo.Get(ctx, "someKey")
+
+	ttl, err := repo.local.TTL(ctx, "someKey")
+	assert.Nil(t, err, "Expect session to be cached locally")
+	assert.LessOrEqual(t, ttl, 20*time.Millisecond, "Expect local copy not to outlive the backend session")
+}
+
+func TestTieredRepositoryShouldDropStaleLocalCopiesOnFailedReplace(t *testing.T) {
+	t.Parallel()
+
+	backend := newMemoryCache(1, time.Minute, 0, 0)
+	repo := NewTieredRepository(backend, &testInvalidationBus{}, 10, time.Minute).(*tieredRepository)
+
+	repo.Set(ctx, "someKey", "first")
+	repo.Get(ctx, "someKey")
+	backend.Set(ctx, "someKey", "second")
+
+	replaced, err := repo.Replace(ctx, "someKey", "first", "third", 0)
+	assert.Nil(t, err, "Expect err is nil when replacing a session")
+	assert.False(t, replaced, "Expect a session changed meanwhile not to be replaced")
+
+	val, _ := repo.Get(ctx, "someKey")
+	assert.True(t, val == "second", "Expect the stale local copy to be dropped")
+}
+
+// racingRepository runs onGet after reading a session and before returning it,
+// as a change landing while a slow read is on its way back.
+type racingRepository struct {
+	*memoryCache
+	onGet func()
+}
+
+func (r *racingRepository) Get(ctx context.Context, key string) (interface{}, error) {
+	val, err := r.memoryCache.Get(ctx, key)
+	if r.onGet != nil {
+		onGet := r.onGet
+		r.onGet = nil
+		onGet()
+	}
+	return val, err
+}
+
+func TestTieredRepositoryShouldNotKeepReadsOlderThanAChange(t *testing.T) {
+	t.Parallel()
+
+	backend := &racingRepository{memoryCache: newMemoryCache(1, time.Minute, 0, 0)}
+	repo := NewTieredRepository(backend, &testInvalidationBus{}, 10, time.Minute)
+
+	repo.Set(ctx, "someKey", "first")
+	backend.onGet = func() { repo.Set(ctx, "someKey", "second") }
+
+	val, err := repo.Get(ctx, "someKey")
+	assert.Nil(t, err, "Expect err is nil when retrieving session value")
+	assert.True(t, val == "first", "Expect the value read before the change")
+
+	val, _ = repo.Get(ctx, "someKey")
+	assert.True(t, val == "second", "Expect the value read before the change not to be kept locally")
+}
+
+func TestTieredRepositoryShouldExpireLocalCopies(t *testing.T) {
+	t.Parallel()
+
+	backend := newMemoryCache(1, time.Minute, 0, 0)
+	repo := NewTieredRepository(backend, &testInvalidationBus{}, 10, time.Minute).(*tieredRepository)
+
+	repo.Set(ctx, "someKey", "someValue")
+	repo.Get(ctx, "someKey")
+
+	err := repo.Expire(ctx, "someKey", 20*time.Millisecond)
+	assert.Nil(t, err, "Expect err is nil when expiring a session")
+
+	ttl, err := repo.local.TTL(ctx, "someKey")
+	assert.Nil(t, err, "Expect session to be cached locally")
+	assert.LessOrEqual(t, ttl, 20*time.Millisecond, "Expect local copy to expire along with the session")
+}
@@ -0,0 +1,64 @@
+package adapters
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+)
+
+// encodeValue turns a session value into the string representation stored by
+// the adapters, following the same rules go-redis applies to command arguments.
+func encodeValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case encoding.BinaryMarshaler:
+		b, err := v.MarshalBinary()
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	default:
+		return "", fmt.Errorf("can't marshal %T (implement encoding.BinaryMarshaler)", value)
+	}
+}
+
+// pickFields returns the requested top level fields of a JSON object session
+// value, leaving out the ones it does not hold.
+func pickFields(val string, fields []string) (map[string]interface{}, error) {
+
+	var current map[string]interface{}
+	if err := json.Unmarshal([]byte(val), &current); err != nil {
+		return nil, fmt.Errorf("session is not a JSON object: %w", err)
+	}
+
+	result := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if value, ok := current[field]; ok {
+			result[field] = value
+		}
+	}
+	return result, nil
+}
+
+// mergeFields overwrites the given top level fields of a JSON object session
+// value, returning the encoded result.
+func mergeFields(val string, values map[string]interface{}) (string, error) {
+
+	var current map[string]interface{}
+	if err := json.Unmarshal([]byte(val), &current); err != nil {
+		return "", fmt.Errorf("session is not a JSON object: %w", err)
+	}
+
+	for field, value := range values {
+		current[field] = value
+	}
+
+	merged, err := json.Marshal(current)
+	if err != nil {
+		return "", err
+	}
+	return string(merged), nil
+}
@@ -0,0 +1,80 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IDFormat is the format of the IDs generated for sessions created without
+// one. Every format draws its randomness from crypto/rand.
+type IDFormat string
+
+const (
+	// IDFormatRandom is 256 random bits, base64url encoded without padding.
+	IDFormatRandom IDFormat = "random"
+	// IDFormatUUID is a random (version 4) UUID.
+	IDFormatUUID IDFormat = "uuid"
+	// IDFormatULID is a ULID: a millisecond timestamp followed by 80 random
+	// bits, so IDs sort by creation time.
+	IDFormatULID IDFormat = "ulid"
+)
+
+// ParseIDFormat reads the name of an ID format.
+func ParseIDFormat(s string) (IDFormat, error) {
+	switch format := IDFormat(s); format {
+	case IDFormatRandom, IDFormatUUID, IDFormatULID:
+		return format, nil
+	default:
+		return "", fmt.Errorf("%w: id format '%s' not supported", ErrInvalid, s)
+	}
+}
+
+// NewID generates an ID in the format f.
+func (f IDFormat) NewID() (string, error) {
+	switch f {
+	case IDFormatRandom:
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return "", fmt.Errorf("error '%s' when generating session id", err)
+		}
+		return base64.RawURLEncoding.EncodeToString(b), nil
+	case IDFormatUUID:
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return "", fmt.Errorf("error '%s' when generating session id", err)
+		}
+		return id.String(), nil
+	case IDFormatULID:
+		return newULID(time.Now())
+	default:
+		return "", fmt.Errorf("%w: id format '%s' not supported", ErrInvalid, f)
+	}
+}
+
+// crockfordBase32 is the alphabet ULIDs are written with.
+const crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+func newULID(now time.Time) (string, error) {
+
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(now.UnixMilli())<<16)
+	if _, err := rand.Read(b[6:]); err != nil {
+		return "", fmt.Errorf("error '%s' when generating session id", err)
+	}
+
+	// The 128 bits are written 5 at a time from the end, the first of the 26
+	// characters holding the 3 bits left.
+	hi, lo := binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])
+	id := make([]byte, 26)
+	for i := len(id) - 1; i >= 0; i-- {
+		id[i] = crockfordBase32[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(id), nil
+}
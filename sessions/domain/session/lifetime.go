@@ -0,0 +1,56 @@
+package session
+
+import (
+	"fmt"
+	"time"
+)
+
+// LifetimePolicy bounds how long sessions stay valid, on top of their TTL.
+// Whichever limit is reached first ends a session.
+type LifetimePolicy struct {
+	// Idle turns the TTL of sessions into an idle timeout, their expiry being
+	// restarted whenever they are read.
+	Idle bool
+	// Absolute is the longest a session stays valid after being created,
+	// however recently it was used, none when zero.
+	Absolute time.Duration
+}
+
+// Deadline returns when s stops being valid whatever its TTL, zero when it
+// has none: the earliest of its absolute lifetime and, for a rotated session,
+// its grace period ending.
+func (p LifetimePolicy) Deadline(s *Session) time.Time {
+	var deadline time.Time
+	if p.Absolute > 0 && !s.CreatedAt.IsZero() {
+		deadline = s.CreatedAt.Add(p.Absolute)
+	}
+	if !s.GraceEnds.IsZero() && (deadline.IsZero() || s.GraceEnds.Before(deadline)) {
+		deadline = s.GraceEnds
+	}
+	return deadline
+}
+
+// Check returns ErrNotFound when s is not valid at now, as it is either not
+// valid yet or past its deadline.
+func (p LifetimePolicy) Check(s *Session, now time.Time) error {
+	if now.Before(s.NotBefore) {
+		return fmt.Errorf("%w: session is not valid before %s", ErrNotFound, s.NotBefore.Format(time.RFC3339))
+	}
+	if !s.GraceEnds.IsZero() && !now.Before(s.GraceEnds) {
+		return fmt.Errorf("%w: session was rotated and its grace period is over", ErrNotFound)
+	}
+	if deadline := p.Deadline(s); !deadline.IsZero() && !now.Before(deadline) {
+		return fmt.Errorf("%w: session is past its absolute lifetime", ErrNotFound)
+	}
+	return nil
+}
+
+// Expiry returns the earliest of s expiring and reaching its deadline, zero
+// when neither is known.
+func (p LifetimePolicy) Expiry(s *Session) time.Time {
+	deadline := p.Deadline(s)
+	if s.ExpiresAt.IsZero() || (!deadline.IsZero() && deadline.Before(s.ExpiresAt)) {
+		return deadline
+	}
+	return s.ExpiresAt
+}
@@ -0,0 +1,274 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Patch changes the data of a session. Apply may modify data, and returns the
+// data as patched.
+type Patch interface {
+	Apply(data Data) (Data, error)
+}
+
+// MergePatch is an RFC 7396 JSON Merge Patch. Its members replace the members
+// of the data with the same name, objects being merged recursively, and null
+// members remove them.
+type MergePatch map[string]interface{}
+
+func (p MergePatch) Apply(data Data) (Data, error) {
+	if p == nil {
+		return nil, fmt.Errorf("%w: merge patch must be an object", ErrInvalid)
+	}
+	if data == nil {
+		data = Data{}
+	}
+	return mergePatch(map[string]interface{}(data), map[string]interface{}(p)).(map[string]interface{}), nil
+}
+
+func mergePatch(target interface{}, patch interface{}) interface{} {
+
+	members, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	object, ok := target.(map[string]interface{})
+	if !ok {
+		object = make(map[string]interface{}, len(members))
+	}
+	for name, value := range members {
+		if value == nil {
+			delete(object, name)
+			continue
+		}
+		object[name] = mergePatch(object[name], value)
+	}
+	return object
+}
+
+// JSONPatch is an RFC 6902 JSON Patch, its operations applied in order. The
+// whole patch fails when one of them does.
+type JSONPatch []PatchOperation
+
+// PatchOperation is one of the operations of a JSON Patch: add, remove,
+// replace, move, copy or test. From is only used by move and copy, and Value by
+// add, replace and test.
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Apply fails with ErrConflict when a test operation does not hold, as the
+// session is not in the state the patch expects, and with ErrInvalid when an
+// operation cannot be applied.
+func (p JSONPatch) Apply(data Data) (Data, error) {
+
+	for i, op := range p {
+		var err error
+		if data, err = op.apply(data); err != nil {
+			if op.Op == "test" && !errors.Is(err, ErrInvalid) {
+				return nil, fmt.Errorf("%w: operation %d: %s", ErrConflict, i, err)
+			}
+			return nil, fmt.Errorf("%w: operation %d (%s %s) cannot be applied: %s", ErrInvalid, i, op.Op, op.Path, err)
+		}
+	}
+	return data, nil
+}
+
+// NeedsValue tells whether the operation takes a value, as add, replace and
+// test do. A null value is still a value.
+func (op PatchOperation) NeedsValue() bool {
+	return op.Op == "add" || op.Op == "replace" || op.Op == "test"
+}
+
+func (op PatchOperation) apply(data Data) (Data, error) {
+
+	path, err := ParsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add":
+		return path.Add(data, copyValue(op.Value))
+	case "remove":
+		return path.Remove(data)
+	case "replace":
+		return path.Replace(data, copyValue(op.Value))
+	case "move", "copy":
+		from, err := ParsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := from.Get(data)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "copy" {
+			return path.Add(data, copyValue(value))
+		}
+		if len(from) < len(path) && isPrefix(from, path) {
+			return nil, fmt.Errorf("%w: '%s' cannot be moved into itself", ErrInvalid, from)
+		}
+		if data, err = from.Remove(data); err != nil {
+			return nil, err
+		}
+		return path.Add(data, value)
+	case "test":
+		value, err := path.Get(data)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(value, op.Value) {
+			return nil, fmt.Errorf("value at '%s' is not the expected one", path)
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown operation '%s'", ErrInvalid, op.Op)
+	}
+}
+
+// SetValue sets the value Path refers to, replacing it when there is one and
+// adding it otherwise. Unlike the operations of a JSON Patch, it fails with
+// ErrNotFound when the value cannot be reached.
+type SetValue struct {
+	Path  Pointer
+	Value interface{}
+}
+
+func (p SetValue) Apply(data Data) (Data, error) {
+	if _, err := p.Path.Get(data); err == nil {
+		return p.Path.Replace(data, p.Value)
+	}
+	return p.Path.Add(data, p.Value)
+}
+
+// SetFields replaces the top level fields of the data with the same name, and
+// adds the others.
+type SetFields Data
+
+func (p SetFields) Apply(data Data) (Data, error) {
+	for field, value := range p {
+		data[field] = copyValue(value)
+	}
+	return data, nil
+}
+
+// RemoveValue removes the value Path refers to, failing with ErrNotFound when
+// there is none.
+type RemoveValue struct {
+	Path Pointer
+}
+
+func (p RemoveValue) Apply(data Data) (Data, error) {
+	return p.Path.Remove(data)
+}
+
+// MaxCounter bounds the counters Increment keeps, to the integers every JSON
+// decoder reads exactly.
+const MaxCounter = 1<<53 - 1
+
+// Increment adds Delta to the integer Path refers to, counting from zero when
+// there is none. It fails with ErrInvalid when the value is not an integer or
+// the counter would go past MaxCounter either way, and with ErrNotFound when
+// its parent cannot be reached.
+type Increment struct {
+	Path  Pointer
+	Delta int64
+}
+
+func (p Increment) Apply(data Data) (Data, error) {
+
+	if len(p.Path) == 0 {
+		return nil, fmt.Errorf("%w: the whole session is not a counter", ErrInvalid)
+	}
+
+	var counter int64
+	value, err := p.Path.Get(data)
+	switch {
+	case errors.Is(err, ErrNotFound):
+	case err != nil:
+		return nil, err
+	default:
+		var ok bool
+		if counter, ok = Counter(value); !ok {
+			return nil, fmt.Errorf("%w: value at '%s' is not an integer", ErrInvalid, p.Path)
+		}
+	}
+
+	sum := counter + p.Delta
+	if p.Delta > MaxCounter || p.Delta < -MaxCounter || sum > MaxCounter || sum < -MaxCounter {
+		return nil, fmt.Errorf("%w: value at '%s' would be out of range", ErrInvalid, p.Path)
+	}
+	if err != nil {
+		return p.Path.Add(data, sum)
+	}
+	return p.Path.Replace(data, sum)
+}
+
+// Counter reads value as the integer a counter holds, whether it was decoded
+// as a float64 or a json.Number. It reports false for any other value, and for
+// integers past MaxCounter.
+func Counter(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return v, v <= MaxCounter && v >= -MaxCounter
+	case float64:
+		if v != math.Trunc(v) || math.Abs(v) > MaxCounter {
+			return 0, false
+		}
+		return int64(v), true
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return Counter(n)
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return 0, false
+		}
+		return Counter(f)
+	default:
+		return 0, false
+	}
+}
+
+// isPrefix tells whether p refers to a value within the one prefix refers to,
+// or to the same one.
+func isPrefix(prefix, p Pointer) bool {
+	if len(prefix) > len(p) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != p[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// copyValue copies the objects and arrays of value, so values held in more
+// than one place of the data can be changed independently.
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for name, member := range v {
+			copied[name] = copyValue(member)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, element := range v {
+			copied[i] = copyValue(element)
+		}
+		return copied
+	default:
+		return value
+	}
+}
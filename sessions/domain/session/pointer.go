@@ -0,0 +1,210 @@
+package session
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Pointer is an RFC 6901 JSON Pointer to a value held by the data of a
+// session, as the reference tokens it is made of. The empty pointer refers to
+// the whole data.
+type Pointer []string
+
+// ParsePointer reads a JSON Pointer such as "/props/cart".
+func ParsePointer(s string) (Pointer, error) {
+	if s == "" {
+		return Pointer{}, nil
+	}
+	if !strings.HasPrefix(s, "/") {
+		return nil, fmt.Errorf("%w: pointer '%s' must start with /", ErrInvalid, s)
+	}
+
+	tokens := strings.Split(s[1:], "/")
+	for i, token := range tokens {
+		unescaped, err := unescapeToken(token)
+		if err != nil {
+			return nil, fmt.Errorf("%w: pointer '%s' %s", ErrInvalid, s, err)
+		}
+		tokens[i] = unescaped
+	}
+	return tokens, nil
+}
+
+func unescapeToken(token string) (string, error) {
+	for i := 0; i < len(token); i++ {
+		if token[i] == '~' && (i+1 == len(token) || (token[i+1] != '0' && token[i+1] != '1')) {
+			return "", fmt.Errorf("has an invalid escape in '%s'", token)
+		}
+	}
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(token), nil
+}
+
+func (p Pointer) String() string {
+	var b strings.Builder
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+	for _, token := range p {
+		b.WriteString("/")
+		b.WriteString(escaper.Replace(token))
+	}
+	return b.String()
+}
+
+// Get returns the value p refers to in data, or ErrNotFound when there is
+// none.
+func (p Pointer) Get(data Data) (interface{}, error) {
+
+	var node interface{} = map[string]interface{}(data)
+	for i, token := range p {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			child, ok := n[token]
+			if !ok {
+				return nil, p.notFound(i)
+			}
+			node = child
+		case []interface{}:
+			index, err := arrayIndex(token, len(n)-1)
+			if err != nil {
+				return nil, p.notFound(i)
+			}
+			node = n[index]
+		default:
+			return nil, p.notFound(i)
+		}
+	}
+	return node, nil
+}
+
+// Add sets the value p refers to in data, as the add operation of JSON Patch:
+// members are added or replaced, array elements are inserted and "-" appends to
+// an array. It returns the updated data.
+func (p Pointer) Add(data Data, value interface{}) (Data, error) {
+	return p.edit(data, editAdd, value)
+}
+
+// Replace changes the value p refers to in data, which must exist. It returns
+// the updated data.
+func (p Pointer) Replace(data Data, value interface{}) (Data, error) {
+	return p.edit(data, editReplace, value)
+}
+
+// Remove deletes the value p refers to from data, which must exist. It
+// returns the updated data.
+func (p Pointer) Remove(data Data) (Data, error) {
+	if len(p) == 0 {
+		return nil, fmt.Errorf("%w: the whole session cannot be removed", ErrInvalid)
+	}
+	return p.edit(data, editRemove, nil)
+}
+
+type edit int
+
+const (
+	editAdd edit = iota
+	editReplace
+	editRemove
+)
+
+func (p Pointer) edit(data Data, op edit, value interface{}) (Data, error) {
+
+	if data == nil {
+		data = Data{}
+	}
+	updated, err := p.editNode(map[string]interface{}(data), 0, op, value)
+	if err != nil {
+		return nil, err
+	}
+
+	root, ok := updated.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: session data must be an object", ErrInvalid)
+	}
+	return root, nil
+}
+
+// editNode applies op to the value the tokens of p from i on refer to within
+// node, and returns node updated.
+func (p Pointer) editNode(node interface{}, i int, op edit, value interface{}) (interface{}, error) {
+
+	if i == len(p) {
+		return value, nil
+	}
+	token, last := p[i], i == len(p)-1
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[token]
+		if !ok && (!last || op != editAdd) {
+			return nil, p.notFound(i)
+		}
+		if !last {
+			updated, err := p.editNode(child, i+1, op, value)
+			if err != nil {
+				return nil, err
+			}
+			n[token] = updated
+			return n, nil
+		}
+		if op == editRemove {
+			delete(n, token)
+		} else {
+			n[token] = value
+		}
+		return n, nil
+
+	case []interface{}:
+		if last && op == editAdd {
+			if token == "-" {
+				return append(n, value), nil
+			}
+			index, err := arrayIndex(token, len(n))
+			if err != nil {
+				return nil, p.notFound(i)
+			}
+			n = append(n, nil)
+			copy(n[index+1:], n[index:])
+			n[index] = value
+			return n, nil
+		}
+
+		index, err := arrayIndex(token, len(n)-1)
+		if err != nil {
+			return nil, p.notFound(i)
+		}
+		if !last {
+			updated, err := p.editNode(n[index], i+1, op, value)
+			if err != nil {
+				return nil, err
+			}
+			n[index] = updated
+			return n, nil
+		}
+		if op == editRemove {
+			return append(n[:index], n[index+1:]...), nil
+		}
+		n[index] = value
+		return n, nil
+
+	default:
+		return nil, p.notFound(i)
+	}
+}
+
+// notFound reports the tokens of p up to i do not refer to a value.
+func (p Pointer) notFound(i int) error {
+	return fmt.Errorf("%w: no value at '%s'", ErrNotFound, p[:i+1])
+}
+
+// arrayIndex reads token as an array index up to max, which RFC 6901 writes
+// without leading zeros.
+func arrayIndex(token string, max int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.Trim(token, "0123456789") != "" {
+		return 0, fmt.Errorf("'%s' is not an array index", token)
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > max {
+		return 0, fmt.Errorf("'%s' is not an array index", token)
+	}
+	return index, nil
+}
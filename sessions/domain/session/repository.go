@@ -1,9 +1,133 @@
 package session
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
 
+// ErrNotFound is returned by repositories when a session key does not exist or has expired.
+var ErrNotFound = errors.New("session not found")
+
+// ErrNotSupported is returned when the configured repository cannot perform an operation.
+var ErrNotSupported = errors.New("operation not supported by the session repository")
+
+// ErrUnavailable is returned when the session backend cannot be reached, or is
+// deemed down after failing repeatedly.
+var ErrUnavailable = errors.New("session backend unavailable")
+
+// ErrInvalid is returned when a session or a request about it is not valid.
+var ErrInvalid = errors.New("invalid session")
+
+// ErrPreconditionFailed is returned when a conditional write does not apply to
+// the session as stored, like when its version is not the expected one.
+var ErrPreconditionFailed = errors.New("session precondition failed")
+
+// ErrConflict is returned when a write keeps losing the race against concurrent
+// writes of the same session.
+var ErrConflict = errors.New("session changed concurrently")
+
+// ErrReadOnly is returned when writing a session that was rotated, which is
+// only kept under its old ID for reading until its grace period ends.
+var ErrReadOnly = errors.New("session is read-only")
+
+// SetOptions tune how a session is stored.
+type SetOptions struct {
+	// TTL keeps the session for that long instead of the default expiry when
+	// it is not zero.
+	TTL time.Duration
+	// KeepTTL leaves an existing session the time it has left instead of
+	// restarting its expiry. TTL only applies when the session is created.
+	KeepTTL bool
+	// NotBefore keeps the session from being valid until then when it is not
+	// zero. The TTL of the session counts from then.
+	NotBefore time.Time
+	// IfVersion only writes the session while it is stored with that version
+	// when it is not zero.
+	IfVersion int64
+	// CreateOnly only writes the session when it does not exist yet.
+	CreateOnly bool
+	// UpdateOnly only writes the session when it exists already.
+	UpdateOnly bool
+	// KeepSubject leaves an existing session the subject it belongs to instead
+	// of giving it the one of the session written.
+	KeepSubject bool
+}
+
+// Validate checks the conditions of opts can all hold at once.
+func (opts SetOptions) Validate() error {
+	if opts.IfVersion < 0 {
+		return fmt.Errorf("%w: expected version cannot be negative", ErrInvalid)
+	}
+	if opts.CreateOnly && (opts.UpdateOnly || opts.IfVersion != 0) {
+		return fmt.Errorf("%w: a create only write cannot expect an existing session", ErrInvalid)
+	}
+	return nil
+}
+
+// Check tells whether the conditions of opts hold for current, the session as
+// stored or nil when there is none.
+func (opts SetOptions) Check(current *Session) error {
+	switch {
+	case opts.CreateOnly && current != nil:
+		return fmt.Errorf("%w: session already exists", ErrPreconditionFailed)
+	case (opts.UpdateOnly || opts.IfVersion != 0) && current == nil:
+		return fmt.Errorf("%w: session does not exist", ErrPreconditionFailed)
+	case opts.IfVersion != 0 && current.Version != opts.IfVersion:
+		return fmt.Errorf("%w: session is at version %d, not %d", ErrPreconditionFailed, current.Version, opts.IfVersion)
+	}
+	return nil
+}
+
+// Conditional tells whether opts make the write depend on the stored session.
+func (opts SetOptions) Conditional() bool {
+	return opts.IfVersion != 0 || opts.CreateOnly || opts.UpdateOnly
+}
+
+// Repository keeps sessions under their IDs until they expire, treating the
+// sessions that are not valid under its lifetime policy as not found.
 type Repository interface {
-	Set(ctx context.Context, key string, value interface{}) error
-	Get(ctx context.Context, key string) (interface{}, error)
-	Delete(ctx context.Context, key string) (int64, error)
+	// Set stores s, filling in its timestamps, expiry and version as stored.
+	// It fails with ErrPreconditionFailed when the conditions of opts do not
+	// hold, and with ErrConflict when concurrent writes keep getting in first.
+	Set(ctx context.Context, s *Session, opts SetOptions) error
+	Get(ctx context.Context, id string) (*Session, error)
+	Delete(ctx context.Context, id string) error
+	// Touch restarts the expiry of a session without rewriting it, keeping it
+	// for ttl from now, or for its own TTL when ttl is zero. It returns when
+	// the session expires, zero when it never does.
+	Touch(ctx context.Context, id string, ttl time.Duration) (time.Time, error)
+	// Patch changes the data of a session as patch tells, atomically, keeping
+	// the time it has left, and returns the version it is written at. When
+	// ifVersion is not zero the session is only patched while it is at that
+	// version.
+	Patch(ctx context.Context, id string, patch Patch, ifVersion int64) (int64, error)
+	// Increment adds delta to the integer path refers to within a session, as
+	// patching it does, and returns the integer it is left at. Stores able to
+	// increment it in place do so in one write, which concurrent writes cannot
+	// fail; with other stores it fails with ErrConflict when they keep
+	// getting in first, as patching does.
+	Increment(ctx context.Context, id string, path Pointer, delta int64) (int64, error)
+	// Rotate moves a session to newID, along with the time it has left, and
+	// returns it as stored there. The session stays under id for grace, as it
+	// was but read-only, or is deleted right away when grace is zero. It fails
+	// with ErrPreconditionFailed when newID is in use.
+	Rotate(ctx context.Context, id, newID string, grace time.Duration) (*Session, error)
+	// GetSubjectSessions returns the valid sessions of subject, oldest first.
+	GetSubjectSessions(ctx context.Context, subject string) ([]*Session, error)
+	// DeleteSubjectSessions deletes every session of subject, and returns how
+	// many there were.
+	DeleteSubjectSessions(ctx context.Context, subject string) (int, error)
+}
+
+// FieldRepository is implemented by repositories able to read and write some of
+// the top level fields of a session without touching the rest of it.
+type FieldRepository interface {
+	GetFields(ctx context.Context, id string, fields []string) (Data, error)
+	// SetFields writes values over the top level fields of a session with the
+	// same name, atomically, as Patch does, and returns the version the
+	// session is written at. When ifVersion is not zero the session is only
+	// written while it is at that version.
+	SetFields(ctx context.Context, id string, values Data, ifVersion int64) (int64, error)
 }
@@ -0,0 +1,88 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ReservedPrefix starts the names of the fields the service keeps next to the
+// data of a session, which the data itself cannot use.
+const ReservedPrefix = "__"
+
+// ReservedKeyPrefix starts the keys stores may keep next to the sessions, such
+// as the indexes of subjects, which sessions cannot be stored under. None of
+// the ID formats generates its ':', so generated IDs never start with it.
+const ReservedKeyPrefix = ReservedPrefix + ":"
+
+// Data holds the values of a session by their top level field names.
+type Data map[string]interface{}
+
+// Session is a set of values kept under an ID until it expires.
+type Session struct {
+	ID   string
+	Data Data
+	// CreatedAt and UpdatedAt are zero for sessions stored before they were
+	// tracked.
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// ExpiresAt is zero for sessions that never expire, or whose expiry is not
+	// known. It accounts for the lifetime policy sessions are kept under.
+	ExpiresAt time.Time
+	// NotBefore is when the session becomes valid, zero when it is valid as
+	// soon as it is created.
+	NotBefore time.Time
+	// TTL is the time the session is kept for after being written or touched,
+	// zero when it follows the default expiry.
+	TTL time.Duration
+	// Version counts the writes of the session, starting at 1 when it is
+	// created.
+	Version int64
+	// Subject is who the session belongs to, such as a user ID, empty when it
+	// belongs to no one. The sessions of a subject can be listed and deleted
+	// together.
+	Subject string
+	// RotatedTo is the ID the session was rotated to, when it is only kept
+	// under this one until GraceEnds for requests already on their way with
+	// it. Such sessions can be read, but not written nor touched.
+	RotatedTo string
+	GraceEnds time.Time
+}
+
+// CheckWritable fails with ErrReadOnly when s was rotated.
+func (s *Session) CheckWritable() error {
+	if s.RotatedTo != "" {
+		return fmt.Errorf("%w: session was rotated to another key", ErrReadOnly)
+	}
+	return nil
+}
+
+// Validate checks that data does not use reserved field names.
+func (d Data) Validate() error {
+	for field := range d {
+		if err := ValidateField(field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateID checks that id is not empty, and does not use the keys reserved
+// for what the service stores next to the sessions.
+func ValidateID(id string) error {
+	if id == "" {
+		return fmt.Errorf("%w: session key cannot be empty", ErrInvalid)
+	}
+	if strings.HasPrefix(id, ReservedKeyPrefix) {
+		return fmt.Errorf("%w: session key '%s' is reserved", ErrInvalid, id)
+	}
+	return nil
+}
+
+// ValidateField checks that field is not a reserved field name.
+func ValidateField(field string) error {
+	if strings.HasPrefix(field, ReservedPrefix) {
+		return fmt.Errorf("%w: field '%s' is reserved", ErrInvalid, field)
+	}
+	return nil
+}
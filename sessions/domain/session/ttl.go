@@ -0,0 +1,30 @@
+package session
+
+import (
+	"fmt"
+	"time"
+)
+
+// TTLPolicy bounds the time callers can ask a session to be kept for.
+type TTLPolicy struct {
+	// Max is the longest ttl allowed, none when zero.
+	Max time.Duration
+	// Clamp shortens the ttls over Max to Max instead of rejecting them.
+	Clamp bool
+}
+
+// Apply returns the ttl to store a session with when ttl is asked for. A zero
+// ttl stands for the default expiry and is always allowed.
+func (p TTLPolicy) Apply(ttl time.Duration) (time.Duration, error) {
+
+	if ttl < 0 {
+		return 0, fmt.Errorf("%w: ttl cannot be negative", ErrInvalid)
+	}
+	if p.Max <= 0 || ttl <= p.Max {
+		return ttl, nil
+	}
+	if p.Clamp {
+		return p.Max, nil
+	}
+	return 0, fmt.Errorf("%w: ttl %s is over the maximum of %s", ErrInvalid, ttl, p.Max)
+}
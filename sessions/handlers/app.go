@@ -6,12 +6,24 @@ import (
 )
 
 type Commands struct {
-	DeleteSession command.DeleteSessionHandler
-	SetSession    command.SetSessionHandler
+	CreateSession           command.CreateSessionHandler
+	DeleteSession           command.DeleteSessionHandler
+	DeleteSessionValue      command.DeleteSessionValueHandler
+	DeleteSubjectSessions   command.DeleteSubjectSessionsHandler
+	IncrementSessionCounter command.IncrementSessionCounterHandler
+	PatchSession            command.PatchSessionHandler
+	RotateSession           command.RotateSessionHandler
+	SetSession              command.SetSessionHandler
+	SetSessionFields        command.SetSessionFieldsHandler
+	SetSessionValue         command.SetSessionValueHandler
+	TouchSession            command.TouchSessionHandler
 }
 
 type Queries struct {
-	GetSession query.GetSessionHandler
+	GetSession         query.GetSessionHandler
+	GetSessionFields   query.GetSessionFieldsHandler
+	GetSessionValue    query.GetSessionValueHandler
+	GetSubjectSessions query.GetSubjectSessionsHandler
 }
 
 type Application struct {
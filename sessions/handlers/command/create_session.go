@@ -0,0 +1,88 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jruben-rg/go-commons-handler/decorator"
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+)
+
+// maxIDAttempts bounds how many IDs are generated for a session before giving
+// up, should every one of them be in use already.
+const maxIDAttempts = 3
+
+type CreateSession struct {
+	Value session.Data
+	// TTL keeps the session for that long instead of the default expiry when
+	// it is not zero, as allowed by the configured policy.
+	TTL time.Duration
+	// NotBefore keeps the session from being valid until then when it is not
+	// zero.
+	NotBefore time.Time
+	// Subject is who the session belongs to, none when empty.
+	Subject string
+}
+
+// CreateSessionHandler returns the ID generated for the session, unlike other
+// commands, as callers have no other way to learn it.
+type CreateSessionHandler decorator.QueryHandler[CreateSession, string]
+
+type createSessionHandler struct {
+	sessionRepo session.Repository
+	ttlPolicy   session.TTLPolicy
+	idFormat    session.IDFormat
+}
+
+// NewCreateSessionHandler returns a handler that stores sessions under IDs it
+// generates in idFormat. Sessions are only written when their ID is not in use,
+// the store checking it atomically, and another ID is tried otherwise.
+func NewCreateSessionHandler(
+	sessionRepo session.Repository,
+	ttlPolicy session.TTLPolicy,
+	idFormat session.IDFormat,
+	logger *logrus.Entry,
+) CreateSessionHandler {
+
+	if sessionRepo == nil {
+		panic("nil sessionRepo")
+	}
+
+	return decorator.WithQueryDecorators[CreateSession, string](
+		createSessionHandler{sessionRepo: sessionRepo, ttlPolicy: ttlPolicy, idFormat: idFormat},
+		logger,
+	)
+}
+
+func (h createSessionHandler) Handle(ctx context.Context, cmd CreateSession) (string, error) {
+
+	ttl, err := h.ttlPolicy.Apply(cmd.TTL)
+	if err != nil {
+		return "", err
+	}
+
+	for i := 0; i < maxIDAttempts; i++ {
+		id, err := h.idFormat.NewID()
+		if err != nil {
+			return "", err
+		}
+
+		err = h.sessionRepo.Set(ctx, &session.Session{ID: id, Data: cmd.Value, Subject: cmd.Subject}, session.SetOptions{
+			TTL:        ttl,
+			NotBefore:  cmd.NotBefore,
+			CreateOnly: true,
+		})
+		if errors.Is(err, session.ErrPreconditionFailed) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("error when trying to create session %s: %w", id, err)
+		}
+		return id, nil
+	}
+
+	return "", fmt.Errorf("%w: gave up creating session after %d ids in use", session.ErrConflict, maxIDAttempts)
+}
@@ -0,0 +1,135 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCreateRepository fails the writes with the errors in errs, in order.
+type TestCreateRepository struct {
+	session.Repository
+	errs []error
+	ids  []string
+	opts session.SetOptions
+}
+
+func (tcr *TestCreateRepository) Set(ctx context.Context, s *session.Session, opts session.SetOptions) error {
+	tcr.ids = append(tcr.ids, s.ID)
+	tcr.opts = opts
+	if len(tcr.errs) < len(tcr.ids) {
+		return nil
+	}
+	return tcr.errs[len(tcr.ids)-1]
+}
+
+func TestCreateSessionHandlerShouldGenerateIDs(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+
+	tests := []struct {
+		scenario   string
+		format     session.IDFormat
+		expectedID *regexp.Regexp
+	}{
+		{
+			scenario:   "Should generate 256 random bits base64url encoded",
+			format:     session.IDFormatRandom,
+			expectedID: regexp.MustCompile(`^[A-Za-z0-9_-]{43}$`),
+		},
+		{
+			scenario:   "Should generate random UUIDs",
+			format:     session.IDFormatUUID,
+			expectedID: regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`),
+		},
+		{
+			scenario:   "Should generate ULIDs",
+			format:     session.IDFormatULID,
+			expectedID: regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`),
+		},
+	}
+
+	for _, test := range tests {
+
+		repo := &TestCreateRepository{}
+		handler := NewCreateSessionHandler(repo, session.TTLPolicy{}, test.format, logger)
+
+		first, err := handler.Handle(context.Background(), CreateSession{Value: session.Data{"user": "someUser"}})
+		assert.Nil(t, err, test.scenario)
+		second, err := handler.Handle(context.Background(), CreateSession{Value: session.Data{"user": "someUser"}})
+		assert.Nil(t, err, test.scenario)
+
+		assert.Regexp(t, test.expectedID, first, test.scenario)
+		assert.NotEqual(t, first, second, test.scenario)
+		assert.Equal(t, []string{first, second}, repo.ids, test.scenario)
+		assert.True(t, repo.opts.CreateOnly, "Expect sessions to only be created under ids not in use")
+	}
+}
+
+func TestCreateSessionHandlerShouldRetryIDsInUse(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+	inUse := fmt.Errorf("wrapped: %w", session.ErrPreconditionFailed)
+
+	tests := []struct {
+		scenario    string
+		errs        []error
+		expectedErr error
+		expectedIDs int
+	}{
+		{
+			scenario:    "Should try another id when the first one is in use",
+			errs:        []error{inUse},
+			expectedIDs: 2,
+		},
+		{
+			scenario:    "Should give up when every id is in use",
+			errs:        []error{inUse, inUse, inUse},
+			expectedErr: session.ErrConflict,
+			expectedIDs: maxIDAttempts,
+		},
+		{
+			scenario:    "Should not try another id when the store fails",
+			errs:        []error{session.ErrUnavailable},
+			expectedErr: session.ErrUnavailable,
+			expectedIDs: 1,
+		},
+	}
+
+	for _, test := range tests {
+
+		repo := &TestCreateRepository{errs: test.errs}
+		handler := NewCreateSessionHandler(repo, session.TTLPolicy{}, session.IDFormatRandom, logger)
+		id, err := handler.Handle(context.Background(), CreateSession{})
+
+		if test.expectedErr != nil {
+			assert.ErrorIs(t, err, test.expectedErr, test.scenario)
+			assert.Empty(t, id, test.scenario)
+		} else {
+			assert.Nil(t, err, test.scenario)
+			assert.Equal(t, repo.ids[len(repo.ids)-1], id, test.scenario)
+		}
+		assert.Len(t, repo.ids, test.expectedIDs, test.scenario)
+	}
+}
+
+func TestCreateSessionHandlerShouldPanicIfNilRepo(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Handle method did not panic")
+		}
+	}()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+	handler := NewCreateSessionHandler(nil, session.TTLPolicy{}, session.IDFormatRandom, logger)
+	handler.Handle(context.Background(), CreateSession{})
+}
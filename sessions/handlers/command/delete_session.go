@@ -34,6 +34,5 @@ func NewDeleteSessionHandler(
 }
 
 func (h deleteSessionHandler) Handle(ctx context.Context, cmd DeleteSession) error {
-	_, err := h.sessionRepo.Delete(ctx, cmd.Key)
-	return err
+	return h.sessionRepo.Delete(ctx, cmd.Key)
 }
@@ -13,13 +13,12 @@ import (
 type TestDeleteRepository struct {
 	session.Repository
 	err     error
-	val     int64
 	invoked bool
 }
 
-func (tdr *TestDeleteRepository) Delete(ctx context.Context, key string) (int64, error) {
+func (tdr *TestDeleteRepository) Delete(ctx context.Context, id string) error {
 	tdr.invoked = true
-	return tdr.val, tdr.err
+	return tdr.err
 }
 
 type TestDeleteSession deleteSessionHandler
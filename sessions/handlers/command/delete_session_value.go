@@ -0,0 +1,59 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jruben-rg/go-commons-handler/decorator"
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+)
+
+type DeleteSessionValue struct {
+	Key string
+	// Pointer is the JSON Pointer (RFC 6901) to the value within the session.
+	Pointer string
+	// IfVersion only deletes the value while the session is at that version
+	// when it is not zero.
+	IfVersion int64
+}
+
+// DeleteSessionValueHandler returns the version the session is written at.
+type DeleteSessionValueHandler decorator.QueryHandler[DeleteSessionValue, int64]
+
+type deleteSessionValueHandler struct {
+	sessionRepo session.Repository
+}
+
+// NewDeleteSessionValueHandler returns a handler that removes a single value
+// of a session, atomically, keeping the rest of it.
+func NewDeleteSessionValueHandler(
+	sessionRepo session.Repository,
+	logger *logrus.Entry,
+) DeleteSessionValueHandler {
+
+	if sessionRepo == nil {
+		panic("nil sessionRepo")
+	}
+
+	return decorator.WithQueryDecorators[DeleteSessionValue, int64](
+		deleteSessionValueHandler{sessionRepo: sessionRepo},
+		logger,
+	)
+}
+
+func (h deleteSessionValueHandler) Handle(ctx context.Context, cmd DeleteSessionValue) (int64, error) {
+
+	path, err := session.ParsePointer(cmd.Pointer)
+	if err != nil {
+		return 0, err
+	}
+
+	patch := session.RemoveValue{Path: path}
+	version, err := h.sessionRepo.Patch(ctx, cmd.Key, patch, cmd.IfVersion)
+	if err != nil {
+		return 0, fmt.Errorf("error when trying to delete value %s of session %s: %w", cmd.Pointer, cmd.Key, err)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,77 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteSessionValueHandlerShouldPatchTheValue(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+
+	tests := []struct {
+		scenario        string
+		pointer         string
+		repoErr         error
+		expectedErr     error
+		isPatchExpected bool
+	}{
+		{
+			scenario:        "Should return error if repository returns error",
+			pointer:         "/props/cart",
+			repoErr:         fmt.Errorf("%w: no value at '/props'", session.ErrNotFound),
+			expectedErr:     session.ErrNotFound,
+			isPatchExpected: true,
+		},
+		{
+			scenario:    "Should reject an invalid pointer without patching",
+			pointer:     "/props/~2",
+			expectedErr: session.ErrInvalid,
+		},
+		{
+			scenario:        "Should delete the value",
+			pointer:         "/props/cart",
+			isPatchExpected: true,
+		},
+	}
+
+	for _, test := range tests {
+
+		repo := &TestPatchRepository{err: test.repoErr}
+		handler := NewDeleteSessionValueHandler(repo, logger)
+		version, err := handler.Handle(context.Background(), DeleteSessionValue{Key: "key", Pointer: test.pointer, IfVersion: 2})
+
+		if test.expectedErr != nil {
+			assert.ErrorIs(t, err, test.expectedErr, test.scenario)
+		} else {
+			assert.Nil(t, err, test.scenario)
+			assert.Equal(t, int64(3), version, "Expect the version written to be returned")
+		}
+
+		assert.Equal(t, test.isPatchExpected, repo.invoked, test.scenario)
+		if test.isPatchExpected {
+			assert.Equal(t, session.RemoveValue{Path: session.Pointer{"props", "cart"}}, repo.patch, test.scenario)
+			assert.Equal(t, int64(2), repo.ifVersion, test.scenario)
+		}
+	}
+}
+
+func TestDeleteSessionValueHandlerShouldPanicIfNilRepo(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Handle method did not panic")
+		}
+	}()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+	handler := NewDeleteSessionValueHandler(nil, logger)
+	handler.Handle(context.Background(), DeleteSessionValue{})
+}
@@ -0,0 +1,46 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jruben-rg/go-commons-handler/decorator"
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+)
+
+type DeleteSubjectSessions struct {
+	Subject string
+}
+
+// DeleteSubjectSessionsHandler returns how many sessions were deleted, unlike
+// other commands, so callers can tell whether the subject had any.
+type DeleteSubjectSessionsHandler decorator.QueryHandler[DeleteSubjectSessions, int]
+
+type deleteSubjectSessionsHandler struct {
+	sessionRepo session.Repository
+}
+
+func NewDeleteSubjectSessionsHandler(
+	sessionRepo session.Repository,
+	logger *logrus.Entry,
+) DeleteSubjectSessionsHandler {
+
+	if sessionRepo == nil {
+		panic("nil sessionRepo")
+	}
+
+	return decorator.WithQueryDecorators[DeleteSubjectSessions, int](
+		deleteSubjectSessionsHandler{sessionRepo: sessionRepo},
+		logger,
+	)
+}
+
+func (h deleteSubjectSessionsHandler) Handle(ctx context.Context, cmd DeleteSubjectSessions) (int, error) {
+
+	deleted, err := h.sessionRepo.DeleteSubjectSessions(ctx, cmd.Subject)
+	if err != nil {
+		return deleted, fmt.Errorf("error when trying to delete sessions of subject %s: %w", cmd.Subject, err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,75 @@
+package command
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestDeleteSubjectSessionsRepository struct {
+	session.Repository
+	err     error
+	deleted int
+	subject string
+}
+
+func (tdsr *TestDeleteSubjectSessionsRepository) DeleteSubjectSessions(ctx context.Context, subject string) (int, error) {
+	tdsr.subject = subject
+	return tdsr.deleted, tdsr.err
+}
+
+func TestDeleteSubjectSessionsHandlerShouldInvokeDeleteSubjectSessionsMethod(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+
+	tests := []struct {
+		scenario    string
+		repoErr     error
+		deleted     int
+		expectedErr error
+	}{
+		{
+			scenario:    "Should return error and the sessions deleted so far if repository returns error",
+			repoErr:     session.ErrUnavailable,
+			deleted:     1,
+			expectedErr: session.ErrUnavailable,
+		},
+		{
+			scenario: "Should return how many sessions were deleted",
+			deleted:  3,
+		},
+	}
+
+	for _, test := range tests {
+
+		repo := &TestDeleteSubjectSessionsRepository{err: test.repoErr, deleted: test.deleted}
+		handler := NewDeleteSubjectSessionsHandler(repo, logger)
+		deleted, err := handler.Handle(context.Background(), DeleteSubjectSessions{Subject: "someUser"})
+
+		if test.expectedErr != nil {
+			assert.ErrorIs(t, err, test.expectedErr, test.scenario)
+		} else {
+			assert.Nil(t, err, test.scenario)
+		}
+		assert.Equal(t, test.deleted, deleted, test.scenario)
+		assert.Equal(t, "someUser", repo.subject, test.scenario)
+	}
+}
+
+func TestDeleteSubjectSessionsHandlerShouldPanicIfNilRepo(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Handle method did not panic")
+		}
+	}()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+	handler := NewDeleteSubjectSessionsHandler(nil, logger)
+	handler.Handle(context.Background(), DeleteSubjectSessions{})
+}
@@ -0,0 +1,58 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jruben-rg/go-commons-handler/decorator"
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+)
+
+type IncrementSessionCounter struct {
+	Key string
+	// Pointer is the JSON Pointer (RFC 6901) to the counter within the session.
+	Pointer string
+	// Delta is added to the counter, a negative one decrementing it.
+	Delta int64
+}
+
+// IncrementSessionCounterHandler returns the value the counter is left at, as
+// reading it afterwards would race other increments.
+type IncrementSessionCounterHandler decorator.QueryHandler[IncrementSessionCounter, int64]
+
+type incrementSessionCounterHandler struct {
+	sessionRepo session.Repository
+}
+
+// NewIncrementSessionCounterHandler returns a handler that adds to an integer
+// held by a session, atomically, creating it when missing.
+func NewIncrementSessionCounterHandler(
+	sessionRepo session.Repository,
+	logger *logrus.Entry,
+) IncrementSessionCounterHandler {
+
+	if sessionRepo == nil {
+		panic("nil sessionRepo")
+	}
+
+	return decorator.WithQueryDecorators[IncrementSessionCounter, int64](
+		incrementSessionCounterHandler{sessionRepo: sessionRepo},
+		logger,
+	)
+}
+
+func (h incrementSessionCounterHandler) Handle(ctx context.Context, cmd IncrementSessionCounter) (int64, error) {
+
+	path, err := session.ParsePointer(cmd.Pointer)
+	if err != nil {
+		return 0, err
+	}
+
+	value, err := h.sessionRepo.Increment(ctx, cmd.Key, path, cmd.Delta)
+	if err != nil {
+		return 0, fmt.Errorf("error when trying to increment counter %s of session %s: %w", cmd.Pointer, cmd.Key, err)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,95 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestIncrementRepository struct {
+	session.Repository
+	err     error
+	value   int64
+	invoked bool
+	path    session.Pointer
+	delta   int64
+}
+
+func (tir *TestIncrementRepository) Increment(ctx context.Context, id string, path session.Pointer, delta int64) (int64, error) {
+	tir.invoked = true
+	tir.path = path
+	tir.delta = delta
+	return tir.value, tir.err
+}
+
+func TestIncrementSessionCounterHandlerShouldInvokeIncrementMethod(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+
+	tests := []struct {
+		scenario            string
+		pointer             string
+		repoErr             error
+		expectedErr         error
+		expectedVal         int64
+		isIncrementExpected bool
+	}{
+		{
+			scenario:            "Should return error if repository returns error",
+			pointer:             "/stats/failed",
+			repoErr:             fmt.Errorf("%w: value at '/stats/failed' is not a number", session.ErrInvalid),
+			expectedErr:         session.ErrInvalid,
+			isIncrementExpected: true,
+		},
+		{
+			scenario:    "Should reject an invalid pointer without incrementing",
+			pointer:     "stats",
+			expectedErr: session.ErrInvalid,
+		},
+		{
+			scenario:            "Should return the value the counter is left at",
+			pointer:             "/stats/failed",
+			expectedVal:         3,
+			isIncrementExpected: true,
+		},
+	}
+
+	for _, test := range tests {
+
+		repo := &TestIncrementRepository{err: test.repoErr, value: 3}
+		handler := NewIncrementSessionCounterHandler(repo, logger)
+		val, err := handler.Handle(context.Background(), IncrementSessionCounter{Key: "key", Pointer: test.pointer, Delta: -1})
+
+		if test.expectedErr != nil {
+			assert.ErrorIs(t, err, test.expectedErr, test.scenario)
+		} else {
+			assert.Nil(t, err, test.scenario)
+		}
+
+		assert.Equal(t, test.expectedVal, val, test.scenario)
+		assert.Equal(t, test.isIncrementExpected, repo.invoked, test.scenario)
+		if test.isIncrementExpected {
+			assert.Equal(t, session.Pointer{"stats", "failed"}, repo.path, test.scenario)
+			assert.Equal(t, int64(-1), repo.delta, test.scenario)
+		}
+	}
+}
+
+func TestIncrementSessionCounterHandlerShouldPanicIfNilRepo(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Handle method did not panic")
+		}
+	}()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+	handler := NewIncrementSessionCounterHandler(nil, logger)
+	handler.Handle(context.Background(), IncrementSessionCounter{})
+}
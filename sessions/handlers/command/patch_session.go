@@ -0,0 +1,54 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jruben-rg/go-commons-handler/decorator"
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+)
+
+type PatchSession struct {
+	Key   string
+	Patch session.Patch
+	// IfVersion only patches the session while it is at that version when it
+	// is not zero.
+	IfVersion int64
+}
+
+// PatchSessionHandler returns the version the session is written at.
+type PatchSessionHandler decorator.QueryHandler[PatchSession, int64]
+
+type patchSessionHandler struct {
+	sessionRepo session.Repository
+}
+
+func NewPatchSessionHandler(
+	sessionRepo session.Repository,
+	logger *logrus.Entry,
+) PatchSessionHandler {
+
+	if sessionRepo == nil {
+		panic("nil sessionRepo")
+	}
+
+	return decorator.WithQueryDecorators[PatchSession, int64](
+		patchSessionHandler{sessionRepo: sessionRepo},
+		logger,
+	)
+}
+
+func (h patchSessionHandler) Handle(ctx context.Context, cmd PatchSession) (int64, error) {
+
+	if cmd.Patch == nil {
+		return 0, fmt.Errorf("%w: patch cannot be empty", session.ErrInvalid)
+	}
+
+	version, err := h.sessionRepo.Patch(ctx, cmd.Key, cmd.Patch, cmd.IfVersion)
+	if err != nil {
+		return 0, fmt.Errorf("error when trying to patch session %s: %w", cmd.Key, err)
+	}
+
+	return version, nil
+}
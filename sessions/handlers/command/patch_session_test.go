@@ -0,0 +1,94 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestPatchRepository struct {
+	session.Repository
+	err       error
+	invoked   bool
+	patch     session.Patch
+	ifVersion int64
+}
+
+func (tpr *TestPatchRepository) Patch(ctx context.Context, id string, patch session.Patch, ifVersion int64) (int64, error) {
+	tpr.invoked = true
+	tpr.patch = patch
+	tpr.ifVersion = ifVersion
+	if tpr.err != nil {
+		return 0, tpr.err
+	}
+	return ifVersion + 1, nil
+}
+
+func TestPatchSessionHandlerShouldInvokePatchMethod(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+
+	tests := []struct {
+		scenario        string
+		patch           session.Patch
+		repoErr         error
+		isErrorExpected bool
+		isPatchExpected bool
+	}{
+		{
+			scenario:        "Should return error if repository returns error",
+			patch:           session.MergePatch{"field": "value"},
+			repoErr:         fmt.Errorf("Repository error"),
+			isErrorExpected: true,
+			isPatchExpected: true,
+		},
+		{
+			scenario:        "Should reject a missing patch without patching",
+			isErrorExpected: true,
+		},
+		{
+			scenario:        "Should patch the session",
+			patch:           session.JSONPatch{{Op: "remove", Path: "/field"}},
+			isPatchExpected: true,
+		},
+	}
+
+	for _, test := range tests {
+
+		repo := &TestPatchRepository{err: test.repoErr}
+		handler := NewPatchSessionHandler(repo, logger)
+		version, err := handler.Handle(context.Background(), PatchSession{Key: "key", Patch: test.patch, IfVersion: 2})
+
+		if test.isErrorExpected {
+			assert.NotNil(t, err, test.scenario)
+		} else {
+			assert.Nil(t, err, test.scenario)
+			assert.Equal(t, int64(3), version, "Expect the version written to be returned")
+		}
+
+		assert.Equal(t, test.isPatchExpected, repo.invoked, test.scenario)
+		if test.isPatchExpected {
+			assert.Equal(t, test.patch, repo.patch, test.scenario)
+			assert.Equal(t, int64(2), repo.ifVersion, test.scenario)
+		}
+	}
+}
+
+func TestPatchSessionHandlerShouldPanicIfNilRepo(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Handle method did not panic")
+		}
+	}()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+	handler := NewPatchSessionHandler(nil, logger)
+	handler.Handle(context.Background(), PatchSession{})
+}
@@ -0,0 +1,78 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jruben-rg/go-commons-handler/decorator"
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+)
+
+type RotateSession struct {
+	Key string
+	// Grace keeps the session under its old key for that long, so requests
+	// already on their way with it still succeed. The old key stops working
+	// right away when it is zero.
+	Grace time.Duration
+}
+
+// RotateSessionHandler returns the ID the session is moved to, as callers have
+// no other way to learn it.
+type RotateSessionHandler decorator.QueryHandler[RotateSession, string]
+
+type rotateSessionHandler struct {
+	sessionRepo session.Repository
+	idFormat    session.IDFormat
+	maxGrace    time.Duration
+}
+
+// NewRotateSessionHandler returns a handler that moves sessions to IDs it
+// generates in idFormat, allowing grace periods of up to maxGrace, or of any
+// length when it is zero.
+func NewRotateSessionHandler(
+	sessionRepo session.Repository,
+	idFormat session.IDFormat,
+	maxGrace time.Duration,
+	logger *logrus.Entry,
+) RotateSessionHandler {
+
+	if sessionRepo == nil {
+		panic("nil sessionRepo")
+	}
+
+	return decorator.WithQueryDecorators[RotateSession, string](
+		rotateSessionHandler{sessionRepo: sessionRepo, idFormat: idFormat, maxGrace: maxGrace},
+		logger,
+	)
+}
+
+func (h rotateSessionHandler) Handle(ctx context.Context, cmd RotateSession) (string, error) {
+
+	if cmd.Grace < 0 {
+		return "", fmt.Errorf("%w: grace period cannot be negative", session.ErrInvalid)
+	}
+	if h.maxGrace > 0 && cmd.Grace > h.maxGrace {
+		return "", fmt.Errorf("%w: grace period %s is over the maximum of %s", session.ErrInvalid, cmd.Grace, h.maxGrace)
+	}
+
+	for i := 0; i < maxIDAttempts; i++ {
+		id, err := h.idFormat.NewID()
+		if err != nil {
+			return "", err
+		}
+
+		_, err = h.sessionRepo.Rotate(ctx, cmd.Key, id, cmd.Grace)
+		if errors.Is(err, session.ErrPreconditionFailed) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("error when trying to rotate session %s: %w", cmd.Key, err)
+		}
+		return id, nil
+	}
+
+	return "", fmt.Errorf("%w: gave up rotating session after %d ids in use", session.ErrConflict, maxIDAttempts)
+}
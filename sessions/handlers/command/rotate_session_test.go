@@ -0,0 +1,111 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRotateRepository fails the rotations with the errors in errs, in order.
+type TestRotateRepository struct {
+	session.Repository
+	errs   []error
+	newIDs []string
+	id     string
+	grace  time.Duration
+}
+
+func (trr *TestRotateRepository) Rotate(ctx context.Context, id, newID string, grace time.Duration) (*session.Session, error) {
+	trr.newIDs = append(trr.newIDs, newID)
+	trr.id = id
+	trr.grace = grace
+	if len(trr.errs) < len(trr.newIDs) {
+		return &session.Session{ID: newID}, nil
+	}
+	return nil, trr.errs[len(trr.newIDs)-1]
+}
+
+func TestRotateSessionHandlerShouldInvokeRotateMethod(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+	inUse := fmt.Errorf("wrapped: %w", session.ErrPreconditionFailed)
+
+	tests := []struct {
+		scenario       string
+		grace          time.Duration
+		errs           []error
+		expectedErr    error
+		expectedNewIDs int
+	}{
+		{
+			scenario:       "Should rotate the session to a new id",
+			grace:          5 * time.Second,
+			expectedNewIDs: 1,
+		},
+		{
+			scenario:       "Should try another id when the first one is in use",
+			errs:           []error{inUse},
+			expectedNewIDs: 2,
+		},
+		{
+			scenario:       "Should give up when every id is in use",
+			errs:           []error{inUse, inUse, inUse},
+			expectedErr:    session.ErrConflict,
+			expectedNewIDs: maxIDAttempts,
+		},
+		{
+			scenario:       "Should return error if session does not exist",
+			errs:           []error{session.ErrNotFound},
+			expectedErr:    session.ErrNotFound,
+			expectedNewIDs: 1,
+		},
+		{
+			scenario:    "Should reject a grace period over the maximum without rotating",
+			grace:       time.Minute + time.Second,
+			expectedErr: session.ErrInvalid,
+		},
+		{
+			scenario:    "Should reject a negative grace period without rotating",
+			grace:       -time.Second,
+			expectedErr: session.ErrInvalid,
+		},
+	}
+
+	for _, test := range tests {
+
+		repo := &TestRotateRepository{errs: test.errs}
+		handler := NewRotateSessionHandler(repo, session.IDFormatRandom, time.Minute, logger)
+		id, err := handler.Handle(context.Background(), RotateSession{Key: "key", Grace: test.grace})
+
+		if test.expectedErr != nil {
+			assert.ErrorIs(t, err, test.expectedErr, test.scenario)
+			assert.Empty(t, id, test.scenario)
+		} else {
+			assert.Nil(t, err, test.scenario)
+			assert.Equal(t, repo.newIDs[len(repo.newIDs)-1], id, test.scenario)
+			assert.Equal(t, "key", repo.id, test.scenario)
+			assert.Equal(t, test.grace, repo.grace, test.scenario)
+		}
+		assert.Len(t, repo.newIDs, test.expectedNewIDs, test.scenario)
+	}
+}
+
+func TestRotateSessionHandlerShouldPanicIfNilRepo(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Handle method did not panic")
+		}
+	}()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+	handler := NewRotateSessionHandler(nil, session.IDFormatRandom, 0, logger)
+	handler.Handle(context.Background(), RotateSession{})
+}
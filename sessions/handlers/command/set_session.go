@@ -2,29 +2,49 @@ package command
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/jruben-rg/go-commons-handler/decorator"
 	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
 	"github.com/sirupsen/logrus"
 )
 
-type SessionValue map[string]interface{}
-
 type SetSession struct {
 	Key   string
-	Value SessionValue
+	Value session.Data
+	// TTL keeps the session for that long instead of the default expiry when
+	// it is not zero, as allowed by the configured policy.
+	TTL time.Duration
+	// KeepTTL leaves an existing session the time it has left instead of
+	// restarting its expiry.
+	KeepTTL bool
+	// NotBefore keeps the session from being valid until then when it is not
+	// zero.
+	NotBefore time.Time
+	// IfVersion only writes the session while it is at that version when it is
+	// not zero.
+	IfVersion int64
+	// CreateOnly only writes the session when it does not exist yet.
+	CreateOnly bool
+	// UpdateOnly only writes the session when it exists already.
+	UpdateOnly bool
+	// Subject is who the session belongs to, none when empty. An existing
+	// session keeps the subject it belongs to when nil.
+	Subject *string
 }
 
-type SetSessionHandler decorator.CommandHandler[SetSession]
+// SetSessionHandler returns the version the session is written at.
+type SetSessionHandler decorator.QueryHandler[SetSession, int64]
 
 type setSessionHandler struct {
 	sessionRepo session.Repository
+	ttlPolicy   session.TTLPolicy
 }
 
 func NewSetSessionHandler(
 	sessionRepo session.Repository,
+	ttlPolicy session.TTLPolicy,
 	logger *logrus.Entry,
 ) SetSessionHandler {
 
@@ -32,26 +52,35 @@ func NewSetSessionHandler(
 		panic("nil sessionRepo")
 	}
 
-	return decorator.WithCommandDecorator[SetSession](
-		setSessionHandler{sessionRepo: sessionRepo},
+	return decorator.WithQueryDecorators[SetSession, int64](
+		setSessionHandler{sessionRepo: sessionRepo, ttlPolicy: ttlPolicy},
 		logger,
 	)
 }
 
-func (h setSessionHandler) Handle(ctx context.Context, cmd SetSession) error {
+func (h setSessionHandler) Handle(ctx context.Context, cmd SetSession) (int64, error) {
 
-	err := h.sessionRepo.Set(ctx, cmd.Key, cmd.Value)
+	ttl, err := h.ttlPolicy.Apply(cmd.TTL)
 	if err != nil {
-		return fmt.Errorf("error when trying to set session %s", cmd.Key)
+		return 0, err
 	}
 
-	return nil
-}
-
-func (s SessionValue) MarshalBinary() ([]byte, error) {
-	return json.Marshal(s)
-}
+	s := &session.Session{ID: cmd.Key, Data: cmd.Value}
+	if cmd.Subject != nil {
+		s.Subject = *cmd.Subject
+	}
+	err = h.sessionRepo.Set(ctx, s, session.SetOptions{
+		TTL:         ttl,
+		KeepTTL:     cmd.KeepTTL,
+		NotBefore:   cmd.NotBefore,
+		IfVersion:   cmd.IfVersion,
+		CreateOnly:  cmd.CreateOnly,
+		UpdateOnly:  cmd.UpdateOnly,
+		KeepSubject: cmd.Subject == nil,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error when trying to set session %s: %w", cmd.Key, err)
+	}
 
-func (s SessionValue) UnmarshalBinary(data []byte) error {
-	return json.Unmarshal(data, &s)
+	return s.Version, nil
 }
@@ -0,0 +1,56 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jruben-rg/go-commons-handler/decorator"
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+)
+
+type SetSessionFields struct {
+	Key    string
+	Values session.Data
+	// IfVersion only writes the fields while the session is at that version
+	// when it is not zero.
+	IfVersion int64
+}
+
+// SetSessionFieldsHandler returns the version the session is written at.
+type SetSessionFieldsHandler decorator.QueryHandler[SetSessionFields, int64]
+
+type setSessionFieldsHandler struct {
+	fieldRepo session.FieldRepository
+}
+
+func NewSetSessionFieldsHandler(
+	sessionRepo session.Repository,
+	logger *logrus.Entry,
+) SetSessionFieldsHandler {
+
+	if sessionRepo == nil {
+		panic("nil sessionRepo")
+	}
+
+	fieldRepo, _ := sessionRepo.(session.FieldRepository)
+
+	return decorator.WithQueryDecorators[SetSessionFields, int64](
+		setSessionFieldsHandler{fieldRepo: fieldRepo},
+		logger,
+	)
+}
+
+func (h setSessionFieldsHandler) Handle(ctx context.Context, cmd SetSessionFields) (int64, error) {
+
+	if h.fieldRepo == nil {
+		return 0, session.ErrNotSupported
+	}
+
+	version, err := h.fieldRepo.SetFields(ctx, cmd.Key, cmd.Values, cmd.IfVersion)
+	if err != nil {
+		return 0, fmt.Errorf("error when trying to set fields of session %s: %w", cmd.Key, err)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,99 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestSetFieldsRepository struct {
+	session.Repository
+	session.FieldRepository
+	err       error
+	invoked   bool
+	ifVersion int64
+}
+
+func (tsfr *TestSetFieldsRepository) SetFields(ctx context.Context, id string, values session.Data, ifVersion int64) (int64, error) {
+	tsfr.invoked = true
+	tsfr.ifVersion = ifVersion
+	if tsfr.err != nil {
+		return 0, tsfr.err
+	}
+	return ifVersion + 1, nil
+}
+
+func TestSetSessionFieldsHandlerShouldInvokeSetFieldsMethod(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+
+	tests := []struct {
+		scenario        string
+		expectedErr     error
+		isErrorExpected bool
+	}{
+		{
+			scenario:        "Should return error if repository returns error",
+			expectedErr:     fmt.Errorf("Repository error"),
+			isErrorExpected: true,
+		},
+		{
+			scenario:        "Should return not found error if repository does not hold the session",
+			expectedErr:     session.ErrNotFound,
+			isErrorExpected: true,
+		},
+		{
+			scenario:        "Should not return error if repository does not return error",
+			expectedErr:     nil,
+			isErrorExpected: false,
+		},
+	}
+
+	for _, test := range tests {
+
+		repo := &TestSetFieldsRepository{err: test.expectedErr}
+		handler := NewSetSessionFieldsHandler(repo, logger)
+		version, err := handler.Handle(context.Background(), SetSessionFields{IfVersion: 3})
+
+		if test.isErrorExpected {
+			assert.ErrorIs(t, err, test.expectedErr, test.scenario)
+		} else {
+			assert.Nil(t, err, test.scenario)
+			assert.Equal(t, int64(4), version, "Expect the version written to be returned")
+		}
+
+		assert.True(t, repo.invoked == true, "SetFields method has been invoked")
+		assert.Equal(t, int64(3), repo.ifVersion, "Expect the expected version to be passed on")
+	}
+
+}
+
+func TestSetSessionFieldsHandlerShouldReturnNotSupportedIfRepositoryCannotSetFields(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+	handler := NewSetSessionFieldsHandler(&TestSetRepository{}, logger)
+	_, err := handler.Handle(context.Background(), SetSessionFields{})
+
+	assert.ErrorIs(t, err, session.ErrNotSupported, "Expect not supported error")
+}
+
+func TestSetSessionFieldsHandlerShouldPanicIfNilRepo(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Handle method did not panic")
+		}
+	}()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+	handler := NewSetSessionFieldsHandler(nil, logger)
+	handler.Handle(context.Background(), SetSessionFields{})
+
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
 	"github.com/sirupsen/logrus"
@@ -14,10 +15,15 @@ type TestSetRepository struct {
 	session.Repository
 	err     error
 	invoked bool
+	opts    session.SetOptions
 }
 
-func (tsr *TestSetRepository) Set(ctx context.Context, key string, value interface{}) error {
+func (tsr *TestSetRepository) Set(ctx context.Context, s *session.Session, opts session.SetOptions) error {
 	tsr.invoked = true
+	tsr.opts = opts
+	if tsr.err == nil {
+		s.Version = 4
+	}
 	return tsr.err
 }
 
@@ -48,13 +54,14 @@ func TestSetSessionHandlerShouldInvokeSetMethod(t *testing.T) {
 	for _, test := range tests {
 
 		repo := &TestSetRepository{err: test.expectedErr}
-		handler := NewSetSessionHandler(repo, logger)
-		err := handler.Handle(context.Background(), SetSession{})
+		handler := NewSetSessionHandler(repo, session.TTLPolicy{}, logger)
+		version, err := handler.Handle(context.Background(), SetSession{})
 
 		if test.isErrorExpected {
 			assert.NotNil(t, err, "An error is expected from the set repository")
 		} else {
 			assert.Nil(t, err, "No error is expected from the set repository")
+			assert.Equal(t, int64(4), version, "Expect the version written to be returned")
 		}
 
 		assert.True(t, repo.invoked == true, "Set method has been invoked")
@@ -62,6 +69,105 @@ func TestSetSessionHandlerShouldInvokeSetMethod(t *testing.T) {
 
 }
 
+func TestSetSessionHandlerShouldApplyTTLPolicy(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+
+	tests := []struct {
+		scenario        string
+		policy          session.TTLPolicy
+		ttl             time.Duration
+		expectedTTL     time.Duration
+		isErrorExpected bool
+	}{
+		{
+			scenario:    "Should keep the default expiry if no ttl is given",
+			policy:      session.TTLPolicy{Max: time.Hour},
+			expectedTTL: 0,
+		},
+		{
+			scenario:    "Should pass the ttl if it is within the maximum",
+			policy:      session.TTLPolicy{Max: time.Hour},
+			ttl:         time.Minute,
+			expectedTTL: time.Minute,
+		},
+		{
+			scenario:    "Should pass any ttl if there is no maximum",
+			ttl:         24 * time.Hour,
+			expectedTTL: 24 * time.Hour,
+		},
+		{
+			scenario:        "Should reject a ttl over the maximum",
+			policy:          session.TTLPolicy{Max: time.Hour},
+			ttl:             2 * time.Hour,
+			isErrorExpected: true,
+		},
+		{
+			scenario:    "Should clamp a ttl over the maximum if configured to",
+			policy:      session.TTLPolicy{Max: time.Hour, Clamp: true},
+			ttl:         2 * time.Hour,
+			expectedTTL: time.Hour,
+		},
+		{
+			scenario:        "Should reject a negative ttl",
+			ttl:             -time.Second,
+			isErrorExpected: true,
+		},
+	}
+
+	for _, test := range tests {
+
+		repo := &TestSetRepository{}
+		handler := NewSetSessionHandler(repo, test.policy, logger)
+		_, err := handler.Handle(context.Background(), SetSession{Key: "key", TTL: test.ttl})
+
+		if test.isErrorExpected {
+			assert.ErrorIs(t, err, session.ErrInvalid, test.scenario)
+			assert.False(t, repo.invoked, test.scenario)
+			continue
+		}
+
+		assert.Nil(t, err, test.scenario)
+		assert.Equal(t, test.expectedTTL, repo.opts.TTL, test.scenario)
+	}
+}
+
+func TestSetSessionHandlerShouldPassSetOptions(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+
+	repo := &TestSetRepository{}
+	handler := NewSetSessionHandler(repo, session.TTLPolicy{}, logger)
+	notBefore := time.Now().Add(time.Hour)
+	_, err := handler.Handle(context.Background(), SetSession{Key: "key", KeepTTL: true, NotBefore: notBefore, IfVersion: 3, UpdateOnly: true})
+
+	assert.Nil(t, err, "No error is expected from the set repository")
+	assert.True(t, repo.opts.KeepTTL, "Expect the repository to be asked to keep the ttl")
+	assert.Equal(t, notBefore, repo.opts.NotBefore, "Expect the repository to be given when the session becomes valid")
+	assert.Equal(t, int64(3), repo.opts.IfVersion, "Expect the repository to be given the expected version")
+	assert.True(t, repo.opts.UpdateOnly, "Expect the repository to be asked to only update the session")
+}
+
+func TestSetSessionHandlerShouldKeepSubjectUnlessGiven(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+
+	repo := &TestSetRepository{}
+	handler := NewSetSessionHandler(repo, session.TTLPolicy{}, logger)
+
+	_, err := handler.Handle(context.Background(), SetSession{Key: "key"})
+	assert.Nil(t, err, "No error is expected from the set repository")
+	assert.True(t, repo.opts.KeepSubject, "Expect the repository to be asked to keep the subject when none is given")
+
+	noOne := ""
+	_, err = handler.Handle(context.Background(), SetSession{Key: "key", Subject: &noOne})
+	assert.Nil(t, err, "No error is expected from the set repository")
+	assert.False(t, repo.opts.KeepSubject, "Expect the repository to be asked to clear the subject when it is empty")
+}
+
 func TestSetSessionHandlerShouldPanicIfNilRepo(t *testing.T) {
 	t.Parallel()
 
@@ -72,7 +178,7 @@ func TestSetSessionHandlerShouldPanicIfNilRepo(t *testing.T) {
 	}()
 
 	logger := logrus.NewEntry(logrus.StandardLogger())
-	handler := NewSetSessionHandler(nil, logger)
+	handler := NewSetSessionHandler(nil, session.TTLPolicy{}, logger)
 	handler.Handle(context.Background(), SetSession{})
 
 }
@@ -0,0 +1,60 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jruben-rg/go-commons-handler/decorator"
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+)
+
+type SetSessionValue struct {
+	Key string
+	// Pointer is the JSON Pointer (RFC 6901) to the value within the session.
+	Pointer string
+	Value   interface{}
+	// IfVersion only sets the value while the session is at that version when
+	// it is not zero.
+	IfVersion int64
+}
+
+// SetSessionValueHandler returns the version the session is written at.
+type SetSessionValueHandler decorator.QueryHandler[SetSessionValue, int64]
+
+type setSessionValueHandler struct {
+	sessionRepo session.Repository
+}
+
+// NewSetSessionValueHandler returns a handler that sets a single value of a
+// session, atomically, keeping the rest of it.
+func NewSetSessionValueHandler(
+	sessionRepo session.Repository,
+	logger *logrus.Entry,
+) SetSessionValueHandler {
+
+	if sessionRepo == nil {
+		panic("nil sessionRepo")
+	}
+
+	return decorator.WithQueryDecorators[SetSessionValue, int64](
+		setSessionValueHandler{sessionRepo: sessionRepo},
+		logger,
+	)
+}
+
+func (h setSessionValueHandler) Handle(ctx context.Context, cmd SetSessionValue) (int64, error) {
+
+	path, err := session.ParsePointer(cmd.Pointer)
+	if err != nil {
+		return 0, err
+	}
+
+	patch := session.SetValue{Path: path, Value: cmd.Value}
+	version, err := h.sessionRepo.Patch(ctx, cmd.Key, patch, cmd.IfVersion)
+	if err != nil {
+		return 0, fmt.Errorf("error when trying to set value %s of session %s: %w", cmd.Pointer, cmd.Key, err)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,58 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jruben-rg/go-commons-handler/decorator"
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+)
+
+type TouchSession struct {
+	Key string
+	// TTL keeps the session for that long from now when it is not zero, as
+	// allowed by the configured policy, instead of for its own TTL.
+	TTL time.Duration
+}
+
+// TouchSessionHandler returns when the session expires after being touched,
+// zero when it never does.
+type TouchSessionHandler decorator.QueryHandler[TouchSession, time.Time]
+
+type touchSessionHandler struct {
+	sessionRepo session.Repository
+	ttlPolicy   session.TTLPolicy
+}
+
+func NewTouchSessionHandler(
+	sessionRepo session.Repository,
+	ttlPolicy session.TTLPolicy,
+	logger *logrus.Entry,
+) TouchSessionHandler {
+
+	if sessionRepo == nil {
+		panic("nil sessionRepo")
+	}
+
+	return decorator.WithQueryDecorators[TouchSession, time.Time](
+		touchSessionHandler{sessionRepo: sessionRepo, ttlPolicy: ttlPolicy},
+		logger,
+	)
+}
+
+func (h touchSessionHandler) Handle(ctx context.Context, cmd TouchSession) (time.Time, error) {
+
+	ttl, err := h.ttlPolicy.Apply(cmd.TTL)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	expiresAt, err := h.sessionRepo.Touch(ctx, cmd.Key, ttl)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error when trying to touch session %s: %w", cmd.Key, err)
+	}
+
+	return expiresAt, nil
+}
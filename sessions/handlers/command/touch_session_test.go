@@ -0,0 +1,108 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestTouchRepository struct {
+	session.Repository
+	err       error
+	invoked   bool
+	ttl       time.Duration
+	expiresAt time.Time
+}
+
+func (ttr *TestTouchRepository) Touch(ctx context.Context, id string, ttl time.Duration) (time.Time, error) {
+	ttr.invoked = true
+	ttr.ttl = ttl
+	return ttr.expiresAt, ttr.err
+}
+
+func TestTouchSessionHandlerShouldInvokeTouchMethod(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+	expiresAt := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		scenario          string
+		policy            session.TTLPolicy
+		ttl               time.Duration
+		repoErr           error
+		repoExpiresAt     time.Time
+		expectedTTL       time.Duration
+		expectedExpiresAt time.Time
+		isErrorExpected   bool
+		isTouchExpected   bool
+	}{
+		{
+			scenario:        "Should return error if repository returns error",
+			repoErr:         fmt.Errorf("Repository error"),
+			isErrorExpected: true,
+			isTouchExpected: true,
+		},
+		{
+			scenario:          "Should touch for the session own ttl when none is given",
+			repoExpiresAt:     expiresAt,
+			expectedExpiresAt: expiresAt,
+			isTouchExpected:   true,
+		},
+		{
+			scenario:        "Should return no expiry for sessions that never expire",
+			isTouchExpected: true,
+		},
+		{
+			scenario:        "Should clamp a ttl over the maximum",
+			policy:          session.TTLPolicy{Max: time.Minute, Clamp: true},
+			ttl:             time.Hour,
+			expectedTTL:     time.Minute,
+			isTouchExpected: true,
+		},
+		{
+			scenario:        "Should reject a ttl over the maximum without touching",
+			policy:          session.TTLPolicy{Max: time.Minute},
+			ttl:             time.Hour,
+			isErrorExpected: true,
+		},
+	}
+
+	for _, test := range tests {
+
+		repo := &TestTouchRepository{err: test.repoErr, expiresAt: test.repoExpiresAt}
+		handler := NewTouchSessionHandler(repo, test.policy, logger)
+		expiresAt, err := handler.Handle(context.Background(), TouchSession{Key: "key", TTL: test.ttl})
+
+		if test.isErrorExpected {
+			assert.NotNil(t, err, test.scenario)
+		} else {
+			assert.Nil(t, err, test.scenario)
+		}
+
+		assert.Equal(t, test.isTouchExpected, repo.invoked, test.scenario)
+		assert.Equal(t, test.expectedTTL, repo.ttl, test.scenario)
+		assert.Equal(t, test.expectedExpiresAt, expiresAt, test.scenario)
+	}
+
+}
+
+func TestTouchSessionHandlerShouldPanicIfNilRepo(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Handle method did not panic")
+		}
+	}()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+	handler := NewTouchSessionHandler(nil, session.TTLPolicy{}, logger)
+	handler.Handle(context.Background(), TouchSession{Key: ""})
+
+}
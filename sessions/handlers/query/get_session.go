@@ -2,6 +2,7 @@ package query
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/jruben-rg/go-commons-handler/decorator"
 	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
@@ -12,14 +13,19 @@ type GetSession struct {
 	Key string
 }
 
-type GetSessionHandler decorator.QueryHandler[GetSession, interface{}]
+type GetSessionHandler decorator.QueryHandler[GetSession, *session.Session]
 
 type getSessionHandler struct {
 	sessionRepo session.Repository
+	lifetime    session.LifetimePolicy
 }
 
+// NewGetSessionHandler returns a handler that, when the lifetime policy sets an
+// idle timeout, restarts the expiry of every session it reads, but for those
+// rotated, which are only kept until their grace period ends.
 func NewGetSessionHandler(
 	sessionRepo session.Repository,
+	lifetime session.LifetimePolicy,
 	logger *logrus.Entry,
 ) GetSessionHandler {
 
@@ -27,12 +33,23 @@ func NewGetSessionHandler(
 		panic("nil SessionRepo")
 	}
 
-	return decorator.WithQueryDecorators[GetSession, interface{}](
-		getSessionHandler{sessionRepo: sessionRepo},
+	return decorator.WithQueryDecorators[GetSession, *session.Session](
+		getSessionHandler{sessionRepo: sessionRepo, lifetime: lifetime},
 		logger,
 	)
 }
 
-func (h getSessionHandler) Handle(ctx context.Context, getSession GetSession) (interface{}, error) {
-	return h.sessionRepo.Get(ctx, getSession.Key)
+func (h getSessionHandler) Handle(ctx context.Context, getSession GetSession) (*session.Session, error) {
+
+	s, err := h.sessionRepo.Get(ctx, getSession.Key)
+	if err != nil || !h.lifetime.Idle || s.RotatedTo != "" {
+		return s, err
+	}
+
+	expiresAt, err := h.sessionRepo.Touch(ctx, getSession.Key, s.TTL)
+	if err != nil {
+		return nil, fmt.Errorf("error when trying to touch session %s: %w", getSession.Key, err)
+	}
+	s.ExpiresAt = expiresAt
+	return s, nil
 }
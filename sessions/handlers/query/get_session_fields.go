@@ -0,0 +1,46 @@
+package query
+
+import (
+	"context"
+
+	"github.com/jruben-rg/go-commons-handler/decorator"
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+)
+
+type GetSessionFields struct {
+	Key    string
+	Fields []string
+}
+
+type GetSessionFieldsHandler decorator.QueryHandler[GetSessionFields, session.Data]
+
+type getSessionFieldsHandler struct {
+	fieldRepo session.FieldRepository
+}
+
+func NewGetSessionFieldsHandler(
+	sessionRepo session.Repository,
+	logger *logrus.Entry,
+) GetSessionFieldsHandler {
+
+	if sessionRepo == nil {
+		panic("nil SessionRepo")
+	}
+
+	fieldRepo, _ := sessionRepo.(session.FieldRepository)
+
+	return decorator.WithQueryDecorators[GetSessionFields, session.Data](
+		getSessionFieldsHandler{fieldRepo: fieldRepo},
+		logger,
+	)
+}
+
+func (h getSessionFieldsHandler) Handle(ctx context.Context, getSessionFields GetSessionFields) (session.Data, error) {
+
+	if h.fieldRepo == nil {
+		return nil, session.ErrNotSupported
+	}
+
+	return h.fieldRepo.GetFields(ctx, getSessionFields.Key, getSessionFields.Fields)
+}
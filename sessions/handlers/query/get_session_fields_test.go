@@ -0,0 +1,92 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestGetFieldsRepository struct {
+	session.Repository
+	session.FieldRepository
+	err     error
+	value   session.Data
+	invoked bool
+}
+
+func (tgfr *TestGetFieldsRepository) GetFields(ctx context.Context, id string, fields []string) (session.Data, error) {
+	tgfr.invoked = true
+	return tgfr.value, tgfr.err
+}
+
+func TestGetSessionFieldsHandlerShouldInvokeGetFieldsMethod(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+
+	tests := []struct {
+		scenario        string
+		expectedErr     error
+		expectedVal     session.Data
+		isErrorExpected bool
+	}{
+		{
+			scenario:        "Should return error if repository returns error",
+			expectedErr:     fmt.Errorf("Repository error"),
+			expectedVal:     nil,
+			isErrorExpected: true,
+		},
+		{
+			scenario:        "Should not return error if repository does not return error",
+			expectedErr:     nil,
+			expectedVal:     session.Data{"expected": "value"},
+			isErrorExpected: false,
+		},
+	}
+
+	for _, test := range tests {
+
+		repo := &TestGetFieldsRepository{value: test.expectedVal, err: test.expectedErr}
+		handler := NewGetSessionFieldsHandler(repo, logger)
+		val, err := handler.Handle(context.Background(), GetSessionFields{Fields: []string{"expected"}})
+
+		if test.isErrorExpected {
+			assert.NotNil(t, err, "An error is expected from the GetFields repository")
+		} else {
+			assert.Nil(t, err, "No error is expected from the GetFields repository")
+		}
+
+		assert.Equal(t, test.expectedVal, val, "Value from GetFields method matches expected result")
+		assert.True(t, repo.invoked == true, "GetFields method has been invoked")
+	}
+
+}
+
+func TestGetSessionFieldsHandlerShouldReturnNotSupportedIfRepositoryCannotGetFields(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+	handler := NewGetSessionFieldsHandler(&TestGetRepository{}, logger)
+	_, err := handler.Handle(context.Background(), GetSessionFields{})
+
+	assert.ErrorIs(t, err, session.ErrNotSupported, "Expect not supported error")
+}
+
+func TestGetSessionFieldsHandlerShouldPanicIfNilRepo(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Handle method did not panic")
+		}
+	}()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+	handler := NewGetSessionFieldsHandler(nil, logger)
+	handler.Handle(context.Background(), GetSessionFields{})
+
+}
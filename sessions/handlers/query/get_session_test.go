@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
 	"github.com/sirupsen/logrus"
@@ -13,15 +14,23 @@ import (
 type TestGetRepository struct {
 	session.Repository
 	err     error
-	value   interface{}
+	value   *session.Session
 	invoked bool
+	touched bool
+	ttl     time.Duration
 }
 
-func (tgr *TestGetRepository) Get(ctx context.Context, key string) (interface{}, error) {
+func (tgr *TestGetRepository) Get(ctx context.Context, id string) (*session.Session, error) {
 	tgr.invoked = true
 	return tgr.value, tgr.err
 }
 
+func (tgr *TestGetRepository) Touch(ctx context.Context, id string, ttl time.Duration) (time.Time, error) {
+	tgr.touched = true
+	tgr.ttl = ttl
+	return time.UnixMilli(2_000_000), nil
+}
+
 type TestGetSession getSessionHandler
 
 func TestGetSessionHandlerShouldInvokeGetMethod(t *testing.T) {
@@ -32,7 +41,7 @@ func TestGetSessionHandlerShouldInvokeGetMethod(t *testing.T) {
 	tests := []struct {
 		scenario        string
 		expectedErr     error
-		expectedVal     interface{}
+		expectedVal     *session.Session
 		isErrorExpected bool
 	}{
 		{
@@ -44,7 +53,7 @@ func TestGetSessionHandlerShouldInvokeGetMethod(t *testing.T) {
 		{
 			scenario:        "Should not return error if repository does not return error",
 			expectedErr:     nil,
-			expectedVal:     `{"expected":"value"}`,
+			expectedVal:     &session.Session{ID: "key", Data: session.Data{"expected": "value"}},
 			isErrorExpected: false,
 		},
 	}
@@ -52,7 +61,7 @@ func TestGetSessionHandlerShouldInvokeGetMethod(t *testing.T) {
 	for _, test := range tests {
 
 		repo := &TestGetRepository{value: test.expectedVal, err: test.expectedErr}
-		handler := NewGetSessionHandler(repo, logger)
+		handler := NewGetSessionHandler(repo, session.LifetimePolicy{}, logger)
 		val, err := handler.Handle(context.Background(), GetSession{})
 
 		if test.isErrorExpected {
@@ -63,10 +72,42 @@ func TestGetSessionHandlerShouldInvokeGetMethod(t *testing.T) {
 
 		assert.True(t, val == test.expectedVal, "Value from Get method matches expected result")
 		assert.True(t, repo.invoked == true, "Get method has been invoked")
+		assert.False(t, repo.touched, "Touch method is not invoked without sliding expiration")
 	}
 
 }
 
+func TestGetSessionHandlerShouldTouchSessionWhenSliding(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+
+	repo := &TestGetRepository{value: &session.Session{ID: "key", TTL: time.Minute}}
+	handler := NewGetSessionHandler(repo, session.LifetimePolicy{Idle: true}, logger)
+	val, err := handler.Handle(context.Background(), GetSession{Key: "key"})
+
+	assert.Nil(t, err, "No error is expected when touching the session read")
+	assert.True(t, repo.touched, "Touch method has been invoked")
+	assert.Equal(t, time.Minute, repo.ttl, "Session is touched for its own ttl")
+	assert.Equal(t, time.UnixMilli(2_000_000), val.ExpiresAt, "Session reports the expiry set by the touch")
+
+	repo = &TestGetRepository{err: session.ErrNotFound}
+	handler = NewGetSessionHandler(repo, session.LifetimePolicy{Idle: true}, logger)
+	_, err = handler.Handle(context.Background(), GetSession{Key: "key"})
+
+	assert.ErrorIs(t, err, session.ErrNotFound, "Missing session is reported")
+	assert.False(t, repo.touched, "Missing session is not touched")
+
+	rotated := &session.Session{ID: "key", TTL: time.Minute, RotatedTo: "newKey", GraceEnds: time.UnixMilli(1_000_000)}
+	repo = &TestGetRepository{value: rotated}
+	handler = NewGetSessionHandler(repo, session.LifetimePolicy{Idle: true}, logger)
+	val, err = handler.Handle(context.Background(), GetSession{Key: "key"})
+
+	assert.Nil(t, err, "No error is expected when reading a rotated session")
+	assert.Equal(t, rotated, val, "Rotated session is returned as read")
+	assert.False(t, repo.touched, "Rotated session is not touched past its grace period")
+}
+
 func TestSetSessionHandlerShouldPanicIfNilRepo(t *testing.T) {
 	t.Parallel()
 
@@ -77,7 +118,7 @@ func TestSetSessionHandlerShouldPanicIfNilRepo(t *testing.T) {
 	}()
 
 	logger := logrus.NewEntry(logrus.StandardLogger())
-	handler := NewGetSessionHandler(nil, logger)
+	handler := NewGetSessionHandler(nil, session.LifetimePolicy{}, logger)
 	handler.Handle(context.Background(), GetSession{})
 
 }
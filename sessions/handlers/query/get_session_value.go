@@ -0,0 +1,54 @@
+package query
+
+import (
+	"context"
+
+	"github.com/jruben-rg/go-commons-handler/decorator"
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+)
+
+type GetSessionValue struct {
+	Key string
+	// Pointer is the JSON Pointer (RFC 6901) to the value within the session.
+	Pointer string
+}
+
+type GetSessionValueHandler decorator.QueryHandler[GetSessionValue, interface{}]
+
+type getSessionValueHandler struct {
+	getSession getSessionHandler
+}
+
+// NewGetSessionValueHandler returns a handler that reads a single value of a
+// session, restarting its expiry as reading the whole session does.
+func NewGetSessionValueHandler(
+	sessionRepo session.Repository,
+	lifetime session.LifetimePolicy,
+	logger *logrus.Entry,
+) GetSessionValueHandler {
+
+	if sessionRepo == nil {
+		panic("nil SessionRepo")
+	}
+
+	return decorator.WithQueryDecorators[GetSessionValue, interface{}](
+		getSessionValueHandler{getSession: getSessionHandler{sessionRepo: sessionRepo, lifetime: lifetime}},
+		logger,
+	)
+}
+
+func (h getSessionValueHandler) Handle(ctx context.Context, getSessionValue GetSessionValue) (interface{}, error) {
+
+	path, err := session.ParsePointer(getSessionValue.Pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := h.getSession.Handle(ctx, GetSession{Key: getSessionValue.Key})
+	if err != nil {
+		return nil, err
+	}
+
+	return path.Get(s.Data)
+}
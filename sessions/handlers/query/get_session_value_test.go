@@ -0,0 +1,63 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSessionValueHandlerShouldReadTheValue(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+
+	stored := &session.Session{ID: "key", Data: session.Data{
+		"props": map[string]interface{}{"cart": []interface{}{"apple", "pear"}},
+	}}
+
+	tests := []struct {
+		scenario       string
+		pointer        string
+		expectedVal    interface{}
+		expectedErr    error
+		isReadExpected bool
+	}{
+		{
+			scenario:       "Should read a nested value",
+			pointer:        "/props/cart/1",
+			expectedVal:    "pear",
+			isReadExpected: true,
+		},
+		{
+			scenario:       "Should not find a missing value",
+			pointer:        "/props/theme",
+			expectedErr:    session.ErrNotFound,
+			isReadExpected: true,
+		},
+		{
+			scenario:    "Should reject an invalid pointer without reading",
+			pointer:     "props",
+			expectedErr: session.ErrInvalid,
+		},
+	}
+
+	for _, test := range tests {
+
+		repo := &TestGetRepository{value: stored}
+		handler := NewGetSessionValueHandler(repo, session.LifetimePolicy{Idle: true}, logger)
+		val, err := handler.Handle(context.Background(), GetSessionValue{Key: "key", Pointer: test.pointer})
+
+		if test.expectedErr != nil {
+			assert.ErrorIs(t, err, test.expectedErr, test.scenario)
+		} else {
+			assert.Nil(t, err, test.scenario)
+		}
+
+		assert.Equal(t, test.expectedVal, val, test.scenario)
+		assert.Equal(t, test.isReadExpected, repo.invoked, test.scenario)
+		assert.Equal(t, test.isReadExpected, repo.touched, "Expect reading a value to touch the session when sliding")
+	}
+}
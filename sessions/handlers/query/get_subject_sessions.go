@@ -0,0 +1,46 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jruben-rg/go-commons-handler/decorator"
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+)
+
+type GetSubjectSessions struct {
+	Subject string
+}
+
+type GetSubjectSessionsHandler decorator.QueryHandler[GetSubjectSessions, []*session.Session]
+
+type getSubjectSessionsHandler struct {
+	sessionRepo session.Repository
+}
+
+// NewGetSubjectSessionsHandler returns a handler listing the sessions of a
+// subject. Unlike reading a session, listing it never restarts its expiry.
+func NewGetSubjectSessionsHandler(
+	sessionRepo session.Repository,
+	logger *logrus.Entry,
+) GetSubjectSessionsHandler {
+
+	if sessionRepo == nil {
+		panic("nil SessionRepo")
+	}
+
+	return decorator.WithQueryDecorators[GetSubjectSessions, []*session.Session](
+		getSubjectSessionsHandler{sessionRepo: sessionRepo},
+		logger,
+	)
+}
+
+func (h getSubjectSessionsHandler) Handle(ctx context.Context, getSubjectSessions GetSubjectSessions) ([]*session.Session, error) {
+
+	sessions, err := h.sessionRepo.GetSubjectSessions(ctx, getSubjectSessions.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("error when trying to get sessions of subject %s: %w", getSubjectSessions.Subject, err)
+	}
+	return sessions, nil
+}
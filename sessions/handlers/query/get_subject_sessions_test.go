@@ -0,0 +1,75 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jruben-rg/go-session-svc/sessions/domain/session"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestGetSubjectSessionsRepository struct {
+	session.Repository
+	err      error
+	sessions []*session.Session
+	subject  string
+}
+
+func (tgsr *TestGetSubjectSessionsRepository) GetSubjectSessions(ctx context.Context, subject string) ([]*session.Session, error) {
+	tgsr.subject = subject
+	return tgsr.sessions, tgsr.err
+}
+
+func TestGetSubjectSessionsHandlerShouldInvokeGetSubjectSessionsMethod(t *testing.T) {
+	t.Parallel()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+
+	tests := []struct {
+		scenario    string
+		repoErr     error
+		sessions    []*session.Session
+		expectedErr error
+	}{
+		{
+			scenario:    "Should return error if repository returns error",
+			repoErr:     session.ErrNotSupported,
+			expectedErr: session.ErrNotSupported,
+		},
+		{
+			scenario: "Should return the sessions of the subject",
+			sessions: []*session.Session{{ID: "someKey", Subject: "someUser"}},
+		},
+	}
+
+	for _, test := range tests {
+
+		repo := &TestGetSubjectSessionsRepository{err: test.repoErr, sessions: test.sessions}
+		handler := NewGetSubjectSessionsHandler(repo, logger)
+		sessions, err := handler.Handle(context.Background(), GetSubjectSessions{Subject: "someUser"})
+
+		if test.expectedErr != nil {
+			assert.ErrorIs(t, err, test.expectedErr, test.scenario)
+			assert.Nil(t, sessions, test.scenario)
+		} else {
+			assert.Nil(t, err, test.scenario)
+			assert.Equal(t, test.sessions, sessions, test.scenario)
+		}
+		assert.Equal(t, "someUser", repo.subject, test.scenario)
+	}
+}
+
+func TestGetSubjectSessionsHandlerShouldPanicIfNilRepo(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Handle method did not panic")
+		}
+	}()
+
+	logger := logrus.NewEntry(logrus.StandardLogger())
+	handler := NewGetSubjectSessionsHandler(nil, logger)
+	handler.Handle(context.Background(), GetSubjectSessions{})
+}